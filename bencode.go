@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Bencode is the wire format of nREPL messages. Decoded values are
+// int64, string, []interface{} and map[string]interface{}.
+
+func bdecode(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == 'i':
+		s, err := readUntil(r, 'e')
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case c == 'l':
+		res := []interface{}{}
+		for {
+			if c, err := r.ReadByte(); err != nil {
+				return nil, err
+			} else if c == 'e' {
+				return res, nil
+			}
+			r.UnreadByte()
+			v, err := bdecode(r)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+	case c == 'd':
+		res := map[string]interface{}{}
+		for {
+			if c, err := r.ReadByte(); err != nil {
+				return nil, err
+			} else if c == 'e' {
+				return res, nil
+			}
+			r.UnreadByte()
+			k, err := bdecode(r)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errors.New("bencode: dictionary key must be a string")
+			}
+			v, err := bdecode(r)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = v
+		}
+	case c >= '0' && c <= '9':
+		r.UnreadByte()
+		s, err := readUntil(r, ':')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf), nil
+	default:
+		return nil, fmt.Errorf("bencode: unexpected character %q", c)
+	}
+}
+
+func readUntil(r *bufio.Reader, delim byte) (string, error) {
+	s, err := r.ReadString(delim)
+	if err != nil {
+		return "", err
+	}
+	return s[:len(s)-1], nil
+}
+
+func bencode(w io.Writer, v interface{}) error {
+	var err error
+	switch v := v.(type) {
+	case int:
+		_, err = fmt.Fprintf(w, "i%de", v)
+	case int64:
+		_, err = fmt.Fprintf(w, "i%de", v)
+	case bool:
+		if v {
+			_, err = io.WriteString(w, "i1e")
+		} else {
+			_, err = io.WriteString(w, "i0e")
+		}
+	case string:
+		_, err = fmt.Fprintf(w, "%d:%s", len(v), v)
+	case []string:
+		l := make([]interface{}, len(v))
+		for i, s := range v {
+			l[i] = s
+		}
+		return bencode(w, l)
+	case []interface{}:
+		if _, err = io.WriteString(w, "l"); err != nil {
+			return err
+		}
+		for _, e := range v {
+			if err = bencode(w, e); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "e")
+	case map[string]interface{}:
+		if _, err = io.WriteString(w, "d"); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err = bencode(w, k); err != nil {
+				return err
+			}
+			if err = bencode(w, v[k]); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "e")
+	default:
+		err = fmt.Errorf("bencode: cannot encode %T", v)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/candid82/joker/core"
+)
+
+// bundle builds the executable output running the script in filename,
+// appending the script and the libs it loads to the Joker executable
+// runtime (or the running one if runtime is empty).
+// Returns false if the script can't be bundled.
+func bundle(rt *Runtime, filename string, runtime string, output string) bool {
+	b, err := BuildBundle(rt, filename)
+	if err != nil {
+		if ErrorHandler != nil {
+			ErrorHandler(err)
+		} else {
+			fmt.Fprintln(Stderr, err)
+		}
+		return false
+	}
+	if runtime == "" {
+		if runtime, err = os.Executable(); err != nil {
+			fmt.Fprintln(Stderr, "Error: ", err)
+			return false
+		}
+	}
+	if err := WriteBundle(b, runtime, output); err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+		return false
+	}
+	return true
+}
+
+// runBundle runs the script bundled with the running executable,
+// passing all the command line arguments to it.
+// Returns false if there is no bundled script.
+func runBundle(rt *Runtime) bool {
+	exe, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	b, err := ReadBundle(exe)
+	if err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+		ExitJoker(1)
+	}
+	if b == nil {
+		return false
+	}
+	ProcessCoreData()
+	GLOBAL_ENV.ReferCoreToUser()
+	GLOBAL_ENV.SetEnvArgs(os.Args[1:])
+	GLOBAL_ENV.SetClassPath(os.Getenv("JOKER_CLASSPATH"))
+	if err := b.Run(rt); err != nil {
+		ExitJoker(1)
+	}
+	return true
+}
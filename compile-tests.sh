@@ -0,0 +1,3 @@
+#!/usr/bin/env bash
+
+./joker tests/run-compile-tests.joke "$@"
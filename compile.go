@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+// dirLibs returns the names of the libs whose source files are in dir
+// (recursively), dir being a root of the classpath.
+func dirLibs(dir string) ([]string, bool) {
+	var libs []string
+	ok := true
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			fmt.Fprintln(Stderr, "Error: ", err)
+			ok = false
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".joke" || info.Name() == "data_readers.joke" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, strings.TrimSuffix(path, ".joke"))
+		if err != nil {
+			fmt.Fprintln(Stderr, "Error: ", err)
+			ok = false
+			return nil
+		}
+		libs = append(libs, strings.Join(strings.Split(rel, string(filepath.Separator)), "."))
+		return nil
+	})
+	return libs, ok
+}
+
+// compileLibs compiles the libs named by targets into packed .jokec files,
+// which are written to outDir or, if it's empty, next to the source files.
+// A target that is a directory stands for all the libs in it and is added
+// to the classpath. Returns false if any of the libs can't be compiled.
+func compileLibs(rt *Runtime, targets []string, outDir string) bool {
+	var libs []string
+	cp := classPath
+	for _, target := range targets {
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			dl, ok := dirLibs(target)
+			if !ok {
+				return false
+			}
+			libs = append(libs, dl...)
+			cp = target + string(filepath.ListSeparator) + cp
+			continue
+		}
+		libs = append(libs, target)
+	}
+	GLOBAL_ENV.SetClassPath(cp)
+	compilePath := "nil"
+	if outDir != "" {
+		compilePath = MakeString(outDir).ToString(true)
+	}
+	code := fmt.Sprintf("(binding [*compile-path* %s] (doseq [lib '[%s]] (when-not (contains? (loaded-libs) lib) (compile lib))))",
+		compilePath, strings.Join(libs, " "))
+	return ProcessReader(rt, NewReader(strings.NewReader(code), "<compile>"), "", EVAL) == nil
+}
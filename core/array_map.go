@@ -277,7 +277,7 @@ func (m *ArrayMap) Seq() Seq {
 	return &ArrayMapSeq{m: m, index: 0}
 }
 
-func (m *ArrayMap) Call(args []Object) Object {
+func (m *ArrayMap) Call(rt *Runtime, args []Object) Object {
 	return callMap(m, args)
 }
 
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"fmt"
+)
+
+// AssertBinary returns the content of String or Bytes obj as a byte slice.
+func AssertBinary(obj Object, msg string) []byte {
+	switch c := obj.(type) {
+	case String:
+		return []byte(c.S)
+	case *Bytes:
+		return c.B
+	default:
+		if msg == "" {
+			msg = fmt.Sprintf("Expected %s, got %s", "String or Bytes", obj.GetType().ToString(false))
+		}
+		panic(RT.NewError(msg))
+	}
+}
+
+func EnsureBinary(args []Object, index int) []byte {
+	switch c := args[index].(type) {
+	case String:
+		return []byte(c.S)
+	case *Bytes:
+		return c.B
+	default:
+		panic(RT.NewArgTypeError(index, c, "String or Bytes"))
+	}
+}
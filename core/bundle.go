@@ -0,0 +1,260 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// Bundle is a script packed together with the libs it loads,
+	// to be appended to a Joker executable by joker --bundle.
+	// Running the executable evaluates the script and calls its main function.
+	Bundle struct {
+		Ns       string // namespace of the script, which defines main
+		Filename string
+		Code     []byte
+		libs     []bundledLib
+	}
+
+	bundledLib struct {
+		name     string
+		filename string
+		code     []byte
+	}
+)
+
+// bundleMagic ends the executables with a bundle appended.
+// It's preceded by the size of the bundle.
+const bundleMagic = "\x00JOKER-BUNDLE\n"
+
+var (
+	buildingBundle *Bundle // collects the libs loaded while building a bundle
+	runningBundle  *Bundle // provides the libs loaded while running a bundle
+)
+
+func appendBytes(p []byte, b []byte) []byte {
+	p = appendInt(p, len(b))
+	return append(p, b...)
+}
+
+func extractBundleInt(p []byte) (int, []byte) {
+	if len(p) < 8 {
+		panic(RT.NewError("Corrupted bundle"))
+	}
+	return extractInt(p)
+}
+
+func extractBytes(p []byte) ([]byte, []byte) {
+	size, p := extractBundleInt(p)
+	if size < 0 || size > len(p) {
+		panic(RT.NewError("Corrupted bundle"))
+	}
+	return p[:size], p[size:]
+}
+
+func (b *Bundle) addLib(libname string, filename string, code []byte) {
+	b.libs = append(b.libs, bundledLib{name: libname, filename: filename, code: code})
+}
+
+func (b *Bundle) findLib(libname string) *bundledLib {
+	for i := range b.libs {
+		if b.libs[i].name == libname {
+			return &b.libs[i]
+		}
+	}
+	return nil
+}
+
+// bundledLibFilename returns the name of the source file
+// lib was bundled from, if the running bundle has it.
+func bundledLibFilename(libname string) (string, bool) {
+	if runningBundle == nil {
+		return "", false
+	}
+	if lib := runningBundle.findLib(libname); lib != nil {
+		return lib.filename, true
+	}
+	return "", false
+}
+
+// BuildBundle evaluates the script in filename, packing it together
+// with the libs it loads (found via *classpath* and *ns-sources*).
+// The script must define a main function in its namespace.
+func BuildBundle(rt *Runtime, filename string) (b *Bundle, err error) {
+	saved := rt.Save()
+	defer func() {
+		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
+			buildingBundle = nil
+			switch r := r.(type) {
+			case Error:
+				err = r
+			default:
+				panic(r)
+			}
+		}
+	}()
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := filepath.Abs(filename)
+	PanicOnErr(err)
+	GLOBAL_ENV.SetMainFilename(f)
+	b = &Bundle{Filename: f}
+	buildingBundle = b
+	b.Code = compileReader(rt, NewReader(bytes.NewReader(source), filename), filename)
+	buildingBundle = nil
+	ns := GLOBAL_ENV.CurrentNamespace(rt)
+	if ns.Resolve("main") == nil {
+		return nil, errors.New(filename + " must define function main in namespace " + ns.Name.Name())
+	}
+	b.Ns = ns.Name.Name()
+	return b, nil
+}
+
+// Pack returns the bundle in the form appended to executables.
+func (b *Bundle) Pack() []byte {
+	var p []byte
+	p = appendBytes(p, []byte(VERSION))
+	p = appendBytes(p, []byte(b.Ns))
+	p = appendBytes(p, []byte(b.Filename))
+	p = appendBytes(p, b.Code)
+	p = appendInt(p, len(b.libs))
+	for _, lib := range b.libs {
+		p = appendBytes(p, []byte(lib.name))
+		p = appendBytes(p, []byte(lib.filename))
+		p = appendBytes(p, lib.code)
+	}
+	p = appendInt(p, len(p))
+	return append(p, bundleMagic...)
+}
+
+func unpackBundle(p []byte) *Bundle {
+	var version, ns, filename []byte
+	version, p = extractBytes(p)
+	if string(version) != VERSION {
+		panic(RT.NewError("Bundle was built by Joker " + string(version) + ", which differs from the runtime's version " + VERSION))
+	}
+	b := &Bundle{}
+	ns, p = extractBytes(p)
+	filename, p = extractBytes(p)
+	b.Ns, b.Filename = string(ns), string(filename)
+	b.Code, p = extractBytes(p)
+	count, p := extractBundleInt(p)
+	for i := 0; i < count; i++ {
+		var name, filename, code []byte
+		name, p = extractBytes(p)
+		filename, p = extractBytes(p)
+		code, p = extractBytes(p)
+		b.addLib(string(name), string(filename), code)
+	}
+	return b
+}
+
+// bundleSize returns the size of the bundle (including trailer)
+// ending with trailer, or 0 if trailer doesn't end a bundle.
+func bundleSize(trailer []byte) int {
+	if !bytes.HasSuffix(trailer, []byte(bundleMagic)) || len(trailer) < 8+len(bundleMagic) {
+		return 0
+	}
+	size, _ := extractInt(trailer[len(trailer)-len(bundleMagic)-8:])
+	return size + 8 + len(bundleMagic)
+}
+
+// ReadBundle reads the bundle appended to the executable in filename.
+// Returns nil if there is none.
+func ReadBundle(filename string) (b *Bundle, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case Error:
+				err = r
+			default:
+				panic(r)
+			}
+		}
+	}()
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	trailer := make([]byte, 8+len(bundleMagic))
+	if info.Size() < int64(len(trailer)) {
+		return nil, nil
+	}
+	if _, err := f.ReadAt(trailer, info.Size()-int64(len(trailer))); err != nil {
+		return nil, err
+	}
+	size := bundleSize(trailer)
+	if size == 0 {
+		return nil, nil
+	}
+	if size < len(trailer) || int64(size) > info.Size() {
+		return nil, errors.New("Corrupted bundle in " + filename)
+	}
+	p := make([]byte, size-len(trailer))
+	if _, err := f.ReadAt(p, info.Size()-int64(size)); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return unpackBundle(p), nil
+}
+
+// WriteBundle writes the executable runtime with b appended to it to filename.
+// A bundle already appended to runtime is replaced.
+func WriteBundle(b *Bundle, runtime string, filename string) error {
+	exe, err := ioutil.ReadFile(runtime)
+	if err != nil {
+		return err
+	}
+	if trailerSize := 8 + len(bundleMagic); len(exe) >= trailerSize {
+		if size := bundleSize(exe[len(exe)-trailerSize:]); size != 0 {
+			if size < trailerSize || size > len(exe) {
+				return errors.New("Corrupted bundle in " + runtime)
+			}
+			exe = exe[:len(exe)-size]
+		}
+	}
+	return ioutil.WriteFile(filename, append(exe, b.Pack()...), 0755)
+}
+
+// Run evaluates the bundled script and calls its main function with
+// the elements of *command-line-args* as arguments.
+// The libs the script loads are taken from the bundle when it has them.
+func (b *Bundle) Run(rt *Runtime) error {
+	runningBundle = b
+	GLOBAL_ENV.SetMainFilename(b.Filename)
+	if err := tryEvalPacked(rt, b.Code, b.Filename); err != nil {
+		reportError(err)
+		return err
+	}
+	main := "(apply " + b.Ns + "/main *command-line-args*)"
+	return ProcessReader(rt, NewReader(strings.NewReader(main), "<main>"), "", EVAL)
+}
+
+func tryEvalPacked(rt *Runtime, code []byte, filename string) (err error) {
+	saved := rt.Save()
+	defer func() {
+		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
+			switch r := r.(type) {
+			case Error:
+				err = r
+			default:
+				panic(r)
+			}
+		}
+	}()
+	evalPacked(rt, code, filename)
+	return nil
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRuntime writes a fake runtime consisting of exe followed by
+// a bundle trailer declaring the given size.
+func writeRuntime(t *testing.T, exe string, size int) string {
+	t.Helper()
+	p := appendInt([]byte(exe), size)
+	filename := filepath.Join(t.TempDir(), "runtime")
+	if err := os.WriteFile(filename, append(p, bundleMagic...), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestWriteAndReadBundle(t *testing.T) {
+	runtime := filepath.Join(t.TempDir(), "runtime")
+	if err := os.WriteFile(runtime, []byte("runtime"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	b := &Bundle{Ns: "tool", Filename: "/src/tool.joke", Code: []byte("code")}
+	b.addLib("tool.lib", "/src/tool/lib.joke", []byte("lib code"))
+	exe := filepath.Join(t.TempDir(), "tool")
+	if err := WriteBundle(b, runtime, exe); err != nil {
+		t.Fatal(err)
+	}
+	// Bundling with the executable as runtime replaces the bundle.
+	b.Code = []byte("new code")
+	if err := WriteBundle(b, exe, exe); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(content, []byte("runtime")) || bytes.Count(content, []byte(bundleMagic)) != 1 {
+		t.Errorf("Unexpected executable %q", content)
+	}
+	res, err := ReadBundle(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Ns != b.Ns || res.Filename != b.Filename || string(res.Code) != "new code" {
+		t.Errorf("Unexpected bundle %+v", res)
+	}
+	if lib := res.findLib("tool.lib"); lib == nil || string(lib.code) != "lib code" {
+		t.Errorf("Unexpected lib %+v", lib)
+	}
+	if res, err := ReadBundle(runtime); res != nil || err != nil {
+		t.Errorf("Expected no bundle in runtime, got %+v, %v", res, err)
+	}
+}
+
+func TestCorruptedBundle(t *testing.T) {
+	// Declared sizes not fitting the file.
+	for _, size := range []int{1000, -1, 0x7fffffffffffffff} {
+		runtime := writeRuntime(t, "runtime", size)
+		err := WriteBundle(&Bundle{}, runtime, filepath.Join(t.TempDir(), "tool"))
+		if err == nil || !strings.Contains(err.Error(), "Corrupted bundle") {
+			t.Errorf("Expected corrupted bundle error writing bundle of size %d, got %v", size, err)
+		}
+		if _, err := ReadBundle(runtime); err == nil || !strings.Contains(err.Error(), "Corrupted bundle") {
+			t.Errorf("Expected corrupted bundle error reading bundle of size %d, got %v", size, err)
+		}
+	}
+	// Bundle too short for its content.
+	if _, err := ReadBundle(writeRuntime(t, "runtime", 2)); err == nil || !strings.Contains(err.Error(), "Corrupted bundle") {
+		t.Errorf("Expected corrupted bundle error, got %v", err)
+	}
+}
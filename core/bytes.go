@@ -0,0 +1,96 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+type (
+	// Bytes is an immutable byte array. B must not be modified
+	// once the value is created.
+	Bytes struct {
+		B []byte
+	}
+)
+
+const bytesHashMask uint32 = 0x3c6ef372
+
+func MakeBytes(b []byte) *Bytes {
+	return &Bytes{B: b}
+}
+
+// ParseBytesLiteral returns Bytes represented by the hexadecimal string
+// of #bytes literal.
+func ParseBytesLiteral(s string) (*Bytes, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid #bytes literal: %s", err.Error())
+	}
+	return MakeBytes(b), nil
+}
+
+func (b *Bytes) ToString(escape bool) string {
+	if escape {
+		return "#bytes \"" + hex.EncodeToString(b.B) + "\""
+	}
+	return string(b.B)
+}
+
+func (b *Bytes) Equals(other interface{}) bool {
+	switch other := other.(type) {
+	case *Bytes:
+		return bytes.Equal(b.B, other.B)
+	default:
+		return false
+	}
+}
+
+func (b *Bytes) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (b *Bytes) GetType() *Type {
+	return TYPE.Bytes
+}
+
+func (b *Bytes) Hash() uint32 {
+	h := getHash()
+	h.Write(b.B)
+	return h.Sum32() ^ bytesHashMask
+}
+
+func (b *Bytes) WithInfo(info *ObjectInfo) Object {
+	return b
+}
+
+func (b *Bytes) Count() int {
+	return len(b.B)
+}
+
+func (b *Bytes) Seq() Seq {
+	ints := make([]Object, len(b.B))
+	for i, c := range b.B {
+		ints[i] = Int{I: int(c)}
+	}
+	return &ArraySeq{arr: ints}
+}
+
+func (b *Bytes) Nth(i int) Object {
+	if i < 0 || i >= len(b.B) {
+		panic(RT.NewError(fmt.Sprintf("Index %d is out of bounds [0..%d]", i, len(b.B)-1)))
+	}
+	return Int{I: int(b.B[i])}
+}
+
+func (b *Bytes) TryNth(i int, d Object) Object {
+	if i < 0 || i >= len(b.B) {
+		return d
+	}
+	return Int{I: int(b.B[i])}
+}
+
+func (b *Bytes) Compare(other Object) int {
+	b2 := AssertBytes(other, "Cannot compare Bytes and "+other.GetType().ToString(false))
+	return bytes.Compare(b.B, b2.B)
+}
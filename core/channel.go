@@ -1,6 +1,10 @@
 package core
 
 import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -9,17 +13,73 @@ type (
 		value Object
 		err   Error
 	}
+	BufferPolicy  int
+	ChannelBuffer struct {
+		size   int
+		policy BufferPolicy
+		hash   uint32
+	}
 	Channel struct {
 		ch       chan FutureResult
 		isClosed bool
+		policy   BufferPolicy
 		hash     uint32
+		mu       sync.Mutex // guards isClosed
+		putMu    sync.Mutex // serializes puts to sliding buffers
+	}
+	altOp struct {
+		ch    *Channel
+		val   Object
+		isPut bool
 	}
 )
 
+const (
+	FIXED_BUFFER BufferPolicy = iota
+	SLIDING_BUFFER
+	DROPPING_BUFFER
+)
+
 func MakeFutureResult(value Object, err Error) FutureResult {
 	return FutureResult{value: value, err: err}
 }
 
+func MakeChannelBuffer(size int, policy BufferPolicy) *ChannelBuffer {
+	if size < 0 {
+		panic(RT.NewError("Buffer size must be non-negative"))
+	}
+	if size == 0 && policy != FIXED_BUFFER {
+		panic(RT.NewError("Sliding and dropping buffers must have positive size"))
+	}
+	res := &ChannelBuffer{size: size, policy: policy}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (b *ChannelBuffer) ToString(escape bool) string {
+	return "#object[ChannelBuffer]"
+}
+
+func (b *ChannelBuffer) Equals(other interface{}) bool {
+	return b == other
+}
+
+func (b *ChannelBuffer) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (b *ChannelBuffer) GetType() *Type {
+	return TYPE.ChannelBuffer
+}
+
+func (b *ChannelBuffer) Hash() uint32 {
+	return b.hash
+}
+
+func (b *ChannelBuffer) WithInfo(info *ObjectInfo) Object {
+	return b
+}
+
 func (ch *Channel) ToString(escape bool) string {
 	return "#object[Channel]"
 }
@@ -50,13 +110,274 @@ func MakeChannel(ch chan FutureResult) *Channel {
 	return res
 }
 
+func MakeBufferedChannel(buf *ChannelBuffer) *Channel {
+	res := MakeChannel(make(chan FutureResult, buf.size))
+	res.policy = buf.policy
+	return res
+}
+
+// MakeTimeoutChannel returns a channel that closes after d.
+func MakeTimeoutChannel(d time.Duration) *Channel {
+	res := MakeChannel(make(chan FutureResult))
+	time.AfterFunc(d, res.Close)
+	return res
+}
+
 func ExtractChannel(args []Object, index int) *Channel {
 	return EnsureChannel(args, index)
 }
 
 func (ch *Channel) Close() {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 	if !ch.isClosed {
 		close(ch.ch)
 		ch.isClosed = true
 	}
 }
+
+func (ch *Channel) IsClosed() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.isClosed
+}
+
+// trySend puts v on ch if it can be done without blocking.
+// Puts to sliding and dropping buffers never block.
+// Panics if ch is closed.
+func (ch *Channel) trySend(v FutureResult) bool {
+	switch ch.policy {
+	case SLIDING_BUFFER:
+		ch.putMu.Lock()
+		defer ch.putMu.Unlock()
+		for {
+			select {
+			case ch.ch <- v:
+				return true
+			default:
+			}
+			// Make room by dropping the oldest value.
+			select {
+			case <-ch.ch:
+			default:
+			}
+		}
+	case DROPPING_BUFFER:
+		select {
+		case ch.ch <- v:
+		default:
+		}
+		return true
+	default:
+		select {
+		case ch.ch <- v:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Put puts v on ch, blocking until there is room for it.
+// Returns false if ch is closed.
+func (ch *Channel) Put(rt *Runtime, v Object) (ok bool) {
+	if ch.IsClosed() {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if err, isErr := r.(Error); isErr {
+				panic(err)
+			}
+			ok = false
+		}
+	}()
+	r := MakeFutureResult(v, nil)
+	if ch.policy != FIXED_BUFFER {
+		return ch.trySend(r)
+	}
+	select {
+	case ch.ch <- r:
+	case <-rt.interrupts():
+		rt.throwInterrupted()
+	}
+	return true
+}
+
+// Offer puts v on ch if it can be done without blocking.
+// Returns FALSE if ch is closed and NIL if the put would block.
+func (ch *Channel) Offer(v Object) (res Object) {
+	if ch.IsClosed() {
+		return Boolean{B: false}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			res = Boolean{B: false}
+		}
+	}()
+	if ch.trySend(MakeFutureResult(v, nil)) {
+		return Boolean{B: true}
+	}
+	return NIL
+}
+
+func (r FutureResult) valueOrPanic() Object {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.value
+}
+
+// Take takes a value from ch, blocking until one is available.
+// Returns NIL if ch is closed and empty.
+func (ch *Channel) Take(rt *Runtime) Object {
+	select {
+	case res, ok := <-ch.ch:
+		if !ok {
+			return NIL
+		}
+		return res.valueOrPanic()
+	case <-rt.interrupts():
+		rt.throwInterrupted()
+		return nil
+	}
+}
+
+// Poll takes a value from ch if it can be done without blocking.
+func (ch *Channel) Poll() Object {
+	select {
+	case res, ok := <-ch.ch:
+		if !ok {
+			return NIL
+		}
+		return res.valueOrPanic()
+	default:
+		return NIL
+	}
+}
+
+func (op *altOp) result(val Object) Object {
+	return NewVectorFrom(val, op.ch)
+}
+
+// try attempts to complete op without blocking.
+func (op *altOp) try() (res Object, ok bool) {
+	if !op.isPut {
+		select {
+		case r, open := <-op.ch.ch:
+			if !open {
+				return op.result(NIL), true
+			}
+			return op.result(r.valueOrPanic()), true
+		default:
+			return nil, false
+		}
+	}
+	if op.ch.IsClosed() {
+		return op.result(Boolean{B: false}), true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			res, ok = op.result(Boolean{B: false}), true
+		}
+	}()
+	if op.ch.trySend(MakeFutureResult(op.val, nil)) {
+		return op.result(Boolean{B: true}), true
+	}
+	return nil, false
+}
+
+func parseAltOps(ports Seqable) []altOp {
+	var ops []altOp
+	for s := ports.Seq(); !s.IsEmpty(); s = s.Rest() {
+		switch p := s.First().(type) {
+		case *Channel:
+			ops = append(ops, altOp{ch: p})
+		case *Vector:
+			if p.Count() != 2 {
+				panic(RT.NewError("Put operation must be a vector of channel and value, got " + p.ToString(true)))
+			}
+			val := p.at(1)
+			if val.Equals(NIL) {
+				panic(RT.NewError("Can't put nil on channel"))
+			}
+			ops = append(ops, altOp{ch: AssertChannel(p.at(0), ""), val: val, isPut: true})
+		default:
+			panic(RT.NewError("Port must be a channel or a vector of channel and value, got " + p.GetType().ToString(false)))
+		}
+	}
+	if len(ops) == 0 {
+		panic(RT.NewError("alts! requires at least one port"))
+	}
+	return ops
+}
+
+// Alts completes at most one of the channel operations described by ports
+// and returns a vector of the result and the channel of the operation.
+// Unless priority is true, ready operations are picked at random.
+// If no operation is ready and defaultValue is not nil,
+// returns defaultValue and :default instead of blocking.
+func Alts(rt *Runtime, ports Seqable, priority bool, defaultValue Object) Object {
+	ops := parseAltOps(ports)
+	if !priority {
+		rand.Shuffle(len(ops), func(i, j int) { ops[i], ops[j] = ops[j], ops[i] })
+	}
+	for i := range ops {
+		if res, ok := ops[i].try(); ok {
+			return res
+		}
+	}
+	if defaultValue != nil {
+		return NewVectorFrom(defaultValue, MakeKeyword("default"))
+	}
+	return selectOps(rt, ops)
+}
+
+func selectOps(rt *Runtime, ops []altOp) (res Object) {
+	cases := make([]reflect.SelectCase, len(ops), len(ops)+1)
+	for i, op := range ops {
+		if op.isPut {
+			cases[i] = reflect.SelectCase{
+				Dir:  reflect.SelectSend,
+				Chan: reflect.ValueOf(op.ch.ch),
+				Send: reflect.ValueOf(MakeFutureResult(op.val, nil)),
+			}
+		} else {
+			cases[i] = reflect.SelectCase{
+				Dir:  reflect.SelectRecv,
+				Chan: reflect.ValueOf(op.ch.ch),
+			}
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(Error); ok {
+				panic(err)
+			}
+			// One of the channels was closed while we were waiting to put on it.
+			for i := range ops {
+				if ops[i].isPut && ops[i].ch.IsClosed() {
+					res = ops[i].result(Boolean{B: false})
+					return
+				}
+			}
+			panic(r)
+		}
+	}()
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(rt.interrupts()),
+	})
+	chosen, recv, recvOK := reflect.Select(cases)
+	if chosen == len(ops) {
+		rt.throwInterrupted()
+	}
+	op := &ops[chosen]
+	if op.isPut {
+		return op.result(Boolean{B: true})
+	}
+	if !recvOK {
+		return op.result(NIL)
+	}
+	return op.result(recv.Interface().(FutureResult).valueOrPanic())
+}
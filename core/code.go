@@ -323,14 +323,6 @@ func UniqueId(obj interface{}) (id string) {
 // Joker code needs.  (These could be put into object.go, parse.go,
 // ns.go, etc., as appropriate, if desired.)
 
-func (v *Var) Expr() Expr {
-	return v.expr
-}
-
-func (v Var) Namespace() *Namespace {
-	return v.ns
-}
-
 func (v *VarRefExpr) Var() *Var {
 	return v.vr
 }
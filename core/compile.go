@@ -0,0 +1,285 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// PackedFileExt is the extension of the files libs are compiled into.
+	PackedFileExt = ".jokec"
+
+	packedFileMagic = "JOKEC\n"
+)
+
+type (
+	// libSource describes the source file a lib was loaded from.
+	libSource struct {
+		filename string
+		hash     [sha256.Size]byte
+		deps     []string // libs the lib requires
+	}
+	// packedDep is a lib whose source a packed file depends on,
+	// e.g. because it was compiled with the lib's macros.
+	packedDep struct {
+		name     string
+		filename string
+		hash     [sha256.Size]byte
+	}
+)
+
+var (
+	libSourcesMu sync.Mutex
+	libSources   = map[string]*libSource{} // libs loaded from files, by name
+	libPaths     = map[string]string{}     // names of the libs, by the paths they are loaded by
+)
+
+// noteLibSource records that lib is loaded (by pathname) from source read from filename.
+func noteLibSource(libname string, pathname string, filename string, source []byte) {
+	libSourcesMu.Lock()
+	defer libSourcesMu.Unlock()
+	libSources[libname] = &libSource{filename: filename, hash: sha256.Sum256(source)}
+	libPaths[pathname] = libname
+}
+
+// noteLibRequired records that the lib loaded by path requires lib.
+func noteLibRequired(path string, libname string) {
+	libSourcesMu.Lock()
+	defer libSourcesMu.Unlock()
+	src := libSources[libPaths[path]]
+	if src == nil {
+		return
+	}
+	for _, dep := range src.deps {
+		if dep == libname {
+			return
+		}
+	}
+	src.deps = append(src.deps, libname)
+}
+
+// packedDeps returns the libs loaded from files lib requires,
+// directly or indirectly.
+func packedDeps(libname string) []packedDep {
+	libSourcesMu.Lock()
+	defer libSourcesMu.Unlock()
+	var res []packedDep
+	seen := map[string]bool{libname: true}
+	var visit func(name string)
+	visit = func(name string) {
+		for _, dep := range libSources[name].deps {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			if src := libSources[dep]; src != nil {
+				res = append(res, packedDep{name: dep, filename: src.filename, hash: src.hash})
+				visit(dep)
+			}
+		}
+	}
+	if libSources[libname] != nil {
+		visit(libname)
+	}
+	return res
+}
+
+// isUpToDate reports whether the source of dep hasn't changed since
+// the packed file depending on it was compiled. The source is the one
+// dep is loaded from, or, if it's not loaded yet, the one it was loaded
+// from at compile time.
+func (dep *packedDep) isUpToDate() bool {
+	libSourcesMu.Lock()
+	src := libSources[dep.name]
+	libSourcesMu.Unlock()
+	if src != nil {
+		return src.hash == dep.hash
+	}
+	source, err := ioutil.ReadFile(dep.filename)
+	return err == nil && sha256.Sum256(source) == dep.hash
+}
+
+// packedFileHeader returns the header of the packed file compiled
+// from source by this version of Joker.
+func packedFileHeader(source []byte) []byte {
+	sum := sha256.Sum256(source)
+	p := []byte(packedFileMagic)
+	p = appendInt(p, len(VERSION))
+	p = append(p, VERSION...)
+	return append(p, sum[:]...)
+}
+
+func appendPackedDeps(p []byte, deps []packedDep) []byte {
+	p = appendInt(p, len(deps))
+	for _, dep := range deps {
+		p = appendBytes(p, []byte(dep.name))
+		p = appendBytes(p, []byte(dep.filename))
+		p = append(p, dep.hash[:]...)
+	}
+	return p
+}
+
+// extractPackedBytes is like extractBytes, but returns false
+// instead of panicking if p is too short.
+func extractPackedBytes(p []byte) ([]byte, []byte, bool) {
+	if len(p) < 8 {
+		return nil, nil, false
+	}
+	size, p := extractInt(p)
+	if size < 0 || size > len(p) {
+		return nil, nil, false
+	}
+	return p[:size], p[size:], true
+}
+
+func extractPackedDeps(p []byte) ([]packedDep, []byte, bool) {
+	if len(p) < 8 {
+		return nil, nil, false
+	}
+	count, p := extractInt(p)
+	if count < 0 || count > len(p) {
+		return nil, nil, false
+	}
+	deps := make([]packedDep, count)
+	for i := range deps {
+		var name, filename []byte
+		var ok bool
+		if name, p, ok = extractPackedBytes(p); !ok {
+			return nil, nil, false
+		}
+		if filename, p, ok = extractPackedBytes(p); !ok || len(p) < sha256.Size {
+			return nil, nil, false
+		}
+		deps[i].name, deps[i].filename = string(name), string(filename)
+		p = p[copy(deps[i].hash[:], p):]
+	}
+	return deps, p, true
+}
+
+// packedCode returns the packed code from data, the content of a packed file,
+// if the file is up to date, i.e. was compiled from source by this version of Joker
+// and the sources of the libs it depends on haven't changed since.
+func packedCode(data []byte, source []byte) ([]byte, bool) {
+	header := packedFileHeader(source)
+	if !bytes.HasPrefix(data, header) {
+		return nil, false
+	}
+	deps, code, ok := extractPackedDeps(data[len(header):])
+	if !ok {
+		return nil, false
+	}
+	for i := range deps {
+		if !deps[i].isUpToDate() {
+			return nil, false
+		}
+	}
+	return code, true
+}
+
+func libPath(libname string) string {
+	return filepath.Join(strings.Split(libname, ".")...)
+}
+
+// packedFilename returns the name of the file lib loaded from
+// filename is compiled into.
+func packedFilename(rt *Runtime, libname string, filename string) string {
+	if dir, ok := GLOBAL_ENV.CoreNamespace.Resolve("*compile-path*").Resolve(rt).(String); ok {
+		return filepath.Join(dir.S, libPath(libname)) + PackedFileExt
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + PackedFileExt
+}
+
+// findPackedCode looks for an up-to-date packed file of lib loaded
+// from filename, first next to filename and then in the roots of classpath.
+func findPackedCode(libname string, filename string, source []byte, classpath *Vector) (string, []byte) {
+	candidates := []string{strings.TrimSuffix(filename, filepath.Ext(filename)) + PackedFileExt}
+	for i := 0; i < classpath.Count(); i++ {
+		if root, ok := classpath.at(i).(String); ok && root.S != "" {
+			candidates = append(candidates, filepath.Join(root.S, libPath(libname))+PackedFileExt)
+		}
+	}
+	for _, packedFile := range candidates {
+		data, err := ioutil.ReadFile(packedFile)
+		if err != nil {
+			continue
+		}
+		if code, ok := packedCode(data, source); ok {
+			return packedFile, code
+		}
+	}
+	return "", nil
+}
+
+func isCompiling(rt *Runtime) bool {
+	return ToBool(GLOBAL_ENV.CoreNamespace.Resolve("*compile-files*").Resolve(rt))
+}
+
+// compileReader evaluates the code from reader, as ProcessReaderFromEval does,
+// and returns it packed.
+func compileReader(rt *Runtime, reader *Reader, filename string) []byte {
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
+	if filename != "" {
+		currentFilename := parseContext.GlobalEnv.file.root()
+		defer func() {
+			parseContext.GlobalEnv.SetFilename(currentFilename)
+		}()
+		s, err := filepath.Abs(filename)
+		PanicOnErr(err)
+		parseContext.GlobalEnv.SetFilename(MakeString(s))
+	}
+	packEnv := NewPackEnv()
+	packEnv.rt = rt
+	packEnv.checkReadable = true
+	var p []byte
+	for {
+		obj, err := TryRead(rt, reader)
+		if err == io.EOF {
+			return append(packEnv.Pack(nil), p...)
+		}
+		PanicOnErr(err)
+		expr, err := TryParse(obj, parseContext)
+		PanicOnErr(err)
+		p = expr.Pack(p, packEnv)
+		_, err = rt.TryEval(expr)
+		PanicOnErr(err)
+	}
+}
+
+// compileLib loads lib from source, which was read from filename,
+// and writes the packed file for it.
+func compileLib(rt *Runtime, libname string, filename string, source []byte) string {
+	code := compileReader(rt, NewReader(bytes.NewReader(source), filename), filename)
+	packedFile := packedFilename(rt, libname, filename)
+	PanicOnErr(os.MkdirAll(filepath.Dir(packedFile), 0777))
+	header := appendPackedDeps(packedFileHeader(source), packedDeps(libname))
+	PanicOnErr(ioutil.WriteFile(packedFile, append(header, code...), 0666))
+	if VerbosityLevel > 0 {
+		fmt.Fprintf(Stderr, "compileLib: Compiled %s into %s\n", libname, packedFile)
+	}
+	return packedFile
+}
+
+// evalPacked evaluates the packed code of a lib loaded from filename.
+func evalPacked(rt *Runtime, code []byte, filename string) {
+	currentFilename := GLOBAL_ENV.file.root()
+	defer func() {
+		GLOBAL_ENV.SetFilename(currentFilename)
+	}()
+	s, err := filepath.Abs(filename)
+	PanicOnErr(err)
+	GLOBAL_ENV.SetFilename(MakeString(s))
+	header, p := UnpackHeader(rt, code, GLOBAL_ENV)
+	for len(p) > 0 {
+		var expr Expr
+		expr, p = UnpackExpr(p, header)
+		_, err := rt.TryEval(expr)
+		PanicOnErr(err)
+	}
+}
@@ -0,0 +1,452 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// Debugger is notified when evaluation pauses. Paused is called
+	// on the goroutine that paused, and evaluation resumes according
+	// to the returned action once it returns.
+	Debugger interface {
+		Paused(ev *DebugEvent) DebugAction
+	}
+	DebugAction int
+	DebugEvent  struct {
+		// Reason is one of "breakpoint", "step", "pause" or "break"
+		// (the latter for calls to joker.debug/break!).
+		Reason string
+		// Frames are the frames of the paused goroutine, innermost first.
+		Frames  []*DebugFrame
+		Runtime *Runtime
+	}
+	DebugFrame struct {
+		Name string
+		Pos  Position
+		env  *LocalEnv
+		rt   *Runtime
+	}
+	DebugLocal struct {
+		Name  string
+		Value Object
+	}
+)
+
+const (
+	DEBUG_CONTINUE DebugAction = iota
+	DEBUG_STEP_IN
+	DEBUG_STEP_OVER
+	DEBUG_STEP_OUT
+	DEBUG_ABORT
+)
+
+var (
+	// debugging is non-zero once a debugger is installed.
+	// It is checked by Eval before doing any debugger work.
+	debugging      int32
+	pauseRequested int32
+	debugger       Debugger
+	// pauseMutex makes goroutines pause one at a time.
+	pauseMutex sync.Mutex
+
+	breakpointsMutex sync.RWMutex
+	// breakpoints maps absolute filenames to sets of line numbers.
+	breakpoints = map[string]map[int]bool{}
+	// absFilenames caches absolute filenames of positions.
+	absFilenames = map[*string]string{}
+
+	gensymLocalRegex = regexp.MustCompile(`__\d+(__auto__)?$`)
+)
+
+// SetDebugger installs d as the debugger. Evaluation checks for
+// breakpoints and steps only after a debugger is installed.
+func SetDebugger(d Debugger) {
+	pauseMutex.Lock()
+	defer pauseMutex.Unlock()
+	debugger = d
+	if d != nil {
+		atomic.StoreInt32(&debugging, 1)
+	} else {
+		atomic.StoreInt32(&debugging, 0)
+	}
+}
+
+// UseTerminalDebugger installs the debugger that interacts
+// with the user via stdin and stderr, unless another debugger
+// is already installed.
+func UseTerminalDebugger() {
+	pauseMutex.Lock()
+	installed := debugger != nil
+	pauseMutex.Unlock()
+	if !installed {
+		SetDebugger(&terminalDebugger{})
+	}
+}
+
+func absFilename(filename string) string {
+	if f, err := filepath.Abs(filename); err == nil {
+		return f
+	}
+	return filename
+}
+
+func SetBreakpoint(filename string, line int) {
+	breakpointsMutex.Lock()
+	defer breakpointsMutex.Unlock()
+	filename = absFilename(filename)
+	if breakpoints[filename] == nil {
+		breakpoints[filename] = map[int]bool{}
+	}
+	breakpoints[filename][line] = true
+}
+
+// SetFileBreakpoints replaces breakpoints in the file with the given lines.
+func SetFileBreakpoints(filename string, lines []int) {
+	breakpointsMutex.Lock()
+	defer breakpointsMutex.Unlock()
+	filename = absFilename(filename)
+	delete(breakpoints, filename)
+	for _, line := range lines {
+		if breakpoints[filename] == nil {
+			breakpoints[filename] = map[int]bool{}
+		}
+		breakpoints[filename][line] = true
+	}
+}
+
+// ClearBreakpoints removes breakpoints in the file, or all
+// breakpoints if filename is empty.
+func ClearBreakpoints(filename string) {
+	breakpointsMutex.Lock()
+	defer breakpointsMutex.Unlock()
+	if filename == "" {
+		breakpoints = map[string]map[int]bool{}
+	} else {
+		delete(breakpoints, absFilename(filename))
+	}
+}
+
+// Breakpoints returns a map of absolute filenames to sorted line numbers.
+func Breakpoints() map[string][]int {
+	breakpointsMutex.RLock()
+	defer breakpointsMutex.RUnlock()
+	res := make(map[string][]int)
+	for filename, lines := range breakpoints {
+		for line := range lines {
+			res[filename] = append(res[filename], line)
+		}
+		sort.Ints(res[filename])
+	}
+	return res
+}
+
+func hasBreakpoint(pos Position) bool {
+	breakpointsMutex.RLock()
+	if len(breakpoints) == 0 {
+		breakpointsMutex.RUnlock()
+		return false
+	}
+	filename, ok := absFilenames[pos.filename]
+	breakpointsMutex.RUnlock()
+	if !ok {
+		filename = absFilename(*pos.filename)
+		breakpointsMutex.Lock()
+		absFilenames[pos.filename] = filename
+		breakpointsMutex.Unlock()
+	}
+	breakpointsMutex.RLock()
+	defer breakpointsMutex.RUnlock()
+	return breakpoints[filename][pos.startLine]
+}
+
+// RequestPause makes the next goroutine that evaluates
+// an expression starting a new line pause.
+func RequestPause() {
+	atomic.StoreInt32(&pauseRequested, 1)
+}
+
+// DebugBreak pauses the goroutine evaluating in rt, installing
+// the terminal debugger if no debugger is installed.
+func DebugBreak(rt *Runtime) {
+	UseTerminalDebugger()
+	if rt.inDebugger {
+		return
+	}
+	debugPause(rt, "break")
+}
+
+func debugCheck(rt *Runtime, expr Expr, parentExpr Expr) {
+	if rt.inDebugger {
+		return
+	}
+	// Macro calls are evaluated when the code is parsed, not run.
+	if _, ok := expr.(*MacroCallExpr); ok {
+		return
+	}
+	pos := expr.Pos()
+	if pos.filename == nil || pos.startLine <= 0 || strings.HasPrefix(*pos.filename, "<joker.") {
+		return
+	}
+	// Only expressions that start a new line are stopped at,
+	// so that stepping goes line by line rather than form by form.
+	if parentExpr != nil {
+		parentPos := parentExpr.Pos()
+		if parentPos.startLine == pos.startLine && parentPos.filename != nil && *parentPos.filename == *pos.filename {
+			return
+		}
+	}
+	reason := ""
+	depth := rt.callstack.Depth()
+	switch rt.debugStep {
+	case DEBUG_STEP_IN:
+		reason = "step"
+	case DEBUG_STEP_OVER:
+		if depth <= rt.debugDepth {
+			reason = "step"
+		}
+	case DEBUG_STEP_OUT:
+		if depth < rt.debugDepth {
+			reason = "step"
+		}
+	}
+	if reason == "" && hasBreakpoint(pos) {
+		reason = "breakpoint"
+	}
+	if reason == "" && atomic.LoadInt32(&pauseRequested) != 0 && atomic.CompareAndSwapInt32(&pauseRequested, 1, 0) {
+		reason = "pause"
+	}
+	if reason != "" {
+		debugPause(rt, reason)
+	}
+}
+
+func debugPause(rt *Runtime, reason string) {
+	pauseMutex.Lock()
+	defer pauseMutex.Unlock()
+	if debugger == nil {
+		return
+	}
+	rt.inDebugger = true
+	defer func() { rt.inDebugger = false }()
+	action := debugger.Paused(&DebugEvent{
+		Reason:  reason,
+		Frames:  rt.debugFrames(),
+		Runtime: rt,
+	})
+	if action == DEBUG_ABORT {
+		rt.debugStep = DEBUG_CONTINUE
+		panic(rt.NewError("Evaluation aborted by debugger"))
+	}
+	rt.debugStep = action
+	rt.debugDepth = rt.callstack.Depth()
+}
+
+func (rt *Runtime) debugFrames() []*DebugFrame {
+	frames := rt.stackFrames()
+	callers := rt.callstack.frames()
+	res := make([]*DebugFrame, len(frames))
+	for i, f := range frames {
+		env := rt.currentEnv
+		if i < len(callers) {
+			env = callers[i].env
+		}
+		res[len(frames)-1-i] = &DebugFrame{Name: f.Name, Pos: f.Pos, env: env, rt: rt}
+	}
+	return res
+}
+
+// Locals returns local bindings visible in the frame, in the order
+// they were bound. Shadowed bindings and bindings generated
+// by macros (such as destructuring) are omitted.
+func (f *DebugFrame) Locals() []DebugLocal {
+	var res []DebugLocal
+	seen := make(map[string]bool)
+	for env := f.env; env != nil; env = env.parent {
+		for i := len(env.bindings) - 1; i >= 0; i-- {
+			if i >= len(env.names) {
+				continue
+			}
+			name := env.names[i].ToString(false)
+			if seen[name] || gensymLocalRegex.MatchString(name) {
+				continue
+			}
+			seen[name] = true
+			res = append(res, DebugLocal{Name: name, Value: env.bindings[i]})
+		}
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+// Eval evaluates code with the locals of the frame in scope.
+// It must be called on the paused goroutine.
+func (f *DebugFrame) Eval(code string) (obj Object, err error) {
+	reader := NewReader(strings.NewReader(code), "<debug>")
+	form, err := TryRead(f.rt, reader)
+	if err != nil {
+		return nil, err
+	}
+	// Locals are passed as arguments to a function wrapping the form:
+	// ((fn* [local1 local2 ...] form) 'value1 'value2 ...)
+	locals := f.Locals()
+	names := make([]Object, len(locals))
+	call := []Object{nil}
+	for i, l := range locals {
+		names[i] = MakeSymbol(l.Name)
+		call = append(call, NewListFrom(MakeSymbol("quote"), l.Value))
+	}
+	call[0] = NewListFrom(MakeSymbol("fn*"), NewVectorFrom(names...), form)
+	expr, err := TryParse(NewListFrom(call...), &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: f.rt})
+	if err != nil {
+		return nil, err
+	}
+	return f.rt.TryEval(expr)
+}
+
+type terminalDebugger struct {
+	frame int
+}
+
+func (d *terminalDebugger) Paused(ev *DebugEvent) DebugAction {
+	d.frame = 0
+	fmt.Fprintf(Stderr, "Paused (%s) at %s\n", ev.Reason, formatDebugFrame(ev.Frames[0]))
+	printSourceLine(ev.Frames[0].Pos)
+	for {
+		fmt.Fprint(Stderr, "debug> ")
+		line, err := readDebugLine(Stdin)
+		if err != nil && line == "" {
+			fmt.Fprintln(Stderr)
+			return DEBUG_CONTINUE
+		}
+		cmd := strings.TrimSpace(line)
+		arg := ""
+		if i := strings.IndexAny(cmd, " \t"); i > 0 {
+			cmd, arg = cmd[:i], strings.TrimSpace(cmd[i:])
+		}
+		switch cmd {
+		case "":
+		case "c", "continue":
+			return DEBUG_CONTINUE
+		case "s", "step":
+			return DEBUG_STEP_IN
+		case "n", "next":
+			return DEBUG_STEP_OVER
+		case "o", "out":
+			return DEBUG_STEP_OUT
+		case "q", "quit":
+			return DEBUG_ABORT
+		case "bt", "backtrace":
+			for i, f := range ev.Frames {
+				marker := " "
+				if i == d.frame {
+					marker = "*"
+				}
+				fmt.Fprintf(Stderr, "%s %d %s\n", marker, i, formatDebugFrame(f))
+			}
+		case "f", "frame":
+			if n, err := strconv.Atoi(arg); err == nil && n >= 0 && n < len(ev.Frames) {
+				d.frame = n
+				fmt.Fprintf(Stderr, "%d %s\n", n, formatDebugFrame(ev.Frames[n]))
+			} else {
+				fmt.Fprintf(Stderr, "Frame number must be between 0 and %d\n", len(ev.Frames)-1)
+			}
+		case "l", "locals":
+			for _, l := range ev.Frames[d.frame].Locals() {
+				fmt.Fprintf(Stderr, "%s = %s\n", l.Name, l.Value.ToString(true))
+			}
+		case "b", "break":
+			i := strings.LastIndex(arg, ":")
+			if line, err := strconv.Atoi(arg[i+1:]); i > 0 && err == nil {
+				SetBreakpoint(arg[:i], line)
+			} else {
+				fmt.Fprintln(Stderr, "Usage: break file:line")
+			}
+		case "clear":
+			ClearBreakpoints(arg)
+		case "h", "help":
+			fmt.Fprint(Stderr, terminalDebuggerHelp)
+		default:
+			obj, err := ev.Frames[d.frame].Eval(line)
+			if err != nil {
+				fmt.Fprintln(Stderr, err.Error())
+			} else {
+				fmt.Fprintln(Stderr, obj.ToString(true))
+			}
+		}
+	}
+}
+
+const terminalDebuggerHelp = `  c, continue       continue evaluation
+  s, step           step to the next line, entering function calls
+  n, next           step to the next line in the current function
+  o, out            step out of the current function
+  bt, backtrace     print the call stack
+  f, frame <n>      select frame n of the call stack
+  l, locals         print local bindings of the selected frame
+  b, break <file:line>
+                    set a breakpoint
+  clear [<file>]    clear breakpoints in the file (or all breakpoints)
+  q, quit           abort evaluation
+  h, help           print this help
+Any other input is evaluated as an expression in the selected frame.
+`
+
+func formatDebugFrame(f *DebugFrame) string {
+	return fmt.Sprintf("%s %s:%d:%d", f.Name, f.Pos.Filename(), f.Pos.startLine, f.Pos.startColumn)
+}
+
+// SourceLine returns the line of the source file at pos, if available.
+func SourceLine(pos Position) (string, bool) {
+	if pos.filename == nil || pos.startLine <= 0 {
+		return "", false
+	}
+	f, err := os.Open(*pos.filename)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n == pos.startLine {
+			return scanner.Text(), true
+		}
+	}
+	return "", false
+}
+
+func printSourceLine(pos Position) {
+	if line, ok := SourceLine(pos); ok {
+		fmt.Fprintf(Stderr, "%5d  %s\n", pos.startLine, line)
+	}
+}
+
+// readDebugLine reads a line from r one byte at a time,
+// so that no input meant for the program is buffered.
+func readDebugLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return b.String(), nil
+			}
+			b.WriteByte(buf[0])
+		}
+		if err != nil {
+			return b.String(), err
+		}
+	}
+}
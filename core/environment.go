@@ -91,8 +91,8 @@ func (env *Env) SetStdIO(stdin, stdout, stderr Object) {
 	env.stderr.Value = stderr
 }
 
-func (env *Env) StdIO() (stdin, stdout, stderr Object) {
-	return env.stdin.Value, env.stdout.Value, env.stderr.Value
+func (env *Env) StdIO(rt *Runtime) (stdin, stdout, stderr Object) {
+	return env.stdin.Resolve(rt), env.stdout.Resolve(rt), env.stderr.Resolve(rt)
 }
 
 /* This runs after invariant initialization, which includes calling
@@ -106,25 +106,33 @@ func (env *Env) SetMainFilename(filename string) {
    NewEnv().  NOTE: Any changes to the list of run-time
    initializations must be reflected in gen_code/gen_code.go.  */
 func (env *Env) SetFilename(obj Object) {
-	env.file.Value = obj
+	env.file.setRoot(obj)
 }
 
-func (env *Env) IsStdIn(obj Object) bool {
-	return env.stdin.Value == obj
+// ResetLoadedLibs empties *loaded-libs*, so that required libs
+// are loaded again.
+func (env *Env) ResetLoadedLibs() {
+	env.libs.setRoot(EmptySet())
 }
 
-func (env *Env) CurrentNamespace() *Namespace {
-	return AssertNamespace(env.ns.Value, "")
+func (env *Env) IsStdIn(rt *Runtime, obj Object) bool {
+	return env.stdin.Resolve(rt) == obj
 }
 
-func (env *Env) SetCurrentNamespace(ns *Namespace) {
-	env.ns.Value = ns
+func (env *Env) CurrentNamespace(rt *Runtime) *Namespace {
+	return AssertNamespace(env.ns.Resolve(rt), "")
+}
+
+func (env *Env) SetCurrentNamespace(rt *Runtime, ns *Namespace) {
+	env.ns.Set(rt, ns)
 }
 
 func (env *Env) EnsureNamespace(sym Symbol) *Namespace {
 	if sym.ns != nil {
 		panic(RT.NewError("Namespace's name cannot be qualified: " + sym.ToString(false)))
 	}
+	envLock.Lock()
+	defer envLock.Unlock()
 	if env.Namespaces[sym.name] == nil {
 		env.Namespaces[sym.name] = NewNamespace(sym)
 	}
@@ -142,10 +150,12 @@ func (env *Env) NamespaceFor(ns *Namespace, s Symbol) *Namespace {
 	if s.ns == nil {
 		res = ns
 	} else {
+		envLock.Lock()
 		res = ns.aliases[s.ns]
 		if res == nil {
 			res = env.Namespaces[s.ns]
 		}
+		envLock.Unlock()
 	}
 	if res != nil {
 		res.MaybeLazy("NamespaceFor")
@@ -158,7 +168,10 @@ func (env *Env) ResolveIn(n *Namespace, s Symbol) (*Var, bool) {
 	if ns == nil {
 		return nil, false
 	}
-	if v, ok := ns.mappings[s.name]; ok {
+	envLock.Lock()
+	v, ok := ns.mappings[s.name]
+	envLock.Unlock()
+	if ok {
 		return v, true
 	}
 	if s.Equals(env.IN_NS_VAR.name) {
@@ -170,21 +183,33 @@ func (env *Env) ResolveIn(n *Namespace, s Symbol) (*Var, bool) {
 	return nil, false
 }
 
-func (env *Env) Resolve(s Symbol) (*Var, bool) {
-	return env.ResolveIn(env.CurrentNamespace(), s)
+func (env *Env) Resolve(rt *Runtime, s Symbol) (*Var, bool) {
+	return env.ResolveIn(env.CurrentNamespace(rt), s)
 }
 
 func (env *Env) FindNamespace(s Symbol) *Namespace {
 	if s.ns != nil {
 		return nil
 	}
+	envLock.Lock()
 	ns := env.Namespaces[s.name]
+	envLock.Unlock()
 	if ns != nil {
 		ns.MaybeLazy("FindNameSpace")
 	}
 	return ns
 }
 
+func (env *Env) AllNamespaces() []*Namespace {
+	envLock.Lock()
+	defer envLock.Unlock()
+	res := make([]*Namespace, 0, len(env.Namespaces))
+	for _, ns := range env.Namespaces {
+		res = append(res, ns)
+	}
+	return res
+}
+
 func (env *Env) RemoveNamespace(s Symbol) *Namespace {
 	if s.ns != nil {
 		return nil
@@ -192,19 +217,21 @@ func (env *Env) RemoveNamespace(s Symbol) *Namespace {
 	if s.Equals(SYMBOLS.joker_core) {
 		panic(RT.NewError("Cannot remove core namespace"))
 	}
+	envLock.Lock()
+	defer envLock.Unlock()
 	ns := env.Namespaces[s.name]
 	delete(env.Namespaces, s.name)
 	return ns
 }
 
-func (env *Env) ResolveSymbol(s Symbol) Symbol {
+func (env *Env) ResolveSymbol(rt *Runtime, s Symbol) Symbol {
 	if strings.ContainsRune(*s.name, '.') {
 		return s
 	}
 	if s.ns == nil && TYPES[s.name] != nil {
 		return s
 	}
-	currentNs := env.CurrentNamespace()
+	currentNs := env.CurrentNamespace(rt)
 	if s.ns != nil {
 		ns := env.NamespaceFor(currentNs, s)
 		if ns == nil || ns.Name.name == s.ns {
@@ -239,5 +266,5 @@ func (env *Env) ResolveSymbol(s Symbol) Symbol {
 }
 
 func init() {
-	GLOBAL_ENV.SetCurrentNamespace(GLOBAL_ENV.EnsureNamespace(MakeSymbol("user")))
+	GLOBAL_ENV.ns.setRoot(GLOBAL_ENV.EnsureNamespace(MakeSymbol("user")))
 }
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+)
+
+type (
+	// ErrorInfo describes an error in the form reported by --error-format json.
+	ErrorInfo struct {
+		Type       string       `json:"type"`
+		Message    string       `json:"message"`
+		File       string       `json:"file,omitempty"`
+		Line       int          `json:"line,omitempty"`
+		Col        int          `json:"col,omitempty"`
+		Data       string       `json:"data,omitempty"` // ex-info data, printed readably
+		Stacktrace []StackEntry `json:"stacktrace,omitempty"`
+		Cause      *ErrorInfo   `json:"cause,omitempty"`
+	}
+	StackEntry struct {
+		Fn   string `json:"fn"`
+		File string `json:"file"`
+		Line int    `json:"line"`
+		Col  int    `json:"col"`
+	}
+)
+
+func (rt *Runtime) newClassError(msg string, class *Type) *EvalError {
+	res := rt.NewError(msg)
+	res.class = class
+	return res
+}
+
+// NewGoError returns the error to throw for err returned by Go code.
+// The error's class depends on the kind of err and the errors it wraps,
+// which become its causes.
+func (rt *Runtime) NewGoError(err error) *EvalError {
+	if e, ok := err.(*EvalError); ok {
+		return e
+	}
+	res := rt.NewError(err.Error())
+	res.class = goErrorClass(err)
+	res.err = err
+	return res
+}
+
+func goErrorClass(err error) *Type {
+	var timeout interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &timeout) && timeout.Timeout()) {
+		return TYPE.TimeoutError
+	}
+	var readErr ReadError
+	if errors.As(err, &readErr) {
+		return TYPE.ReadError
+	}
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	if errors.As(err, &pathErr) || errors.As(err, &linkErr) || errors.As(err, &syscallErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrExist) || errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrClosed) {
+		return TYPE.IOError
+	}
+	return nil
+}
+
+// Unwrap returns the Go error err was created from, if any,
+// so that errors.Is and errors.As see through Joker errors.
+func (err *EvalError) Unwrap() error {
+	return err.err
+}
+
+// Cause returns the error wrapped by the Go error err was created from,
+// or nil if there is none.
+func (err *EvalError) Cause() *EvalError {
+	if err.err == nil {
+		return nil
+	}
+	cause := errors.Unwrap(err.err)
+	if cause == nil {
+		return nil
+	}
+	if e, ok := cause.(*EvalError); ok {
+		return e
+	}
+	return &EvalError{msg: cause.Error(), pos: err.pos, rt: err.rt, class: goErrorClass(cause), err: cause}
+}
+
+// errorCause returns the cause of a Joker error, or NIL.
+func errorCause(err Error) Object {
+	switch err := err.(type) {
+	case *ExInfo:
+		if ok, res := err.Get(KEYWORDS.cause); ok {
+			return res
+		}
+	case *EvalError:
+		if cause := err.Cause(); cause != nil {
+			return cause
+		}
+	}
+	return NIL
+}
+
+// errorStackFrames returns the frames of the callstack at the
+// time err was created, or nil if err doesn't have them.
+func errorStackFrames(err Error) []StackFrame {
+	switch err := err.(type) {
+	case *EvalError:
+		if err.rt == nil {
+			return nil
+		}
+		frames := err.rt.stackFrames()
+		// The innermost frame is where the error was created,
+		// which may differ from the current expression of the runtime.
+		frames[len(frames)-1].Pos = err.pos
+		return frames
+	case *ExInfo:
+		return err.rt.stackFrames()
+	}
+	return nil
+}
+
+func stackEntries(frames []StackFrame) []StackEntry {
+	res := make([]StackEntry, len(frames))
+	for i, f := range frames {
+		res[i] = StackEntry{Fn: f.Name, File: f.Pos.Filename(), Line: f.Pos.startLine, Col: f.Pos.startColumn}
+	}
+	return res
+}
+
+func stacktraceVector(frames []StackFrame) *Vector {
+	res := EmptyVector()
+	for _, e := range stackEntries(frames) {
+		m := EmptyArrayMap()
+		m.Add(MakeKeyword("fn"), MakeString(e.Fn))
+		m.Add(MakeKeyword("file"), MakeString(e.File))
+		m.Add(MakeKeyword("line"), MakeInt(e.Line))
+		m.Add(MakeKeyword("col"), MakeInt(e.Col))
+		res = res.Conjoin(m)
+	}
+	return res
+}
+
+// NewErrorInfo describes err, which is any error returned
+// by TryRead, TryParse or TryEval.
+func NewErrorInfo(err error) *ErrorInfo {
+	return newErrorInfo(err, nil)
+}
+
+// newErrorInfo describes err, omitting its stacktrace
+// if it's the same as that of the error err caused.
+func newErrorInfo(err error, effectRt *Runtime) *ErrorInfo {
+	res := &ErrorInfo{Type: "Error", Message: err.Error()}
+	var rt *Runtime
+	var pos Position
+	switch e := err.(type) {
+	case ReadError:
+		res.Type, res.Message, pos = "ReadError", e.msg, e.Pos()
+	case *ParseError:
+		res.Type, res.Message, pos = "ParseError", e.msg, e.Pos()
+	case *EvalError:
+		res.Type, res.Message, pos, rt = e.GetType().name, e.message(), e.Pos(), e.rt
+	case *ExInfo:
+		res.Type, res.Message, rt = "ExInfo", e.Message().ToString(false), e.rt
+		if ok, data := e.Get(KEYWORDS.data); ok {
+			res.Data = data.ToString(true)
+			if ok, form := data.(Map).Get(KEYWORDS.form); ok && form.GetInfo() != nil {
+				pos = form.GetInfo().Pos()
+			}
+		}
+	}
+	if pos.filename != nil || pos.startLine > 0 {
+		res.File, res.Line, res.Col = pos.Filename(), pos.startLine, pos.startColumn
+	}
+	if e, ok := err.(Error); ok {
+		if frames := errorStackFrames(e); len(frames) > 1 && rt != effectRt {
+			res.Stacktrace = stackEntries(frames)
+		}
+		if cause, ok := errorCause(e).(Error); ok {
+			res.Cause = newErrorInfo(cause, rt)
+		}
+	}
+	return res
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -14,79 +14,173 @@ type (
 		Pos() Position
 	}
 	EvalError struct {
-		msg  string
-		pos  Position
-		rt   *Runtime
-		hash uint32
+		msg   string
+		pos   Position
+		rt    *Runtime // snapshot of the runtime the error was thrown in
+		hash  uint32
+		class *Type // error class, if more specific than EvalError
+		err   error // Go error this error was created from, if any
+		// format, if set, makes the message of the error out of the name
+		// of the function being called when the error is completed
+		// (see Runtime.complete).
+		format func(name string) string
 	}
 	Frame struct {
 		traceable Traceable
+		env       *LocalEnv // local environment of the caller
 	}
+	// Callstack is an immutable list of frames, innermost first,
+	// so that errors and runtimes can share it without copying.
+	// The empty callstack is nil.
 	Callstack struct {
-		frames []Frame
+		frame Frame
+		prev  *Callstack
+		depth int
+	}
+	// StackFrame is an entry of a stacktrace: the name of a function
+	// and the position evaluation is at in it.
+	StackFrame struct {
+		Name string
+		Pos  Position
 	}
 	Runtime struct {
 		callstack   *Callstack
 		currentExpr Expr
-		GIL         sync.Mutex
+		currentEnv  *LocalEnv
+		bindings    *bindingFrame
+		interrupt   *interruptState
+		// parent is the runtime a child runtime was created from (see child)
+		// and initial is the state the child started with.
+		parent  *Runtime
+		initial EvalState
+		// inDebugger is set while the debugger is paused in this runtime,
+		// so that expressions evaluated by the debugger don't pause it again.
+		inDebugger bool
+		debugStep  DebugAction
+		debugDepth int
+	}
+	// EvalState is the part of the state of a runtime that is restored
+	// when an error thrown by the code it evaluates is caught
+	// (see Runtime.Save).
+	EvalState struct {
+		callstack   *Callstack
+		currentExpr Expr
+		currentEnv  *LocalEnv
 	}
 )
 
-var RT *Runtime = &Runtime{
-	callstack: &Callstack{frames: make([]Frame, 0, 50)},
-}
+// RT stands for the runtime evaluating the calling code in the places
+// that don't have it at hand, which is where errors are made. Errors
+// made with it are completed with the position and the stacktrace they
+// were thrown at when they are caught (see Runtime.recovered).
+var RT = &Runtime{}
 
 func (rt *Runtime) clone() *Runtime {
 	return &Runtime{
-		callstack:   rt.callstack.clone(),
+		callstack:   rt.callstack,
 		currentExpr: rt.currentExpr,
 	}
 }
 
 func (rt *Runtime) NewError(msg string) *EvalError {
-	res := &EvalError{
-		msg: msg,
-		rt:  rt.clone(),
+	res := &EvalError{msg: msg}
+	if rt != RT {
+		rt.complete(res)
 	}
-	if rt.currentExpr != nil {
-		res.pos = rt.currentExpr.Pos()
+	return res
+}
+
+// complete fills in the position and the stacktrace of err,
+// which was thrown while rt was evaluating its current expression.
+func (rt *Runtime) complete(err *EvalError) {
+	if err.format != nil {
+		name := ""
+		if tr, ok := rt.currentExpr.(Traceable); ok {
+			name = tr.Name()
+		}
+		err.msg = err.format(name)
+		err.format = nil
+	}
+	if err.pos.filename == nil && err.pos.startLine == 0 && rt.currentExpr != nil {
+		err.pos = rt.currentExpr.Pos()
+	}
+	err.rt = rt.clone()
+}
+
+// newCallError returns an error about a call of the function being called,
+// whose message format makes out of the name of the function.
+func (rt *Runtime) newCallError(format func(name string) string) *EvalError {
+	res := &EvalError{format: format}
+	if rt != RT {
+		rt.complete(res)
 	}
 	return res
 }
 
 func (rt *Runtime) NewArgTypeError(index int, obj Object, expectedType string) *EvalError {
-	name := rt.currentExpr.(Traceable).Name()
-	return rt.NewError(fmt.Sprintf("Arg[%d] of %s must have type %s, got %s", index, name, expectedType, obj.GetType().ToString(false)))
+	return rt.newCallError(func(name string) string {
+		return fmt.Sprintf("Arg[%d] of %s must have type %s, got %s", index, name, expectedType, obj.GetType().ToString(false))
+	})
 }
 
 func (rt *Runtime) NewErrorWithPos(msg string, pos Position) *EvalError {
-	return &EvalError{
-		msg: msg,
-		pos: pos,
-		rt:  rt.clone(),
+	res := &EvalError{msg: msg, pos: pos}
+	if rt != RT {
+		rt.complete(res)
 	}
+	return res
 }
 
-func (rt *Runtime) stacktrace() string {
-	var b bytes.Buffer
+// Save returns the state of rt to restore with Recovered
+// if evaluation that is about to start throws an error.
+func (rt *Runtime) Save() EvalState {
+	return EvalState{callstack: rt.callstack, currentExpr: rt.currentExpr, currentEnv: rt.currentEnv}
+}
+
+// Recovered must be called with the value r recovered from a panic of
+// code evaluated in rt since saved was saved, before rt evaluates anything
+// else. If r is an error made without knowing the runtime, it's completed
+// with the position and the stacktrace it was thrown at, which rt still
+// holds. Then the state of rt is restored to saved.
+func (rt *Runtime) Recovered(r interface{}, saved EvalState) {
+	if err, ok := r.(*EvalError); ok && err.rt == nil {
+		rt.complete(err)
+	}
+	rt.callstack, rt.currentExpr, rt.currentEnv = saved.callstack, saved.currentExpr, saved.currentEnv
+}
+
+// stackFrames returns the frames of the callstack, outermost first,
+// with the position each function is at.
+func (rt *Runtime) stackFrames() []StackFrame {
+	res := make([]StackFrame, rt.callstack.Depth()+1)
 	pos := Position{}
 	if rt.currentExpr != nil {
 		pos = rt.currentExpr.Pos()
 	}
-	name := "global"
-	for _, f := range rt.callstack.frames {
-		framePos := f.traceable.Pos()
-		b.WriteString(fmt.Sprintf("  %s %s:%d:%d\n", name, framePos.Filename(), framePos.startLine, framePos.startColumn))
-		name = f.traceable.Name()
-		if strings.HasPrefix(name, "#'") {
-			name = name[2:]
+	i := len(res) - 1
+	for s := rt.callstack; s != nil; s = s.prev {
+		res[i] = StackFrame{Name: strings.TrimPrefix(s.frame.traceable.Name(), "#'"), Pos: pos}
+		pos = s.frame.traceable.Pos()
+		i--
+	}
+	res[0] = StackFrame{Name: "global", Pos: pos}
+	return res
+}
+
+func (rt *Runtime) stacktrace() string {
+	var b bytes.Buffer
+	for i, f := range rt.stackFrames() {
+		if i > 0 {
+			b.WriteString("\n")
 		}
+		b.WriteString(fmt.Sprintf("  %s %s:%d:%d", f.Name, f.Pos.Filename(), f.Pos.startLine, f.Pos.startColumn))
 	}
-	b.WriteString(fmt.Sprintf("  %s %s:%d:%d", name, pos.Filename(), pos.startLine, pos.startColumn))
 	return b.String()
 }
 
-func (rt *Runtime) pushFrame() {
+// pushFrame adds a frame for the function being called to the callstack
+// and returns the callstack to restore when the function returns.
+func (rt *Runtime) pushFrame() *Callstack {
 	// TODO: this is all wrong. We cannot rely on
 	// currentExpr for stacktraces. Instead, each Callable
 	// should know it's name / position.
@@ -96,37 +190,47 @@ func (rt *Runtime) pushFrame() {
 	} else {
 		tr = &CallExpr{}
 	}
-	rt.callstack.pushFrame(Frame{traceable: tr})
+	caller := rt.callstack
+	rt.callstack = &Callstack{frame: Frame{traceable: tr, env: rt.currentEnv}, prev: caller, depth: caller.Depth() + 1}
+	return caller
 }
 
-func (rt *Runtime) popFrame() {
-	rt.callstack.popFrame()
-}
-
-func Eval(expr Expr, env *LocalEnv) Object {
-	parentExpr := RT.currentExpr
-	RT.currentExpr = expr
-	defer (func() { RT.currentExpr = parentExpr })()
-	return expr.Eval(env)
-}
-
-func (s *Callstack) pushFrame(frame Frame) {
-	s.frames = append(s.frames, frame)
+// Eval evaluates expr in env. If it throws an error, rt is left
+// at the expression that threw it until the error is caught
+// (see recovered).
+func (rt *Runtime) Eval(expr Expr, env *LocalEnv) Object {
+	if atomic.LoadInt32(&rt.interrupt.interrupted) != 0 {
+		panic(rt.interruptError())
+	}
+	parentExpr, parentEnv := rt.currentExpr, rt.currentEnv
+	rt.currentExpr, rt.currentEnv = expr, env
+	if atomic.LoadInt32(&debugging) != 0 {
+		debugCheck(rt, expr, parentExpr)
+	}
+	res := expr.Eval(rt, env)
+	rt.currentExpr, rt.currentEnv = parentExpr, parentEnv
+	return res
 }
 
-func (s *Callstack) popFrame() {
-	s.frames = s.frames[:len(s.frames)-1]
+// Depth returns the number of frames in s.
+func (s *Callstack) Depth() int {
+	if s == nil {
+		return 0
+	}
+	return s.depth
 }
 
-func (s *Callstack) clone() *Callstack {
-	res := &Callstack{frames: make([]Frame, len(s.frames))}
-	copy(res.frames, s.frames)
-	return res
+// outermost returns the outermost frame of s, which must not be empty.
+func (s *Callstack) outermost() Frame {
+	for s.prev != nil {
+		s = s.prev
+	}
+	return s.frame
 }
 
 func (s *Callstack) String() string {
 	var b bytes.Buffer
-	for _, f := range s.frames {
+	for _, f := range s.frames() {
 		pos := f.traceable.Pos()
 		b.WriteString(fmt.Sprintf("%s %s:%d:%d\n", f.traceable.Name(), pos.Filename(), pos.startLine, pos.startColumn))
 	}
@@ -136,8 +240,18 @@ func (s *Callstack) String() string {
 	return b.String()
 }
 
+// frames returns the frames of s, outermost first.
+func (s *Callstack) frames() []Frame {
+	res := make([]Frame, s.Depth())
+	for i := len(res) - 1; s != nil; s = s.prev {
+		res[i] = s.frame
+		i--
+	}
+	return res
+}
+
 func MakeEvalError(msg string, pos Position, rt *Runtime) *EvalError {
-	res := &EvalError{msg, pos, rt, 0}
+	res := &EvalError{msg: msg, pos: pos, rt: rt}
 	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
 	return res
 }
@@ -155,6 +269,9 @@ func (err *EvalError) GetInfo() *ObjectInfo {
 }
 
 func (err *EvalError) GetType() *Type {
+	if err.class != nil {
+		return err.class
+	}
 	return TYPE.EvalError
 }
 
@@ -166,131 +283,147 @@ func (err *EvalError) WithInfo(info *ObjectInfo) Object {
 	return err
 }
 
+func (err *EvalError) message() string {
+	if err.format != nil {
+		return err.format("fn")
+	}
+	return err.msg
+}
+
 func (err *EvalError) Message() Object {
-	return MakeString(err.msg)
+	return MakeString(err.message())
+}
+
+// hasStacktrace reports whether err was thrown inside a function call.
+func (err *EvalError) hasStacktrace() bool {
+	return err.rt != nil && err.rt.callstack != nil
+}
+
+func (err *EvalError) Pos() Position {
+	if err.hasStacktrace() && LINTER_MODE {
+		return err.rt.callstack.outermost().traceable.Pos()
+	}
+	return err.pos
 }
 
 func (err *EvalError) Error() string {
-	pos := err.pos
-	if len(err.rt.callstack.frames) > 0 && !LINTER_MODE {
-		return fmt.Sprintf("%s:%d:%d: Eval error: %s\nStacktrace:\n%s", pos.Filename(), pos.startLine, pos.startColumn, err.msg, err.rt.stacktrace())
-	} else {
-		if len(err.rt.callstack.frames) > 0 {
-			pos = err.rt.callstack.frames[0].traceable.Pos()
-		}
-		return fmt.Sprintf("%s:%d:%d: Eval error: %s", pos.Filename(), pos.startLine, pos.startColumn, err.msg)
+	pos := err.Pos()
+	if err.hasStacktrace() && !LINTER_MODE {
+		return fmt.Sprintf("%s:%d:%d: Eval error: %s\nStacktrace:\n%s", pos.Filename(), pos.startLine, pos.startColumn, err.message(), err.rt.stacktrace())
 	}
+	return fmt.Sprintf("%s:%d:%d: Eval error: %s", pos.Filename(), pos.startLine, pos.startColumn, err.message())
 }
 
-func (expr *VarRefExpr) Eval(env *LocalEnv) Object {
-	return expr.vr.Resolve()
+func (expr *VarRefExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	return expr.vr.Resolve(rt)
 }
 
-func (expr *SetMacroExpr) Eval(env *LocalEnv) Object {
+func (expr *SetMacroExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	expr.vr.isMacro = true
 	expr.vr.isUsed = false
-	if fn, ok := expr.vr.Value.(*Fn); ok {
+	if fn, ok := expr.vr.root().(*Fn); ok {
 		fn.isMacro = true
 	}
 	setMacroMeta(expr.vr)
 	return expr.vr
 }
 
-func (expr *BindingExpr) Eval(env *LocalEnv) Object {
+func (expr *BindingExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	for i := env.frame; i > expr.binding.frame; i-- {
 		env = env.parent
 	}
 	return env.bindings[expr.binding.index]
 }
 
-func (expr *LiteralExpr) Eval(env *LocalEnv) Object {
+func (expr *LiteralExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	return expr.obj
 }
 
-func (expr *VectorExpr) Eval(env *LocalEnv) Object {
+func (expr *VectorExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	res := EmptyVector()
 	for _, e := range expr.v {
-		res = res.Conjoin(Eval(e, env))
+		res = res.Conjoin(rt.Eval(e, env))
 	}
 	return res
 }
 
-func (expr *MapExpr) Eval(env *LocalEnv) Object {
+func (expr *MapExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	if int64(len(expr.keys)) > HASHMAP_THRESHOLD/2 {
 		res := EmptyHashMap
 		for i := range expr.keys {
-			key := Eval(expr.keys[i], env)
+			key := rt.Eval(expr.keys[i], env)
 			if res.containsKey(key) {
-				panic(RT.NewError("Duplicate key: " + key.ToString(false)))
+				panic(rt.NewError("Duplicate key: " + key.ToString(false)))
 			}
-			res = res.Assoc(key, Eval(expr.values[i], env)).(*HashMap)
+			res = res.Assoc(key, rt.Eval(expr.values[i], env)).(*HashMap)
 		}
 		return res
 	}
 	res := EmptyArrayMap()
 	for i := range expr.keys {
-		key := Eval(expr.keys[i], env)
-		if !res.Add(key, Eval(expr.values[i], env)) {
-			panic(RT.NewError("Duplicate key: " + key.ToString(false)))
+		key := rt.Eval(expr.keys[i], env)
+		if !res.Add(key, rt.Eval(expr.values[i], env)) {
+			panic(rt.NewError("Duplicate key: " + key.ToString(false)))
 		}
 	}
 	return res
 }
 
-func (expr *SetExpr) Eval(env *LocalEnv) Object {
+func (expr *SetExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	res := EmptySet()
 	for _, elemExpr := range expr.elements {
-		el := Eval(elemExpr, env)
+		el := rt.Eval(elemExpr, env)
 		if !res.Add(el) {
-			panic(RT.NewError("Duplicate set element: " + el.ToString(false)))
+			panic(rt.NewError("Duplicate set element: " + el.ToString(false)))
 		}
 	}
 	return res
 }
 
-func (expr *DefExpr) Eval(env *LocalEnv) Object {
+func (expr *DefExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	if expr.value != nil {
-		expr.vr.Value = Eval(expr.value, env)
-	}
-	meta := EmptyArrayMap()
-	meta.Add(KEYWORDS.line, Int{I: expr.startLine})
-	meta.Add(KEYWORDS.column, Int{I: expr.startColumn})
-	meta.Add(KEYWORDS.file, String{S: *expr.filename})
-	meta.Add(KEYWORDS.ns, expr.vr.ns)
-	meta.Add(KEYWORDS.name, expr.vr.name)
-	expr.vr.meta = meta
+		expr.vr.setRoot(rt.Eval(expr.value, env))
+	}
+	m := EmptyArrayMap()
+	m.Add(KEYWORDS.line, Int{I: expr.startLine})
+	m.Add(KEYWORDS.column, Int{I: expr.startColumn})
+	m.Add(KEYWORDS.file, String{S: *expr.filename})
+	m.Add(KEYWORDS.ns, expr.vr.ns)
+	m.Add(KEYWORDS.name, expr.vr.name)
+	var meta Map = m
 	if expr.meta != nil {
-		expr.vr.meta = expr.vr.meta.Merge(Eval(expr.meta, env).(Map))
+		meta = meta.Merge(rt.Eval(expr.meta, env).(Map))
 	}
 	// isMacro can be set by set-macro__ during parse stage
 	if expr.vr.isMacro {
-		expr.vr.meta = expr.vr.meta.Assoc(KEYWORDS.macro, Boolean{B: true}).(Map)
+		meta = meta.Assoc(KEYWORDS.macro, Boolean{B: true}).(Map)
 	}
+	expr.vr.ResetMeta(meta)
 	return expr.vr
 }
 
-func (expr *MetaExpr) Eval(env *LocalEnv) Object {
-	meta := Eval(expr.meta, env)
-	res := Eval(expr.expr, env)
+func (expr *MetaExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	meta := rt.Eval(expr.meta, env)
+	res := rt.Eval(expr.expr, env)
 	return res.(Meta).WithMeta(meta.(Map))
 }
 
-func evalSeq(exprs []Expr, env *LocalEnv) []Object {
+func evalSeq(rt *Runtime, exprs []Expr, env *LocalEnv) []Object {
 	res := make([]Object, len(exprs))
 	for i, expr := range exprs {
-		res[i] = Eval(expr, env)
+		res[i] = rt.Eval(expr, env)
 	}
 	return res
 }
 
-func (expr *CallExpr) Eval(env *LocalEnv) Object {
-	callable := Eval(expr.callable, env)
+func (expr *CallExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	callable := rt.Eval(expr.callable, env)
 	switch callable := callable.(type) {
 	case Callable:
-		args := evalSeq(expr.args, env)
-		return callable.Call(args)
+		args := evalSeq(rt, expr.args, env)
+		return callable.Call(rt, args)
 	default:
-		panic(RT.NewErrorWithPos(callable.ToString(false)+" is not a Fn", expr.callable.Pos()))
+		panic(rt.NewErrorWithPos(callable.ToString(false)+" is not a Fn", expr.callable.Pos()))
 	}
 }
 
@@ -314,29 +447,31 @@ func (expr *CallExpr) Name() string {
 	}
 }
 
-func (expr *ThrowExpr) Eval(env *LocalEnv) Object {
-	e := Eval(expr.e, env)
+func (expr *ThrowExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	e := rt.Eval(expr.e, env)
 	switch e.(type) {
 	case Error:
 		panic(e)
 	default:
-		panic(RT.NewError("Cannot throw " + e.ToString(false)))
+		panic(rt.NewError("Cannot throw " + e.ToString(false)))
 	}
 }
 
-func (expr *TryExpr) Eval(env *LocalEnv) (obj Object) {
+func (expr *TryExpr) Eval(rt *Runtime, env *LocalEnv) (obj Object) {
+	saved := rt.Save()
 	defer func() {
 		defer func() {
 			if expr.finallyExpr != nil {
-				evalBody(expr.finallyExpr, env)
+				evalBody(rt, expr.finallyExpr, env)
 			}
 		}()
 		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
 			switch r := r.(type) {
 			case Error:
 				for _, catchExpr := range expr.catches {
 					if IsInstance(catchExpr.excType, r) {
-						obj = evalBody(catchExpr.body, env.addFrame([]Object{r}))
+						obj = evalBody(rt, catchExpr.body, env.addFrame([]Object{r}, []Symbol{catchExpr.excSymbol}))
 						return
 					}
 				}
@@ -346,26 +481,26 @@ func (expr *TryExpr) Eval(env *LocalEnv) (obj Object) {
 			}
 		}
 	}()
-	return evalBody(expr.body, env)
+	return evalBody(rt, expr.body, env)
 }
 
-func (expr *CatchExpr) Eval(env *LocalEnv) (obj Object) {
-	panic(RT.NewError("This should never happen!"))
+func (expr *CatchExpr) Eval(rt *Runtime, env *LocalEnv) (obj Object) {
+	panic(rt.NewError("This should never happen!"))
 }
 
-func evalBody(body []Expr, env *LocalEnv) Object {
+func evalBody(rt *Runtime, body []Expr, env *LocalEnv) Object {
 	var res Object = NIL
 	for _, expr := range body {
-		res = Eval(expr, env)
+		res = rt.Eval(expr, env)
 	}
 	return res
 }
 
-func evalLoop(body []Expr, env *LocalEnv) Object {
+func evalLoop(rt *Runtime, body []Expr, env *LocalEnv) Object {
 	var res Object = NIL
 loop:
 	for _, expr := range body {
-		res = Eval(expr, env)
+		res = rt.Eval(expr, env)
 	}
 	switch res := res.(type) {
 	default:
@@ -376,61 +511,63 @@ loop:
 	}
 }
 
-func (doExpr *DoExpr) Eval(env *LocalEnv) Object {
-	return evalBody(doExpr.body, env)
+func (doExpr *DoExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	return evalBody(rt, doExpr.body, env)
 }
 
-func (expr *IfExpr) Eval(env *LocalEnv) Object {
-	if ToBool(Eval(expr.cond, env)) {
-		return Eval(expr.positive, env)
+func (expr *IfExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	if ToBool(rt.Eval(expr.cond, env)) {
+		return rt.Eval(expr.positive, env)
 	}
-	return Eval(expr.negative, env)
+	return rt.Eval(expr.negative, env)
 }
 
-func (expr *FnExpr) Eval(env *LocalEnv) Object {
+func (expr *FnExpr) Eval(rt *Runtime, env *LocalEnv) Object {
 	res := &Fn{fnExpr: expr}
 	if expr.self.name != nil {
-		env = env.addFrame([]Object{res})
+		env = env.addFrame([]Object{res}, []Symbol{expr.self})
 	}
 	res.env = env
 	return res
 }
 
-func (expr *FnArityExpr) Eval(env *LocalEnv) Object {
-	panic(RT.NewError("This should never happen!"))
+func (expr *FnArityExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	panic(rt.NewError("This should never happen!"))
 }
 
-func (expr *LetExpr) Eval(env *LocalEnv) Object {
-	env = env.addEmptyFrame(len(expr.names))
+func (expr *LetExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	env = env.addEmptyFrame(expr.names)
 	for _, bindingExpr := range expr.values {
-		env.addBinding(Eval(bindingExpr, env))
+		env.addBinding(rt.Eval(bindingExpr, env))
 	}
-	return evalBody(expr.body, env)
+	return evalBody(rt, expr.body, env)
 }
 
-func (expr *LoopExpr) Eval(env *LocalEnv) Object {
-	env = env.addEmptyFrame(len(expr.names))
+func (expr *LoopExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	env = env.addEmptyFrame(expr.names)
 	for _, bindingExpr := range expr.values {
-		env.addBinding(Eval(bindingExpr, env))
+		env.addBinding(rt.Eval(bindingExpr, env))
 	}
-	return evalLoop(expr.body, env)
+	return evalLoop(rt, expr.body, env)
 }
 
-func (expr *RecurExpr) Eval(env *LocalEnv) Object {
-	return RecurBindings(evalSeq(expr.args, env))
+func (expr *RecurExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	return RecurBindings(evalSeq(rt, expr.args, env))
 }
 
-func (expr *MacroCallExpr) Eval(env *LocalEnv) Object {
-	return expr.macro.Call(expr.args)
+func (expr *MacroCallExpr) Eval(rt *Runtime, env *LocalEnv) Object {
+	return expr.macro.Call(rt, expr.args)
 }
 
 func (expr *MacroCallExpr) Name() string {
 	return expr.name
 }
 
-func TryEval(expr Expr) (obj Object, err error) {
+func (rt *Runtime) TryEval(expr Expr) (obj Object, err error) {
+	saved := rt.Save()
 	defer func() {
 		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
 			switch r.(type) {
 			case *EvalError:
 				err = r.(error)
@@ -441,11 +578,14 @@ func TryEval(expr Expr) (obj Object, err error) {
 			}
 		}
 	}()
-	return Eval(expr, nil), nil
+	return rt.Eval(expr, nil), nil
 }
 
 func PanicOnErr(err error) {
 	if err != nil {
-		panic(RT.NewError(err.Error()))
+		if e, ok := err.(Error); ok {
+			panic(e)
+		}
+		panic(RT.NewGoError(err))
 	}
 }
@@ -0,0 +1,591 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	formatKind int
+
+	// formatNode is a form read with its original spelling,
+	// along with the comments and line breaks around it.
+	formatNode struct {
+		kind     formatKind
+		text     string        // atom, comment, reader macro (e.g. "'" or "#_") or opening delimiter
+		close    string        // closing delimiter of a collection
+		children []*formatNode // collection elements and comments, or the form following reader macro
+		newlines int           // line breaks before the node
+		comma    bool          // node is followed by a comma
+	}
+
+	// indentRule says how to indent the body of a list form.
+	indentRule struct {
+		body  bool // always indent body by 2 spaces
+		block int  // indent body by 2 spaces if at most block args are on the first line
+	}
+
+	formatPrinter struct {
+		b     strings.Builder
+		col   int
+		rules map[string]indentRule
+	}
+)
+
+const (
+	formatAtom formatKind = iota
+	formatComment
+	formatPrefix
+	formatColl
+)
+
+var (
+	defaultIndentRules = map[string]indentRule{
+		"fn":              {body: true},
+		"bound-fn":        {body: true},
+		"reify":           {body: true},
+		"deftest":         {body: true},
+		"comment":         {body: true},
+		"do":              {block: 0},
+		"try":             {block: 0},
+		"finally":         {block: 0},
+		"delay":           {block: 0},
+		"future":          {block: 0},
+		"with-out-str":    {block: 0},
+		"let":             {block: 1},
+		"letfn":           {block: 1},
+		"loop":            {block: 1},
+		"binding":         {block: 1},
+		"if":              {block: 1},
+		"if-not":          {block: 1},
+		"if-let":          {block: 1},
+		"if-some":         {block: 1},
+		"when":            {block: 1},
+		"when-not":        {block: 1},
+		"when-let":        {block: 1},
+		"when-some":       {block: 1},
+		"when-first":      {block: 1},
+		"while":           {block: 1},
+		"doseq":           {block: 1},
+		"dotimes":         {block: 1},
+		"for":             {block: 1},
+		"case":            {block: 1},
+		"cond->":          {block: 1},
+		"cond->>":         {block: 1},
+		"doto":            {block: 1},
+		"locking":         {block: 1},
+		"ns":              {block: 1},
+		"testing":         {block: 1},
+		"extend":          {block: 1},
+		"extend-type":     {block: 1},
+		"extend-protocol": {block: 1},
+		"condp":           {block: 2},
+		"catch":           {block: 2},
+		"as->":            {block: 2},
+		"proxy":           {block: 2},
+	}
+	// Config rules from .joker :format map, by symbol.
+	formatIndents map[string]indentRule
+)
+
+func parseIndentRule(obj Object) (indentRule, bool) {
+	switch obj := obj.(type) {
+	case Keyword:
+		if obj.Name() == "body" {
+			return indentRule{body: true}, true
+		}
+	case Int:
+		if obj.I >= 0 {
+			return indentRule{block: obj.I}, true
+		}
+	}
+	return indentRule{}, false
+}
+
+// parseFormatConfig parses :format value of .joker file, e.g.
+// {:indent {with-db :body, my-if 1}}.
+func parseFormatConfig(obj Object) (map[string]indentRule, error) {
+	m, ok := obj.(Map)
+	if !ok {
+		return nil, errors.New(":format value must be a map, got " + obj.GetType().ToString(false))
+	}
+	res := map[string]indentRule{}
+	ok, indent := m.Get(MakeKeyword("indent"))
+	if !ok {
+		return res, nil
+	}
+	indentMap, ok := indent.(Map)
+	if !ok {
+		return nil, errors.New(":indent value must be a map, got " + indent.GetType().ToString(false))
+	}
+	for iter := indentMap.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		sym, ok := p.Key.(Symbol)
+		if !ok {
+			return nil, errors.New(":indent keys must be symbols, got " + p.Key.GetType().ToString(false))
+		}
+		rule, ok := parseIndentRule(p.Value)
+		if !ok {
+			return nil, errors.New("indent rule for " + sym.ToString(false) + " must be :body or a non-negative integer, got " + p.Value.ToString(true))
+		}
+		addIndentRule(res, sym, rule)
+	}
+	return res, nil
+}
+
+// addIndentRule adds rule for sym, which also applies to
+// unqualified sym when it is namespace qualified.
+func addIndentRule(rules map[string]indentRule, sym Symbol, rule indentRule) {
+	rules[sym.ToString(false)] = rule
+	if sym.ns != nil {
+		if _, ok := rules[*sym.name]; !ok {
+			rules[*sym.name] = rule
+		}
+	}
+}
+
+// indentRules returns indentation rules: the defaults, overridden by
+// macros from :known-macros (indented as bodies), overridden by
+// :indent rules from :format config.
+func indentRules() map[string]indentRule {
+	res := map[string]indentRule{}
+	for k, v := range defaultIndentRules {
+		res[k] = v
+	}
+	if LINTER_CONFIG != nil {
+		if config, ok := LINTER_CONFIG.Value.(Map); ok {
+			if ok, km := config.Get(KEYWORDS.knownMacros); ok {
+				if km, ok := km.(Map); ok {
+					for iter := km.Iter(); iter.HasNext(); {
+						if sym, ok := iter.Next().Key.(Symbol); ok {
+							res[sym.ToString(false)] = indentRule{body: true}
+							if sym.ns != nil {
+								res[*sym.name] = indentRule{body: true}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	for k, v := range formatIndents {
+		res[k] = v
+	}
+	return res
+}
+
+func (p *formatPrinter) rule(head string) (indentRule, bool) {
+	if rule, ok := p.rules[head]; ok {
+		return rule, true
+	}
+	name := head
+	if i := strings.LastIndexByte(head, '/'); i > 0 && i < len(head)-1 {
+		name = head[i+1:]
+		if rule, ok := p.rules[name]; ok {
+			return rule, true
+		}
+	}
+	if strings.HasPrefix(name, "def") || strings.HasPrefix(name, "with-") {
+		return indentRule{body: true}, true
+	}
+	return indentRule{}, false
+}
+
+func readFormatToken(reader *Reader, first rune) string {
+	var b bytes.Buffer
+	b.WriteRune(first)
+	for !isDelimiter(reader.Peek()) {
+		b.WriteRune(reader.Get())
+	}
+	return b.String()
+}
+
+func readFormatString(reader *Reader, prefix string) string {
+	var b bytes.Buffer
+	b.WriteString(prefix)
+	for {
+		r := reader.Get()
+		if r == EOF {
+			panic(MakeReadError(reader, "Non-terminated string literal"))
+		}
+		b.WriteRune(r)
+		switch r {
+		case '"':
+			return b.String()
+		case '\\':
+			r = reader.Get()
+			if r == EOF {
+				panic(MakeReadError(reader, "Non-terminated string literal"))
+			}
+			b.WriteRune(r)
+		}
+	}
+}
+
+func readFormatCharacter(reader *Reader) string {
+	r := reader.Get()
+	if r == EOF {
+		panic(MakeReadError(reader, "Incomplete character literal"))
+	}
+	// The first rune may be a delimiter, e.g. \(
+	return "\\" + readFormatToken(reader, r)
+}
+
+func readFormatComment(reader *Reader, prefix string) *formatNode {
+	var b bytes.Buffer
+	b.WriteString(prefix)
+	for r := reader.Peek(); r != '\n' && r != EOF; r = reader.Peek() {
+		b.WriteRune(reader.Get())
+	}
+	return &formatNode{kind: formatComment, text: strings.TrimRight(b.String(), " \t\r")}
+}
+
+func readFormatColl(reader *Reader, open string, close rune) *formatNode {
+	return &formatNode{
+		kind:     formatColl,
+		text:     open,
+		close:    string(close),
+		children: readFormatSeq(reader, close),
+	}
+}
+
+// readFormatNext reads the form following reader macro,
+// along with the comments preceding it.
+func readFormatNext(reader *Reader) []*formatNode {
+	var nodes []*formatNode
+	newlines := 0
+	for {
+		r := reader.Get()
+		switch {
+		case r == '\n':
+			newlines++
+		case isWhitespace(r):
+		case r == ';':
+			node := readFormatComment(reader, ";")
+			node.newlines = newlines
+			newlines = 0
+			nodes = append(nodes, node)
+		default:
+			reader.Unget()
+			node := readFormatForm(reader)
+			node.newlines = newlines
+			return append(nodes, node)
+		}
+	}
+}
+
+// readFormatPrefix reads reader macro with the form following it.
+// Metadata is read together with the form it is attached to
+// if that form is on the same line.
+func readFormatPrefix(reader *Reader, prefix string) *formatNode {
+	node := &formatNode{
+		kind:     formatPrefix,
+		text:     prefix,
+		children: readFormatNext(reader),
+	}
+	if prefix == "^" {
+		r := reader.Get()
+		for r == ' ' || r == '\t' {
+			r = reader.Get()
+		}
+		reader.Unget()
+		switch r {
+		case '\n', '\r', ';', ',', ')', ']', '}', EOF:
+		default:
+			node.children = append(node.children, readFormatForm(reader))
+		}
+	}
+	return node
+}
+
+func readFormatDispatch(reader *Reader) *formatNode {
+	r := reader.Get()
+	switch r {
+	case '{':
+		return readFormatColl(reader, "#{", '}')
+	case '(':
+		return readFormatColl(reader, "#(", ')')
+	case '"':
+		return &formatNode{text: readFormatString(reader, "#\"")}
+	case '\'':
+		return readFormatPrefix(reader, "#'")
+	case '_':
+		return readFormatPrefix(reader, "#_")
+	case '!':
+		return readFormatComment(reader, "#!")
+	case '#':
+		return &formatNode{text: "#" + readFormatToken(reader, r)}
+	case '?':
+		open := "#?"
+		if reader.Peek() == '@' {
+			reader.Get()
+			open += "@"
+		}
+		if reader.Get() != '(' {
+			panic(MakeReadError(reader, "Reader conditional body must be a list"))
+		}
+		return readFormatColl(reader, open+"(", ')')
+	case ':':
+		open := "#" + readFormatToken(reader, r)
+		if reader.Get() != '{' {
+			panic(MakeReadError(reader, "Namespaced map must be followed by a map"))
+		}
+		return readFormatColl(reader, open+"{", '}')
+	}
+	if isDelimiter(r) {
+		panic(MakeReadError(reader, "Unsupported reader macro #"+string(r)))
+	}
+	// Tagged literal; the tagged form is read as the next form.
+	return &formatNode{text: "#" + readFormatToken(reader, r)}
+}
+
+func readFormatForm(reader *Reader) *formatNode {
+	r := reader.Get()
+	switch r {
+	case EOF:
+		panic(MakeReadError(reader, "Unexpected end of file"))
+	case '(':
+		return readFormatColl(reader, "(", ')')
+	case '[':
+		return readFormatColl(reader, "[", ']')
+	case '{':
+		return readFormatColl(reader, "{", '}')
+	case ')', ']', '}':
+		panic(MakeReadError(reader, "Unexpected "+string(r)))
+	case '"':
+		return &formatNode{text: readFormatString(reader, "\"")}
+	case '\\':
+		return &formatNode{text: readFormatCharacter(reader)}
+	case '\'', '`', '@', '^':
+		return readFormatPrefix(reader, string(r))
+	case '~':
+		if reader.Peek() == '@' {
+			reader.Get()
+			return readFormatPrefix(reader, "~@")
+		}
+		return readFormatPrefix(reader, "~")
+	case '#':
+		return readFormatDispatch(reader)
+	}
+	return &formatNode{text: readFormatToken(reader, r)}
+}
+
+// readFormatSeq reads forms and comments up to
+// close delimiter (EOF for top level forms).
+func readFormatSeq(reader *Reader, close rune) []*formatNode {
+	var nodes []*formatNode
+	newlines := 0
+	for {
+		r := reader.Get()
+		switch {
+		case r == close:
+			return nodes
+		case r == EOF:
+			panic(MakeReadError(reader, "Unexpected end of file, expected "+string(close)))
+		case r == ')' || r == ']' || r == '}':
+			panic(MakeReadError(reader, "Unexpected "+string(r)))
+		case r == '\n':
+			newlines++
+		case r == ',':
+			if len(nodes) > 0 {
+				nodes[len(nodes)-1].comma = true
+			}
+		case isWhitespace(r):
+		case r == ';':
+			node := readFormatComment(reader, ";")
+			node.newlines = newlines
+			newlines = 0
+			nodes = append(nodes, node)
+		default:
+			reader.Unget()
+			node := readFormatForm(reader)
+			node.newlines = newlines
+			newlines = 0
+			nodes = append(nodes, node)
+		}
+	}
+}
+
+func (p *formatPrinter) write(s string) {
+	p.b.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.col += utf8.RuneCountInString(s)
+	}
+}
+
+// newline writes n line breaks (at most one blank line)
+// followed by indentation.
+func (p *formatPrinter) newline(n int, indent int) {
+	if n > 2 {
+		n = 2
+	}
+	p.b.WriteString(strings.Repeat("\n", n))
+	p.b.WriteString(strings.Repeat(" ", indent))
+	p.col = indent
+}
+
+func (p *formatPrinter) printNode(node *formatNode) {
+	switch node.kind {
+	case formatPrefix:
+		p.printPrefix(node)
+	case formatColl:
+		p.printColl(node)
+	default:
+		p.write(node.text)
+	}
+	if node.comma {
+		p.write(",")
+	}
+}
+
+// printPrefix prints reader macro and the form following it
+// (with the form metadata is attached to in case of ^).
+// Line breaks are indented to the column of the reader macro.
+func (p *formatPrinter) printPrefix(node *formatNode) {
+	start := p.col
+	p.write(node.text)
+	attached := true
+	afterComment := false
+	for _, child := range node.children {
+		n := child.newlines
+		if afterComment && n == 0 {
+			n = 1
+		}
+		if n > 0 {
+			p.newline(n, start)
+		} else if !attached || child.kind == formatComment {
+			p.write(" ")
+		}
+		p.printNode(child)
+		afterComment = child.kind == formatComment
+		if child.kind != formatComment {
+			attached = false
+		}
+	}
+}
+
+func isSymbolNode(node *formatNode) bool {
+	if node.kind != formatAtom {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(node.text)
+	return r != ':' && r != '#' && !isNumberStart(node.text) && isSymbolInitial(r)
+}
+
+func isNumberStart(s string) bool {
+	if len(s) > 1 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// bodyIndent returns the indentation of list elements
+// following the first line, given the column of list's opening
+// delimiter and the number of args (elements after the head)
+// on the first line.
+func (p *formatPrinter) bodyIndent(node *formatNode, start int, args int) (int, bool) {
+	if node.text != "(" && node.text != "#(" {
+		return 0, false
+	}
+	head := node.children[0]
+	if !isSymbolNode(head) {
+		return 0, false
+	}
+	rule, ok := p.rule(head.text)
+	if !ok || (!rule.body && args > rule.block) {
+		return 0, false
+	}
+	return start + 2, true
+}
+
+func (p *formatPrinter) printColl(node *formatNode) {
+	start := p.col
+	p.write(node.text)
+	// Elements are aligned with the first one unless
+	// the list is indented as a body or the head is followed
+	// by args on the same line, which elements are aligned with.
+	indent := start + utf8.RuneCountInString(node.text)
+	indentKnown := false
+	argCol := -1
+	args := -1 // number of elements after the head on the first line
+	afterComment := false
+	for i, child := range node.children {
+		n := child.newlines
+		if afterComment && n == 0 {
+			n = 1
+		}
+		if i == 0 && n > 1 {
+			n = 1
+		}
+		if n > 0 {
+			if !indentKnown {
+				indentKnown = true
+				if i > 0 {
+					if bi, ok := p.bodyIndent(node, start, args); ok {
+						indent = bi
+					} else if argCol >= 0 && (node.text == "(" || node.text == "#(") {
+						indent = argCol
+					}
+				}
+			}
+			p.newline(n, indent)
+		} else if i > 0 || child.kind == formatComment {
+			p.write(" ")
+		}
+		if i == 1 && n == 0 {
+			argCol = p.col
+		}
+		p.printNode(child)
+		afterComment = child.kind == formatComment
+		if !afterComment {
+			args++
+		}
+	}
+	if afterComment {
+		p.newline(1, indent)
+	}
+	p.write(node.close)
+}
+
+func (p *formatPrinter) printTopLevel(nodes []*formatNode) {
+	afterComment := false
+	for i, node := range nodes {
+		n := node.newlines
+		if afterComment && n == 0 {
+			n = 1
+		}
+		if i > 0 {
+			if n > 0 {
+				p.newline(n, 0)
+			} else {
+				p.write(" ")
+			}
+		}
+		p.printNode(node)
+		afterComment = node.kind == formatComment
+	}
+	if len(nodes) > 0 {
+		p.write("\n")
+	}
+}
+
+// Format reads the code from reader and returns it formatted.
+// Comments, reader conditionals, discarded forms and the spelling
+// of literals are preserved, as are line breaks between forms
+// (except that blank lines are collapsed). Indentation and spacing
+// are normalized.
+func Format(reader *Reader) (res string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	nodes := readFormatSeq(reader, EOF)
+	p := &formatPrinter{rules: indentRules()}
+	p.printTopLevel(nodes)
+	return p.b.String(), nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"time"
+	"unsafe"
+)
+
+type (
+	Future struct {
+		done   chan struct{}
+		result FutureResult
+		hash   uint32
+	}
+)
+
+// callInGoroutine calls f, capturing the error it may throw in the result.
+func callInGoroutine(rt *Runtime, f Callable) (res FutureResult) {
+	saved := rt.Save()
+	defer func() {
+		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
+			switch r := r.(type) {
+			case Error:
+				res = MakeFutureResult(NIL, r)
+			default:
+				panic(r)
+			}
+		}
+	}()
+	return MakeFutureResult(f.Call(rt, []Object{}), nil)
+}
+
+// MakeFuture calls f in a new goroutine, with a runtime forked
+// from rt, and returns a Future that yields the result of the call.
+func MakeFuture(rt *Runtime, f Callable) *Future {
+	res := &Future{done: make(chan struct{})}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	rt.Go(func(rt *Runtime) {
+		defer close(res.done)
+		res.result = callInGoroutine(rt, f)
+	})
+	return res
+}
+
+func (fut *Future) ToString(escape bool) string {
+	return "#object[Future]"
+}
+
+func (fut *Future) Equals(other interface{}) bool {
+	return fut == other
+}
+
+func (fut *Future) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (fut *Future) GetType() *Type {
+	return TYPE.Future
+}
+
+func (fut *Future) Hash() uint32 {
+	return fut.hash
+}
+
+func (fut *Future) WithInfo(info *ObjectInfo) Object {
+	return fut
+}
+
+func (fut *Future) value() Object {
+	if fut.result.err != nil {
+		panic(fut.result.err)
+	}
+	return fut.result.value
+}
+
+func (fut *Future) Deref(rt *Runtime) Object {
+	select {
+	case <-fut.done:
+	case <-rt.interrupts():
+		rt.throwInterrupted()
+	}
+	return fut.value()
+}
+
+// DerefWithTimeout returns timeoutValue if the future
+// doesn't complete within timeout.
+func (fut *Future) DerefWithTimeout(rt *Runtime, timeout time.Duration, timeoutValue Object) Object {
+	select {
+	case <-fut.done:
+		return fut.value()
+	case <-time.After(timeout):
+		return timeoutValue
+	case <-rt.interrupts():
+		rt.throwInterrupted()
+		return nil
+	}
+}
+
+func (fut *Future) IsRealized() bool {
+	select {
+	case <-fut.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,7 +5,10 @@ package main
 // Imports of std libraries required by core libraries go here.
 import (
 	_ "github.com/candid82/joker/std/html"
+	_ "github.com/candid82/joker/std/json"
 	_ "github.com/candid82/joker/std/string"
+	_ "github.com/candid82/joker/std/time"
+	_ "github.com/candid82/joker/std/url"
 )
 
 import (
@@ -58,6 +61,10 @@ var CoreSourceFiles []FileInfo = []FileInfo{
 		Name:     "<joker.set>",
 		Filename: "set.joke",
 	},
+	{
+		Name:     "<joker.async>",
+		Filename: "async.joke",
+	},
 	{
 		Name:     "<joker.tools.cli>",
 		Filename: "tools_cli.joke",
@@ -94,6 +101,14 @@ var CoreSourceFiles []FileInfo = []FileInfo{
 		Name:     "<joker.better-cond>",
 		Filename: "better_cond.joke",
 	},
+	{
+		Name:     "<joker.http.route>",
+		Filename: "http_route.joke",
+	},
+	{
+		Name:     "<joker.http.middleware>",
+		Filename: "http_middleware.joke",
+	},
 }
 
 func parseArgs(args []string) {
@@ -200,7 +215,7 @@ func runtimeSortFunc(runtime []string, i, j int) bool {
 	return iStrings[1] < jStrings[1]
 }
 
-func compileLinterFile(f FileInfo) {
+func compileLinterFile(rt *Runtime, f FileInfo) {
 	const dataTemplate string = `// Generated by gen_code. Don't modify manually!
 
 // +build !gen_code
@@ -218,11 +233,11 @@ func init() {
 		fmt.Printf("READING/EMITTING LINTER FILE %s\n", f.Filename)
 	}
 
-	GLOBAL_ENV.SetCurrentNamespace(GLOBAL_ENV.CoreNamespace)
+	GLOBAL_ENV.SetCurrentNamespace(rt, GLOBAL_ENV.CoreNamespace)
 
 	file, err := os.Open("data/" + f.Filename)
 	PanicOnErr(err)
-	content, err := PackReader(NewReader(bufio.NewReader(file), f.Name), f.Filename)
+	content, err := PackReader(rt, NewReader(bufio.NewReader(file), f.Name), f.Filename)
 	PanicOnErr(err)
 	file.Close()
 
@@ -258,6 +273,7 @@ func init() {
 
 func main() {
 	parseArgs(os.Args)
+	rt := NewRuntime()
 
 	coreSourceFilename := map[string]string{}
 	namespaceIndex := 0
@@ -265,7 +281,7 @@ func main() {
 	var linterFiles []FileInfo
 
 	for _, f := range CoreSourceFiles {
-		GLOBAL_ENV.SetCurrentNamespace(GLOBAL_ENV.CoreNamespace)
+		GLOBAL_ENV.SetCurrentNamespace(rt, GLOBAL_ENV.CoreNamespace)
 		nsName := CoreNameAsNamespaceName(f.Name)
 		nsNamePtr := STRINGS.Intern(nsName)
 
@@ -295,7 +311,7 @@ func main() {
 
 		file, err := os.Open("data/" + f.Filename)
 		PanicOnErr(err)
-		err = ProcessReader(NewReader(bufio.NewReader(file), f.Name), f.Filename, EVAL)
+		err = ProcessReader(rt, NewReader(bufio.NewReader(file), f.Name), f.Filename, EVAL)
 		PanicOnErr(err)
 		file.Close()
 
@@ -306,7 +322,7 @@ func main() {
 		}
 	}
 
-	GLOBAL_ENV.SetCurrentNamespace(GLOBAL_ENV.CoreNamespace)
+	GLOBAL_ENV.SetCurrentNamespace(rt, GLOBAL_ENV.CoreNamespace)
 
 	statics := []string{}
 	runtime := []string{}
@@ -437,7 +453,7 @@ func init() {
 {runtime}
 `
 
-		GLOBAL_ENV.SetCurrentNamespace(ns)
+		GLOBAL_ENV.SetCurrentNamespace(rt, ns)
 
 		if _, found := genEnv.Namespaces[nsName]; !found {
 			if VerbosityLevel > 0 {
@@ -588,7 +604,7 @@ import (
 	/* postponed until code gen is done. */
 
 	for _, f := range linterFiles {
-		compileLinterFile(f)
+		compileLinterFile(rt, f)
 	}
 
 	/* Generate a_data.go, which contributes to populating
@@ -613,8 +629,8 @@ func (genEnv *GenEnv) emitProc(target string, p Proc) string {
 		*genEnv.GenGo.Statics = append(*genEnv.GenGo.Statics, fmt.Sprintf(`
 // package std/%s defines an init() function that sets this to the same as its local var %s:
 var %s_var ProcFn
-func %s(a []Object) Object {
-	return %s_var(a)
+func %s(rt *Runtime, a []Object) Object {
+	return %s_var(rt, a)
 }`[1:],
 			pkgName, fnName, thunkName, thunkName, thunkName))
 		newPackage = fmt.Sprintf(`
@@ -640,6 +656,18 @@ func (genEnv *GenEnv) emitPtrToRegexp(target string, v reflect.Value) string {
 	return fmt.Sprintf("nil /* %s: &%s */", genEnv.Namespace.ToString(false), source)
 }
 
+func (genEnv *GenEnv) emitPtrToBigInt(target string, v reflect.Value) string {
+	b := v.Interface().(*BigInt).BigInt()
+	if !b.IsInt64() {
+		panic(fmt.Sprintf("unsupported BigInt value %s", b))
+	}
+	source := fmt.Sprintf("MakeBigInt(%d)", b.Int64())
+	*genEnv.GenGo.Runtime = append(*genEnv.GenGo.Runtime, fmt.Sprintf(`
+	%s = %s`[1:],
+		gen_go.AsTarget(target), source))
+	return fmt.Sprintf("nil /* %s: %s */", genEnv.Namespace.ToString(false), source)
+}
+
 func coreTypeString(s string) string {
 	return strings.Replace(s, "core.", "", 1)
 }
@@ -829,6 +857,9 @@ func (genEnv *GenEnv) pointerHookFn(target string, ptr, v reflect.Value) string
 	case "regexp":
 		return genEnv.emitPtrToRegexp(target, ptr)
 	}
+	if _, ok := ptr.Interface().(*BigInt); ok {
+		return genEnv.emitPtrToBigInt(target, ptr)
+	}
 
 	switch pkg := path.Base(v.Type().PkgPath()); pkg {
 	case "core":
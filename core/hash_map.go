@@ -829,7 +829,7 @@ func (m *HashMap) Without(key Object) Map {
 	return res
 }
 
-func (m *HashMap) Call(args []Object) Object {
+func (m *HashMap) Call(rt *Runtime, args []Object) Object {
 	return callMap(m, args)
 }
 
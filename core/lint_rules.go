@@ -0,0 +1,214 @@
+package core
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	// suppression silences linter problems reported inside
+	// a form marked with :joker/ignore.
+	suppression struct {
+		pos   Position
+		rules map[string]bool // nil means all rules
+	}
+)
+
+var (
+	// Rule levels are "on" (enabled with default severity),
+	// "off", "warning" or "error".
+	defaultRuleLevels = map[string]string{
+		"if-without-else":              "off",
+		"if-with-nil-else":             "off",
+		"unused-fn-parameters":         "off",
+		"unsorted-required-namespaces": "off",
+		"unused-referred-var":          "off",
+	}
+	// Rule levels from ns metadata, by filename.
+	fileRuleLevels = map[string]map[string]string{}
+	// Suppressed forms, by filename.
+	suppressions = map[string][]suppression{}
+)
+
+func parseRuleLevel(v Object) (string, bool) {
+	switch v := v.(type) {
+	case Boolean:
+		if v.B {
+			return "on", true
+		}
+		return "off", true
+	case Keyword:
+		switch v.Name() {
+		case "off", "warning", "error":
+			return v.Name(), true
+		}
+	}
+	return "", false
+}
+
+func parseRuleLevels(rules Map) (map[string]string, error) {
+	res := map[string]string{}
+	for iter := rules.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		k, ok := p.Key.(Keyword)
+		if !ok {
+			return nil, errors.New(":rules keys must be keywords, got " + p.Key.GetType().ToString(false))
+		}
+		level, ok := parseRuleLevel(p.Value)
+		if !ok {
+			return nil, errors.New("rule " + k.ToString(false) + " must be true, false, :off, :warning or :error, got " + p.Value.ToString(true))
+		}
+		res[k.Name()] = level
+	}
+	return res, nil
+}
+
+// setFileRuleLevels applies rule levels set in ns metadata, e.g.
+// (ns ^{:joker/lint {:rules {:unused-binding :off}}} my.ns),
+// to the rest of the file.
+func setFileRuleLevels(obj Object, pos Position) {
+	m, ok := obj.(Meta)
+	if !ok || m.GetMeta() == nil {
+		return
+	}
+	ok, config := m.GetMeta().Get(KEYWORDS.jokerLint)
+	if !ok {
+		return
+	}
+	var rules Object = NIL
+	if config, ok := config.(Map); ok {
+		_, rules = config.Get(KEYWORDS.rules)
+	}
+	rulesMap, ok := rules.(Map)
+	if !ok {
+		printParseWarning(pos, "lint-config", ":joker/lint value must be a map with :rules map")
+		return
+	}
+	levels, err := parseRuleLevels(rulesMap)
+	if err != nil {
+		printParseWarning(pos, "lint-config", err.Error())
+		return
+	}
+	fileRuleLevels[pos.Filename()] = levels
+}
+
+func ruleLevel(rule string, filename string) string {
+	if level, ok := fileRuleLevels[filename][rule]; ok {
+		return level
+	}
+	if level, ok := WARNINGS.ruleLevels[rule]; ok {
+		return level
+	}
+	return defaultRuleLevels[rule]
+}
+
+func isRuleEnabled(rule string, pos Position) bool {
+	return ruleLevel(rule, pos.Filename()) != "off"
+}
+
+// withLevel returns problem kind (e.g. "Parse warning")
+// with severity changed according to level.
+func withLevel(kind string, level string) string {
+	switch level {
+	case "warning":
+		if strings.HasSuffix(kind, " error") {
+			return strings.TrimSuffix(kind, " error") + " warning"
+		}
+	case "error":
+		if strings.HasSuffix(kind, " warning") {
+			return strings.TrimSuffix(kind, " warning") + " error"
+		}
+	}
+	return kind
+}
+
+func ignoredRules(obj Object) (map[string]bool, bool) {
+	switch obj := obj.(type) {
+	case Boolean:
+		return nil, obj.B
+	case Seqable:
+		res := map[string]bool{}
+		for s := obj.Seq(); !s.IsEmpty(); s = s.Rest() {
+			switch r := s.First().(type) {
+			case Keyword:
+				res[r.Name()] = true
+			case Symbol:
+				res[r.Name()] = true
+			case String:
+				res[r.S] = true
+			}
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+// suppress records that problems found inside obj should not be
+// reported if ignore (either true or a vector of rules) says so.
+func suppress(obj Object, ignore Object) {
+	if !LINTER_MODE {
+		return
+	}
+	info := obj.GetInfo()
+	if info == nil {
+		return
+	}
+	rules, ok := ignoredRules(ignore)
+	if !ok {
+		return
+	}
+	filename := info.Filename()
+	suppressions[filename] = append(suppressions[filename], suppression{pos: info.Position, rules: rules})
+}
+
+// suppressIfIgnored handles :joker/ignore key in obj's metadata.
+func suppressIfIgnored(obj Object, meta Map) {
+	if ok, ignore := meta.Get(KEYWORDS.jokerIgnore); ok {
+		suppress(obj, ignore)
+	}
+}
+
+// ignoreMarker returns the value of :joker/ignore marker discarded
+// with #_, i.e. #_:joker/ignore or #_{:joker/ignore [rule ...]}.
+func ignoreMarker(obj Object) Object {
+	switch obj := obj.(type) {
+	case Keyword:
+		if obj.Equals(KEYWORDS.jokerIgnore) {
+			return Boolean{B: true}
+		}
+	case Map:
+		if ok, ignore := obj.Get(KEYWORDS.jokerIgnore); ok {
+			return ignore
+		}
+	}
+	return nil
+}
+
+func (pos Position) contains(other Position) bool {
+	if other.startLine < pos.startLine || other.startLine > pos.endLine {
+		return false
+	}
+	if other.startLine == pos.startLine && other.startColumn < pos.startColumn {
+		return false
+	}
+	if other.startLine == pos.endLine && other.startColumn > pos.endColumn {
+		return false
+	}
+	return true
+}
+
+func isSuppressed(p *Problem) bool {
+	for _, s := range suppressions[p.Pos.Filename()] {
+		if (s.rules == nil || s.rules[p.Rule]) && s.pos.contains(p.Pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetFileLinterSettings forgets suppressed forms and rule levels
+// from ns metadata of the file, so that it can be linted again.
+func ResetFileLinterSettings(filename string) {
+	delete(fileRuleLevels, filename)
+	delete(suppressions, filename)
+}
@@ -62,6 +62,8 @@ func mapEquals(m Map, other interface{}) bool {
 	switch otherMap := other.(type) {
 	case Nil:
 		return false
+	case *Record:
+		return false
 	case Map:
 		if m.Count() != otherMap.Count() {
 			return false
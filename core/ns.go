@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 )
 
 type (
@@ -54,23 +55,57 @@ func (ns *Namespace) ResetMeta(newMeta Map) Map {
 	return ns.meta
 }
 
-func (ns *Namespace) AlterMeta(fn *Fn, args []Object) Map {
-	return AlterMeta(&ns.MetaHolder, fn, args)
+func (ns *Namespace) AlterMeta(rt *Runtime, fn *Fn, args []Object) Map {
+	return AlterMeta(rt, &ns.MetaHolder, fn, args)
 }
 
 func (ns *Namespace) Hash() uint32 {
 	return ns.hash
 }
 
+var (
+	// lazyMu guards Lazy of namespaces and lazyInits.
+	lazyMu sync.Mutex
+	// lazyInits maps namespaces being lazily initialized to channels
+	// that are closed when they are done.
+	lazyInits = map[*Namespace]chan struct{}{}
+)
+
+// MaybeLazy initializes ns if it's lazily initialized and hasn't been
+// initialized yet, or waits for it to be if that's already under way.
 func (ns *Namespace) MaybeLazy(doc string) {
-	if ns.Lazy != nil {
-		lazyFn := ns.Lazy
-		ns.Lazy = nil
-		lazyFn()
-		if VerbosityLevel > 0 {
-			fmt.Fprintf(Stderr, "NamespaceFor: Lazily initialized %s for %s\n", *ns.Name.name, doc)
-		}
+	lazyMu.Lock()
+	if done, ok := lazyInits[ns]; ok {
+		lazyMu.Unlock()
+		<-done
+		return
+	}
+	lazyFn := ns.Lazy
+	if lazyFn == nil {
+		lazyMu.Unlock()
+		return
 	}
+	ns.Lazy = nil
+	done := make(chan struct{})
+	lazyInits[ns] = done
+	lazyMu.Unlock()
+	defer func() {
+		lazyMu.Lock()
+		delete(lazyInits, ns)
+		lazyMu.Unlock()
+		close(done)
+	}()
+	lazyFn()
+	if VerbosityLevel > 0 {
+		fmt.Fprintf(Stderr, "NamespaceFor: Lazily initialized %s for %s\n", *ns.Name.name, doc)
+	}
+}
+
+// isInitialized reports whether ns is not waiting to be lazily initialized.
+func (ns *Namespace) isInitialized() bool {
+	lazyMu.Lock()
+	defer lazyMu.Unlock()
+	return ns.Lazy == nil
 }
 
 const nsHashMask uint32 = 0x90569f6f
@@ -85,6 +120,8 @@ func NewNamespace(sym Symbol) *Namespace {
 }
 
 func (ns *Namespace) Refer(sym Symbol, vr *Var) *Var {
+	envLock.Lock()
+	defer envLock.Unlock()
 	if sym.ns != nil {
 		panic(RT.NewError("Can't intern namespace-qualified symbol " + sym.ToString(false)))
 	}
@@ -93,6 +130,8 @@ func (ns *Namespace) Refer(sym Symbol, vr *Var) *Var {
 }
 
 func (ns *Namespace) ReferAll(other *Namespace) {
+	envLock.Lock()
+	defer envLock.Unlock()
 	for name, vr := range other.mappings {
 		if !vr.isPrivate {
 			ns.mappings[name] = vr
@@ -107,6 +146,8 @@ func (ns *Namespace) InternFake(sym Symbol) *Var {
 }
 
 func (ns *Namespace) Intern(sym Symbol) *Var {
+	envLock.Lock()
+	defer envLock.Unlock()
 	if sym.ns != nil {
 		panic(RT.NewError("Can't intern namespace-qualified symbol " + sym.ToString(false)))
 	}
@@ -128,7 +169,7 @@ func (ns *Namespace) Intern(sym Symbol) *Var {
 			}
 			ns.mappings[sym.name] = newVar
 			if !strings.HasPrefix(ns.Name.Name(), "joker.") {
-				printParseWarning(sym.GetInfo().Pos(), fmt.Sprintf("WARNING: %s already refers to: %s in namespace %s, being replaced by: %s\n",
+				printParseWarning(sym.GetInfo().Pos(), "core-var-replaced", fmt.Sprintf("WARNING: %s already refers to: %s in namespace %s, being replaced by: %s\n",
 					sym.ToString(false), existingVar.ToString(false), ns.Name.ToString(false), newVar.ToString(false)))
 			}
 			return newVar
@@ -138,7 +179,7 @@ func (ns *Namespace) Intern(sym Symbol) *Var {
 	}
 	if LINTER_MODE && existingVar.expr != nil && !existingVar.ns.Name.Equals(SYMBOLS.joker_core) {
 		if !isDeclaredInConfig(existingVar) {
-			printParseWarning(sym.GetInfo().Pos(), "Duplicate def of "+existingVar.ToString(false))
+			printParseWarning(sym.GetInfo().Pos(), "duplicate-def", "Duplicate def of "+existingVar.ToString(false))
 		}
 	}
 	return existingVar
@@ -162,7 +203,7 @@ func isDeclaredInConfig(vr *Var) bool {
 
 func (ns *Namespace) InternVar(name string, val Object, meta *ArrayMap) *Var {
 	vr := ns.Intern(MakeSymbol(name))
-	vr.Value = val
+	vr.setRoot(val)
 	meta.Add(KEYWORDS.ns, ns)
 	meta.Add(KEYWORDS.name, vr.name)
 	vr.meta = meta
@@ -170,6 +211,8 @@ func (ns *Namespace) InternVar(name string, val Object, meta *ArrayMap) *Var {
 }
 
 func (ns *Namespace) AddAlias(alias Symbol, namespace *Namespace) {
+	envLock.Lock()
+	defer envLock.Unlock()
 	if alias.ns != nil {
 		panic(RT.NewError("Alias can't be namespace-qualified"))
 	}
@@ -177,7 +220,7 @@ func (ns *Namespace) AddAlias(alias Symbol, namespace *Namespace) {
 	if existing != nil && existing != namespace {
 		msg := "Alias " + alias.ToString(false) + " already exists in namespace " + ns.Name.ToString(false) + ", aliasing " + existing.Name.ToString(false)
 		if LINTER_MODE {
-			printParseError(GetPosition(alias), msg)
+			printParseError(GetPosition(alias), "duplicate-alias", msg)
 			return
 		}
 		panic(RT.NewError(msg))
@@ -186,13 +229,41 @@ func (ns *Namespace) AddAlias(alias Symbol, namespace *Namespace) {
 }
 
 func (ns *Namespace) Resolve(name string) *Var {
+	envLock.Lock()
+	defer envLock.Unlock()
 	return ns.mappings[STRINGS.Intern(name)]
 }
 
+// UnmapDefinedIn removes the vars of ns that were defined in filename,
+// so that they don't linger once their definitions are gone.
+func (ns *Namespace) UnmapDefinedIn(filename string) {
+	envLock.Lock()
+	defer envLock.Unlock()
+	for name, vr := range ns.mappings {
+		if vr.ns == ns && vr.GetInfo() != nil && vr.GetInfo().Filename() == filename {
+			delete(ns.mappings, name)
+		}
+	}
+}
+
+// Mappings returns a snapshot of ns's mappings.
 func (ns *Namespace) Mappings() map[*string]*Var {
-	return ns.mappings
+	envLock.Lock()
+	defer envLock.Unlock()
+	res := make(map[*string]*Var, len(ns.mappings))
+	for k, v := range ns.mappings {
+		res[k] = v
+	}
+	return res
 }
 
+// Aliases returns a snapshot of ns's aliases.
 func (ns *Namespace) Aliases() map[*string]*Namespace {
-	return ns.aliases
+	envLock.Lock()
+	defer envLock.Unlock()
+	res := make(map[*string]*Namespace, len(ns.aliases))
+	for k, v := range ns.aliases {
+		res[k] = v
+	}
+	return res
 }
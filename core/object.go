@@ -1,4 +1,4 @@
-//go:generate go run gen/gen_types.go assert Comparable *Vector Char String Symbol Keyword *Regex Boolean Time Number Seqable Callable *Type Meta Int Double Stack Map Set Associative Reversible Named Comparator *Ratio *Namespace *Var Error *Fn Deref *Atom Ref KVReduce Pending *File io.Reader io.Writer StringReader io.RuneReader *Channel
+//go:generate go run gen/gen_types.go assert Comparable *Vector Char String Symbol Keyword *Regex Boolean Time Number Seqable Callable *Type Meta Int Double Stack Map Set Associative Reversible Named Comparator *Ratio *Namespace *Var Error *Fn Deref *Atom Ref KVReduce Pending *File io.Reader io.Writer StringReader io.RuneReader *Channel *ChannelBuffer *Future *Bytes UUID
 //go:generate go run gen/gen_types.go info *List *ArrayMapSeq *ArrayMap *HashMap *ExInfo *Fn *Var Nil *Ratio *BigInt *BigFloat Char Double Int Boolean Time Keyword *Regex Symbol String *LazySeq *MappingSeq *ArraySeq *ConsSeq *NodeSeq *ArrayNodeSeq *MapSet *Vector *VectorSeq *VectorRSeq
 //go:generate go run -tags gen_code gen_code/gen_code.go
 
@@ -18,6 +18,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 	"unsafe"
@@ -38,6 +40,8 @@ type (
 		MetaHolder
 		name        string
 		reflectType reflect.Type
+		fields      []Keyword // non-nil for types created by defrecord and deftype
+		super       *Type     // non-nil for error classes (see RegErrorClass)
 	}
 	Object interface {
 		Equality
@@ -64,7 +68,7 @@ type (
 		WithMeta(Map) Object
 	}
 	Ref interface {
-		AlterMeta(fn *Fn, args []Object) Map
+		AlterMeta(rt *Runtime, fn *Fn, args []Object) Map
 		ResetMeta(m Map) Map
 	}
 	MetaHolder struct {
@@ -147,8 +151,11 @@ type (
 		isGloballyUsed bool
 		isFake         bool
 		taggedType     *Type
+		isThreadBound  int32        // set once v has been bound by push-thread-bindings__
+		mu             sync.RWMutex // guards Value and meta
+		alterMu        sync.Mutex   // serializes alterRoot calls
 	}
-	ProcFn func([]Object) Object
+	ProcFn func(*Runtime, []Object) Object
 	Proc   struct {
 		Fn      ProcFn
 		Name    string
@@ -167,7 +174,13 @@ type (
 	}
 	RecurBindings []Object
 	Delay         struct {
-		fn    Callable
+		fn      Callable
+		value   Object
+		forcing *Runtime      // runtime calling fn, if any
+		done    chan struct{} // closed when the call of fn by forcing returns
+		mu      sync.Mutex
+	}
+	Reduced struct {
 		value Object
 	}
 	Sequential interface {
@@ -201,11 +214,12 @@ type (
 		Namespace() string
 	}
 	Comparator interface {
-		Compare(a, b Object) int
+		Compare(rt *Runtime, a, b Object) int
 	}
 	SortableSlice struct {
 		s   []Object
 		cmp Comparator
+		rt  *Runtime
 	}
 	Printer interface {
 		Print(writer io.Writer, printReadably bool)
@@ -221,16 +235,18 @@ type (
 	}
 	Atom struct {
 		MetaHolder
-		value Object
+		value   Object
+		version uint64 // incremented on each change of value
+		mu      sync.Mutex
 	}
 	Deref interface {
-		Deref() Object
+		Deref(rt *Runtime) Object
 	}
 	Native interface {
 		Native() interface{}
 	}
 	KVReduce interface {
-		kvreduce(c Callable, init Object) Object
+		kvreduce(rt *Runtime, c Callable, init Object) Object
 	}
 	Pending interface {
 		IsRealized() bool
@@ -244,6 +260,8 @@ type (
 		Counted        *Type
 		Deref          *Type
 		Channel        *Type
+		ChannelBuffer  *Type
+		Future         *Type
 		Error          *Type
 		Gettable       *Type
 		Indexed        *Type
@@ -254,6 +272,7 @@ type (
 		Meta           *Type
 		Named          *Type
 		Number         *Type
+		Object         *Type
 		Pending        *Type
 		Ref            *Type
 		Reversible     *Type
@@ -273,11 +292,16 @@ type (
 		Boolean        *Type
 		Time           *Type
 		Buffer         *Type
+		Bytes          *Type
 		Char           *Type
 		ConsSeq        *Type
 		Delay          *Type
 		Double         *Type
 		EvalError      *Type
+		ArityError     *Type
+		IOError        *Type
+		ReadError      *Type
+		TimeoutError   *Type
 		ExInfo         *Type
 		Fn             *Type
 		File           *Type
@@ -295,11 +319,15 @@ type (
 		Proc           *Type
 		ProcFn         *Type
 		Ratio          *Type
+		Record         *Type
+		Reduced        *Type
 		RecurBindings  *Type
 		Regex          *Type
 		String         *Type
 		Symbol         *Type
+		TaggedLiteral  *Type
 		Type           *Type
+		UUID           *Type
 		Var            *Type
 		Vector         *Type
 		VectorRSeq     *Type
@@ -314,7 +342,21 @@ func (pos Position) Filename() string {
 	return *pos.filename
 }
 
-var hasher hash.Hash32 = fnv.New32a()
+func (pos Position) StartLine() int {
+	return pos.startLine
+}
+
+func (pos Position) StartColumn() int {
+	return pos.startColumn
+}
+
+func (pos Position) EndLine() int {
+	return pos.endLine
+}
+
+func (pos Position) EndColumn() int {
+	return pos.endColumn
+}
 
 func newIteratorError() error {
 	return errors.New("Iterator reached the end of collection")
@@ -327,8 +369,7 @@ func uint32ToBytes(i uint32) []byte {
 }
 
 func getHash() hash.Hash32 {
-	hasher.Reset()
-	return hasher
+	return fnv.New32a()
 }
 
 func hashSymbol(ns, name *string) uint32 {
@@ -388,8 +429,11 @@ func MakeKeyword(nsname string) Keyword {
 }
 
 func PanicArity(n int) {
-	name := RT.currentExpr.(Traceable).Name()
-	panic(RT.NewError(fmt.Sprintf("Wrong number of args (%d) passed to %s", n, name)))
+	err := RT.newCallError(func(name string) string {
+		return fmt.Sprintf("Wrong number of args (%d) passed to %s", n, name)
+	})
+	err.class = TYPE.ArityError
+	panic(err)
 }
 
 func rangeString(min, max int) string {
@@ -409,8 +453,15 @@ func rangeString(min, max int) string {
 }
 
 func PanicArityMinMax(n, min, max int) {
-	name := RT.currentExpr.(Traceable).Name()
-	panic(RT.NewError(fmt.Sprintf("Wrong number of args (%d) passed to %s; expects %s", n, name, rangeString(min, max))))
+	RT.panicArityMinMax(n, min, max)
+}
+
+func (rt *Runtime) panicArityMinMax(n, min, max int) {
+	err := rt.newCallError(func(name string) string {
+		return fmt.Sprintf("Wrong number of args (%d) passed to %s; expects %s", n, name, rangeString(min, max))
+	})
+	err.class = TYPE.ArityError
+	panic(err)
 }
 
 func CheckArity(args []Object, min int, max int) {
@@ -423,7 +474,7 @@ func CheckArity(args []Object, min int, max int) {
 func getMap(k Object, args []Object) Object {
 	CheckArity(args, 1, 2)
 	switch m := args[0].(type) {
-	case Map:
+	case Gettable:
 		ok, v := m.Get(k)
 		if ok {
 			return v
@@ -444,7 +495,7 @@ func (s SortableSlice) Swap(i, j int) {
 }
 
 func (s SortableSlice) Less(i, j int) bool {
-	return s.cmp.Compare(s.s[i], s.s[j]) == -1
+	return s.cmp.Compare(s.rt, s.s[i], s.s[j]) == -1
 }
 
 func HashPtr(ptr uintptr) uint32 {
@@ -482,7 +533,7 @@ func equalsNumbers(x Number, y interface{}) bool {
 }
 
 func (a *Atom) ToString(escape bool) string {
-	return "#object[Atom {:val " + a.value.ToString(escape) + "}]"
+	return "#object[Atom {:val " + a.load().ToString(escape) + "}]"
 }
 
 func (a *Atom) Equals(other interface{}) bool {
@@ -506,9 +557,9 @@ func (a *Atom) WithInfo(info *ObjectInfo) Object {
 }
 
 func (a *Atom) WithMeta(meta Map) Object {
-	res := *a
-	res.meta = SafeMerge(res.meta, meta)
-	return &res
+	res := &Atom{value: a.load()}
+	res.meta = SafeMerge(a.meta, meta)
+	return res
 }
 
 func (a *Atom) ResetMeta(newMeta Map) Map {
@@ -516,14 +567,54 @@ func (a *Atom) ResetMeta(newMeta Map) Map {
 	return a.meta
 }
 
-func (a *Atom) AlterMeta(fn *Fn, args []Object) Map {
-	return AlterMeta(&a.MetaHolder, fn, args)
+func (a *Atom) AlterMeta(rt *Runtime, fn *Fn, args []Object) Map {
+	return AlterMeta(rt, &a.MetaHolder, fn, args)
+}
+
+func (a *Atom) Deref(rt *Runtime) Object {
+	return a.load()
 }
 
-func (a *Atom) Deref() Object {
+func (a *Atom) load() Object {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	return a.value
 }
 
+func (a *Atom) snapshot() (Object, uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.value, a.version
+}
+
+// Swap sets the value of a to f applied to its current value and returns
+// the old and the new values. f is called without holding the lock and
+// may be called more than once if other goroutines change a concurrently.
+func (a *Atom) Swap(f func(Object) Object) (Object, Object) {
+	for {
+		old, version := a.snapshot()
+		res := f(old)
+		a.mu.Lock()
+		if a.version == version {
+			a.value = res
+			a.version++
+			a.mu.Unlock()
+			return old, res
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Reset sets the value of a to val and returns the old value.
+func (a *Atom) Reset(val Object) Object {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	old := a.value
+	a.value = val
+	a.version++
+	return old
+}
+
 func (d *Delay) ToString(escape bool) string {
 	return "#object[Delay]"
 }
@@ -548,21 +639,83 @@ func (d *Delay) WithInfo(info *ObjectInfo) Object {
 	return d
 }
 
-func (d *Delay) Force() Object {
-	if d.value == nil {
-		d.value = d.fn.Call([]Object{})
+// Force returns the value of d, calling its function in rt unless
+// it has been called already. Concurrent calls wait for the first one.
+// Forcing d while it's being forced by the same code throws an error.
+func (d *Delay) Force(rt *Runtime) Object {
+	d.mu.Lock()
+	if res := d.value; res != nil {
+		d.mu.Unlock()
+		return res
 	}
-	return d.value
+	if d.forcing != nil {
+		if rt.descendsFrom(d.forcing) {
+			d.mu.Unlock()
+			panic(rt.NewError("Delay is forced recursively"))
+		}
+		done := d.done
+		d.mu.Unlock()
+		select {
+		case <-done:
+		case <-rt.interrupts():
+			rt.throwInterrupted()
+		}
+		// If the function threw, it's called again.
+		return d.Force(rt)
+	}
+	d.forcing = rt
+	d.done = make(chan struct{})
+	d.mu.Unlock()
+	var res Object
+	defer func() {
+		d.mu.Lock()
+		d.value = res
+		d.forcing = nil
+		close(d.done)
+		d.mu.Unlock()
+	}()
+	res = d.fn.Call(rt, []Object{})
+	return res
 }
 
-func (d *Delay) Deref() Object {
-	return d.Force()
+func (d *Delay) Deref(rt *Runtime) Object {
+	return d.Force(rt)
 }
 
 func (d *Delay) IsRealized() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	return d.value != nil
 }
 
+func (r *Reduced) ToString(escape bool) string {
+	return "#object[Reduced]"
+}
+
+func (r *Reduced) Equals(other interface{}) bool {
+	return r == other
+}
+
+func (r *Reduced) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (r *Reduced) GetType() *Type {
+	return TYPE.Reduced
+}
+
+func (r *Reduced) Hash() uint32 {
+	return HashPtr(uintptr(unsafe.Pointer(r)))
+}
+
+func (r *Reduced) WithInfo(info *ObjectInfo) Object {
+	return r
+}
+
+func (r *Reduced) Deref(rt *Runtime) Object {
+	return r.value
+}
+
 func (t *Type) ToString(escape bool) string {
 	return t.name
 }
@@ -626,25 +779,33 @@ func (exInfo *ExInfo) Message() Object {
 	return NIL
 }
 
-func (exInfo *ExInfo) Error() string {
-	var pos Position
+// problem describes exInfo as a linter problem. Linter macros set
+// :_prefix (e.g. "Parse warning") and :_rule in the data map.
+func (exInfo *ExInfo) problem() *Problem {
+	res := &Problem{Kind: "Exception", Rule: "exception"}
 	_, data := exInfo.Get(KEYWORDS.data)
 	ok, form := data.(Map).Get(KEYWORDS.form)
 	if ok {
 		if form.GetInfo() != nil {
-			pos = form.GetInfo().Pos()
+			res.Pos = form.GetInfo().Pos()
 		}
 	}
-	prefix := "Exception"
 	if ok, pr := data.(Map).Get(KEYWORDS._prefix); ok {
-		prefix = pr.ToString(false)
+		res.Kind = pr.ToString(false)
+	}
+	if ok, rule := data.(Map).Get(KEYWORDS._rule); ok {
+		res.Rule = rule.ToString(false)
 	}
 	_, msg := exInfo.Get(KEYWORDS.message)
-	if len(exInfo.rt.callstack.frames) > 0 && !LINTER_MODE {
-		return fmt.Sprintf("%s:%d:%d: %s: %s\nStacktrace:\n%s", pos.Filename(), pos.startLine, pos.startColumn, prefix, msg.(String).S, exInfo.rt.stacktrace())
-	} else {
-		return fmt.Sprintf("%s:%d:%d: %s: %s", pos.Filename(), pos.startLine, pos.startColumn, prefix, msg.(String).S)
+	res.Message = msg.(String).S
+	return res
+}
+
+func (exInfo *ExInfo) Error() string {
+	if exInfo.rt.callstack != nil && !LINTER_MODE {
+		return fmt.Sprintf("%s\nStacktrace:\n%s", exInfo.problem().String(), exInfo.rt.stacktrace())
 	}
+	return exInfo.problem().String()
 }
 
 func (fn *Fn) ToString(escape bool) string {
@@ -674,15 +835,16 @@ func (fn *Fn) Hash() uint32 {
 	return HashPtr(uintptr(unsafe.Pointer(fn)))
 }
 
-func (fn *Fn) Call(args []Object) Object {
+func (fn *Fn) Call(rt *Runtime, args []Object) Object {
 	min := math.MaxInt32
 	max := -1
 	for _, arity := range fn.fnExpr.arities {
 		a := len(arity.args)
 		if a == len(args) {
-			RT.pushFrame()
-			defer RT.popFrame()
-			return evalLoop(arity.body, fn.env.addFrame(args))
+			caller := rt.pushFrame()
+			res := evalLoop(rt, arity.body, fn.env.addFrame(args, arity.args))
+			rt.callstack = caller
+			return res
 		}
 		if min > a {
 			min = a
@@ -705,7 +867,7 @@ func (fn *Fn) Call(args []Object) Object {
 				max -= 2
 			}
 		}
-		PanicArityMinMax(c, min, max)
+		rt.panicArityMinMax(c, min, max)
 	}
 	var restArgs Object = NIL
 	if len(v.args)-1 < len(args) {
@@ -716,18 +878,19 @@ func (fn *Fn) Call(args []Object) Object {
 		vargs[i] = args[i]
 	}
 	vargs[len(vargs)-1] = restArgs
-	RT.pushFrame()
-	defer RT.popFrame()
-	return evalLoop(v.body, fn.env.addFrame(vargs))
+	caller := rt.pushFrame()
+	res := evalLoop(rt, v.body, fn.env.addFrame(vargs, v.args))
+	rt.callstack = caller
+	return res
 }
 
-func compare(c Callable, a, b Object) int {
-	switch r := c.Call([]Object{a, b}).(type) {
+func compare(rt *Runtime, c Callable, a, b Object) int {
+	switch r := c.Call(rt, []Object{a, b}).(type) {
 	case Boolean:
 		if r.B {
 			return -1
 		}
-		if AssertBoolean(c.Call([]Object{b, a}), "").B {
+		if AssertBoolean(c.Call(rt, []Object{b, a}), "").B {
 			return 1
 		}
 		return 0
@@ -736,16 +899,16 @@ func compare(c Callable, a, b Object) int {
 	}
 }
 
-func (fn *Fn) Compare(a, b Object) int {
-	return compare(fn, a, b)
+func (fn *Fn) Compare(rt *Runtime, a, b Object) int {
+	return compare(rt, fn, a, b)
 }
 
-func (p Proc) Call(args []Object) Object {
-	return p.Fn(args)
+func (p Proc) Call(rt *Runtime, args []Object) Object {
+	return p.Fn(rt, args)
 }
 
-func (p Proc) Compare(a, b Object) int {
-	return compare(p, a, b)
+func (p Proc) Compare(rt *Runtime, a, b Object) int {
+	return compare(rt, p, a, b)
 }
 
 func (p Proc) ToString(escape bool) string {
@@ -788,13 +951,13 @@ func (m MetaHolder) GetMeta() Map {
 	return m.meta
 }
 
-func AlterMeta(m *MetaHolder, fn *Fn, args []Object) Map {
+func AlterMeta(rt *Runtime, m *MetaHolder, fn *Fn, args []Object) Map {
 	meta := m.meta
 	if meta == nil {
 		meta = NIL
 	}
 	fargs := append([]Object{meta}, args...)
-	m.meta = AssertMap(fn.Call(fargs), "")
+	m.meta = AssertMap(fn.Call(rt, fargs), "")
 	return m.meta
 }
 
@@ -808,6 +971,28 @@ func (v *Var) Name() string {
 	return v.ns.Name.ToString(false) + "/" + v.name.ToString(false)
 }
 
+func (v *Var) Namespace() *Namespace {
+	return v.ns
+}
+
+func (v *Var) Expr() Expr {
+	return v.expr
+}
+
+func (v *Var) IsPrivate() bool {
+	return v.isPrivate
+}
+
+func (v *Var) IsMacro() bool {
+	return v.isMacro
+}
+
+// SetMacro marks v as a macro. Used by std namespaces
+// that implement macros in Go.
+func (v *Var) SetMacro() {
+	v.isMacro = true
+}
+
 func (v *Var) ToString(escape bool) string {
 	return "#'" + v.Name()
 }
@@ -818,18 +1003,40 @@ func (v *Var) Equals(other interface{}) bool {
 }
 
 func (v *Var) WithMeta(meta Map) Object {
-	res := *v
-	res.meta = SafeMerge(res.meta, meta)
-	return &res
+	res := &Var{
+		InfoHolder:     v.InfoHolder,
+		ns:             v.ns,
+		name:           v.name,
+		Value:          v.root(),
+		expr:           v.expr,
+		isMacro:        v.isMacro,
+		isPrivate:      v.isPrivate,
+		isDynamic:      v.isDynamic,
+		isUsed:         v.isUsed,
+		isGloballyUsed: v.isGloballyUsed,
+		isFake:         v.isFake,
+		taggedType:     v.taggedType,
+		isThreadBound:  atomic.LoadInt32(&v.isThreadBound),
+	}
+	res.meta = SafeMerge(v.GetMeta(), meta)
+	return res
+}
+
+func (v *Var) GetMeta() Map {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.meta
 }
 
 func (v *Var) ResetMeta(newMeta Map) Map {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	v.meta = newMeta
 	return v.meta
 }
 
-func (v *Var) AlterMeta(fn *Fn, args []Object) Map {
-	return AlterMeta(&v.MetaHolder, fn, args)
+func (v *Var) AlterMeta(rt *Runtime, fn *Fn, args []Object) Map {
+	return AlterMeta(rt, &v.MetaHolder, fn, args)
 }
 
 func (v *Var) GetType() *Type {
@@ -840,22 +1047,89 @@ func (v *Var) Hash() uint32 {
 	return HashPtr(uintptr(unsafe.Pointer(v)))
 }
 
-func (v *Var) Resolve() Object {
-	if v.Value == nil {
-		return NIL
+// Resolve returns the value of v bound in rt, if any,
+// or else the root value of v.
+func (v *Var) Resolve(rt *Runtime) Object {
+	if atomic.LoadInt32(&v.isThreadBound) != 0 {
+		if b := rt.binding(v); b != nil {
+			return b.value.Load().(boundValue).obj
+		}
+	}
+	if res := v.root(); res != nil {
+		return res
 	}
-	return v.Value
+	return NIL
 }
 
-func (v *Var) Call(args []Object) Object {
-	vl := v.Resolve()
+// root returns the root value of v, which is nil if v is unbound.
+func (v *Var) root() Object {
+	v.mu.RLock()
+	res := v.Value
+	v.mu.RUnlock()
+	return res
+}
+
+func (v *Var) setRoot(val Object) {
+	v.mu.Lock()
+	v.Value = val
+	v.mu.Unlock()
+}
+
+// alterRoot replaces the root value of v with f applied to it.
+// Concurrent calls to alterRoot are serialized, so no update is lost.
+func (v *Var) alterRoot(f func(Object) Object) Object {
+	v.alterMu.Lock()
+	defer v.alterMu.Unlock()
+	old := v.root()
+	if old == nil {
+		old = NIL
+	}
+	res := f(old)
+	v.setRoot(res)
+	return res
+}
+
+// alter replaces the value of v bound in rt or, if there is no
+// such binding, the root value of v with f applied to it.
+// Like Set, it can't change a binding established in another goroutine.
+func (v *Var) alter(rt *Runtime, f func(Object) Object) Object {
+	if atomic.LoadInt32(&v.isThreadBound) != 0 {
+		if b := rt.binding(v); b != nil {
+			if !rt.descendsFrom(b.rt) {
+				panic(rt.NewError("Can't set " + v.ToString(false) + " bound in another goroutine"))
+			}
+			res := f(b.value.Load().(boundValue).obj)
+			b.value.Store(boundValue{res})
+			return res
+		}
+	}
+	return v.alterRoot(f)
+}
+
+// Set changes the value of v bound in rt
+// or, if there is no such binding, the root value of v.
+func (v *Var) Set(rt *Runtime, val Object) {
+	if atomic.LoadInt32(&v.isThreadBound) != 0 {
+		if b := rt.binding(v); b != nil {
+			if !rt.descendsFrom(b.rt) {
+				panic(rt.NewError("Can't set " + v.ToString(false) + " bound in another goroutine"))
+			}
+			b.value.Store(boundValue{val})
+			return
+		}
+	}
+	v.setRoot(val)
+}
+
+func (v *Var) Call(rt *Runtime, args []Object) Object {
+	vl := v.Resolve(rt)
 	return AssertCallable(
 		vl,
-		"Var "+v.ToString(false)+" resolves to "+vl.ToString(false)+", which is not a Fn").Call(args)
+		"Var "+v.ToString(false)+" resolves to "+vl.ToString(false)+", which is not a Fn").Call(rt, args)
 }
 
-func (v *Var) Deref() Object {
-	return v.Resolve()
+func (v *Var) Deref(rt *Runtime) Object {
+	return v.Resolve(rt)
 }
 
 func (n Nil) ToString(escape bool) string {
@@ -1062,7 +1336,8 @@ func MakeDouble(d float64) Double {
 
 func (d Double) ToString(escape bool) string {
 	res := fmt.Sprintf("%g", d.D)
-	if !strings.ContainsRune(res, '.') {
+	// Exponent notation (1e-12) is read as Double as is.
+	if !strings.ContainsAny(res, ".eIN") {
 		res += ".0"
 	}
 	return res
@@ -1169,6 +1444,9 @@ func (b Boolean) Compare(other Object) int {
 }
 
 func (t Time) ToString(escape bool) string {
+	if escape {
+		return "#inst \"" + t.T.Format(time.RFC3339Nano) + "\""
+	}
 	return t.T.String()
 }
 
@@ -1244,7 +1522,7 @@ func (k Keyword) Compare(other Object) int {
 	return strings.Compare(k.ToString(false), k2.ToString(false))
 }
 
-func (k Keyword) Call(args []Object) Object {
+func (k Keyword) Call(rt *Runtime, args []Object) Object {
 	return getMap(k, args)
 }
 
@@ -1320,7 +1598,7 @@ func (s Symbol) Compare(other Object) int {
 	return strings.Compare(s.ToString(false), s2.ToString(false))
 }
 
-func (s Symbol) Call(args []Object) Object {
+func (s Symbol) Call(rt *Runtime, args []Object) Object {
 	return getMap(s, args)
 }
 
@@ -1444,8 +1722,18 @@ func (x RecurBindings) WithInfo(info *ObjectInfo) Object {
 }
 
 func IsEqualOrImplements(abstractType *Type, concreteType *Type) bool {
+	if abstractType.super != nil {
+		for t := concreteType; t != nil; t = t.super {
+			if t == abstractType {
+				return true
+			}
+		}
+		return false
+	}
 	if abstractType.reflectType.Kind() == reflect.Interface {
 		return concreteType.reflectType.Implements(abstractType.reflectType)
+	} else if abstractType.fields != nil {
+		return concreteType == abstractType
 	} else {
 		return concreteType.reflectType == abstractType.reflectType
 	}
@@ -1483,7 +1771,7 @@ func RegRefType(name string, inst interface{}, doc string) *Type {
 	}
 	meta := MakeMeta(nil, "(Concrete reference type)"+doc, "1.0")
 	meta.Add(KEYWORDS.name, MakeString(name))
-	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst)}
+	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst), nil, nil}
 	TYPES[STRINGS.Intern(name)] = t
 	return t
 }
@@ -1494,7 +1782,21 @@ func RegType(name string, inst interface{}, doc string) *Type {
 	}
 	meta := MakeMeta(nil, "(Concrete type)"+doc, "1.0")
 	meta.Add(KEYWORDS.name, MakeString(name))
-	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst).Elem()}
+	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst).Elem(), nil, nil}
+	TYPES[STRINGS.Intern(name)] = t
+	return t
+}
+
+// RegErrorClass registers a class of errors represented by the same
+// Go type as super. Errors of the class are instances of super, but
+// not the other way around.
+func RegErrorClass(name string, super *Type, doc string) *Type {
+	if doc != "" {
+		doc = "\n  " + doc
+	}
+	meta := MakeMeta(nil, "(Error class of "+super.name+")"+doc, "1.0")
+	meta.Add(KEYWORDS.name, MakeString(name))
+	t := &Type{MetaHolder{meta}, name, super.reflectType, nil, super}
 	TYPES[STRINGS.Intern(name)] = t
 	return t
 }
@@ -1505,7 +1807,7 @@ func RegInterface(name string, inst interface{}, doc string) *Type {
 	}
 	meta := MakeMeta(nil, "(Interface type)"+doc, "1.0")
 	meta.Add(KEYWORDS.name, MakeString(name))
-	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst).Elem()}
+	t := &Type{MetaHolder{meta}, name, reflect.TypeOf(inst).Elem(), nil, nil}
 	TYPES[STRINGS.Intern(name)] = t
 	return t
 }
@@ -28,6 +28,7 @@ func init() {
 		Meta:           RegInterface("Meta", (*Meta)(nil), ""),
 		Named:          RegInterface("Named", (*Named)(nil), ""),
 		Number:         RegInterface("Number", (*Number)(nil), ""),
+		Object:         RegInterface("Object", (*Object)(nil), "Implemented by all values except nil"),
 		Pending:        RegInterface("Pending", (*Pending)(nil), ""),
 		Ref:            RegInterface("Ref", (*Ref)(nil), ""),
 		Reversible:     RegInterface("Reversible", (*Reversible)(nil), ""),
@@ -47,10 +48,13 @@ func init() {
 		Boolean:        RegType("Boolean", (*Boolean)(nil), "Wraps the Go 'bool' type"),
 		Time:           RegType("Time", (*Time)(nil), "Wraps the Go 'time.Time' type"),
 		Buffer:         RegRefType("Buffer", (*Buffer)(nil), ""),
+		Bytes:          RegRefType("Bytes", (*Bytes)(nil), "Immutable array of bytes, read and printed as #bytes \"<hex digits>\""),
 		Char:           RegType("Char", (*Char)(nil), "Wraps the Go 'rune' type"),
 		ConsSeq:        RegRefType("ConsSeq", (*ConsSeq)(nil), ""),
 		Delay:          RegRefType("Delay", (*Delay)(nil), ""),
 		Channel:        RegRefType("Channel", (*Channel)(nil), ""),
+		ChannelBuffer:  RegRefType("ChannelBuffer", (*ChannelBuffer)(nil), "Buffer of a channel created by buffer, sliding-buffer or dropping-buffer"),
+		Future:         RegRefType("Future", (*Future)(nil), "Result of an asynchronous computation started by future"),
 		Double:         RegType("Double", (*Double)(nil), "Wraps the Go 'float64' type"),
 		EvalError:      RegRefType("EvalError", (*EvalError)(nil), ""),
 		ExInfo:         RegRefType("ExInfo", (*ExInfo)(nil), ""),
@@ -70,14 +74,22 @@ func init() {
 		ParseError:    RegRefType("ParseError", (*ParseError)(nil), ""),
 		Proc:          RegRefType("Proc", (*Proc)(nil), "A callable function implemented via Go code"),
 		Ratio:         RegRefType("Ratio", (*Ratio)(nil), "Wraps the Go 'math.big/Rat' type"),
+		Record:        RegRefType("Record", (*Record)(nil), "Common type of all records created by defrecord"),
+		Reduced:       RegRefType("Reduced", (*Reduced)(nil), "Wraps a value to signal early termination of reduce"),
 		RecurBindings: RegRefType("RecurBindings", (*RecurBindings)(nil), ""),
 		Regex:         RegRefType("Regex", (*Regex)(nil), "Wraps the Go 'regexp.Regexp' type"),
 		String:        RegType("String", (*String)(nil), "Wraps the Go 'string' type"),
 		Symbol:        RegType("Symbol", (*Symbol)(nil), ""),
+		TaggedLiteral: RegRefType("TaggedLiteral", (*TaggedLiteral)(nil), "Tagged literal with no reader function, as returned by tagged-literal"),
 		Type:          RegRefType("Type", (*Type)(nil), ""),
+		UUID:          RegType("UUID", (*UUID)(nil), "Universally unique identifier, read and printed as #uuid \"<hex digits>\""),
 		Var:           RegRefType("Var", (*Var)(nil), ""),
 		Vector:        RegRefType("Vector", (*Vector)(nil), ""),
 		VectorRSeq:    RegRefType("VectorRSeq", (*VectorRSeq)(nil), ""),
 		VectorSeq:     RegRefType("VectorSeq", (*VectorSeq)(nil), ""),
 	}
+	TYPE.ArityError = RegErrorClass("ArityError", TYPE.EvalError, "Thrown when a function is called with a wrong number of arguments")
+	TYPE.IOError = RegErrorClass("IOError", TYPE.EvalError, "Thrown when an I/O operation (on files, network connections etc.) fails")
+	TYPE.ReadError = RegErrorClass("ReadError", TYPE.EvalError, "Thrown when reading code or data (e.g. by read-string) fails")
+	TYPE.TimeoutError = RegErrorClass("TimeoutError", TYPE.EvalError, "Thrown when an operation times out")
 }
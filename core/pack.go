@@ -40,12 +40,15 @@ type (
 		Bindings         map[*Binding]int
 		nextStringIndex  uint16
 		nextBindingIndex int
+		checkReadable    bool // fail on literals that cannot be read back
+		rt               *Runtime
 	}
 
 	PackHeader struct {
 		GlobalEnv *Env
 		Strings   []*string
 		Bindings  []Binding
+		rt        *Runtime
 	}
 )
 
@@ -98,7 +101,7 @@ func (env *PackEnv) Pack(p []byte) []byte {
 	return p
 }
 
-func UnpackHeader(p []byte, env *Env) (*PackHeader, []byte) {
+func UnpackHeader(rt *Runtime, p []byte, env *Env) (*PackHeader, []byte) {
 	stringCount, p := extractInt(p)
 	strs := make([]*string, stringCount)
 	for i := 0; i < stringCount; i++ {
@@ -116,6 +119,7 @@ func UnpackHeader(p []byte, env *Env) (*PackHeader, []byte) {
 	header := &PackHeader{
 		GlobalEnv: env,
 		Strings:   strs,
+		rt:        rt,
 	}
 	bindingCount, p := extractInt(p)
 	bindings := make([]Binding, bindingCount)
@@ -303,8 +307,13 @@ func packObject(obj Object, p []byte, env *PackEnv) []byte {
 	default:
 		p = append(p, NULL)
 		var buf bytes.Buffer
-		PrintObject(obj, &buf)
+		PrintObject(env.rt, obj, &buf)
 		bb := buf.Bytes()
+		if env.checkReadable {
+			if _, err := TryRead(env.rt, NewReader(bytes.NewReader(bb), "<>")); err != nil {
+				panic(RT.NewError("Cannot pack value that cannot be read back: " + string(bb)))
+			}
+		}
 		p = appendInt(p, len(bb))
 		p = append(p, bb...)
 		return p
@@ -322,7 +331,7 @@ func unpackObject(p []byte, header *PackHeader) (Object, []byte) {
 	case NULL:
 		var size int
 		size, p = extractInt(p[1:])
-		obj := readFromReader(bytes.NewReader(p[:size]))
+		obj := readFromReader(header.rt, bytes.NewReader(p[:size]))
 		return obj, p[size:]
 	default:
 		panic(RT.NewError(fmt.Sprintf("Unknown object tag: %d", p[0])))
@@ -515,7 +524,7 @@ func unpackDefExpr(p []byte, header *PackHeader) (*DefExpr, []byte) {
 	name, p := unpackSymbol(p, header)
 	varName := name
 	varName.ns = nil
-	vr := header.GlobalEnv.CurrentNamespace().Intern(varName)
+	vr := header.GlobalEnv.CurrentNamespace(header.rt).Intern(varName)
 	value, p := UnpackExprOrNull(p, header)
 	meta, p := UnpackExprOrNull(p, header)
 	varInfo, p := unpackObjectInfo(p, header)
@@ -578,7 +587,11 @@ func (vr *Var) Pack(p []byte, env *PackEnv) []byte {
 func unpackVar(p []byte, header *PackHeader) (*Var, []byte) {
 	nsName, p := unpackSymbol(p, header)
 	name, p := unpackSymbol(p, header)
-	vr := GLOBAL_ENV.FindNamespace(nsName).mappings[name.name]
+	ns := GLOBAL_ENV.FindNamespace(nsName)
+	if ns == nil {
+		panic(RT.NewError("Error unpacking var: cannot find namespace " + *nsName.name))
+	}
+	vr := ns.mappings[name.name]
 	if vr == nil {
 		panic(RT.NewError("Error unpacking var: cannot find var " + *nsName.name + "/" + *name.name))
 	}
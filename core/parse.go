@@ -11,7 +11,7 @@ import (
 
 type (
 	Expr interface {
-		Eval(env *LocalEnv) Object
+		Eval(rt *Runtime, env *LocalEnv) Object
 		InferType() *Type
 		Pos() Position
 		Dump(includePosition bool) Map
@@ -125,8 +125,16 @@ type (
 		obj Object
 		msg string
 	}
+	// Problem is an error or a warning found by the linter. Rule is
+	// a stable identifier of the kind of problem, e.g. "unused-binding".
+	Problem struct {
+		Pos     Position
+		Kind    string // "Read error", "Parse warning", etc.
+		Rule    string
+		Message string
+	}
 	Callable interface {
-		Call(args []Object) Object
+		Call(rt *Runtime, args []Object) Object
 	}
 	Binding struct {
 		name   Symbol
@@ -141,11 +149,13 @@ type (
 	}
 	LocalEnv struct {
 		bindings []Object
+		names    []Symbol // names of bindings, used by the debugger
 		parent   *LocalEnv
 		frame    int
 	}
 	ParseContext struct {
 		GlobalEnv              *Env
+		Runtime                *Runtime // runtime macros are expanded in
 		localBindings          *Bindings
 		loopBindings           [][]Symbol
 		linterBindings         *Bindings
@@ -153,50 +163,52 @@ type (
 		noRecurAllowed         bool
 		isUnknownCallableScope bool
 	}
+	referredVar struct {
+		sym Symbol
+		vr  *Var
+	}
 	Warnings struct {
-		ifWithoutElse           bool
-		unusedFnParameters      bool
-		fnWithEmptyBody         bool
+		ruleLevels              map[string]string
 		ignoredUnusedNamespaces Set
 		IgnoredFileRegexes      []*regexp.Regexp
 		entryPoints             Set
 	}
 	Keywords struct {
-		tag                Keyword
-		skipUnused         Keyword
-		private            Keyword
-		line               Keyword
-		column             Keyword
-		file               Keyword
-		ns                 Keyword
-		macro              Keyword
-		message            Keyword
-		form               Keyword
-		data               Keyword
-		cause              Keyword
-		arglist            Keyword
-		doc                Keyword
-		added              Keyword
-		meta               Keyword
-		knownMacros        Keyword
-		rules              Keyword
-		ifWithoutElse      Keyword
-		unusedFnParameters Keyword
-		fnWithEmptyBody    Keyword
-		_prefix            Keyword
-		pos                Keyword
-		startLine          Keyword
-		endLine            Keyword
-		startColumn        Keyword
-		endColumn          Keyword
-		filename           Keyword
-		object             Keyword
-		type_              Keyword
-		var_               Keyword
-		value              Keyword
-		vector             Keyword
-		name               Keyword
-		dynamic            Keyword
+		tag         Keyword
+		skipUnused  Keyword
+		private     Keyword
+		line        Keyword
+		column      Keyword
+		file        Keyword
+		ns          Keyword
+		macro       Keyword
+		message     Keyword
+		form        Keyword
+		data        Keyword
+		cause       Keyword
+		arglist     Keyword
+		doc         Keyword
+		added       Keyword
+		meta        Keyword
+		knownMacros Keyword
+		rules       Keyword
+		jokerIgnore Keyword
+		jokerLint   Keyword
+		_prefix     Keyword
+		_rule       Keyword
+		pos         Keyword
+		startLine   Keyword
+		endLine     Keyword
+		startColumn Keyword
+		endColumn   Keyword
+		filename    Keyword
+		object      Keyword
+		type_       Keyword
+		var_        Keyword
+		value       Keyword
+		vector      Keyword
+		name        Keyword
+		dynamic     Keyword
 	}
 	Symbols struct {
 		joker_core         Symbol
@@ -230,6 +242,8 @@ type (
 		hashMap            Symbol
 		hashSet            Symbol
 		defaultDataReaders Symbol
+		dataReaders        Symbol
+		defaultReaderFn    Symbol
 		backslash          Symbol
 		deref              Symbol
 	}
@@ -261,9 +275,19 @@ var (
 	CREATE_NS_VAR  *Var
 	IN_NS_VAR      *Var
 	WARNINGS       = Warnings{
-		fnWithEmptyBody: true,
-		entryPoints:     EmptySet(),
-	}
+		ruleLevels:  map[string]string{},
+		entryPoints: EmptySet(),
+	}
+	referredVars []referredVar
+	// When set, linter problems are passed to ProblemHandler
+	// instead of being printed to Stderr.
+	ProblemHandler func(p *Problem)
+	// When set, errors that stop processing of a file are passed
+	// to ErrorHandler instead of being printed to Stderr.
+	ErrorHandler func(err error)
+	// When set, VarRefHandler is called for every reference to a var
+	// found while parsing in linter mode.
+	VarRefHandler func(vr *Var, pos Position)
 )
 
 func (b *Bindings) ToMap() Map {
@@ -277,9 +301,10 @@ func (b *Bindings) ToMap() Map {
 	return res
 }
 
-func (localEnv *LocalEnv) addEmptyFrame(capacity int) *LocalEnv {
+func (localEnv *LocalEnv) addEmptyFrame(names []Symbol) *LocalEnv {
 	res := LocalEnv{
-		bindings: make([]Object, 0, capacity),
+		bindings: make([]Object, 0, len(names)),
+		names:    names,
 		parent:   localEnv,
 	}
 	if localEnv != nil {
@@ -292,9 +317,10 @@ func (localEnv *LocalEnv) addBinding(obj Object) {
 	localEnv.bindings = append(localEnv.bindings, obj)
 }
 
-func (localEnv *LocalEnv) addFrame(values []Object) *LocalEnv {
+func (localEnv *LocalEnv) addFrame(values []Object, names []Symbol) *LocalEnv {
 	res := LocalEnv{
 		bindings: values,
+		names:    names,
 		parent:   localEnv,
 	}
 	if localEnv != nil {
@@ -306,6 +332,7 @@ func (localEnv *LocalEnv) addFrame(values []Object) *LocalEnv {
 func (localEnv *LocalEnv) replaceFrame(values []Object) *LocalEnv {
 	res := LocalEnv{
 		bindings: values,
+		names:    localEnv.names,
 		parent:   localEnv.parent,
 		frame:    localEnv.frame,
 	}
@@ -348,7 +375,7 @@ func (b *Bindings) AddBinding(sym Symbol, index int, skipUnused bool) {
 	if LINTER_MODE && !skipUnused {
 		old := b.bindings[sym.name]
 		if old != nil && needsUnusedWarning(old) {
-			printParseWarning(GetPosition(old.name), "Unused binding: "+old.name.ToString(false))
+			printParseWarning(GetPosition(old.name), "unused-binding", "Unused binding: "+old.name.ToString(false))
 		}
 	}
 	b.bindings[sym.name] = &Binding{
@@ -395,35 +422,88 @@ func (pos Position) Pos() Position {
 	return pos
 }
 
-func printError(pos Position, msg string) {
+func (p *Problem) IsError() bool {
+	return p.Kind == "Exception" || strings.HasSuffix(p.Kind, " error")
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", p.Pos.Filename(), p.Pos.startLine, p.Pos.startColumn, p.Kind, p.Message)
+}
+
+// reportProblem reports p unless its rule is turned off
+// or p is inside a form marked with :joker/ignore.
+func reportProblem(p *Problem) {
+	if p.Rule != "" {
+		level := ruleLevel(p.Rule, p.Pos.Filename())
+		if level == "off" || isSuppressed(p) {
+			return
+		}
+		p.Kind = withLevel(p.Kind, level)
+	}
 	PROBLEM_COUNT++
-	fmt.Fprintf(Stderr, "%s:%d:%d: %s\n", pos.Filename(), pos.startLine, pos.startColumn, msg)
+	if ProblemHandler != nil {
+		ProblemHandler(p)
+		return
+	}
+	fmt.Fprintln(Stderr, p.String())
 }
 
-func printParseWarning(pos Position, msg string) {
-	printError(pos, "Parse warning: "+msg)
+// reportError prints an error that stopped processing of a file.
+// In linter mode it's reported as a problem like any other.
+func reportError(err error) {
+	if LINTER_MODE {
+		var p *Problem
+		switch err := err.(type) {
+		case ReadError:
+			p = &Problem{Pos: err.Pos(), Kind: "Read error", Rule: "read-error", Message: err.msg}
+		case *ParseError:
+			p = &Problem{Pos: err.Pos(), Kind: "Parse error", Rule: "parse-error", Message: err.msg}
+		case *EvalError:
+			p = &Problem{Pos: err.Pos(), Kind: "Eval error", Rule: "eval-error", Message: err.msg}
+		case *ExInfo:
+			p = err.problem()
+		}
+		if p != nil {
+			reportProblem(p)
+			return
+		}
+	}
+	PROBLEM_COUNT++
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+		return
+	}
+	fmt.Fprintln(Stderr, err)
 }
 
-func printParseError(pos Position, msg string) {
-	printError(pos, "Parse error: "+msg)
+func printError(pos Position, kind string, rule string, msg string) {
+	reportProblem(&Problem{Pos: pos, Kind: kind, Rule: rule, Message: msg})
 }
 
-func printReadWarning(reader *Reader, msg string) {
+func printParseWarning(pos Position, rule string, msg string) {
+	printError(pos, "Parse warning", rule, msg)
+}
+
+func printParseError(pos Position, rule string, msg string) {
+	printError(pos, "Parse error", rule, msg)
+}
+
+func printReadWarning(reader *Reader, rule string, msg string) {
 	pos := Position{
 		filename:    reader.filename,
 		startColumn: reader.column,
 		startLine:   reader.line,
 	}
-	printError(pos, "Read warning: "+msg)
+	printError(pos, "Read warning", rule, msg)
 }
 
-func printReadError(reader *Reader, msg string) {
+func printReadError(reader *Reader, rule string, msg string) {
 	pos := Position{
 		filename:    reader.filename,
 		startColumn: reader.column,
 		startLine:   reader.line,
 	}
-	printError(pos, "Read error: "+msg)
+	printError(pos, "Read error", rule, msg)
 }
 
 func isIgnoredUnusedNamespace(ns *Namespace) bool {
@@ -468,16 +548,16 @@ func WarnOnGloballyUnusedNamespaces() {
 
 	sort.Strings(names)
 	for _, name := range names {
-		printParseWarning(positions[name], "globally unused namespace "+name)
+		printParseWarning(positions[name], "globally-unused-namespace", "globally unused namespace "+name)
 	}
 }
 
-func WarnOnUnusedNamespaces() {
+func WarnOnUnusedNamespaces(rt *Runtime) {
 	var names []string
 	positions := make(map[string]Position)
 
 	for _, ns := range GLOBAL_ENV.Namespaces {
-		if ns != GLOBAL_ENV.CurrentNamespace() && !ns.isUsed && !isIgnoredUnusedNamespace(ns) {
+		if ns != GLOBAL_ENV.CurrentNamespace(rt) && !ns.isUsed && !isIgnoredUnusedNamespace(ns) {
 			pos := ns.Name.GetInfo()
 			if pos != nil && pos.Filename() != "<joker.core>" && pos.Filename() != "<user>" {
 				name := ns.Name.ToString(false)
@@ -489,7 +569,7 @@ func WarnOnUnusedNamespaces() {
 
 	sort.Strings(names)
 	for _, name := range names {
-		printParseWarning(positions[name], "unused namespace "+name)
+		printParseWarning(positions[name], "unused-namespace", "unused namespace "+name)
 	}
 }
 
@@ -527,7 +607,7 @@ func WarnOnGloballyUnusedVars() {
 
 	sort.Strings(names)
 	for _, name := range names {
-		printParseWarning(positions[name], "globally unused var "+name)
+		printParseWarning(positions[name], "globally-unused-var", "globally unused var "+name)
 	}
 }
 
@@ -552,8 +632,25 @@ func WarnOnUnusedVars() {
 
 	sort.Strings(names)
 	for _, name := range names {
-		printParseWarning(positions[name], "unused var "+name)
+		printParseWarning(positions[name], "unused-private-var", "unused var "+name)
 	}
+
+	for _, r := range referredVars {
+		if !r.vr.isUsed && r.vr.ns.isUsed {
+			printParseWarning(GetPosition(r.sym), "unused-referred-var", "unused referred var "+r.sym.ToString(false))
+		}
+	}
+	referredVars = nil
+}
+
+// trackReferredVar remembers var referred by name with
+// :refer or :only to warn if it's not used.
+func trackReferredVar(sym Symbol, vr *Var) {
+	if sym.GetInfo() == nil {
+		return
+	}
+	vr.isUsed = false
+	referredVars = append(referredVars, referredVar{sym: sym, vr: vr})
 }
 
 func NewLiteralExpr(obj Object) *LiteralExpr {
@@ -599,6 +696,10 @@ func (err *ParseError) Message() Object {
 	return MakeString(err.msg)
 }
 
+func (err ParseError) Pos() Position {
+	return GetPosition(err.obj)
+}
+
 func (err ParseError) Error() string {
 	line, column, filename := 0, 0, "<file>"
 	info := err.obj.GetInfo()
@@ -688,6 +789,30 @@ func updateVar(vr *Var, info *ObjectInfo, valueExpr Expr, sym Symbol) {
 	}
 }
 
+// Defs are not evaluated in linter mode, so docstring and arglists
+// are copied into var's meta directly from the def form.
+func updateLinterMeta(vr *Var, meta Map) {
+	okDoc, doc := meta.Get(KEYWORDS.doc)
+	okArglists, arglists := meta.Get(KEYWORDS.arglist)
+	if !okDoc && !okArglists {
+		return
+	}
+	m := vr.GetMeta()
+	if m == nil {
+		m = EmptyArrayMap()
+	}
+	if okDoc {
+		m = m.Assoc(KEYWORDS.doc, doc).(Map)
+	}
+	if okArglists {
+		if seq, ok := arglists.(Seq); ok && seq.First().Equals(SYMBOLS.quote) {
+			arglists = Second(seq)
+		}
+		m = m.Assoc(KEYWORDS.arglist, arglists).(Map)
+	}
+	vr.ResetMeta(m)
+}
+
 func isCreatedByMacro(formSeq Seq) bool {
 	return formSeq.First().GetInfo().Pos().filename == STR.coreFilename
 }
@@ -699,7 +824,7 @@ func parseDef(obj Object, ctx *ParseContext, isForLinter bool) *DefExpr {
 	var meta Map
 	switch sym := s.(type) {
 	case Symbol:
-		if sym.ns != nil && (Symbol{name: sym.ns} != ctx.GlobalEnv.CurrentNamespace().Name) {
+		if sym.ns != nil && (Symbol{name: sym.ns} != ctx.GlobalEnv.CurrentNamespace(ctx.Runtime).Name) {
 			panic(&ParseError{
 				msg: "Can't create defs outside of current ns",
 				obj: obj,
@@ -707,7 +832,7 @@ func parseDef(obj Object, ctx *ParseContext, isForLinter bool) *DefExpr {
 		}
 		symWithoutNs := sym
 		symWithoutNs.ns = nil
-		vr := ctx.GlobalEnv.CurrentNamespace().Intern(symWithoutNs)
+		vr := ctx.GlobalEnv.CurrentNamespace(ctx.Runtime).Intern(symWithoutNs)
 		if isForLinter {
 			vr.isGloballyUsed = true
 		}
@@ -737,6 +862,9 @@ func parseDef(obj Object, ctx *ParseContext, isForLinter bool) *DefExpr {
 		}
 		updateVar(vr, obj.GetInfo(), res.value, sym)
 		if meta != nil {
+			if LINTER_MODE {
+				updateLinterMeta(vr, meta)
+			}
 			res.meta = Parse(DeriveReadObject(obj, meta), ctx)
 		}
 		return res
@@ -760,9 +888,9 @@ func parseBody(seq Seq, ctx *ParseContext) []Expr {
 		res = append(res, expr)
 		if LINTER_MODE {
 			if defExpr, ok := expr.(*DefExpr); ok && !defExpr.isCreatedByMacro {
-				printParseWarning(defExpr.Pos(), "inline def")
+				printParseWarning(defExpr.Pos(), "inline-def", "inline def")
 			} else if doExpr, ok := expr.(*DoExpr); ok && !doExpr.isCreatedByMacro {
-				printParseWarning(doExpr.Pos(), "redundant do form")
+				printParseWarning(doExpr.Pos(), "redundant-do", "redundant do form")
 			}
 		}
 	}
@@ -857,13 +985,13 @@ func addArity(fn *FnExpr, sig Seq, ctx *ParseContext) {
 	}
 
 	if LINTER_MODE {
-		if WARNINGS.fnWithEmptyBody {
+		if isRuleEnabled("fn-with-empty-body", arity.Position) {
 			if len(arity.body) == 0 {
-				printParseWarning(arity.Position, "fn form with empty body")
+				printParseWarning(arity.Position, "fn-with-empty-body", "fn form with empty body")
 			}
 		}
 
-		if WARNINGS.unusedFnParameters {
+		if isRuleEnabled("unused-fn-parameters", arity.Position) {
 			var unused []Symbol
 			for _, b := range ctx.localBindings.bindings {
 				if needsUnusedWarning(b) {
@@ -872,7 +1000,7 @@ func addArity(fn *FnExpr, sig Seq, ctx *ParseContext) {
 			}
 			sort.Sort(BySymbolName(unused))
 			for _, u := range unused {
-				printParseWarning(GetPosition(u), "unused parameter: "+u.ToString(false))
+				printParseWarning(GetPosition(u), "unused-fn-parameters", "unused parameter: "+u.ToString(false))
 			}
 		}
 	}
@@ -893,8 +1021,9 @@ func wrapWithMeta(fnExpr *FnExpr, obj Object, ctx *ParseContext) Expr {
 // Examples:
 // (fn f [] 1 2)
 // (fn f ([] 1 2)
-//       ([a] a 3)
-//       ([a & b] a b))
+//
+//	([a] a 3)
+//	([a & b] a b))
 func parseFn(obj Object, ctx *ParseContext) Expr {
 	res := &FnExpr{Position: GetPosition(obj)}
 	bodies := obj.(Seq).Rest()
@@ -1013,13 +1142,13 @@ func parseTry(obj Object, ctx *ParseContext) *TryExpr {
 	}
 	if LINTER_MODE {
 		if res.body == nil {
-			printParseWarning(res.Pos(), "try form with empty body")
+			printParseWarning(res.Pos(), "empty-body", "try form with empty body")
 		}
 		if res.catches == nil && res.finallyExpr == nil {
-			printParseWarning(res.Pos(), "try form without catch or finally")
+			printParseWarning(res.Pos(), "try-without-catch", "try form without catch or finally")
 		}
 		if res.finallyExpr != nil && len(res.finallyExpr) == 0 {
-			printParseWarning(GetPosition(obj), "finally form with empty body")
+			printParseWarning(GetPosition(obj), "empty-body", "finally form with empty body")
 		}
 	}
 	return res
@@ -1058,7 +1187,7 @@ func parseLetLoop(obj Object, formName string, ctx *ParseContext) *LetExpr {
 		}
 		if LINTER_MODE && formName != "loop" && b.count == 0 {
 			pos := GetPosition(obj)
-			printParseWarning(pos, formName+" form with empty bindings vector")
+			printParseWarning(pos, "empty-bindings", formName+" form with empty bindings vector")
 		}
 		skipUnused := isSkipUnused(b)
 		res.names = make([]Symbol, b.count/2)
@@ -1073,7 +1202,7 @@ func parseLetLoop(obj Object, formName string, ctx *ParseContext) *LetExpr {
 				if sym.ns != nil {
 					msg := "Can't let qualified name: " + sym.ToString(false)
 					if LINTER_MODE {
-						printParseError(GetPosition(s), msg)
+						printParseError(GetPosition(s), "qualified-binding", msg)
 					} else {
 						panic(&ParseError{obj: s, msg: msg})
 					}
@@ -1112,7 +1241,7 @@ func parseLetLoop(obj Object, formName string, ctx *ParseContext) *LetExpr {
 		if LINTER_MODE {
 			if len(res.body) == 0 {
 				pos := GetPosition(obj)
-				printParseWarning(pos, formName+" form with empty body")
+				printParseWarning(pos, "empty-body", formName+" form with empty body")
 			}
 
 			if !skipUnused {
@@ -1124,7 +1253,7 @@ func parseLetLoop(obj Object, formName string, ctx *ParseContext) *LetExpr {
 				}
 				sort.Sort(BySymbolName(unused))
 				for _, u := range unused {
-					printParseWarning(GetPosition(u), "unused binding: "+u.ToString(false))
+					printParseWarning(GetPosition(u), "unused-binding", "unused binding: "+u.ToString(false))
 				}
 			}
 		}
@@ -1161,8 +1290,8 @@ func resolveMacro(obj Object, ctx *ParseContext) *Var {
 		if ctx.GetLocalBinding(sym) != nil {
 			return nil
 		}
-		vr, ok := ctx.GlobalEnv.Resolve(sym)
-		if !ok || !vr.isMacro || vr.Value == nil {
+		vr, ok := ctx.GlobalEnv.Resolve(ctx.Runtime, sym)
+		if !ok || !vr.isMacro || vr.root() == nil {
 			return nil
 		}
 		vr.isUsed = true
@@ -1237,18 +1366,20 @@ func macroexpand1(seq Seq, ctx *ParseContext) Object {
 	if vr != nil {
 		expr := &MacroCallExpr{
 			Position: GetPosition(seq),
-			macro:    vr.Value.(Callable),
+			macro:    vr.root().(Callable),
 			args:     ToSlice(seq.Rest().Cons(ctx.localBindings.ToMap()).Cons(seq)),
 			name:     varCallableString(vr),
 		}
-		return fixInfo(Eval(expr, nil), seq.GetInfo())
+		// Macros are arbitrary code, which may wait for other goroutines.
+		defer readLock.unlockAll(ctx.Runtime)()
+		return fixInfo(ctx.Runtime.Eval(expr, nil), seq.GetInfo())
 	} else {
 		return seq
 	}
 }
 
 func reportNotAFunction(pos Position, name string) {
-	printParseWarning(pos, name+" is not a function")
+	printParseWarning(pos, "not-a-function", name+" is not a function")
 }
 
 func getTaggedType(obj Meta) *Type {
@@ -1313,7 +1444,7 @@ func checkTypes(declaredArgs []Symbol, call *CallExpr) bool {
 			passedType := call.args[i].InferType()
 			if passedType != nil {
 				if !isTypeOneOf(declaredTypes, passedType) {
-					printParseWarning(call.args[i].Pos(), fmt.Sprintf("arg[%d] of %s must have type %s, got %s", i, call.Name(), typesString(declaredTypes), passedType.ToString(false)))
+					printParseWarning(call.args[i].Pos(), "type-mismatch", fmt.Sprintf("arg[%d] of %s must have type %s, got %s", i, call.Name(), typesString(declaredTypes), passedType.ToString(false)))
 					res = true
 				}
 			}
@@ -1342,7 +1473,7 @@ func reportWrongArity(expr *FnExpr, isMacro bool, call *CallExpr, pos Position)
 	if v := selectArity(expr, passedArgsCount); v != nil {
 		return checkTypes(v.args, call)
 	}
-	printParseWarning(pos, fmt.Sprintf("Wrong number of args (%d) passed to %s", len(call.args), call.Name()))
+	printParseWarning(pos, "wrong-arity", fmt.Sprintf("Wrong number of args (%d) passed to %s", len(call.args), call.Name()))
 	return true
 }
 
@@ -1376,7 +1507,7 @@ func parseSetMacro(obj Object, ctx *ParseContext) Expr {
 			res := &SetMacroExpr{
 				vr: vr,
 			}
-			res.Eval(nil)
+			res.Eval(ctx.Runtime, nil)
 			return res
 		}
 	}
@@ -1402,7 +1533,7 @@ func isKnownMacros(sym Symbol) (bool, Seq) {
 	return false, nil
 }
 
-func isUnknownCallable(expr Expr) (bool, Seq) {
+func isUnknownCallable(expr Expr, ctx *ParseContext) (bool, Seq) {
 	if !LINTER_MODE {
 		return false, nil
 	}
@@ -1411,7 +1542,7 @@ func isUnknownCallable(expr Expr) (bool, Seq) {
 			return true, nil
 		}
 		var sym Symbol
-		if c.vr.ns != GLOBAL_ENV.CurrentNamespace() && c.vr.ns != GLOBAL_ENV.CoreNamespace {
+		if c.vr.ns != GLOBAL_ENV.CurrentNamespace(ctx.Runtime) && c.vr.ns != GLOBAL_ENV.CoreNamespace {
 			sym = Symbol{
 				ns:   c.vr.ns.Name.name,
 				name: c.vr.name.name,
@@ -1484,11 +1615,11 @@ func checkCall(expr Expr, isMacro bool, call *CallExpr, pos Position) {
 		reportWrongArity(expr, isMacro, call, pos)
 	case *MapExpr:
 		if argsCount == 0 || argsCount > 2 {
-			printParseWarning(pos, fmt.Sprintf("Wrong number of args (%d) passed to a map", argsCount))
+			printParseWarning(pos, "wrong-arity", fmt.Sprintf("Wrong number of args (%d) passed to a map", argsCount))
 		}
 	case *SetExpr:
 		if argsCount == 0 || argsCount > 1 {
-			printParseWarning(pos, fmt.Sprintf("Wrong number of args (%d) passed to a set", argsCount))
+			printParseWarning(pos, "wrong-arity", fmt.Sprintf("Wrong number of args (%d) passed to a set", argsCount))
 		}
 	case *LiteralExpr:
 		if _, ok := expr.obj.(Callable); !ok && !expr.isSurrogate {
@@ -1498,7 +1629,7 @@ func checkCall(expr Expr, isMacro bool, call *CallExpr, pos Position) {
 		switch expr.obj.(type) {
 		case Keyword:
 			if argsCount == 0 || argsCount > 2 {
-				printParseWarning(pos, fmt.Sprintf("Wrong number of args (%d) passed to %s", argsCount, call.Name()))
+				printParseWarning(pos, "wrong-arity", fmt.Sprintf("Wrong number of args (%d) passed to %s", argsCount, call.Name()))
 			}
 		}
 	case *RecurExpr:
@@ -1533,8 +1664,11 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 			return NewLiteralExpr(Second(seq))
 		case STR._if:
 			checkForm(obj, 3, 4)
-			if LINTER_MODE && SeqCount(seq) < 4 && WARNINGS.ifWithoutElse {
-				printParseWarning(pos, "missing else branch")
+			if LINTER_MODE && SeqCount(seq) < 4 && isRuleEnabled("if-without-else", pos) {
+				printParseWarning(pos, "if-without-else", "missing else branch")
+			}
+			if LINTER_MODE && SeqCount(seq) == 4 && Fourth(seq).Equals(NIL) && !isCreatedByMacro(seq) {
+				printParseWarning(pos, "if-with-nil-else", "use when instead of if with nil else branch")
 			}
 			return &IfExpr{
 				cond:     Parse(Second(seq), ctx),
@@ -1566,23 +1700,26 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 			checkForm(obj, 2, 2)
 			switch sym := Second(seq).(type) {
 			case Symbol:
-				vr, ok := ctx.GlobalEnv.Resolve(sym)
+				vr, ok := ctx.GlobalEnv.Resolve(ctx.Runtime, sym)
 				if !ok {
 					if !LINTER_MODE {
 						panic(&ParseError{obj: obj, msg: "Unable to resolve var " + sym.ToString(false) + " in this context"})
 					}
-					symNs := ctx.GlobalEnv.NamespaceFor(ctx.GlobalEnv.CurrentNamespace(), sym)
+					symNs := ctx.GlobalEnv.NamespaceFor(ctx.GlobalEnv.CurrentNamespace(ctx.Runtime), sym)
 					if !ctx.isUnknownCallableScope {
-						if symNs == nil || symNs == ctx.GlobalEnv.CurrentNamespace() {
-							printParseError(obj.GetInfo().Pos(), "Unable to resolve symbol: "+sym.ToString(false))
+						if symNs == nil || symNs == ctx.GlobalEnv.CurrentNamespace(ctx.Runtime) {
+							printParseError(obj.GetInfo().Pos(), "unresolved-symbol", "Unable to resolve symbol: "+sym.ToString(false))
 						}
 					}
-					vr = InternFakeSymbol(symNs, sym)
+					vr = InternFakeSymbol(ctx.Runtime, symNs, sym)
 				}
 				vr.isUsed = true
 				vr.isGloballyUsed = true
 				vr.ns.isUsed = true
 				vr.ns.isGloballyUsed = true
+				if LINTER_MODE && VarRefHandler != nil {
+					VarRefHandler(vr, GetPosition(sym))
+				}
 				return &LiteralExpr{
 					obj:      vr,
 					Position: pos,
@@ -1598,9 +1735,9 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 			}
 			if LINTER_MODE {
 				if len(res.body) == 0 {
-					printParseWarning(pos, "do form with empty body")
+					printParseWarning(pos, "empty-body", "do form with empty body")
 				} else if len(res.body) == 1 {
-					printParseWarning(pos, "redundant do form")
+					printParseWarning(pos, "redundant-do", "redundant do form")
 				}
 			}
 			return res
@@ -1616,7 +1753,7 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 
 	ctx.isUnknownCallableScope = currentIsUnknownCallableScope
 	callable := Parse(first, ctx)
-	unknown, syms := isUnknownCallable(callable)
+	unknown, syms := isUnknownCallable(callable, ctx)
 	if unknown {
 		ctx.isUnknownCallableScope = true
 		if syms != nil {
@@ -1657,7 +1794,10 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 							c.vr.Value.Equals(inNs.Value) ||
 							c.vr.Value.Equals(createNs.Value)) &&
 							areAllLiteralExprs(res.args) {
-							Eval(res, nil)
+							ctx.Runtime.Eval(res, nil)
+							if c.vr.Value.Equals(inNs.Value) && len(res.args) > 0 {
+								setFileRuleLevels(res.args[0].(*LiteralExpr).obj, pos)
+							}
 						}
 					}
 				case Callable:
@@ -1665,7 +1805,7 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 						if ok, arglist := m.Get(KEYWORDS.arglist); ok {
 							if arglist, ok := arglist.(Seq); ok {
 								if !checkArglist(arglist, len(res.args)) {
-									printParseWarning(pos, fmt.Sprintf("Wrong number of args (%d) passed to %s", len(res.args), res.Name()))
+									printParseWarning(pos, "wrong-arity", fmt.Sprintf("Wrong number of args (%d) passed to %s", len(res.args), res.Name()))
 								}
 							}
 						}
@@ -1684,7 +1824,7 @@ func parseList(obj Object, ctx *ParseContext) Expr {
 	return res
 }
 
-func InternFakeSymbol(ns *Namespace, sym Symbol) *Var {
+func InternFakeSymbol(rt *Runtime, ns *Namespace, sym Symbol) *Var {
 	if ns != nil {
 		fakeSym := Symbol{
 			ns:   nil,
@@ -1696,7 +1836,7 @@ func InternFakeSymbol(ns *Namespace, sym Symbol) *Var {
 		ns:   nil,
 		name: STRINGS.Intern(sym.ToString(false)),
 	}
-	return GLOBAL_ENV.CurrentNamespace().InternFake(fakeSym)
+	return GLOBAL_ENV.CurrentNamespace(rt).InternFake(fakeSym)
 }
 
 func isInteropSymbol(sym Symbol) bool {
@@ -1722,9 +1862,13 @@ func MakeVarRefExpr(vr *Var, obj Object) *VarRefExpr {
 	vr.isGloballyUsed = true
 	vr.ns.isUsed = true
 	vr.ns.isGloballyUsed = true
+	pos := GetPosition(obj)
+	if LINTER_MODE && VarRefHandler != nil {
+		VarRefHandler(vr, pos)
+	}
 	return &VarRefExpr{
 		vr:       vr,
-		Position: GetPosition(obj),
+		Position: pos,
 	}
 }
 
@@ -1738,7 +1882,7 @@ func parseSymbol(obj Object, ctx *ParseContext) Expr {
 			Position: GetPosition(obj),
 		}
 	}
-	if vr, ok := ctx.GlobalEnv.Resolve(sym); ok {
+	if vr, ok := ctx.GlobalEnv.Resolve(ctx.Runtime, sym); ok {
 		return MakeVarRefExpr(vr, obj)
 	}
 	if sym.ns == nil && TYPES[sym.name] != nil {
@@ -1754,7 +1898,7 @@ func parseSymbol(obj Object, ctx *ParseContext) Expr {
 		// Check if this is a "callable namespace"
 		ns := ctx.GlobalEnv.FindNamespace(sym)
 		if ns == nil {
-			ns = ctx.GlobalEnv.CurrentNamespace().aliases[sym.name]
+			ns = ctx.GlobalEnv.CurrentNamespace(ctx.Runtime).aliases[sym.name]
 		}
 		if ns != nil {
 			ns.isUsed = true
@@ -1768,31 +1912,33 @@ func parseSymbol(obj Object, ctx *ParseContext) Expr {
 		}
 		// Check if this is a constructor call
 		if len(parts) == 2 && parts[0] != "" && parts[len(parts)-1] == "" {
-			if vr, ok := ctx.GlobalEnv.Resolve(MakeSymbol(parts[0])); ok {
+			if vr, ok := ctx.GlobalEnv.Resolve(ctx.Runtime, MakeSymbol(parts[0])); ok {
 				return MakeVarRefExpr(vr, obj)
 			}
 		}
 	}
-	symNs := ctx.GlobalEnv.NamespaceFor(ctx.GlobalEnv.CurrentNamespace(), sym)
-	if symNs == nil || symNs == ctx.GlobalEnv.CurrentNamespace() {
+	symNs := ctx.GlobalEnv.NamespaceFor(ctx.GlobalEnv.CurrentNamespace(ctx.Runtime), sym)
+	if symNs == nil || symNs == ctx.GlobalEnv.CurrentNamespace(ctx.Runtime) {
 		if isInteropSymbol(sym) || isJavaSymbol(sym) {
 			return NewSurrogateExpr(sym)
 		}
 		if !ctx.isUnknownCallableScope {
 			if ctx.linterBindings.GetBinding(sym) == nil {
-				printParseError(obj.GetInfo().Pos(), "Unable to resolve symbol: "+sym.ToString(false))
+				printParseError(obj.GetInfo().Pos(), "unresolved-symbol", "Unable to resolve symbol: "+sym.ToString(false))
 			}
 		}
 	}
-	return MakeVarRefExpr(InternFakeSymbol(symNs, sym), obj)
+	return MakeVarRefExpr(InternFakeSymbol(ctx.Runtime, symNs, sym), obj)
 }
 
 func Parse(obj Object, ctx *ParseContext) Expr {
+	readLock.Lock(ctx.Runtime)
+	defer readLock.Unlock()
 	pos := GetPosition(obj)
 	var res Expr
 	canHaveMeta := false
 	switch v := obj.(type) {
-	case Int, String, Char, Double, *BigInt, *BigFloat, Boolean, Nil, *Ratio, Keyword, *Regex, *Type:
+	case Int, String, Char, Double, *BigInt, *BigFloat, Boolean, Nil, *Ratio, Keyword, *Regex, *Type, *Bytes, Time, UUID, *TaggedLiteral:
 		res = NewLiteralExpr(obj)
 	case *Vector:
 		canHaveMeta = true
@@ -1826,7 +1972,6 @@ func Parse(obj Object, ctx *ParseContext) Expr {
 func TryParse(obj Object, ctx *ParseContext) (expr Expr, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			PROBLEM_COUNT++
 			switch r.(type) {
 			case *ParseError:
 				err = r.(error)
@@ -5,41 +5,41 @@ package core
 var (
 	GLOBAL_ENV = NewEnv()
 	KEYWORDS   = Keywords{
-		tag:                MakeKeyword("tag"),
-		skipUnused:         MakeKeyword("skip-unused"),
-		private:            MakeKeyword("private"),
-		line:               MakeKeyword("line"),
-		column:             MakeKeyword("column"),
-		file:               MakeKeyword("file"),
-		ns:                 MakeKeyword("ns"),
-		macro:              MakeKeyword("macro"),
-		message:            MakeKeyword("message"),
-		form:               MakeKeyword("form"),
-		data:               MakeKeyword("data"),
-		cause:              MakeKeyword("cause"),
-		arglist:            MakeKeyword("arglists"),
-		doc:                MakeKeyword("doc"),
-		added:              MakeKeyword("added"),
-		meta:               MakeKeyword("meta"),
-		knownMacros:        MakeKeyword("known-macros"),
-		rules:              MakeKeyword("rules"),
-		ifWithoutElse:      MakeKeyword("if-without-else"),
-		unusedFnParameters: MakeKeyword("unused-fn-parameters"),
-		fnWithEmptyBody:    MakeKeyword("fn-with-empty-body"),
-		_prefix:            MakeKeyword("_prefix"),
-		pos:                MakeKeyword("pos"),
-		startLine:          MakeKeyword("start-line"),
-		endLine:            MakeKeyword("end-line"),
-		startColumn:        MakeKeyword("start-column"),
-		endColumn:          MakeKeyword("end-column"),
-		filename:           MakeKeyword("filename"),
-		object:             MakeKeyword("object"),
-		type_:              MakeKeyword("type"),
-		var_:               MakeKeyword("var"),
-		value:              MakeKeyword("value"),
-		vector:             MakeKeyword("vector"),
-		name:               MakeKeyword("name"),
-		dynamic:            MakeKeyword("dynamic"),
+		tag:         MakeKeyword("tag"),
+		skipUnused:  MakeKeyword("skip-unused"),
+		private:     MakeKeyword("private"),
+		line:        MakeKeyword("line"),
+		column:      MakeKeyword("column"),
+		file:        MakeKeyword("file"),
+		ns:          MakeKeyword("ns"),
+		macro:       MakeKeyword("macro"),
+		message:     MakeKeyword("message"),
+		form:        MakeKeyword("form"),
+		data:        MakeKeyword("data"),
+		cause:       MakeKeyword("cause"),
+		arglist:     MakeKeyword("arglists"),
+		doc:         MakeKeyword("doc"),
+		added:       MakeKeyword("added"),
+		meta:        MakeKeyword("meta"),
+		knownMacros: MakeKeyword("known-macros"),
+		rules:       MakeKeyword("rules"),
+		jokerIgnore: MakeKeyword("joker/ignore"),
+		jokerLint:   MakeKeyword("joker/lint"),
+		_prefix:     MakeKeyword("_prefix"),
+		_rule:       MakeKeyword("_rule"),
+		pos:         MakeKeyword("pos"),
+		startLine:   MakeKeyword("start-line"),
+		endLine:     MakeKeyword("end-line"),
+		startColumn: MakeKeyword("start-column"),
+		endColumn:   MakeKeyword("end-column"),
+		filename:    MakeKeyword("filename"),
+		object:      MakeKeyword("object"),
+		type_:       MakeKeyword("type"),
+		var_:        MakeKeyword("var"),
+		value:       MakeKeyword("value"),
+		vector:      MakeKeyword("vector"),
+		name:        MakeKeyword("name"),
+		dynamic:     MakeKeyword("dynamic"),
 	}
 	SYMBOLS = Symbols{
 		joker_core:         MakeSymbol("joker.core"),
@@ -73,6 +73,8 @@ var (
 		hashMap:            MakeSymbol("hash-map"),
 		hashSet:            MakeSymbol("hash-set"),
 		defaultDataReaders: MakeSymbol("default-data-readers"),
+		dataReaders:        MakeSymbol("*data-readers*"),
+		defaultReaderFn:    MakeSymbol("*default-data-reader-fn*"),
 		backslash:          MakeSymbol("/"),
 		deref:              MakeSymbol("deref"),
 	}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -128,7 +129,15 @@ func ExtractIOWriter(args []Object, index int) io.Writer {
 	return Ensureio_Writer(args, index)
 }
 
-var procMeta = func(args []Object) Object {
+func ExtractBytes(args []Object, index int) []byte {
+	return EnsureBytes(args, index).B
+}
+
+func ExtractBinary(args []Object, index int) []byte {
+	return EnsureBinary(args, index)
+}
+
+var procMeta = func(rt *Runtime, args []Object) Object {
 	switch obj := args[0].(type) {
 	case Meta:
 		meta := obj.GetMeta()
@@ -144,7 +153,7 @@ var procMeta = func(args []Object) Object {
 	return NIL
 }
 
-var procWithMeta = func(args []Object) Object {
+var procWithMeta = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 2, 2)
 	m := EnsureMeta(args, 0)
 	if args[1].Equals(NIL) {
@@ -153,53 +162,53 @@ var procWithMeta = func(args []Object) Object {
 	return m.WithMeta(EnsureMap(args, 1))
 }
 
-var procIsZero = func(args []Object) Object {
+var procIsZero = func(rt *Runtime, args []Object) Object {
 	n := EnsureNumber(args, 0)
 	ops := GetOps(n)
 	return Boolean{B: ops.IsZero(n)}
 }
 
-var procIsPos = func(args []Object) Object {
+var procIsPos = func(rt *Runtime, args []Object) Object {
 	n := EnsureNumber(args, 0)
 	ops := GetOps(n)
 	return Boolean{B: ops.Gt(n, Int{I: 0})}
 }
 
-var procIsNeg = func(args []Object) Object {
+var procIsNeg = func(rt *Runtime, args []Object) Object {
 	n := EnsureNumber(args, 0)
 	ops := GetOps(n)
 	return Boolean{B: ops.Lt(n, Int{I: 0})}
 }
 
-var procAdd = func(args []Object) Object {
+var procAdd = func(rt *Runtime, args []Object) Object {
 	x := AssertNumber(args[0], "")
 	y := AssertNumber(args[1], "")
 	ops := GetOps(x).Combine(GetOps(y))
 	return ops.Add(x, y)
 }
 
-var procAddEx = func(args []Object) Object {
+var procAddEx = func(rt *Runtime, args []Object) Object {
 	x := AssertNumber(args[0], "")
 	y := AssertNumber(args[1], "")
 	ops := GetOps(x).Combine(GetOps(y)).Combine(BIGINT_OPS)
 	return ops.Add(x, y)
 }
 
-var procMultiply = func(args []Object) Object {
+var procMultiply = func(rt *Runtime, args []Object) Object {
 	x := AssertNumber(args[0], "")
 	y := AssertNumber(args[1], "")
 	ops := GetOps(x).Combine(GetOps(y))
 	return ops.Multiply(x, y)
 }
 
-var procMultiplyEx = func(args []Object) Object {
+var procMultiplyEx = func(rt *Runtime, args []Object) Object {
 	x := AssertNumber(args[0], "")
 	y := AssertNumber(args[1], "")
 	ops := GetOps(x).Combine(GetOps(y)).Combine(BIGINT_OPS)
 	return ops.Multiply(x, y)
 }
 
-var procSubtract = func(args []Object) Object {
+var procSubtract = func(rt *Runtime, args []Object) Object {
 	var a, b Object
 	if len(args) == 1 {
 		a = Int{I: 0}
@@ -212,7 +221,7 @@ var procSubtract = func(args []Object) Object {
 	return ops.Subtract(AssertNumber(a, ""), AssertNumber(b, ""))
 }
 
-var procSubtractEx = func(args []Object) Object {
+var procSubtractEx = func(rt *Runtime, args []Object) Object {
 	var a, b Object
 	if len(args) == 1 {
 		a = Int{I: 0}
@@ -225,28 +234,28 @@ var procSubtractEx = func(args []Object) Object {
 	return ops.Subtract(AssertNumber(a, ""), AssertNumber(b, ""))
 }
 
-var procDivide = func(args []Object) Object {
+var procDivide = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	y := EnsureNumber(args, 1)
 	ops := GetOps(x).Combine(GetOps(y))
 	return ops.Divide(x, y)
 }
 
-var procQuot = func(args []Object) Object {
+var procQuot = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	y := EnsureNumber(args, 1)
 	ops := GetOps(x).Combine(GetOps(y))
 	return ops.Quotient(x, y)
 }
 
-var procRem = func(args []Object) Object {
+var procRem = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	y := EnsureNumber(args, 1)
 	ops := GetOps(x).Combine(GetOps(y))
 	return ops.Rem(x, y)
 }
 
-var procBitNot = func(args []Object) Object {
+var procBitNot = func(rt *Runtime, args []Object) Object {
 	x := AssertInt(args[0], "Bit operation not supported for "+args[0].GetType().ToString(false))
 	return Int{I: ^x.I}
 }
@@ -257,65 +266,65 @@ func AssertInts(args []Object) (Int, Int) {
 	return x, y
 }
 
-var procBitAnd = func(args []Object) Object {
+var procBitAnd = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I & y.I}
 }
 
-var procBitOr = func(args []Object) Object {
+var procBitOr = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I | y.I}
 }
 
-var procBitXor = func(args []Object) Object {
+var procBitXor = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I ^ y.I}
 }
 
-var procBitAndNot = func(args []Object) Object {
+var procBitAndNot = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I &^ y.I}
 }
 
-var procBitClear = func(args []Object) Object {
+var procBitClear = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I &^ (1 << uint(y.I))}
 }
 
-var procBitSet = func(args []Object) Object {
+var procBitSet = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I | (1 << uint(y.I))}
 }
 
-var procBitFlip = func(args []Object) Object {
+var procBitFlip = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I ^ (1 << uint(y.I))}
 }
 
-var procBitTest = func(args []Object) Object {
+var procBitTest = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Boolean{B: x.I&(1<<uint(y.I)) != 0}
 }
 
-var procBitShiftLeft = func(args []Object) Object {
+var procBitShiftLeft = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I << uint(y.I)}
 }
 
-var procBitShiftRight = func(args []Object) Object {
+var procBitShiftRight = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: x.I >> uint(y.I)}
 }
 
-var procUnsignedBitShiftRight = func(args []Object) Object {
+var procUnsignedBitShiftRight = func(rt *Runtime, args []Object) Object {
 	x, y := AssertInts(args)
 	return Int{I: int(uint(x.I) >> uint(y.I))}
 }
 
-var procExInfo = func(args []Object) Object {
+var procExInfo = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 2, 3)
 	res := &ExInfo{
-		rt: RT.clone(),
+		rt: rt.clone(),
 	}
 	res.Add(KEYWORDS.message, EnsureString(args, 0))
 	res.Add(KEYWORDS.data, EnsureMap(args, 1))
@@ -325,28 +334,38 @@ var procExInfo = func(args []Object) Object {
 	return res
 }
 
-var procExData = func(args []Object) Object {
+var procExData = func(rt *Runtime, args []Object) Object {
 	if ok, res := args[0].(*ExInfo).Get(KEYWORDS.data); ok {
 		return res
 	}
 	return NIL
 }
 
-var procExCause = func(args []Object) Object {
-	if ok, res := args[0].(*ExInfo).Get(KEYWORDS.cause); ok {
-		return res
+var procExCause = func(rt *Runtime, args []Object) Object {
+	return errorCause(EnsureError(args, 0))
+}
+
+var procStacktrace = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 0, 1)
+	if len(args) == 0 {
+		// Omit the frame of the stacktrace function itself.
+		frames := rt.stackFrames()
+		return stacktraceVector(frames[:len(frames)-1])
+	}
+	if frames := errorStackFrames(EnsureError(args, 0)); frames != nil {
+		return stacktraceVector(frames)
 	}
 	return NIL
 }
 
-var procExMessage = func(args []Object) Object {
+var procExMessage = func(rt *Runtime, args []Object) Object {
 	return args[0].(Error).Message()
 }
 
-var procRegex = func(args []Object) Object {
+var procRegex = func(rt *Runtime, args []Object) Object {
 	r, err := regexp.Compile(EnsureString(args, 0).S)
 	if err != nil {
-		panic(RT.NewError("Invalid regex: " + err.Error()))
+		panic(rt.NewError("Invalid regex: " + err.Error()))
 	}
 	return &Regex{R: r}
 }
@@ -373,7 +392,7 @@ func reGroups(s string, indexes []int) Object {
 	}
 }
 
-var procReSeq = func(args []Object) Object {
+var procReSeq = func(rt *Runtime, args []Object) Object {
 	re := EnsureRegex(args, 0)
 	s := EnsureString(args, 1)
 	matches := re.R.FindAllStringSubmatchIndex(s.S, -1)
@@ -387,23 +406,23 @@ var procReSeq = func(args []Object) Object {
 	return &ArraySeq{arr: res}
 }
 
-var procReFind = func(args []Object) Object {
+var procReFind = func(rt *Runtime, args []Object) Object {
 	re := EnsureRegex(args, 0)
 	s := EnsureString(args, 1)
 	match := re.R.FindStringSubmatchIndex(s.S)
 	return reGroups(s.S, match)
 }
 
-var procRand = func(args []Object) Object {
+var procRand = func(rt *Runtime, args []Object) Object {
 	r := rand.Float64()
 	return Double{D: r}
 }
 
-var procIsSpecialSymbol = func(args []Object) Object {
+var procIsSpecialSymbol = func(rt *Runtime, args []Object) Object {
 	return Boolean{B: IsSpecialSymbol(args[0])}
 }
 
-var procSubs = func(args []Object) Object {
+var procSubs = func(rt *Runtime, args []Object) Object {
 	s := EnsureString(args, 0).S
 	start := EnsureInt(args, 1).I
 	slen := utf8.RuneCountInString(s)
@@ -412,32 +431,107 @@ var procSubs = func(args []Object) Object {
 		end = EnsureInt(args, 2).I
 	}
 	if start < 0 || start > slen {
-		panic(RT.NewError(fmt.Sprintf("String index out of range: %d", start)))
+		panic(rt.NewError(fmt.Sprintf("String index out of range: %d", start)))
 	}
 	if end < 0 || end > slen {
-		panic(RT.NewError(fmt.Sprintf("String index out of range: %d", end)))
+		panic(rt.NewError(fmt.Sprintf("String index out of range: %d", end)))
 	}
 	return String{S: string([]rune(s)[start:end])}
 }
 
-var procIntern = func(args []Object) Object {
+var procBytes = func(rt *Runtime, args []Object) Object {
+	switch obj := args[0].(type) {
+	case *Bytes:
+		return obj
+	case String:
+		return MakeBytes([]byte(obj.S))
+	case *Buffer:
+		return MakeBytes(append([]byte(nil), obj.Bytes()...))
+	case Seqable:
+		var res []byte
+		for s := obj.Seq(); !s.IsEmpty(); s = s.Rest() {
+			n := AssertNumber(s.First(), "Byte must be a number, got "+s.First().GetType().ToString(false)).Int().I
+			if n < -128 || n > 255 {
+				panic(rt.NewError(fmt.Sprintf("Value out of range for byte: %d", n)))
+			}
+			res = append(res, byte(n))
+		}
+		return MakeBytes(res)
+	default:
+		panic(rt.NewArgTypeError(0, obj, "Bytes, String, Buffer or Seqable"))
+	}
+}
+
+var procSubbytes = func(rt *Runtime, args []Object) Object {
+	b := EnsureBytes(args, 0).B
+	start := EnsureInt(args, 1).I
+	end := len(b)
+	if len(args) > 2 {
+		end = EnsureInt(args, 2).I
+	}
+	if start < 0 || start > len(b) {
+		panic(rt.NewError(fmt.Sprintf("Bytes index out of range: %d", start)))
+	}
+	if end < start || end > len(b) {
+		panic(rt.NewError(fmt.Sprintf("Bytes index out of range: %d", end)))
+	}
+	return MakeBytes(b[start:end])
+}
+
+var procReadInst = func(rt *Runtime, args []Object) Object {
+	s := EnsureString(args, 0).S
+	if t, ok := ParseInst(s); ok {
+		return t
+	}
+	panic(rt.NewError("Invalid #inst literal: " + s))
+}
+
+var procReadUUID = func(rt *Runtime, args []Object) Object {
+	s := EnsureString(args, 0).S
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(rt.NewError("Invalid #uuid literal: " + s))
+	}
+	return u
+}
+
+var procParseUUID = func(rt *Runtime, args []Object) Object {
+	if u, err := ParseUUID(EnsureString(args, 0).S); err == nil {
+		return u
+	}
+	return NIL
+}
+
+var procRandomUUID = func(rt *Runtime, args []Object) Object {
+	return RandomUUID()
+}
+
+var procInstMs = func(rt *Runtime, args []Object) Object {
+	return MakeInt(int(EnsureTime(args, 0).T.UnixNano() / int64(time.Millisecond)))
+}
+
+var procTaggedLiteral = func(rt *Runtime, args []Object) Object {
+	return MakeTaggedLiteral(EnsureSymbol(args, 0), args[1])
+}
+
+var procIntern = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
 	vr := ns.Intern(sym)
 	if len(args) == 3 {
-		vr.Value = args[2]
+		vr.setRoot(args[2])
 	}
 	return vr
 }
 
-var procSetMeta = func(args []Object) Object {
+var procSetMeta = func(rt *Runtime, args []Object) Object {
 	vr := EnsureVar(args, 0)
 	meta := EnsureMap(args, 1)
 	vr.meta = meta
 	return NIL
 }
 
-var procAtom = func(args []Object) Object {
+var procAtom = func(rt *Runtime, args []Object) Object {
 	res := &Atom{
 		value: args[0],
 	}
@@ -450,53 +544,50 @@ var procAtom = func(args []Object) Object {
 	return res
 }
 
-var procDeref = func(args []Object) Object {
-	return EnsureDeref(args, 0).Deref()
+var procDeref = func(rt *Runtime, args []Object) Object {
+	return EnsureDeref(args, 0).Deref(rt)
 }
 
-var procSwap = func(args []Object) Object {
+func swapAtom(rt *Runtime, args []Object) (Object, Object) {
 	a := EnsureAtom(args, 0)
 	f := EnsureCallable(args, 1)
-	fargs := append([]Object{a.value}, args[2:]...)
-	a.value = f.Call(fargs)
-	return a.value
+	return a.Swap(func(old Object) Object {
+		return f.Call(rt, append([]Object{old}, args[2:]...))
+	})
 }
 
-var procSwapVals = func(args []Object) Object {
-	a := EnsureAtom(args, 0)
-	f := EnsureCallable(args, 1)
-	fargs := append([]Object{a.value}, args[2:]...)
-	oldValue := a.value
-	a.value = f.Call(fargs)
-	return NewVectorFrom(oldValue, a.value)
+var procSwap = func(rt *Runtime, args []Object) Object {
+	_, res := swapAtom(rt, args)
+	return res
 }
 
-var procReset = func(args []Object) Object {
-	a := EnsureAtom(args, 0)
-	a.value = args[1]
-	return a.value
+var procSwapVals = func(rt *Runtime, args []Object) Object {
+	return NewVectorFrom(swapAtom(rt, args))
 }
 
-var procResetVals = func(args []Object) Object {
-	a := EnsureAtom(args, 0)
-	oldValue := a.value
-	a.value = args[1]
-	return NewVectorFrom(oldValue, a.value)
+var procReset = func(rt *Runtime, args []Object) Object {
+	EnsureAtom(args, 0).Reset(args[1])
+	return args[1]
+}
+
+var procResetVals = func(rt *Runtime, args []Object) Object {
+	oldValue := EnsureAtom(args, 0).Reset(args[1])
+	return NewVectorFrom(oldValue, args[1])
 }
 
-var procAlterMeta = func(args []Object) Object {
+var procAlterMeta = func(rt *Runtime, args []Object) Object {
 	r := EnsureRef(args, 0)
 	f := EnsureFn(args, 1)
-	return r.AlterMeta(f, args[2:])
+	return r.AlterMeta(rt, f, args[2:])
 }
 
-var procResetMeta = func(args []Object) Object {
+var procResetMeta = func(rt *Runtime, args []Object) Object {
 	r := EnsureRef(args, 0)
 	m := EnsureMap(args, 1)
 	return r.ResetMeta(m)
 }
 
-var procEmpty = func(args []Object) Object {
+var procEmpty = func(rt *Runtime, args []Object) Object {
 	switch c := args[0].(type) {
 	case Collection:
 		return c.Empty()
@@ -505,9 +596,9 @@ var procEmpty = func(args []Object) Object {
 	}
 }
 
-var procIsBound = func(args []Object) Object {
+var procIsBound = func(rt *Runtime, args []Object) Object {
 	vr := EnsureVar(args, 0)
-	return Boolean{B: vr.Value != nil}
+	return Boolean{B: vr.root() != nil || rt.binding(vr) != nil}
 }
 
 func toNative(obj Object) interface{} {
@@ -519,7 +610,7 @@ func toNative(obj Object) interface{} {
 	}
 }
 
-var procFormat = func(args []Object) Object {
+var procFormat = func(rt *Runtime, args []Object) Object {
 	s := EnsureString(args, 0)
 	objs := args[1:]
 	fargs := make([]interface{}, len(objs))
@@ -530,23 +621,23 @@ var procFormat = func(args []Object) Object {
 	return String{S: res}
 }
 
-var procList = func(args []Object) Object {
+var procList = func(rt *Runtime, args []Object) Object {
 	return NewListFrom(args...)
 }
 
-var procCons = func(args []Object) Object {
+var procCons = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 2, 2)
 	s := EnsureSeqable(args, 1).Seq()
 	return s.Cons(args[0])
 }
 
-var procFirst = func(args []Object) Object {
+var procFirst = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	s := EnsureSeqable(args, 0).Seq()
 	return s.First()
 }
 
-var procNext = func(args []Object) Object {
+var procNext = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	s := EnsureSeqable(args, 0).Seq()
 	res := s.Rest()
@@ -556,24 +647,24 @@ var procNext = func(args []Object) Object {
 	return res
 }
 
-var procRest = func(args []Object) Object {
+var procRest = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	s := EnsureSeqable(args, 0).Seq()
 	return s.Rest()
 }
 
-var procConj = func(args []Object) Object {
+var procConj = func(rt *Runtime, args []Object) Object {
 	switch c := args[0].(type) {
 	case Conjable:
 		return c.Conj(args[1])
 	case Seq:
 		return c.Cons(args[1])
 	default:
-		panic(RT.NewError("conj's first argument must be a collection, got " + c.GetType().ToString(false)))
+		panic(rt.NewError("conj's first argument must be a collection, got " + c.GetType().ToString(false)))
 	}
 }
 
-var procSeq = func(args []Object) Object {
+var procSeq = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	s := EnsureSeqable(args, 0).Seq()
 	if s.IsEmpty() {
@@ -582,21 +673,21 @@ var procSeq = func(args []Object) Object {
 	return s
 }
 
-var procIsInstance = func(args []Object) Object {
+var procIsInstance = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 2, 2)
 	t := EnsureType(args, 0)
 	return Boolean{B: IsInstance(t, args[1])}
 }
 
-var procAssoc = func(args []Object) Object {
+var procAssoc = func(rt *Runtime, args []Object) Object {
 	return EnsureAssociative(args, 0).Assoc(args[1], args[2])
 }
 
-var procEquals = func(args []Object) Object {
+var procEquals = func(rt *Runtime, args []Object) Object {
 	return Boolean{B: args[0].Equals(args[1])}
 }
 
-var procCount = func(args []Object) Object {
+var procCount = func(rt *Runtime, args []Object) Object {
 	switch obj := args[0].(type) {
 	case Counted:
 		return Int{I: obj.Count()}
@@ -606,13 +697,13 @@ var procCount = func(args []Object) Object {
 	}
 }
 
-var procSubvec = func(args []Object) Object {
+var procSubvec = func(rt *Runtime, args []Object) Object {
 	// TODO: implement proper Subvector structure
 	v := EnsureVector(args, 0)
 	start := EnsureInt(args, 1).I
 	end := EnsureInt(args, 2).I
 	if start > end {
-		panic(RT.NewError(fmt.Sprintf("subvec's start index (%d) is greater than end index (%d)", start, end)))
+		panic(rt.NewError(fmt.Sprintf("subvec's start index (%d) is greater than end index (%d)", start, end)))
 	}
 	subv := make([]Object, 0, end-start)
 	for i := start; i < end; i++ {
@@ -621,28 +712,28 @@ var procSubvec = func(args []Object) Object {
 	return NewVectorFrom(subv...)
 }
 
-var procCast = func(args []Object) Object {
+var procCast = func(rt *Runtime, args []Object) Object {
 	t := EnsureType(args, 0)
 	if t.reflectType.Kind() == reflect.Interface &&
 		args[1].GetType().reflectType.Implements(t.reflectType) ||
 		args[1].GetType().reflectType == t.reflectType {
 		return args[1]
 	}
-	panic(RT.NewError("Cannot cast " + args[1].GetType().ToString(false) + " to " + t.ToString(false)))
+	panic(rt.NewError("Cannot cast " + args[1].GetType().ToString(false) + " to " + t.ToString(false)))
 }
 
-var procVec = func(args []Object) Object {
+var procVec = func(rt *Runtime, args []Object) Object {
 	return NewVectorFromSeq(EnsureSeqable(args, 0).Seq())
 }
 
-var procHashMap = func(args []Object) Object {
+var procHashMap = func(rt *Runtime, args []Object) Object {
 	if len(args)%2 != 0 {
-		panic(RT.NewError("No value supplied for key " + args[len(args)-1].ToString(false)))
+		panic(rt.NewError("No value supplied for key " + args[len(args)-1].ToString(false)))
 	}
 	return NewHashMap(args...)
 }
 
-var procHashSet = func(args []Object) Object {
+var procHashSet = func(rt *Runtime, args []Object) Object {
 	res := EmptySet()
 	for i := 0; i < len(args); i++ {
 		res.Add(args[i])
@@ -656,18 +747,18 @@ func str(args ...Object) string {
 		if !obj.Equals(NIL) {
 			t := obj.GetType()
 			// TODO: this is a hack. Rethink escape parameter in ToString
-			escaped := (t == TYPE.String) || (t == TYPE.Char) || (t == TYPE.Regex)
+			escaped := (t == TYPE.String) || (t == TYPE.Char) || (t == TYPE.Regex) || (t == TYPE.Bytes) || (t == TYPE.Time) || (t == TYPE.UUID)
 			buffer.WriteString(obj.ToString(!escaped))
 		}
 	}
 	return buffer.String()
 }
 
-var procStr = func(args []Object) Object {
+var procStr = func(rt *Runtime, args []Object) Object {
 	return String{S: str(args...)}
 }
 
-var procSymbol = func(args []Object) Object {
+var procSymbol = func(rt *Runtime, args []Object) Object {
 	if len(args) == 1 {
 		return MakeSymbol(EnsureString(args, 0).S)
 	}
@@ -681,7 +772,7 @@ var procSymbol = func(args []Object) Object {
 	}
 }
 
-var procKeyword = func(args []Object) Object {
+var procKeyword = func(rt *Runtime, args []Object) Object {
 	if len(args) == 1 {
 		switch obj := args[0].(type) {
 		case String:
@@ -708,44 +799,45 @@ var procKeyword = func(args []Object) Object {
 	}
 }
 
-var procGensym = func(args []Object) Object {
+var procGensym = func(rt *Runtime, args []Object) Object {
 	return genSym(EnsureString(args, 0).S, "")
 }
 
-var procApply = func(args []Object) Object {
+var procApply = func(rt *Runtime, args []Object) Object {
 	// TODO:
 	// Stacktrace is broken. Need to somehow know
 	// the name of the function passed ...
 	f := EnsureCallable(args, 0)
-	return f.Call(ToSlice(EnsureSeqable(args, 1).Seq()))
+	return f.Call(rt, ToSlice(EnsureSeqable(args, 1).Seq()))
 }
 
-var procLazySeq = func(args []Object) Object {
+var procLazySeq = func(rt *Runtime, args []Object) Object {
 	return &LazySeq{
 		fn: args[0].(*Fn),
+		rt: rt.child(),
 	}
 }
 
-var procDelay = func(args []Object) Object {
+var procDelay = func(rt *Runtime, args []Object) Object {
 	return &Delay{
 		fn: args[0].(*Fn),
 	}
 }
 
-var procForce = func(args []Object) Object {
+var procForce = func(rt *Runtime, args []Object) Object {
 	switch d := args[0].(type) {
 	case *Delay:
-		return d.Force()
+		return d.Force(rt)
 	default:
 		return d
 	}
 }
 
-var procIdentical = func(args []Object) Object {
+var procIdentical = func(rt *Runtime, args []Object) Object {
 	return Boolean{B: args[0] == args[1]}
 }
 
-var procCompare = func(args []Object) Object {
+var procCompare = func(rt *Runtime, args []Object) Object {
 	k1, k2 := args[0], args[1]
 	if k1.Equals(k2) {
 		return Int{I: 0}
@@ -760,59 +852,59 @@ var procCompare = func(args []Object) Object {
 	case Comparable:
 		return Int{I: k1.Compare(k2)}
 	}
-	panic(RT.NewError(fmt.Sprintf("%s (type: %s) is not a Comparable", k1.ToString(true), k1.GetType().ToString(false))))
+	panic(rt.NewError(fmt.Sprintf("%s (type: %s) is not a Comparable", k1.ToString(true), k1.GetType().ToString(false))))
 }
 
-var procInt = func(args []Object) Object {
+var procInt = func(rt *Runtime, args []Object) Object {
 	switch obj := args[0].(type) {
 	case Char:
 		return Int{I: int(obj.Ch)}
 	case Number:
 		return obj.Int()
 	default:
-		panic(RT.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to Int", obj.ToString(true), obj.GetType().ToString(false))))
+		panic(rt.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to Int", obj.ToString(true), obj.GetType().ToString(false))))
 	}
 }
 
-var procNumber = func(args []Object) Object {
+var procNumber = func(rt *Runtime, args []Object) Object {
 	return AssertNumber(args[0], fmt.Sprintf("Cannot cast %s (type: %s) to Number", args[0].ToString(true), args[0].GetType().ToString(false)))
 }
 
-var procDouble = func(args []Object) Object {
+var procDouble = func(rt *Runtime, args []Object) Object {
 	n := AssertNumber(args[0], fmt.Sprintf("Cannot cast %s (type: %s) to Double", args[0].ToString(true), args[0].GetType().ToString(false)))
 	return n.Double()
 }
 
-var procChar = func(args []Object) Object {
+var procChar = func(rt *Runtime, args []Object) Object {
 	switch c := args[0].(type) {
 	case Char:
 		return c
 	case Number:
 		i := c.Int().I
 		if i < MIN_RUNE || i > MAX_RUNE {
-			panic(RT.NewError(fmt.Sprintf("Value out of range for char: %d", i)))
+			panic(rt.NewError(fmt.Sprintf("Value out of range for char: %d", i)))
 		}
 		return Char{Ch: rune(i)}
 	default:
-		panic(RT.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to Char", c.ToString(true), c.GetType().ToString(false))))
+		panic(rt.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to Char", c.ToString(true), c.GetType().ToString(false))))
 	}
 }
 
-var procBoolean = func(args []Object) Object {
+var procBoolean = func(rt *Runtime, args []Object) Object {
 	return Boolean{B: ToBool(args[0])}
 }
 
-var procNumerator = func(args []Object) Object {
+var procNumerator = func(rt *Runtime, args []Object) Object {
 	bi := EnsureRatio(args, 0).r.Num()
 	return &BigInt{b: *bi}
 }
 
-var procDenominator = func(args []Object) Object {
+var procDenominator = func(rt *Runtime, args []Object) Object {
 	bi := EnsureRatio(args, 0).r.Denom()
 	return &BigInt{b: *bi}
 }
 
-var procBigInt = func(args []Object) Object {
+var procBigInt = func(rt *Runtime, args []Object) Object {
 	switch n := args[0].(type) {
 	case Number:
 		return &BigInt{b: *n.BigInt()}
@@ -821,13 +913,13 @@ var procBigInt = func(args []Object) Object {
 		if _, ok := bi.SetString(n.S, 10); ok {
 			return &BigInt{b: bi}
 		}
-		panic(RT.NewError("Invalid number format " + n.S))
+		panic(rt.NewError("Invalid number format " + n.S))
 	default:
-		panic(RT.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to BigInt", n.ToString(true), n.GetType().ToString(false))))
+		panic(rt.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to BigInt", n.ToString(true), n.GetType().ToString(false))))
 	}
 }
 
-var procBigFloat = func(args []Object) Object {
+var procBigFloat = func(rt *Runtime, args []Object) Object {
 	switch n := args[0].(type) {
 	case Number:
 		return &BigFloat{b: *n.BigFloat()}
@@ -836,13 +928,13 @@ var procBigFloat = func(args []Object) Object {
 		if _, ok := b.SetString(n.S); ok {
 			return &BigFloat{b: b}
 		}
-		panic(RT.NewError("Invalid number format " + n.S))
+		panic(rt.NewError("Invalid number format " + n.S))
 	default:
-		panic(RT.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to BigFloat", n.ToString(true), n.GetType().ToString(false))))
+		panic(rt.NewError(fmt.Sprintf("Cannot cast %s (type: %s) to BigFloat", n.ToString(true), n.GetType().ToString(false))))
 	}
 }
 
-var procNth = func(args []Object) Object {
+var procNth = func(rt *Runtime, args []Object) Object {
 	n := EnsureNumber(args, 1).Int().I
 	switch coll := args[0].(type) {
 	case Indexed:
@@ -861,86 +953,86 @@ var procNth = func(args []Object) Object {
 			return SeqNth(coll.Seq(), n)
 		}
 	}
-	panic(RT.NewError("nth not supported on this type: " + args[0].GetType().ToString(false)))
+	panic(rt.NewError("nth not supported on this type: " + args[0].GetType().ToString(false)))
 }
 
-var procLt = func(args []Object) Object {
+var procLt = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Boolean{B: GetOps(a).Combine(GetOps(b)).Lt(a, b)}
 }
 
-var procLte = func(args []Object) Object {
+var procLte = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Boolean{B: GetOps(a).Combine(GetOps(b)).Lte(a, b)}
 }
 
-var procGt = func(args []Object) Object {
+var procGt = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Boolean{B: GetOps(a).Combine(GetOps(b)).Gt(a, b)}
 }
 
-var procGte = func(args []Object) Object {
+var procGte = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Boolean{B: GetOps(a).Combine(GetOps(b)).Gte(a, b)}
 }
 
-var procEq = func(args []Object) Object {
+var procEq = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return MakeBoolean(numbersEq(a, b))
 }
 
-var procMax = func(args []Object) Object {
+var procMax = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Max(a, b)
 }
 
-var procMin = func(args []Object) Object {
+var procMin = func(rt *Runtime, args []Object) Object {
 	a := AssertNumber(args[0], "")
 	b := AssertNumber(args[1], "")
 	return Min(a, b)
 }
 
-var procIncEx = func(args []Object) Object {
+var procIncEx = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	ops := GetOps(x).Combine(BIGINT_OPS)
 	return ops.Add(x, Int{I: 1})
 }
 
-var procDecEx = func(args []Object) Object {
+var procDecEx = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	ops := GetOps(x).Combine(BIGINT_OPS)
 	return ops.Subtract(x, Int{I: 1})
 }
 
-var procInc = func(args []Object) Object {
+var procInc = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	ops := GetOps(x).Combine(INT_OPS)
 	return ops.Add(x, Int{I: 1})
 }
 
-var procDec = func(args []Object) Object {
+var procDec = func(rt *Runtime, args []Object) Object {
 	x := EnsureNumber(args, 0)
 	ops := GetOps(x).Combine(INT_OPS)
 	return ops.Subtract(x, Int{I: 1})
 }
 
-var procPeek = func(args []Object) Object {
+var procPeek = func(rt *Runtime, args []Object) Object {
 	s := AssertStack(args[0], "")
 	return s.Peek()
 }
 
-var procPop = func(args []Object) Object {
+var procPop = func(rt *Runtime, args []Object) Object {
 	s := AssertStack(args[0], "")
 	return s.Pop().(Object)
 }
 
-var procContains = func(args []Object) Object {
+var procContains = func(rt *Runtime, args []Object) Object {
 	switch c := args[0].(type) {
 	case Gettable:
 		ok, _ := c.Get(args[1])
@@ -949,10 +1041,10 @@ var procContains = func(args []Object) Object {
 		}
 		return Boolean{B: false}
 	}
-	panic(RT.NewError("contains? not supported on type " + args[0].GetType().ToString(false)))
+	panic(rt.NewError("contains? not supported on type " + args[0].GetType().ToString(false)))
 }
 
-var procGet = func(args []Object) Object {
+var procGet = func(rt *Runtime, args []Object) Object {
 	switch c := args[0].(type) {
 	case Gettable:
 		ok, v := c.Get(args[1])
@@ -966,15 +1058,15 @@ var procGet = func(args []Object) Object {
 	return NIL
 }
 
-var procDissoc = func(args []Object) Object {
+var procDissoc = func(rt *Runtime, args []Object) Object {
 	return EnsureMap(args, 0).Without(args[1])
 }
 
-var procDisj = func(args []Object) Object {
+var procDisj = func(rt *Runtime, args []Object) Object {
 	return EnsureSet(args, 0).Disjoin(args[1])
 }
 
-var procFind = func(args []Object) Object {
+var procFind = func(rt *Runtime, args []Object) Object {
 	res := EnsureAssociative(args, 0).EntryAt(args[1])
 	if res == nil {
 		return NIL
@@ -982,23 +1074,23 @@ var procFind = func(args []Object) Object {
 	return res
 }
 
-var procKeys = func(args []Object) Object {
+var procKeys = func(rt *Runtime, args []Object) Object {
 	return EnsureMap(args, 0).Keys()
 }
 
-var procVals = func(args []Object) Object {
+var procVals = func(rt *Runtime, args []Object) Object {
 	return EnsureMap(args, 0).Vals()
 }
 
-var procRseq = func(args []Object) Object {
+var procRseq = func(rt *Runtime, args []Object) Object {
 	return EnsureReversible(args, 0).Rseq()
 }
 
-var procName = func(args []Object) Object {
+var procName = func(rt *Runtime, args []Object) Object {
 	return String{S: EnsureNamed(args, 0).Name()}
 }
 
-var procNamespace = func(args []Object) Object {
+var procNamespace = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamed(args, 0).Namespace()
 	if ns == "" {
 		return NIL
@@ -1006,48 +1098,49 @@ var procNamespace = func(args []Object) Object {
 	return String{S: ns}
 }
 
-var procFindVar = func(args []Object) Object {
+var procFindVar = func(rt *Runtime, args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	if sym.ns == nil {
-		panic(RT.NewError("find-var argument must be namespace-qualified symbol"))
+		panic(rt.NewError("find-var argument must be namespace-qualified symbol"))
 	}
-	if v, ok := GLOBAL_ENV.Resolve(sym); ok {
+	if v, ok := GLOBAL_ENV.Resolve(rt, sym); ok {
 		return v
 	}
 	return NIL
 }
 
-var procSort = func(args []Object) Object {
+var procSort = func(rt *Runtime, args []Object) Object {
 	cmp := EnsureComparator(args, 0)
 	coll := EnsureSeqable(args, 1)
 	s := SortableSlice{
 		s:   ToSlice(coll.Seq()),
 		cmp: cmp,
+		rt:  rt,
 	}
 	sort.Sort(s)
 	return &ArraySeq{arr: s.s}
 }
 
-var procEval = func(args []Object) Object {
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+var procEval = func(rt *Runtime, args []Object) Object {
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	expr := Parse(args[0], parseContext)
-	return Eval(expr, nil)
+	return rt.Eval(expr, nil)
 }
 
-var procType = func(args []Object) Object {
+var procType = func(rt *Runtime, args []Object) Object {
 	return args[0].GetType()
 }
 
-var procPprint = func(args []Object) Object {
+var procPprint = func(rt *Runtime, args []Object) Object {
 	obj := args[0]
-	w := Assertio_Writer(GLOBAL_ENV.stdout.Value, "")
+	w := Assertio_Writer(GLOBAL_ENV.stdout.Resolve(rt), "")
 	pprintObject(obj, 0, w)
 	fmt.Fprint(w, "\n")
 	return NIL
 }
 
-func PrintObject(obj Object, w io.Writer) {
-	printReadably := ToBool(GLOBAL_ENV.printReadably.Value)
+func PrintObject(rt *Runtime, obj Object, w io.Writer) {
+	printReadably := ToBool(GLOBAL_ENV.printReadably.Resolve(rt))
 	switch obj := obj.(type) {
 	case Printer:
 		obj.Print(w, printReadably)
@@ -1056,26 +1149,26 @@ func PrintObject(obj Object, w io.Writer) {
 	}
 }
 
-var procPr = func(args []Object) Object {
+var procPr = func(rt *Runtime, args []Object) Object {
 	n := len(args)
 	if n > 0 {
-		f := Assertio_Writer(GLOBAL_ENV.stdout.Value, "")
+		f := Assertio_Writer(GLOBAL_ENV.stdout.Resolve(rt), "")
 		for _, arg := range args[:n-1] {
-			PrintObject(arg, f)
+			PrintObject(rt, arg, f)
 			fmt.Fprint(f, " ")
 		}
-		PrintObject(args[n-1], f)
+		PrintObject(rt, args[n-1], f)
 	}
 	return NIL
 }
 
-var procNewline = func(args []Object) Object {
-	f := Assertio_Writer(GLOBAL_ENV.stdout.Value, "")
+var procNewline = func(rt *Runtime, args []Object) Object {
+	f := Assertio_Writer(GLOBAL_ENV.stdout.Resolve(rt), "")
 	fmt.Fprintln(f)
 	return NIL
 }
 
-var procFlush = func(args []Object) Object {
+var procFlush = func(rt *Runtime, args []Object) Object {
 	switch f := args[0].(type) {
 	case *File:
 		f.Sync()
@@ -1083,21 +1176,21 @@ var procFlush = func(args []Object) Object {
 	return NIL
 }
 
-func readFromReader(reader io.RuneReader) Object {
+func readFromReader(rt *Runtime, reader io.RuneReader) Object {
 	r := NewReader(reader, "<>")
-	obj, err := TryRead(r)
+	obj, err := TryRead(rt, r)
 	PanicOnErr(err)
 	return obj
 }
 
-var procRead = func(args []Object) Object {
+var procRead = func(rt *Runtime, args []Object) Object {
 	f := Ensureio_RuneReader(args, 0)
-	return readFromReader(f)
+	return readFromReader(rt, f)
 }
 
-var procReadString = func(args []Object) Object {
+var procReadString = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
-	return readFromReader(strings.NewReader(EnsureString(args, 0).S))
+	return readFromReader(rt, strings.NewReader(EnsureString(args, 0).S))
 }
 
 func readLine(r StringReader) (s string, e error) {
@@ -1117,9 +1210,9 @@ func readLine(r StringReader) (s string, e error) {
 	return
 }
 
-var procReadLine = func(args []Object) Object {
+var procReadLine = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 0, 0)
-	f := AssertStringReader(GLOBAL_ENV.stdin.Value, "")
+	f := AssertStringReader(GLOBAL_ENV.stdin.Resolve(rt), "")
 	line, err := readLine(f)
 	if err != nil {
 		return NIL
@@ -1127,7 +1220,7 @@ var procReadLine = func(args []Object) Object {
 	return String{S: line}
 }
 
-var procReaderReadLine = func(args []Object) Object {
+var procReaderReadLine = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	rdr := EnsureStringReader(args, 0)
 	line, err := readLine(rdr)
@@ -1137,25 +1230,25 @@ var procReaderReadLine = func(args []Object) Object {
 	return String{S: line}
 }
 
-var procNanoTime = func(args []Object) Object {
+var procNanoTime = func(rt *Runtime, args []Object) Object {
 	return &BigInt{b: *big.NewInt(time.Now().UnixNano())}
 }
 
-var procMacroexpand1 = func(args []Object) Object {
+var procMacroexpand1 = func(rt *Runtime, args []Object) Object {
 	switch s := args[0].(type) {
 	case Seq:
-		parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+		parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 		return macroexpand1(s, parseContext)
 	default:
 		return s
 	}
 }
 
-func loadReader(reader *Reader) (Object, error) {
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+func loadReader(rt *Runtime, reader *Reader) (Object, error) {
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	var lastObj Object = NIL
 	for {
-		obj, err := TryRead(reader)
+		obj, err := TryRead(rt, reader)
 		if err == io.EOF {
 			return lastObj, nil
 		}
@@ -1166,23 +1259,23 @@ func loadReader(reader *Reader) (Object, error) {
 		if err != nil {
 			return nil, err
 		}
-		lastObj, err = TryEval(expr)
+		lastObj, err = rt.TryEval(expr)
 		if err != nil {
 			return nil, err
 		}
 	}
 }
 
-var procLoadString = func(args []Object) Object {
+var procLoadString = func(rt *Runtime, args []Object) Object {
 	s := EnsureString(args, 0)
-	obj, err := loadReader(NewReader(strings.NewReader(s.S), "<string>"))
+	obj, err := loadReader(rt, NewReader(strings.NewReader(s.S), "<string>"))
 	if err != nil {
 		panic(err)
 	}
 	return obj
 }
 
-var procFindNamespace = func(args []Object) Object {
+var procFindNamespace = func(rt *Runtime, args []Object) Object {
 	ns := GLOBAL_ENV.FindNamespace(EnsureSymbol(args, 0))
 	if ns == nil {
 		return NIL
@@ -1190,7 +1283,7 @@ var procFindNamespace = func(args []Object) Object {
 	return ns
 }
 
-var procCreateNamespace = func(args []Object) Object {
+var procCreateNamespace = func(rt *Runtime, args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	res := GLOBAL_ENV.EnsureNamespace(sym)
 	// In linter mode the latest create-ns call overrides position info.
@@ -1203,7 +1296,7 @@ var procCreateNamespace = func(args []Object) Object {
 	return res
 }
 
-var procInjectNamespace = func(args []Object) Object {
+var procInjectNamespace = func(rt *Runtime, args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	ns := GLOBAL_ENV.EnsureNamespace(sym)
 	ns.isUsed = true
@@ -1211,7 +1304,7 @@ var procInjectNamespace = func(args []Object) Object {
 	return ns
 }
 
-var procRemoveNamespace = func(args []Object) Object {
+var procRemoveNamespace = func(rt *Runtime, args []Object) Object {
 	ns := GLOBAL_ENV.RemoveNamespace(EnsureSymbol(args, 0))
 	if ns == nil {
 		return NIL
@@ -1219,19 +1312,22 @@ var procRemoveNamespace = func(args []Object) Object {
 	return ns
 }
 
-var procAllNamespaces = func(args []Object) Object {
-	s := make([]Object, 0, len(GLOBAL_ENV.Namespaces))
-	for _, ns := range GLOBAL_ENV.Namespaces {
-		s = append(s, ns)
+var procAllNamespaces = func(rt *Runtime, args []Object) Object {
+	nss := GLOBAL_ENV.AllNamespaces()
+	s := make([]Object, len(nss))
+	for i, ns := range nss {
+		s[i] = ns
 	}
 	return &ArraySeq{arr: s}
 }
 
-var procNamespaceName = func(args []Object) Object {
+var procNamespaceName = func(rt *Runtime, args []Object) Object {
 	return EnsureNamespace(args, 0).Name
 }
 
-var procNamespaceMap = func(args []Object) Object {
+var procNamespaceMap = func(rt *Runtime, args []Object) Object {
+	envLock.Lock()
+	defer envLock.Unlock()
 	r := &ArrayMap{}
 	for k, v := range EnsureNamespace(args, 0).mappings {
 		r.Add(MakeSymbol(*k), v)
@@ -1239,34 +1335,43 @@ var procNamespaceMap = func(args []Object) Object {
 	return r
 }
 
-var procNamespaceUnmap = func(args []Object) Object {
+var procNamespaceUnmap = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
 	if sym.ns != nil {
-		panic(RT.NewError("Can't unintern namespace-qualified symbol"))
+		panic(rt.NewError("Can't unintern namespace-qualified symbol"))
 	}
+	envLock.Lock()
 	delete(ns.mappings, sym.name)
+	envLock.Unlock()
 	return NIL
 }
 
-var procVarNamespace = func(args []Object) Object {
+var procVarNamespace = func(rt *Runtime, args []Object) Object {
 	v := EnsureVar(args, 0)
 	return v.ns
 }
 
-var procRefer = func(args []Object) Object {
+var procRefer = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
 	v := EnsureVar(args, 2)
 	return ns.Refer(sym, v)
 }
 
-var procAlias = func(args []Object) Object {
+var procTrackReferredVar = func(rt *Runtime, args []Object) Object {
+	trackReferredVar(EnsureSymbol(args, 0), EnsureVar(args, 1))
+	return NIL
+}
+
+var procAlias = func(rt *Runtime, args []Object) Object {
 	EnsureNamespace(args, 0).AddAlias(EnsureSymbol(args, 1), EnsureNamespace(args, 2))
 	return NIL
 }
 
-var procNamespaceAliases = func(args []Object) Object {
+var procNamespaceAliases = func(rt *Runtime, args []Object) Object {
+	envLock.Lock()
+	defer envLock.Unlock()
 	r := &ArrayMap{}
 	for k, v := range EnsureNamespace(args, 0).aliases {
 		r.Add(MakeSymbol(*k), v)
@@ -1274,26 +1379,28 @@ var procNamespaceAliases = func(args []Object) Object {
 	return r
 }
 
-var procNamespaceUnalias = func(args []Object) Object {
+var procNamespaceUnalias = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
 	if sym.ns != nil {
-		panic(RT.NewError("Alias can't be namespace-qualified"))
+		panic(rt.NewError("Alias can't be namespace-qualified"))
 	}
+	envLock.Lock()
 	delete(ns.aliases, sym.name)
+	envLock.Unlock()
 	return NIL
 }
 
-var procVarGet = func(args []Object) Object {
-	return EnsureVar(args, 0).Resolve()
+var procVarGet = func(rt *Runtime, args []Object) Object {
+	return EnsureVar(args, 0).Resolve(rt)
 }
 
-var procVarSet = func(args []Object) Object {
-	EnsureVar(args, 0).Value = args[1]
+var procVarSet = func(rt *Runtime, args []Object) Object {
+	EnsureVar(args, 0).Set(rt, args[1])
 	return args[1]
 }
 
-var procNsResolve = func(args []Object) Object {
+var procNsResolve = func(rt *Runtime, args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
 	if sym.ns == nil && TYPES[sym.name] != nil {
@@ -1305,9 +1412,9 @@ var procNsResolve = func(args []Object) Object {
 	return NIL
 }
 
-var procArrayMap = func(args []Object) Object {
+var procArrayMap = func(rt *Runtime, args []Object) Object {
 	if len(args)%2 == 1 {
-		panic(RT.NewError("No value supplied for key " + args[len(args)-1].ToString(false)))
+		panic(rt.NewError("No value supplied for key " + args[len(args)-1].ToString(false)))
 	}
 	res := EmptyArrayMap()
 	for i := 0; i < len(args); i += 2 {
@@ -1318,7 +1425,7 @@ var procArrayMap = func(args []Object) Object {
 
 const bufferHashMask uint32 = 0x5ed19e84
 
-var procBuffer = func(args []Object) Object {
+var procBuffer = func(rt *Runtime, args []Object) Object {
 	if len(args) > 0 {
 		s := EnsureString(args, 0)
 		return MakeBuffer(bytes.NewBufferString(s.S))
@@ -1326,34 +1433,39 @@ var procBuffer = func(args []Object) Object {
 	return MakeBuffer(&bytes.Buffer{})
 }
 
-var procBufferedReader = func(args []Object) Object {
+var procBufferedReader = func(rt *Runtime, args []Object) Object {
 	switch rdr := args[0].(type) {
 	case io.Reader:
 		return MakeBufferedReader(rdr)
 	default:
-		panic(RT.NewArgTypeError(0, args[0], "IOReader"))
+		panic(rt.NewArgTypeError(0, args[0], "IOReader"))
 	}
 }
 
-var procSlurp = func(args []Object) Object {
-	switch f := args[0].(type) {
+func slurp(f Object) []byte {
+	switch f := f.(type) {
 	case String:
 		b, err := ioutil.ReadFile(f.S)
 		PanicOnErr(err)
-		return String{S: string(b)}
+		return b
 	case io.Reader:
 		b, err := ioutil.ReadAll(f)
 		PanicOnErr(err)
-		return String{S: string(b)}
+		return b
 	default:
-		panic(RT.NewArgTypeError(0, args[0], "String or IOReader"))
+		panic(RT.NewArgTypeError(0, f, "String or IOReader"))
 	}
 }
 
-var procSpit = func(args []Object) Object {
-	f := args[0]
-	content := args[1]
-	opts := EnsureMap(args, 2)
+var procSlurp = func(rt *Runtime, args []Object) Object {
+	return String{S: string(slurp(args[0]))}
+}
+
+var procSlurpBytes = func(rt *Runtime, args []Object) Object {
+	return MakeBytes(slurp(args[0]))
+}
+
+func spit(f Object, content []byte, opts Map) {
 	appendFile := false
 	if ok, append := opts.Get(MakeKeyword("append")); ok {
 		appendFile = ToBool(append)
@@ -1369,18 +1481,27 @@ var procSpit = func(args []Object) Object {
 		file, err := os.OpenFile(f.S, flags, 0644)
 		PanicOnErr(err)
 		defer file.Close()
-		_, err = file.WriteString(str(content))
+		_, err = file.Write(content)
 		PanicOnErr(err)
 	case io.Writer:
-		_, err := io.WriteString(f, str(content))
+		_, err := f.Write(content)
 		PanicOnErr(err)
 	default:
-		panic(RT.NewArgTypeError(0, args[0], "String or IOWriter"))
+		panic(RT.NewArgTypeError(0, f, "String or IOWriter"))
 	}
+}
+
+var procSpit = func(rt *Runtime, args []Object) Object {
+	spit(args[0], []byte(str(args[1])), EnsureMap(args, 2))
+	return NIL
+}
+
+var procSpitBytes = func(rt *Runtime, args []Object) Object {
+	spit(args[0], EnsureBinary(args, 1), EnsureMap(args, 2))
 	return NIL
 }
 
-var procShuffle = func(args []Object) Object {
+var procShuffle = func(rt *Runtime, args []Object) Object {
 	s := ToSlice(EnsureSeqable(args, 0).Seq())
 	for i := range s {
 		j := rand.Intn(i + 1)
@@ -1389,42 +1510,59 @@ var procShuffle = func(args []Object) Object {
 	return NewVectorFrom(s...)
 }
 
-var procIsRealized = func(args []Object) Object {
+var procReduced = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	return &Reduced{value: args[0]}
+}
+
+var procIsReduced = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	_, ok := args[0].(*Reduced)
+	return Boolean{B: ok}
+}
+
+var procIsRealized = func(rt *Runtime, args []Object) Object {
 	return Boolean{B: EnsurePending(args, 0).IsRealized()}
 }
 
-var procDeriveInfo = func(args []Object) Object {
+var procDeriveInfo = func(rt *Runtime, args []Object) Object {
 	dest := args[0]
 	src := args[1]
 	return dest.WithInfo(src.GetInfo())
 }
 
-var procJokerVersion = func(args []Object) Object {
+var procJokerVersion = func(rt *Runtime, args []Object) Object {
 	return String{S: VERSION[1:]}
 }
 
-var procHash = func(args []Object) Object {
+var procHash = func(rt *Runtime, args []Object) Object {
 	return Int{I: int(args[0].Hash())}
 }
 
-func loadFile(filename string) Object {
+func loadFile(rt *Runtime, filename string) Object {
 	var reader *Reader
 	f, err := os.Open(filename)
 	PanicOnErr(err)
 	reader = NewReader(bufio.NewReader(f), filename)
-	ProcessReaderFromEval(reader, filename)
+	ProcessReaderFromEval(rt, reader, filename)
 	return NIL
 }
 
-var procLoadFile = func(args []Object) Object {
+var procLoadFile = func(rt *Runtime, args []Object) Object {
 	filename := EnsureString(args, 0)
-	return loadFile(filename.S)
+	return loadFile(rt, filename.S)
 }
 
-var procLoadLibFromPath = func(args []Object) Object {
+var procLoadLibFromPath = func(rt *Runtime, args []Object) Object {
 	libname := EnsureSymbol(args, 0).Name()
 	pathname := EnsureString(args, 1).S
-	cp := GLOBAL_ENV.classPath.Value
+	if runningBundle != nil {
+		if lib := runningBundle.findLib(libname); lib != nil {
+			evalPacked(rt, lib.code, lib.filename)
+			return NIL
+		}
+	}
+	cp := GLOBAL_ENV.classPath.Resolve(rt)
 	cpvec := AssertVector(cp, "*classpath* must be a Vector, not a "+cp.GetType().ToString(false))
 	count := cpvec.Count()
 	var f *os.File
@@ -1451,19 +1589,41 @@ var procLoadLibFromPath = func(args []Object) Object {
 	}
 	PanicOnErr(canonicalErr)
 	PanicOnErr(err)
-	reader := NewReader(bufio.NewReader(f), filename)
-	ProcessReaderFromEval(reader, filename)
+	source, err := ioutil.ReadAll(f)
+	f.Close()
+	PanicOnErr(err)
+	noteLibSource(libname, pathname, filename, source)
+	if buildingBundle != nil {
+		buildingBundle.addLib(libname, filename, compileReader(rt, NewReader(bytes.NewReader(source), filename), filename))
+		return NIL
+	}
+	if isCompiling(rt) {
+		compileLib(rt, libname, filename, source)
+		return NIL
+	}
+	// Returns the packed file the lib was loaded from, if any.
+	if packedFile, code := findPackedCode(libname, filename, source, cpvec); packedFile != "" {
+		evalPacked(rt, code, filename)
+		return MakeString(packedFile)
+	}
+	reader := NewReader(bytes.NewReader(source), filename)
+	ProcessReaderFromEval(rt, reader, filename)
+	return NIL
+}
+
+var procLibRequired = func(rt *Runtime, args []Object) Object {
+	noteLibRequired(EnsureString(args, 0).S, EnsureSymbol(args, 1).Name())
 	return NIL
 }
 
-var procReduceKv = func(args []Object) Object {
+var procReduceKv = func(rt *Runtime, args []Object) Object {
 	f := EnsureCallable(args, 0)
 	init := args[1]
 	coll := EnsureKVReduce(args, 2)
-	return coll.kvreduce(f, init)
+	return coll.kvreduce(rt, f, init)
 }
 
-var procIndexOf = func(args []Object) Object {
+var procIndexOf = func(rt *Runtime, args []Object) Object {
 	s := EnsureString(args, 0)
 	ch := EnsureChar(args, 1)
 	for i, r := range s.S {
@@ -1474,9 +1634,9 @@ var procIndexOf = func(args []Object) Object {
 	return Int{I: -1}
 }
 
-func libExternalPath(sym Symbol) (path string, ok bool) {
-	nsSourcesVar, _ := GLOBAL_ENV.Resolve(MakeSymbol("joker.core/*ns-sources*"))
-	nsSources := ToSlice(nsSourcesVar.Value.(*Vector).Seq())
+func libExternalPath(rt *Runtime, sym Symbol) (path string, ok bool) {
+	nsSourcesVar, _ := GLOBAL_ENV.Resolve(rt, MakeSymbol("joker.core/*ns-sources*"))
+	nsSources := ToSlice(nsSourcesVar.Resolve(rt).(*Vector).Seq())
 
 	var sourceKey string
 	var sourceMap Map
@@ -1491,7 +1651,7 @@ func libExternalPath(sym Symbol) (path string, ok bool) {
 	if sourceMap != nil {
 		ok, url := sourceMap.Get(MakeKeyword("url"))
 		if !ok {
-			panic(RT.NewError("Key :url not found in ns-sources for: " + sourceKey))
+			panic(rt.NewError("Key :url not found in ns-sources for: " + sourceKey))
 		} else {
 			return externalSourceToPath(sym.Name(), url.ToString(false)), true
 		}
@@ -1499,25 +1659,29 @@ func libExternalPath(sym Symbol) (path string, ok bool) {
 	return
 }
 
-var procLibPath = func(args []Object) Object {
+var procLibPath = func(rt *Runtime, args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	var path string
 
-	path, ok := libExternalPath(sym)
+	if path, ok := bundledLibFilename(sym.Name()); ok {
+		return String{S: path}
+	}
+
+	path, ok := libExternalPath(rt, sym)
 
 	if !ok {
 		var file string
-		if GLOBAL_ENV.file.Value == nil {
+		if GLOBAL_ENV.file.root() == nil {
 			var err error
 			file, err = filepath.Abs("user")
 			PanicOnErr(err)
 		} else {
-			file = AssertString(GLOBAL_ENV.file.Value, "").S
+			file = AssertString(GLOBAL_ENV.file.root(), "").S
 			if linkDest, err := os.Readlink(file); err == nil {
 				file = linkDest
 			}
 		}
-		ns := GLOBAL_ENV.CurrentNamespace().Name
+		ns := GLOBAL_ENV.CurrentNamespace(rt).Name
 
 		parts := strings.Split(ns.Name(), ".")
 		for _ = range parts {
@@ -1532,29 +1696,29 @@ var procLibPath = func(args []Object) Object {
 	return String{S: path}
 }
 
-var procInternFakeVar = func(args []Object) Object {
+var procInternFakeVar = func(rt *Runtime, args []Object) Object {
 	nsSym := EnsureSymbol(args, 0)
 	sym := EnsureSymbol(args, 1)
 	isMacro := ToBool(args[2])
-	res := InternFakeSymbol(GLOBAL_ENV.FindNamespace(nsSym), sym)
+	res := InternFakeSymbol(rt, GLOBAL_ENV.FindNamespace(nsSym), sym)
 	res.isMacro = isMacro
 	return res
 }
 
-var procParse = func(args []Object) Object {
-	lm, _ := GLOBAL_ENV.Resolve(MakeSymbol("joker.core/*linter-mode*"))
+var procParse = func(rt *Runtime, args []Object) Object {
+	lm, _ := GLOBAL_ENV.Resolve(rt, MakeSymbol("joker.core/*linter-mode*"))
 	lm.Value = Boolean{B: true}
 	LINTER_MODE = true
 	defer func() {
 		LINTER_MODE = false
 		lm.Value = Boolean{B: false}
 	}()
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	res := Parse(args[0], parseContext)
 	return res.Dump(false)
 }
 
-var procTypes = func(args []Object) Object {
+var procTypes = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 0, 0)
 	res := EmptyArrayMap()
 	for k, v := range TYPES {
@@ -1563,101 +1727,202 @@ var procTypes = func(args []Object) Object {
 	return res
 }
 
-var procCreateChan = func(args []Object) Object {
+var procMakeType = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 3, 3)
+	name := EnsureString(args, 0)
+	fieldSyms := ToSlice(EnsureVector(args, 1).Seq())
+	fields := make([]Keyword, len(fieldSyms))
+	for i, f := range fieldSyms {
+		fields[i] = MakeKeyword(AssertSymbol(f, "Field name must be a symbol").Name())
+	}
+	return MakeUserType(name.S, fields, EnsureBoolean(args, 2).B)
+}
+
+var procMakeRecord = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	t := EnsureType(args, 0)
+	return NewRecord(t, ToSlice(EnsureVector(args, 1).Seq()))
+}
+
+var procMapToRecord = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	t := EnsureType(args, 0)
+	return NewRecordFromMap(t, EnsureMap(args, 1))
+}
+
+var procMakeTypeInstance = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	t := EnsureType(args, 0)
+	return NewTypeInstance(t, ToSlice(EnsureVector(args, 1).Seq()))
+}
+
+var procTypeField = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	switch obj := args[0].(type) {
+	case *TypeInstance:
+		return obj.Field(args[1])
+	case *Record:
+		_, v := obj.Get(args[1])
+		return v
+	default:
+		panic(rt.NewArgTypeError(0, args[0], "TypeInstance"))
+	}
+}
+
+var procCreateChan = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	switch buf := args[0].(type) {
+	case Nil:
+		return MakeChannel(make(chan FutureResult))
+	case *ChannelBuffer:
+		return MakeBufferedChannel(buf)
+	default:
+		return MakeBufferedChannel(MakeChannelBuffer(EnsureInt(args, 0).I, FIXED_BUFFER))
+	}
+}
+
+var procChannelBuffer = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	n := EnsureInt(args, 0).I
+	switch k := EnsureKeyword(args, 1); k.Name() {
+	case "fixed":
+		return MakeChannelBuffer(n, FIXED_BUFFER)
+	case "sliding":
+		return MakeChannelBuffer(n, SLIDING_BUFFER)
+	case "dropping":
+		return MakeChannelBuffer(n, DROPPING_BUFFER)
+	default:
+		panic(rt.NewError("Unknown buffer policy: " + k.ToString(false)))
+	}
+}
+
+var procTimeout = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
-	n := EnsureInt(args, 0)
-	ch := make(chan FutureResult, n.I)
-	return MakeChannel(ch)
+	ms := EnsureInt(args, 0).I
+	return MakeTimeoutChannel(time.Duration(ms) * time.Millisecond)
 }
 
-var procCloseChan = func(args []Object) Object {
+var procCloseChan = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	EnsureChannel(args, 0).Close()
 	return NIL
 }
 
-var procSend = func(args []Object) (obj Object) {
-	CheckArity(args, 2, 2)
-	ch := EnsureChannel(args, 0)
-	v := args[1]
+func ensurePutValue(args []Object, index int) Object {
+	v := args[index]
 	if v.Equals(NIL) {
 		panic(RT.NewError("Can't put nil on channel"))
 	}
-	if ch.isClosed {
-		return MakeBoolean(false)
-	}
-	obj = MakeBoolean(true)
-	defer func() {
-		if r := recover(); r != nil {
-			RT.GIL.Lock()
-			obj = MakeBoolean(false)
-		}
-	}()
-	RT.GIL.Unlock()
-	ch.ch <- MakeFutureResult(v, nil)
-	RT.GIL.Lock()
-	return
+	return v
 }
 
-var procReceive = func(args []Object) Object {
-	CheckArity(args, 1, 1)
+var procSend = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
 	ch := EnsureChannel(args, 0)
-	RT.GIL.Unlock()
-	res, ok := <-ch.ch
-	RT.GIL.Lock()
-	if !ok {
-		return NIL
-	}
-	if res.err != nil {
-		panic(res.err)
+	return MakeBoolean(ch.Put(rt, ensurePutValue(args, 1)))
+}
+
+var procOffer = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 2)
+	ch := EnsureChannel(args, 0)
+	return ch.Offer(ensurePutValue(args, 1))
+}
+
+var procReceive = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	return EnsureChannel(args, 0).Take(rt)
+}
+
+var procPoll = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	return EnsureChannel(args, 0).Poll()
+}
+
+var procAlts = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 2, 3)
+	ports := EnsureSeqable(args, 0)
+	priority := EnsureBoolean(args, 1).B
+	var defaultValue Object
+	if len(args) > 2 {
+		defaultValue = args[2]
 	}
-	return res.value
+	return Alts(rt, ports, priority, defaultValue)
 }
 
-var procGo = func(args []Object) Object {
+var procGo = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	f := EnsureCallable(args, 0)
 	ch := MakeChannel(make(chan FutureResult, 1))
-	go func() {
-
-		defer func() {
-			if r := recover(); r != nil {
-				switch r := r.(type) {
-				case Error:
-					ch.ch <- MakeFutureResult(NIL, r)
-					ch.Close()
-				default:
-					RT.GIL.Unlock()
-					panic(r)
-				}
-			}
-			RT.GIL.Unlock()
-		}()
-
-		RT.GIL.Lock()
-		res := f.Call([]Object{})
-		ch.ch <- MakeFutureResult(res, nil)
+	rt.Go(func(rt *Runtime) {
+		ch.ch <- callInGoroutine(rt, f)
 		ch.Close()
-	}()
+	})
 	return ch
 }
 
-var procVerbosityLevel = func(args []Object) Object {
+var procFuture = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 1, 1)
+	return MakeFuture(rt, EnsureCallable(args, 0))
+}
+
+var procDerefWithTimeout = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 3, 3)
+	timeout := time.Duration(EnsureNumber(args, 1).Int().I) * time.Millisecond
+	return EnsureFuture(args, 0).DerefWithTimeout(rt, timeout, args[2])
+}
+
+var procAvailableProcessors = func(rt *Runtime, args []Object) Object {
+	CheckArity(args, 0, 0)
+	return MakeInt(runtime.NumCPU())
+}
+
+var procPushThreadBindings = func(rt *Runtime, args []Object) Object {
+	rt.pushBindings(EnsureMap(args, 0))
+	return NIL
+}
+
+var procPopThreadBindings = func(rt *Runtime, args []Object) Object {
+	rt.popBindings()
+	return NIL
+}
+
+var procIsThreadBound = func(rt *Runtime, args []Object) Object {
+	return Boolean{B: rt.binding(EnsureVar(args, 0)) != nil}
+}
+
+var procAlterVarRoot = func(rt *Runtime, args []Object) Object {
+	vr := EnsureVar(args, 0)
+	f := EnsureCallable(args, 1)
+	return vr.alterRoot(func(old Object) Object {
+		return f.Call(rt, append([]Object{old}, args[2:]...))
+	})
+}
+
+var procAlterVar = func(rt *Runtime, args []Object) Object {
+	vr := EnsureVar(args, 0)
+	f := EnsureCallable(args, 1)
+	return vr.alter(rt, func(old Object) Object {
+		return f.Call(rt, append([]Object{old}, args[2:]...))
+	})
+}
+
+var procVerbosityLevel = func(rt *Runtime, args []Object) Object {
 	CheckArity(args, 0, 0)
 	return MakeInt(VerbosityLevel)
 }
 
-var procExit = func(args []Object) Object {
+var procExit = func(rt *Runtime, args []Object) Object {
 	ExitJoker(EnsureInt(args, 0).I)
 	return NIL
 }
 
-func PackReader(reader *Reader, filename string) ([]byte, error) {
+func PackReader(rt *Runtime, reader *Reader, filename string) ([]byte, error) {
 	var p []byte
 	packEnv := NewPackEnv()
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+	packEnv.rt = rt
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	if filename != "" {
-		currentFilename := parseContext.GlobalEnv.file.Value
+		currentFilename := parseContext.GlobalEnv.file.root()
 		defer func() {
 			parseContext.GlobalEnv.SetFilename(currentFilename)
 		}()
@@ -1666,7 +1931,7 @@ func PackReader(reader *Reader, filename string) ([]byte, error) {
 		parseContext.GlobalEnv.SetFilename(MakeString(s))
 	}
 	for {
-		obj, err := TryRead(reader)
+		obj, err := TryRead(rt, reader)
 		if err == io.EOF {
 			var hp []byte
 			hp = packEnv.Pack(hp)
@@ -1682,7 +1947,7 @@ func PackReader(reader *Reader, filename string) ([]byte, error) {
 			return nil, err
 		}
 		p = expr.Pack(p, packEnv)
-		_, err = TryEval(expr)
+		_, err = rt.TryEval(expr)
 		if err != nil {
 			fmt.Fprintln(Stderr, err)
 			return nil, err
@@ -1690,15 +1955,15 @@ func PackReader(reader *Reader, filename string) ([]byte, error) {
 	}
 }
 
-var procIncProblemCount = func(args []Object) Object {
-	PROBLEM_COUNT++
+var procPrintLinterProblem = func(rt *Runtime, args []Object) Object {
+	reportProblem(args[0].(*ExInfo).problem())
 	return NIL
 }
 
-func ProcessReader(reader *Reader, filename string, phase Phase) error {
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+func ProcessReader(rt *Runtime, reader *Reader, filename string, phase Phase) error {
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	if filename != "" {
-		currentFilename := parseContext.GlobalEnv.file.Value
+		currentFilename := parseContext.GlobalEnv.file.root()
 		defer func() {
 			parseContext.GlobalEnv.SetFilename(currentFilename)
 		}()
@@ -1707,12 +1972,12 @@ func ProcessReader(reader *Reader, filename string, phase Phase) error {
 		parseContext.GlobalEnv.SetFilename(MakeString(s))
 	}
 	for {
-		obj, err := TryRead(reader)
+		obj, err := TryRead(rt, reader)
 		if err == io.EOF {
 			return nil
 		}
 		if err != nil {
-			fmt.Fprintln(Stderr, err)
+			reportError(err)
 			return err
 		}
 		if phase == READ {
@@ -1720,15 +1985,15 @@ func ProcessReader(reader *Reader, filename string, phase Phase) error {
 		}
 		expr, err := TryParse(obj, parseContext)
 		if err != nil {
-			fmt.Fprintln(Stderr, err)
+			reportError(err)
 			return err
 		}
 		if phase == PARSE {
 			continue
 		}
-		obj, err = TryEval(expr)
+		obj, err = rt.TryEval(expr)
 		if err != nil {
-			fmt.Fprintln(Stderr, err)
+			reportError(err)
 			return err
 		}
 		if phase == EVAL {
@@ -1740,10 +2005,10 @@ func ProcessReader(reader *Reader, filename string, phase Phase) error {
 	}
 }
 
-func ProcessReaderFromEval(reader *Reader, filename string) {
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+func ProcessReaderFromEval(rt *Runtime, reader *Reader, filename string) {
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
 	if filename != "" {
-		currentFilename := parseContext.GlobalEnv.file.Value
+		currentFilename := parseContext.GlobalEnv.file.root()
 		defer func() {
 			parseContext.GlobalEnv.SetFilename(currentFilename)
 		}()
@@ -1752,31 +2017,34 @@ func ProcessReaderFromEval(reader *Reader, filename string) {
 		parseContext.GlobalEnv.SetFilename(MakeString(s))
 	}
 	for {
-		obj, err := TryRead(reader)
+		obj, err := TryRead(rt, reader)
 		if err == io.EOF {
 			return
 		}
 		PanicOnErr(err)
 		expr, err := TryParse(obj, parseContext)
 		PanicOnErr(err)
-		obj, err = TryEval(expr)
+		obj, err = rt.TryEval(expr)
 		PanicOnErr(err)
 	}
 }
 
 func processData(data []byte) {
-	ns := GLOBAL_ENV.CurrentNamespace()
-	GLOBAL_ENV.SetCurrentNamespace(GLOBAL_ENV.CoreNamespace)
-	defer func() { GLOBAL_ENV.SetCurrentNamespace(ns) }()
-	header, p := UnpackHeader(data, GLOBAL_ENV)
+	// Namespaces are initialized on demand by whichever goroutine
+	// needs them first, so the code gets a runtime of its own.
+	rt := NewRuntime()
+	bindings := EmptyArrayMap()
+	bindings.Add(GLOBAL_ENV.ns, GLOBAL_ENV.CoreNamespace)
+	rt.PushThreadBindings(bindings)
+	header, p := UnpackHeader(rt, data, GLOBAL_ENV)
 	for len(p) > 0 {
 		var expr Expr
 		expr, p = UnpackExpr(p, header)
-		_, err := TryEval(expr)
+		_, err := rt.TryEval(expr)
 		PanicOnErr(err)
 	}
 	if VerbosityLevel > 0 {
-		fmt.Fprintf(Stderr, "processData: Evaluated code for %s\n", GLOBAL_ENV.CurrentNamespace().ToString(false))
+		fmt.Fprintf(Stderr, "processData: Evaluated code for %s\n", GLOBAL_ENV.CurrentNamespace(rt).ToString(false))
 	}
 }
 
@@ -1797,14 +2065,14 @@ func setCoreNamespaces() {
 	vr.Value = set
 }
 
-var procIsNamespaceInitialized = func(args []Object) Object {
+var procIsNamespaceInitialized = func(rt *Runtime, args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	if sym.ns != nil {
-		panic(RT.NewError("Can't ask for namespace info on namespace-qualified symbol"))
+		panic(rt.NewError("Can't ask for namespace info on namespace-qualified symbol"))
 	}
 	// First look for registered (e.g. std) libs
 	ns, found := GLOBAL_ENV.Namespaces[sym.name]
-	return MakeBoolean(found && ns.Lazy == nil)
+	return MakeBoolean(found && ns.isInitialized())
 }
 
 func findConfigFile(filename string, workingDir string, findDir bool) string {
@@ -1879,9 +2147,10 @@ func knownMacrosToMap(km Object) (Map, error) {
 	return res, nil
 }
 
-func ReadConfig(filename string, workingDir string) {
+func ReadConfig(rt *Runtime, filename string, workingDir string) {
 	LINTER_CONFIG = GLOBAL_ENV.CoreNamespace.Intern(MakeSymbol("*linter-config*"))
 	LINTER_CONFIG.Value = EmptyArrayMap()
+	formatIndents = nil
 	configFileName := findConfigFile(filename, workingDir, false)
 	if configFileName == "" {
 		return
@@ -1892,7 +2161,7 @@ func ReadConfig(filename string, workingDir string) {
 		return
 	}
 	r := NewReader(bufio.NewReader(f), configFileName)
-	config, err := TryRead(r)
+	config, err := TryRead(rt, r)
 	if err != nil {
 		printConfigError(configFileName, err.Error())
 		return
@@ -1976,15 +2245,28 @@ func ReadConfig(filename string, workingDir string) {
 			printConfigError(configFileName, ":rules value must be a map, got "+rules.GetType().ToString(false))
 			return
 		}
-		if ok, v := m.Get(KEYWORDS.ifWithoutElse); ok {
-			WARNINGS.ifWithoutElse = ToBool(v)
+		levels, err := parseRuleLevels(m)
+		if err != nil {
+			printConfigError(configFileName, err.Error())
+			return
 		}
-		if ok, v := m.Get(KEYWORDS.unusedFnParameters); ok {
-			WARNINGS.unusedFnParameters = ToBool(v)
+		WARNINGS.ruleLevels = levels
+		// Rules checked in Joker code are only turned off by false.
+		for rule, level := range levels {
+			if level == "off" {
+				m = m.Assoc(MakeKeyword(rule), Boolean{B: false}).(Map)
+			}
 		}
-		if ok, v := m.Get(KEYWORDS.fnWithEmptyBody); ok {
-			WARNINGS.fnWithEmptyBody = ToBool(v)
+		configMap = configMap.Assoc(KEYWORDS.rules, m).(Map)
+	}
+	ok, format := configMap.Get(MakeKeyword("format"))
+	if ok {
+		indents, err := parseFormatConfig(format)
+		if err != nil {
+			printConfigError(configFileName, err.Error())
+			return
 		}
+		formatIndents = indents
 	}
 	LINTER_CONFIG.Value = configMap
 }
@@ -2015,16 +2297,16 @@ func NewReaderFromFile(filename string) (*Reader, error) {
 	return NewReader(bufio.NewReader(f), filename), nil
 }
 
-func ProcessLinterFile(configDir string, filename string) {
+func ProcessLinterFile(rt *Runtime, configDir string, filename string) {
 	linterFileName := filepath.Join(configDir, filename)
 	if _, err := os.Stat(linterFileName); err == nil {
 		if reader, err := NewReaderFromFile(linterFileName); err == nil {
-			ProcessReader(reader, linterFileName, EVAL)
+			ProcessReader(rt, reader, linterFileName, EVAL)
 		}
 	}
 }
 
-func ProcessLinterFiles(dialect Dialect, filename string, workingDir string) {
+func ProcessLinterFiles(rt *Runtime, dialect Dialect, filename string, workingDir string) {
 	if dialect == EDN {
 		return
 	}
@@ -2033,15 +2315,15 @@ func ProcessLinterFiles(dialect Dialect, filename string, workingDir string) {
 		return
 	}
 	if dialect == JOKER {
-		ProcessLinterFile(configDir, "linter.joke")
+		ProcessLinterFile(rt, configDir, "linter.joke")
 		return
 	}
-	ProcessLinterFile(configDir, "linter.cljc")
+	ProcessLinterFile(rt, configDir, "linter.cljc")
 	switch dialect {
 	case CLJS:
-		ProcessLinterFile(configDir, "linter.cljs")
+		ProcessLinterFile(rt, configDir, "linter.cljs")
 	case CLJ:
-		ProcessLinterFile(configDir, "linter.clj")
+		ProcessLinterFile(rt, configDir, "linter.clj")
 	}
 }
 
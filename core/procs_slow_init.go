@@ -126,6 +126,7 @@ func init() {
 	intern("var-ns__", procVarNamespace, "procVarNamespace")
 	intern("ns-initialized?__", procIsNamespaceInitialized, "procIsNamespaceInitialized")
 	intern("refer__", procRefer, "procRefer")
+	intern("track-referred-var__", procTrackReferredVar, "procTrackReferredVar")
 	intern("alias__", procAlias, "procAlias")
 	intern("ns-aliases__", procNamespaceAliases, "procNamespaceAliases")
 	intern("ns-unalias__", procNamespaceUnalias, "procNamespaceUnalias")
@@ -139,12 +140,21 @@ func init() {
 	intern("ex-data__", procExData, "procExData")
 	intern("ex-cause__", procExCause, "procExCause")
 	intern("ex-message__", procExMessage, "procExMessage")
+	intern("stacktrace__", procStacktrace, "procStacktrace")
 	intern("regex__", procRegex, "procRegex")
 	intern("re-seq__", procReSeq, "procReSeq")
 	intern("re-find__", procReFind, "procReFind")
 	intern("rand__", procRand, "procRand")
 	intern("special-symbol?__", procIsSpecialSymbol, "procIsSpecialSymbol")
 	intern("subs__", procSubs, "procSubs")
+	intern("bytes__", procBytes, "procBytes")
+	intern("subbytes__", procSubbytes, "procSubbytes")
+	intern("read-inst__", procReadInst, "procReadInst")
+	intern("read-uuid__", procReadUUID, "procReadUUID")
+	intern("parse-uuid__", procParseUUID, "procParseUUID")
+	intern("random-uuid__", procRandomUUID, "procRandomUUID")
+	intern("inst-ms__", procInstMs, "procInstMs")
+	intern("tagged-literal__", procTaggedLiteral, "procTaggedLiteral")
 	intern("intern__", procIntern, "procIntern")
 	intern("set-meta__", procSetMeta, "procSetMeta")
 	intern("atom__", procAtom, "procAtom")
@@ -163,8 +173,12 @@ func init() {
 	intern("reduce-kv__", procReduceKv, "procReduceKv")
 	intern("slurp__", procSlurp, "procSlurp")
 	intern("spit__", procSpit, "procSpit")
+	intern("slurp-bytes__", procSlurpBytes, "procSlurpBytes")
+	intern("spit-bytes__", procSpitBytes, "procSpitBytes")
 	intern("shuffle__", procShuffle, "procShuffle")
 	intern("realized?__", procIsRealized, "procIsRealized")
+	intern("reduced__", procReduced, "procReduced")
+	intern("reduced?__", procIsReduced, "procIsReduced")
 	intern("derive-info__", procDeriveInfo, "procDeriveInfo")
 	intern("joker-version__", procJokerVersion, "procJokerVersion")
 
@@ -172,15 +186,34 @@ func init() {
 
 	intern("index-of__", procIndexOf, "procIndexOf")
 	intern("lib-path__", procLibPath, "procLibPath")
+	intern("lib-required__", procLibRequired, "procLibRequired")
 	intern("intern-fake-var__", procInternFakeVar, "procInternFakeVar")
 	intern("parse__", procParse, "procParse")
-	intern("inc-problem-count__", procIncProblemCount, "procIncProblemCount")
+	intern("print-linter-problem__", procPrintLinterProblem, "procPrintLinterProblem")
 	intern("types__", procTypes, "procTypes")
 	intern("go__", procGo, "procGo")
 	intern("<!__", procReceive, "procReceive")
 	intern(">!__", procSend, "procSend")
 	intern("chan__", procCreateChan, "procCreateChan")
 	intern("close!__", procCloseChan, "procCloseChan")
+	intern("chan-buffer__", procChannelBuffer, "procChannelBuffer")
+	intern("timeout__", procTimeout, "procTimeout")
+	intern("offer!__", procOffer, "procOffer")
+	intern("poll!__", procPoll, "procPoll")
+	intern("alts!__", procAlts, "procAlts")
+	intern("future__", procFuture, "procFuture")
+	intern("deref-with-timeout__", procDerefWithTimeout, "procDerefWithTimeout")
+	intern("available-processors__", procAvailableProcessors, "procAvailableProcessors")
+	intern("push-thread-bindings__", procPushThreadBindings, "procPushThreadBindings")
+	intern("pop-thread-bindings__", procPopThreadBindings, "procPopThreadBindings")
+	intern("thread-bound?__", procIsThreadBound, "procIsThreadBound")
+	intern("alter-var-root__", procAlterVarRoot, "procAlterVarRoot")
+	intern("alter-var__", procAlterVar, "procAlterVar")
+	intern("make-type__", procMakeType, "procMakeType")
+	intern("make-record__", procMakeRecord, "procMakeRecord")
+	intern("map->record__", procMapToRecord, "procMapToRecord")
+	intern("make-type-instance__", procMakeTypeInstance, "procMakeTypeInstance")
+	intern("type-field__", procTypeField, "procTypeField")
 
 	intern("go-spew__", procGoSpew, "procGoSpew")
 	intern("verbosity-level__", procVerbosityLevel, "procVerbosityLevel")
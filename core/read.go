@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 )
@@ -36,7 +37,7 @@ var (
 
 var (
 	ARGS   map[int]Symbol
-	GENSYM int
+	GENSYM int64
 )
 
 var NIL = Nil{}
@@ -131,6 +132,14 @@ func (err ReadError) Message() Object {
 	return MakeString(err.msg)
 }
 
+func (err ReadError) Pos() Position {
+	return Position{
+		filename:    err.filename,
+		startLine:   err.line,
+		startColumn: err.column,
+	}
+}
+
 func (err ReadError) Error() string {
 	return fmt.Sprintf("%s:%d:%d: Read error: %s", filename(err.filename), err.line, err.column, err.msg)
 }
@@ -184,7 +193,10 @@ func eatWhitespace(reader *Reader) {
 		}
 		if r == '#' && reader.Peek() == '_' {
 			reader.Get()
-			Read(reader)
+			obj, _ := Read(reader)
+			if ignore := ignoreMarker(obj); ignore != nil {
+				reader.ignore = ignore
+			}
 			r = reader.Get()
 			continue
 		}
@@ -405,11 +417,11 @@ func readSymbol(reader *Reader, first rune) Object {
 		}
 		if str[0] == ':' {
 			sym := MakeSymbol(str[1:])
-			ns := GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(), sym)
+			ns := GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(reader.rt), sym)
 			if ns == nil {
 				msg := fmt.Sprintf("Unable to resolve namespace %s in keyword %s", *sym.ns, ":"+str)
 				if LINTER_MODE {
-					printReadWarning(reader, msg)
+					printReadWarning(reader, "unresolved-namespace", msg)
 					return MakeReadObject(reader, MakeKeyword(*sym.name))
 				}
 				panic(MakeReadError(reader, msg))
@@ -517,6 +529,14 @@ func readString(reader *Reader) Object {
 	return MakeReadObject(reader, String{S: b.String()})
 }
 
+// closeCollection consumes the closing delimiter of a collection.
+// A :joker/ignore marker not followed by a form in the collection
+// is dropped, so that it doesn't apply to forms after it.
+func closeCollection(reader *Reader) {
+	reader.ignore = nil
+	reader.Get()
+}
+
 func readList(reader *Reader) Object {
 	s := make([]Object, 0, 10)
 	eatWhitespace(reader)
@@ -534,7 +554,7 @@ func readList(reader *Reader) Object {
 		eatWhitespace(reader)
 		r = reader.Peek()
 	}
-	reader.Get()
+	closeCollection(reader)
 	list := EmptyList
 	for i := len(s) - 1; i >= 0; i-- {
 		list = list.conj(s[i])
@@ -560,7 +580,7 @@ func readVector(reader *Reader) Object {
 		eatWhitespace(reader)
 		r = reader.Peek()
 	}
-	reader.Get()
+	closeCollection(reader)
 	return MakeReadObject(reader, res)
 }
 
@@ -608,7 +628,7 @@ func readMapWithNamespace(reader *Reader, nsname string) Object {
 		eatWhitespace(reader)
 		r = reader.Peek()
 	}
-	reader.Get()
+	closeCollection(reader)
 	if len(objs)%2 != 0 {
 		panic(MakeReadError(reader, "Map literal must contain an even number of forms"))
 	}
@@ -654,7 +674,7 @@ func readSet(reader *Reader) Object {
 		eatWhitespace(reader)
 		r = reader.Peek()
 	}
-	reader.Get()
+	closeCollection(reader)
 	return MakeReadObject(reader, set)
 }
 
@@ -720,8 +740,7 @@ func isTerminatingMacro(r rune) bool {
 }
 
 func genSym(prefix string, postfix string) Symbol {
-	GENSYM++
-	return MakeSymbol(fmt.Sprintf("%s%d%s", prefix, GENSYM, postfix))
+	return MakeSymbol(fmt.Sprintf("%s%d%s", prefix, atomic.AddInt64(&GENSYM, 1), postfix))
 }
 
 func generateSymbol(prefix string) Symbol {
@@ -818,7 +837,7 @@ func makeSyntaxQuote(obj Object, env map[*string]Symbol, reader *Reader) Object
 			}
 			obj = DeriveReadObject(obj, sym)
 		} else {
-			obj = DeriveReadObject(obj, GLOBAL_ENV.ResolveSymbol(s))
+			obj = DeriveReadObject(obj, GLOBAL_ENV.ResolveSymbol(reader.rt, s))
 		}
 		return makeQuote(obj, SYMBOLS.quote)
 	case Seq:
@@ -850,30 +869,44 @@ func filename(f *string) string {
 func handleNoReaderError(reader *Reader, s Symbol) Object {
 	if LINTER_MODE {
 		if DIALECT != EDN {
-			printReadWarning(reader, "No reader function for tag "+s.ToString(false))
+			printReadWarning(reader, "unknown-tag", "No reader function for tag "+s.ToString(false))
 		}
 		return readFirst(reader)
 	}
 	panic(MakeReadError(reader, "No reader function for tag "+s.ToString(false)))
 }
 
+func readBytesLiteral(reader *Reader, obj Object) Object {
+	str, ok := obj.(String)
+	if !ok {
+		panic(MakeReadError(reader, "#bytes literal must be a string"))
+	}
+	b, err := ParseBytesLiteral(str.S)
+	if err != nil {
+		panic(MakeReadError(reader, err.Error()))
+	}
+	return b
+}
+
 func readTagged(reader *Reader) Object {
 	obj := readFirst(reader)
 	switch s := obj.(type) {
 	case Symbol:
-		readersVar, ok := GLOBAL_ENV.CoreNamespace.mappings[SYMBOLS.defaultDataReaders.name]
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if reader.tagReader != nil {
+			return reader.tagReader(s, readFirst(reader))
 		}
-		readersMap, ok := readersVar.Value.(Map)
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if s.ns == nil && *s.name == "bytes" {
+			return readBytesLiteral(reader, readFirst(reader))
 		}
-		ok, readFunc := readersMap.Get(s)
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if readFunc := dataReader(reader.rt, s); readFunc != nil {
+			return readFunc.Call(reader.rt, []Object{readFirst(reader)})
 		}
-		return AssertVar(readFunc, "").Call([]Object{readFirst(reader)})
+		if !LINTER_MODE {
+			if defaultFunc := defaultDataReaderFn(reader.rt); defaultFunc != nil {
+				return defaultFunc.Call(reader.rt, []Object{s, readFirst(reader)})
+			}
+		}
+		return handleNoReaderError(reader, s)
 	default:
 		panic(MakeReadError(reader, "Reader tag must be a symbol"))
 	}
@@ -893,7 +926,7 @@ func readConditional(reader *Reader) (Object, bool) {
 	cond := readList(reader).(*List)
 	if cond.count%2 != 0 {
 		if LINTER_MODE {
-			printReadError(reader, "Reader conditional requires an even number of forms")
+			printReadError(reader, "reader-conditional", "Reader conditional requires an even number of forms")
 		} else {
 			panic(MakeReadError(reader, "Reader conditional requires an even number of forms"))
 		}
@@ -906,7 +939,7 @@ func readConditional(reader *Reader) (Object, bool) {
 				if !ok {
 					msg := "Spliced form in reader conditional must be Seqable, got " + v.GetType().ToString(false)
 					if LINTER_MODE {
-						printReadError(reader, msg)
+						printReadError(reader, "reader-conditional", msg)
 						return EmptyVector(), true
 					} else {
 						panic(MakeReadError(reader, msg))
@@ -954,13 +987,13 @@ func readNamespacedMap(reader *Reader) Object {
 	var nsname string
 	if auto {
 		if sym == nil {
-			nsname = GLOBAL_ENV.CurrentNamespace().Name.Name()
+			nsname = GLOBAL_ENV.CurrentNamespace(reader.rt).Name.Name()
 		} else {
 			sym, ok := sym.(Symbol)
 			if !ok || sym.ns != nil {
 				panic(MakeReadError(reader, "Namespaced map must specify a valid namespace: "+sym.ToString(false)))
 			}
-			ns := GLOBAL_ENV.CurrentNamespace().aliases[sym.name]
+			ns := GLOBAL_ENV.CurrentNamespace(reader.rt).aliases[sym.name]
 			if ns == nil {
 				ns = GLOBAL_ENV.Namespaces[sym.name]
 			}
@@ -1019,6 +1052,7 @@ func readDispatch(reader *Reader) (Object, bool) {
 func readWithMeta(reader *Reader) Object {
 	meta := readMeta(reader)
 	nextObj := readFirst(reader)
+	suppressIfIgnored(nextObj, meta)
 	switch v := nextObj.(type) {
 	case Meta:
 		return DeriveReadObject(nextObj, v.WithMeta(meta))
@@ -1041,6 +1075,16 @@ func readFirst(reader *Reader) Object {
 
 func Read(reader *Reader) (Object, bool) {
 	eatWhitespace(reader)
+	if ignore := reader.ignore; ignore != nil {
+		reader.ignore = nil
+		obj, multi := readObject(reader)
+		suppress(obj, ignore)
+		return obj, multi
+	}
+	return readObject(reader)
+}
+
+func readObject(reader *Reader) (Object, bool) {
 	r := reader.Get()
 	pushPos(reader)
 	switch {
@@ -1101,10 +1145,12 @@ func Read(reader *Reader) (Object, bool) {
 	panic(MakeReadError(reader, fmt.Sprintf("Unexpected %c", r)))
 }
 
-func TryRead(reader *Reader) (obj Object, err error) {
+func TryRead(rt *Runtime, reader *Reader) (obj Object, err error) {
+	readLock.Lock(rt)
+	defer readLock.Unlock()
+	reader.rt = rt
 	defer func() {
 		if r := recover(); r != nil {
-			PROBLEM_COUNT++
 			err = r.(error)
 		}
 	}()
@@ -1118,7 +1164,6 @@ func TryRead(reader *Reader) (obj Object, err error) {
 			return obj, nil
 		}
 		if obj.(*Vector).Count() > 0 {
-			PROBLEM_COUNT++
 			return NIL, MakeReadError(reader, "Reader conditional splicing not allowed at the top level.")
 		}
 	}
@@ -14,6 +14,9 @@ type (
 		isEof          bool
 		rewind         int
 		filename       *string
+		ignore         Object // :joker/ignore value for the next form
+		tagReader      func(tag Symbol, obj Object) Object
+		rt             *Runtime // runtime data reader functions are called in
 	}
 )
 
@@ -27,6 +30,12 @@ func NewReader(runeReader io.RuneReader, filename string) *Reader {
 	}
 }
 
+// SetTagReader makes reader read tagged literals with f instead of
+// the functions from *default-data-readers*.
+func (reader *Reader) SetTagReader(f func(tag Symbol, obj Object) Object) {
+	reader.tagReader = f
+}
+
 func (reader *Reader) Get() rune {
 	if reader.isEof {
 		return EOF
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+type (
+	Record struct {
+		InfoHolder
+		MetaHolder
+		rtype *Type
+		vals  []Object
+		ext   Map
+	}
+	TypeInstance struct {
+		InfoHolder
+		rtype *Type
+		vals  []Object
+	}
+)
+
+// MakeUserType creates a new type as defined by defrecord (record is true)
+// or deftype. Unlike built-in types, such types are compared by identity.
+func MakeUserType(name string, fields []Keyword, record bool) *Type {
+	var rt reflect.Type
+	kind := "Record type"
+	if record {
+		rt = reflect.TypeOf((*Record)(nil))
+	} else {
+		rt = reflect.TypeOf((*TypeInstance)(nil))
+		kind = "Type"
+	}
+	basis := make([]Object, len(fields))
+	for i, f := range fields {
+		basis[i] = MakeSymbol(*f.name)
+	}
+	meta := MakeMeta(nil, "("+kind+")", "1.0")
+	meta.Add(KEYWORDS.name, MakeString(name))
+	meta.Add(MakeKeyword("fields"), NewVectorFrom(basis...))
+	if fields == nil {
+		fields = []Keyword{}
+	}
+	return &Type{MetaHolder{meta}, name, rt, fields, nil}
+}
+
+func (t *Type) fieldIndex(key Object) int {
+	if k, ok := key.(Keyword); ok {
+		for i, f := range t.fields {
+			if f.Equals(k) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func checkFieldCount(t *Type, vals []Object) {
+	if len(vals) != len(t.fields) {
+		panic(RT.newClassError(fmt.Sprintf("Wrong number of args (%d) passed to constructor of %s", len(vals), t.name), TYPE.ArityError))
+	}
+}
+
+func NewRecord(t *Type, vals []Object) *Record {
+	checkFieldCount(t, vals)
+	return &Record{rtype: t, vals: vals}
+}
+
+func NewRecordFromMap(t *Type, m Map) *Record {
+	res := &Record{rtype: t, vals: make([]Object, len(t.fields))}
+	for i, f := range t.fields {
+		if ok, v := m.Get(f); ok {
+			res.vals[i] = v
+		} else {
+			res.vals[i] = NIL
+		}
+	}
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		if t.fieldIndex(p.Key) == -1 {
+			res.ext = res.extMap().Assoc(p.Key, p.Value).(Map)
+		}
+	}
+	return res
+}
+
+func (r *Record) extMap() Map {
+	if r.ext == nil {
+		return EmptyArrayMap()
+	}
+	return r.ext
+}
+
+func (r *Record) toArrayMap() *ArrayMap {
+	res := EmptyArrayMap()
+	for i, f := range r.rtype.fields {
+		res.arr = append(res.arr, f, r.vals[i])
+	}
+	if r.ext != nil {
+		for iter := r.ext.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			res.arr = append(res.arr, p.Key, p.Value)
+		}
+	}
+	return res
+}
+
+func (r *Record) ToString(escape bool) string {
+	return "#" + r.rtype.name + mapToString(r, escape)
+}
+
+func (r *Record) Pprint(w io.Writer, indent int) int {
+	fmt.Fprint(w, "#"+r.rtype.name)
+	return pprintMap(r, w, indent+len(r.rtype.name)+1)
+}
+
+func (r *Record) Equals(other interface{}) bool {
+	if r == other {
+		return true
+	}
+	o, ok := other.(*Record)
+	if !ok || o.rtype != r.rtype {
+		return false
+	}
+	return mapEquals(r.toArrayMap(), o.toArrayMap())
+}
+
+func (r *Record) GetType() *Type {
+	return r.rtype
+}
+
+func (r *Record) Hash() uint32 {
+	return hashUnordered(r.Seq(), r.rtype.Hash())
+}
+
+func (r *Record) WithInfo(info *ObjectInfo) Object {
+	res := *r
+	res.info = info
+	return &res
+}
+
+func (r *Record) WithMeta(meta Map) Object {
+	res := *r
+	res.meta = SafeMerge(res.meta, meta)
+	return &res
+}
+
+func (r *Record) Get(key Object) (bool, Object) {
+	if i := r.rtype.fieldIndex(key); i != -1 {
+		return true, r.vals[i]
+	}
+	if r.ext == nil {
+		return false, nil
+	}
+	return r.ext.Get(key)
+}
+
+func (r *Record) EntryAt(key Object) *Vector {
+	if ok, v := r.Get(key); ok {
+		return NewVectorFrom(key, v)
+	}
+	return nil
+}
+
+func (r *Record) Assoc(key, val Object) Associative {
+	res := *r
+	if i := r.rtype.fieldIndex(key); i != -1 {
+		res.vals = make([]Object, len(r.vals))
+		copy(res.vals, r.vals)
+		res.vals[i] = val
+	} else {
+		res.ext = r.extMap().Assoc(key, val).(Map)
+	}
+	return &res
+}
+
+// Without returns a plain map when one of the record's fields is removed,
+// since the result no longer conforms to the record type.
+func (r *Record) Without(key Object) Map {
+	if r.rtype.fieldIndex(key) != -1 {
+		res := r.toArrayMap().Without(key)
+		if r.meta != nil {
+			return res.(Meta).WithMeta(r.meta).(Map)
+		}
+		return res
+	}
+	if r.ext == nil {
+		return r
+	}
+	res := *r
+	res.ext = r.ext.Without(key)
+	if res.ext.Count() == 0 {
+		res.ext = nil
+	}
+	return &res
+}
+
+func (r *Record) Merge(other Map) Map {
+	var res Map = r
+	for iter := other.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		res = res.Assoc(p.Key, p.Value).(Map)
+	}
+	return res
+}
+
+func (r *Record) Conj(obj Object) Conjable {
+	return mapConj(r, obj)
+}
+
+func (r *Record) Count() int {
+	res := len(r.vals)
+	if r.ext != nil {
+		res += r.ext.Count()
+	}
+	return res
+}
+
+func (r *Record) Seq() Seq {
+	return r.toArrayMap().Seq()
+}
+
+func (r *Record) Keys() Seq {
+	return r.toArrayMap().Keys()
+}
+
+func (r *Record) Vals() Seq {
+	return r.toArrayMap().Vals()
+}
+
+func (r *Record) Iter() MapIterator {
+	return r.toArrayMap().Iter()
+}
+
+func (r *Record) Empty() Collection {
+	panic(RT.NewError("Can't create empty: " + r.rtype.name))
+}
+
+func (r *Record) kvreduce(rt *Runtime, c Callable, init Object) Object {
+	res := init
+	for iter := r.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		res = c.Call(rt, []Object{res, p.Key, p.Value})
+		if rd, ok := res.(*Reduced); ok {
+			return rd.value
+		}
+	}
+	return res
+}
+
+func NewTypeInstance(t *Type, vals []Object) *TypeInstance {
+	checkFieldCount(t, vals)
+	return &TypeInstance{rtype: t, vals: vals}
+}
+
+func (ti *TypeInstance) Field(key Object) Object {
+	i := ti.rtype.fieldIndex(key)
+	if i == -1 {
+		panic(RT.NewError("No field " + key.ToString(false) + " in type " + ti.rtype.name))
+	}
+	return ti.vals[i]
+}
+
+func (ti *TypeInstance) ToString(escape bool) string {
+	return "#object[" + ti.rtype.name + "]"
+}
+
+func (ti *TypeInstance) Equals(other interface{}) bool {
+	return ti == other
+}
+
+func (ti *TypeInstance) GetType() *Type {
+	return ti.rtype
+}
+
+func (ti *TypeInstance) Hash() uint32 {
+	return HashPtr(uintptr(unsafe.Pointer(ti)))
+}
+
+func (ti *TypeInstance) WithInfo(info *ObjectInfo) Object {
+	return ti
+}
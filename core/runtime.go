@@ -0,0 +1,234 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// bindingFrame holds the thread-local values established by a single
+	// push-thread-bindings__ call. Frames are immutable once pushed, so
+	// runtimes forked while bindings are in effect can share them.
+	bindingFrame struct {
+		vals map[*Var]*threadBinding
+		prev *bindingFrame
+	}
+	threadBinding struct {
+		value atomic.Value // holds boundValue
+		rt    *Runtime
+	}
+	boundValue struct {
+		obj Object
+	}
+	// interruptState is shared by a runtime and the runtimes
+	// lazy values created in it are realized in.
+	interruptState struct {
+		interrupted int32
+		ch          chan struct{} // signalled along with setting interrupted
+	}
+)
+
+// NewRuntime returns a runtime to evaluate code in.
+// A runtime must only be used by one goroutine at a time.
+func NewRuntime() *Runtime {
+	return newRuntime(nil)
+}
+
+func newRuntime(bindings *bindingFrame) *Runtime {
+	return &Runtime{
+		bindings:  bindings,
+		interrupt: &interruptState{ch: make(chan struct{}, 1)},
+	}
+}
+
+// Fork returns a runtime for another goroutine to evaluate code in.
+// Thread-local bindings in effect in rt are conveyed to it.
+// It must be called by the goroutine rt is used by.
+func (rt *Runtime) Fork() *Runtime {
+	return newRuntime(rt.bindings)
+}
+
+// Go runs f in a new goroutine with a runtime forked from rt.
+func (rt *Runtime) Go(f func(rt *Runtime)) {
+	child := rt.Fork()
+	go f(child)
+}
+
+// child returns a runtime for lazy values (such as lazy sequences)
+// created in rt to be realized in, whichever goroutine does it.
+// It has the bindings and the callstack rt has at the moment
+// and is interrupted along with rt.
+func (rt *Runtime) child() *Runtime {
+	parent, initial := rt, rt.Save()
+	if rt.parent != nil {
+		// Lazy values created while realizing another one start where
+		// it started, so that a chain of them doesn't grow the callstack.
+		parent, initial = rt.parent, rt.initial
+	}
+	return &Runtime{
+		callstack:   initial.callstack,
+		currentExpr: initial.currentExpr,
+		currentEnv:  initial.currentEnv,
+		bindings:    rt.bindings,
+		interrupt:   rt.interrupt,
+		parent:      parent,
+		initial:     initial,
+	}
+}
+
+// descendsFrom reports whether rt is other
+// or a child (see child) of it.
+func (rt *Runtime) descendsFrom(other *Runtime) bool {
+	for ; rt != nil; rt = rt.parent {
+		if rt == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Interrupt makes the goroutine that uses rt throw an error the next
+// time it evaluates an expression, or right away if it's blocked taking
+// from or putting to a channel, waiting for a future or sleeping.
+func (rt *Runtime) Interrupt() {
+	atomic.StoreInt32(&rt.interrupt.interrupted, 1)
+	select {
+	case rt.interrupt.ch <- struct{}{}:
+	default:
+	}
+}
+
+// ClearInterrupt cancels a pending Interrupt.
+func (rt *Runtime) ClearInterrupt() {
+	atomic.StoreInt32(&rt.interrupt.interrupted, 0)
+	select {
+	case <-rt.interrupt.ch:
+	default:
+	}
+}
+
+// interruptError cancels the pending Interrupt and returns
+// the error it makes the goroutine throw.
+func (rt *Runtime) interruptError() *EvalError {
+	rt.ClearInterrupt()
+	return rt.NewError("Evaluation interrupted")
+}
+
+// interrupts returns the channel that receives a value when rt
+// is interrupted, for blocking operations to select on.
+func (rt *Runtime) interrupts() chan struct{} {
+	return rt.interrupt.ch
+}
+
+func (rt *Runtime) throwInterrupted() {
+	panic(rt.interruptError())
+}
+
+// Sleep pauses the calling goroutine for at least d,
+// unless rt is interrupted.
+func (rt *Runtime) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-rt.interrupts():
+		rt.throwInterrupted()
+	}
+}
+
+func (rt *Runtime) pushBindings(m Map) {
+	frame := &bindingFrame{
+		vals: make(map[*Var]*threadBinding, m.Count()),
+		prev: rt.bindings,
+	}
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		vr := AssertVar(p.Key, "")
+		b := &threadBinding{rt: rt}
+		b.value.Store(boundValue{p.Value})
+		frame.vals[vr] = b
+		atomic.StoreInt32(&vr.isThreadBound, 1)
+	}
+	rt.bindings = frame
+}
+
+func (rt *Runtime) popBindings() {
+	if rt.bindings == nil {
+		panic(rt.NewError("Pop without matching push"))
+	}
+	rt.bindings = rt.bindings.prev
+}
+
+// PushThreadBindings establishes thread-local bindings of the vars
+// in m in rt. Must be followed by PopThreadBindings.
+func (rt *Runtime) PushThreadBindings(m Map) {
+	rt.pushBindings(m)
+}
+
+func (rt *Runtime) PopThreadBindings() {
+	rt.popBindings()
+}
+
+func (rt *Runtime) binding(vr *Var) *threadBinding {
+	for f := rt.bindings; f != nil; f = f.prev {
+		if b, ok := f.vals[vr]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// reentrantMutex is a mutex that can be locked again
+// by the runtime already holding it.
+type reentrantMutex struct {
+	mu    sync.Mutex
+	owner atomic.Pointer[Runtime]
+	count int
+}
+
+func (m *reentrantMutex) Lock(rt *Runtime) {
+	if m.owner.Load() == rt {
+		m.count++
+		return
+	}
+	m.mu.Lock()
+	m.owner.Store(rt)
+	m.count = 1
+}
+
+func (m *reentrantMutex) Unlock() {
+	m.count--
+	if m.count == 0 {
+		m.owner.Store(nil)
+		m.mu.Unlock()
+	}
+}
+
+// unlockAll releases m if it is held by rt, however many
+// times it was locked, and returns a function that restores it.
+func (m *reentrantMutex) unlockAll(rt *Runtime) func() {
+	if m.owner.Load() != rt {
+		return func() {}
+	}
+	n := m.count
+	m.count = 0
+	m.owner.Store(nil)
+	m.mu.Unlock()
+	return func() {
+		m.Lock(rt)
+		m.count = n
+	}
+}
+
+// readLock guards the state of the reader and the parser, which are
+// not safe for concurrent use. Macros are expanded while holding it,
+// hence it has to be reentrant.
+var readLock reentrantMutex
+
+// envLock guards namespaces and their mappings.
+// It's never held while evaluating code.
+var envLock sync.Mutex
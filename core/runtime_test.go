@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	GLOBAL_ENV.InitEnv(os.Stdin, os.Stdout, os.Stderr, nil)
+	ProcessCoreData()
+	GLOBAL_ENV.ReferCoreToUser()
+	os.Exit(m.Run())
+}
+
+// evalString reads, parses and evaluates the forms in s
+// and returns the value of the last one.
+func evalString(s string) (Object, error) {
+	rt := NewRuntime()
+	reader := NewReader(strings.NewReader(s), "<test>")
+	ctx := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
+	var res Object = NIL
+	for {
+		obj, err := TryRead(rt, reader)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		expr, err := TryParse(obj, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if res, err = rt.TryEval(expr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parallel calls f(i) for i in [0, n) from n goroutines not started
+// by Joker, and reports the errors it returns.
+func parallel(t *testing.T, n int, f func(i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParallelEval(t *testing.T) {
+	parallel(t, 16, func(i int) error {
+		for j := 0; j < 20; j++ {
+			res, err := evalString(fmt.Sprintf("(let [x %d] (reduce + (map #(* x %%) (range 100))))", i))
+			if err != nil {
+				return err
+			}
+			if expected := MakeInt(i * 4950); !res.Equals(expected) {
+				return fmt.Errorf("expected %s, got %s", expected.ToString(true), res.ToString(true))
+			}
+		}
+		return nil
+	})
+}
+
+func TestParallelDefinitions(t *testing.T) {
+	parallel(t, 16, func(i int) error {
+		_, err := evalString(fmt.Sprintf("(def stress-var-%d %d) (defn stress-fn-%d [] stress-var-%d)", i, i, i, i))
+		return err
+	})
+	parallel(t, 16, func(i int) error {
+		res, err := evalString(fmt.Sprintf("(stress-fn-%d)", i))
+		if err == nil && !res.Equals(MakeInt(i)) {
+			err = fmt.Errorf("expected %d, got %s", i, res.ToString(true))
+		}
+		return err
+	})
+}
+
+func TestConcurrentLoadedLibs(t *testing.T) {
+	const n = 32
+	parallel(t, n, func(i int) error {
+		_, err := evalString(fmt.Sprintf("(binding [*ns* *ns*] (ns stress.ns%d))", i))
+		return err
+	})
+	libs := GLOBAL_ENV.CoreNamespace.Resolve("*loaded-libs*").Resolve(NewRuntime()).(Set)
+	for i := 0; i < n; i++ {
+		if ok, _ := libs.Get(MakeSymbol(fmt.Sprintf("stress.ns%d", i))); !ok {
+			t.Errorf("stress.ns%d is missing from *loaded-libs*", i)
+		}
+	}
+}
+
+func TestConcurrentAlterVarRoot(t *testing.T) {
+	if _, err := evalString("(def stress-counter 0)"); err != nil {
+		t.Fatal(err)
+	}
+	parallel(t, 8, func(i int) error {
+		_, err := evalString("(dotimes [_ 100] (alter-var-root #'stress-counter inc))")
+		return err
+	})
+	res, err := evalString("stress-counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Equals(MakeInt(800)) {
+		t.Errorf("expected 800, got %s", res.ToString(true))
+	}
+}
+
+func TestGoroutinesAndBindings(t *testing.T) {
+	res, err := evalString(`
+(def ^:dynamic *stress-binding* 0)
+(binding [*stress-binding* 1]
+  (->> (range 50)
+       (map (fn [i] (future (binding [*stress-binding* i] (+ i *stress-binding*)))))
+       doall
+       (map deref)
+       (reduce +)
+       (+ *stress-binding*)))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Equals(MakeInt(2451)) {
+		t.Errorf("expected 2451, got %s", res.ToString(true))
+	}
+}
+
+func TestDelayForcedRecursively(t *testing.T) {
+	_, err := evalString("(def stress-delay (delay (inc @stress-delay))) @stress-delay")
+	if err == nil || !strings.Contains(err.Error(), "Delay is forced recursively") {
+		t.Fatalf("expected recursive force error, got %v", err)
+	}
+}
+
+func TestDelayForcedConcurrently(t *testing.T) {
+	if _, err := evalString("(def stress-delay-calls (atom 0)) (def stress-delay-2 (delay (swap! stress-delay-calls inc)))"); err != nil {
+		t.Fatal(err)
+	}
+	parallel(t, 16, func(i int) error {
+		res, err := evalString("@stress-delay-2")
+		if err == nil && !res.Equals(MakeInt(1)) {
+			err = fmt.Errorf("expected 1, got %s", res.ToString(true))
+		}
+		return err
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sync"
 )
 
 type (
@@ -37,7 +38,9 @@ type (
 		InfoHolder
 		MetaHolder
 		fn  Callable
+		rt  *Runtime // runtime fn is called in, see Runtime.child
 		seq Seq
+		mu  sync.Mutex // guards realization of seq
 	}
 	MappingSeq struct {
 		InfoHolder
@@ -126,13 +129,18 @@ func (seq *LazySeq) Seq() Seq {
 	return seq
 }
 
-func (seq *LazySeq) realize() {
+func (seq *LazySeq) realize() Seq {
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
 	if seq.seq == nil {
-		seq.seq = AssertSeqable(seq.fn.Call([]Object{}), "").Seq()
+		seq.seq = AssertSeqable(seq.fn.Call(seq.rt, []Object{}), "").Seq()
 	}
+	return seq.seq
 }
 
 func (seq *LazySeq) IsRealized() bool {
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
 	return seq.seq != nil
 }
 
@@ -149,9 +157,11 @@ func (seq *LazySeq) Pprint(w io.Writer, indent int) int {
 }
 
 func (seq *LazySeq) WithMeta(meta Map) Object {
-	res := *seq
-	res.meta = SafeMerge(res.meta, meta)
-	return &res
+	seq.mu.Lock()
+	res := &LazySeq{InfoHolder: seq.InfoHolder, fn: seq.fn, rt: seq.rt, seq: seq.seq}
+	seq.mu.Unlock()
+	res.meta = SafeMerge(seq.meta, meta)
+	return res
 }
 
 func (seq *LazySeq) GetType() *Type {
@@ -163,18 +173,15 @@ func (seq *LazySeq) Hash() uint32 {
 }
 
 func (seq *LazySeq) First() Object {
-	seq.realize()
-	return seq.seq.First()
+	return seq.realize().First()
 }
 
 func (seq *LazySeq) Rest() Seq {
-	seq.realize()
-	return seq.seq.Rest()
+	return seq.realize().Rest()
 }
 
 func (seq *LazySeq) IsEmpty() bool {
-	seq.realize()
-	return seq.seq.IsEmpty()
+	return seq.realize().IsEmpty()
 }
 
 func (seq *LazySeq) Cons(obj Object) Seq {
@@ -183,8 +190,10 @@ func (seq *LazySeq) Cons(obj Object) Seq {
 
 func (seq *LazySeq) sequential() {}
 
-func NewLazySeq(c Callable) *LazySeq {
-	return &LazySeq{fn: c}
+// NewLazySeq returns a sequence of the elements of the seqable returned
+// by c, which is called when the sequence is first realized.
+func NewLazySeq(rt *Runtime, c Callable) *LazySeq {
+	return &LazySeq{fn: c, rt: rt.child()}
 }
 
 func (seq *ArraySeq) Seq() Seq {
@@ -97,7 +97,7 @@ func (set *MapSet) Count() int {
 	return set.m.Count()
 }
 
-func (set *MapSet) Call(args []Object) Object {
+func (set *MapSet) Call(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	if ok, _ := set.Get(args[0]); ok {
 		return args[0]
@@ -2,6 +2,6 @@
 
 package core
 
-var procGoSpew = func(args []Object) (res Object) {
+var procGoSpew = func(rt *Runtime, args []Object) (res Object) {
 	return MakeBoolean(false)
 }
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// TaggedLiteral is a tagged literal read with no reader function
+	// for its tag, as returned by tagged-literal.
+	TaggedLiteral struct {
+		Tag  Symbol
+		Form Object
+	}
+)
+
+const taggedLiteralHashMask uint32 = 0x9b05688c
+
+// instLayouts are the formats of #inst literals, from the most to the least specific.
+var instLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ParseInst parses the string of #inst literal, which is an RFC 3339
+// timestamp, possibly missing its trailing parts.
+func ParseInst(s string) (Time, bool) {
+	for _, layout := range instLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return MakeTime(t), true
+		}
+	}
+	return Time{}, false
+}
+
+func MakeTaggedLiteral(tag Symbol, form Object) *TaggedLiteral {
+	return &TaggedLiteral{Tag: tag, Form: form}
+}
+
+func (t *TaggedLiteral) ToString(escape bool) string {
+	return "#" + t.Tag.ToString(escape) + " " + t.Form.ToString(escape)
+}
+
+func (t *TaggedLiteral) Equals(other interface{}) bool {
+	switch other := other.(type) {
+	case *TaggedLiteral:
+		return t.Tag.Equals(other.Tag) && t.Form.Equals(other.Form)
+	default:
+		return false
+	}
+}
+
+func (t *TaggedLiteral) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (t *TaggedLiteral) GetType() *Type {
+	return TYPE.TaggedLiteral
+}
+
+func (t *TaggedLiteral) Hash() uint32 {
+	return (31*t.Tag.Hash() + t.Form.Hash()) ^ taggedLiteralHashMask
+}
+
+func (t *TaggedLiteral) WithInfo(info *ObjectInfo) Object {
+	return t
+}
+
+func (t *TaggedLiteral) Get(key Object) (bool, Object) {
+	switch {
+	case key.Equals(KEYWORDS.tag):
+		return true, t.Tag
+	case key.Equals(KEYWORDS.form):
+		return true, t.Form
+	}
+	return false, nil
+}
+
+// dataReader returns the reader function for tag from *data-readers*
+// or default-data-readers, in that order, or nil if there is none.
+// The namespace of a Var from data_readers.joke is loaded the first time
+// its tag is read.
+func dataReader(rt *Runtime, tag Symbol) Callable {
+	for _, sym := range []Symbol{SYMBOLS.dataReaders, SYMBOLS.defaultDataReaders} {
+		readersVar, ok := GLOBAL_ENV.CoreNamespace.mappings[sym.name]
+		if !ok || readersVar.root() == nil {
+			continue
+		}
+		readers, ok := readersVar.Resolve(rt).(Map)
+		if !ok {
+			continue
+		}
+		if ok, f := readers.Get(tag); ok {
+			if v, ok := f.(*Var); ok && v.root() == nil && !v.ns.Name.Equals(SYMBOLS.joker_core) {
+				GLOBAL_ENV.CoreNamespace.Resolve("require").Call(rt, []Object{v.ns.Name})
+			}
+			return AssertCallable(f, "Reader function for tag "+tag.ToString(false)+" must be callable")
+		}
+	}
+	return nil
+}
+
+// defaultDataReaderFn returns the value of *default-data-reader-fn*,
+// or nil if it's not set.
+func defaultDataReaderFn(rt *Runtime) Callable {
+	fnVar, ok := GLOBAL_ENV.CoreNamespace.mappings[SYMBOLS.defaultReaderFn.name]
+	if !ok || fnVar.root() == nil {
+		return nil
+	}
+	switch f := fnVar.Resolve(rt).(type) {
+	case Nil:
+		return nil
+	default:
+		return AssertCallable(f, "*default-data-reader-fn* must be callable")
+	}
+}
+
+// LoadDataReaders adds the mappings from data_readers.joke files found
+// in the roots of *classpath* to the root binding of *data-readers*.
+// Each file must contain a map of tag symbols to namespace-qualified
+// symbols naming reader Vars. The empty classpath root stands for dir.
+func (env *Env) LoadDataReaders(rt *Runtime, dir string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	cp := AssertVector(env.classPath.Resolve(rt), "*classpath* must be a Vector")
+	readersVar := env.CoreNamespace.mappings[SYMBOLS.dataReaders.name]
+	readers := AssertMap(readersVar.root(), "*data-readers* must be a Map")
+	type source struct {
+		sym      Symbol
+		filename string
+	}
+	sources := make(map[string]source)
+	for i := 0; i < cp.Count(); i++ {
+		root := AssertString(cp.at(i), "*classpath* must contain only Strings").S
+		if root == "" {
+			root = dir
+		}
+		filename := filepath.Join(root, "data_readers.joke")
+		f, err := os.Open(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		PanicOnErr(err)
+		obj, err := TryRead(rt, NewReader(bufio.NewReader(f), filename))
+		f.Close()
+		if err == io.EOF {
+			continue
+		}
+		PanicOnErr(err)
+		m, ok := obj.(Map)
+		if !ok {
+			panic(rt.NewError(filename + " must contain a map, not a " + obj.GetType().ToString(false)))
+		}
+		for iter := m.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			tag, ok := p.Key.(Symbol)
+			if !ok {
+				panic(rt.NewError("Invalid data reader tag in " + filename + ": " + p.Key.ToString(true)))
+			}
+			sym, ok := p.Value.(Symbol)
+			if !ok || sym.ns == nil {
+				panic(rt.NewError("Invalid data reader var in " + filename + ": " + p.Value.ToString(true)))
+			}
+			if prev, ok := sources[tag.ToString(false)]; ok && !prev.sym.Equals(sym) {
+				panic(rt.NewError("Conflicting data reader mapping for tag " + tag.ToString(false) + " in " +
+					filename + ": " + sym.ToString(false) + " (already mapped to " + prev.sym.ToString(false) +
+					" in " + prev.filename + ")"))
+			}
+			sources[tag.ToString(false)] = source{sym: sym, filename: filename}
+			ns := env.EnsureNamespace(MakeSymbol(*sym.ns))
+			readers = readers.Assoc(tag, ns.Intern(MakeSymbol(*sym.name))).(Map)
+		}
+	}
+	readersVar.setRoot(readers)
+	return nil
+}
@@ -825,3 +825,87 @@ func EnsureChannel(args []Object, index int) *Channel {
 		panic(RT.NewArgTypeError(index, c, "Channel"))
 	}
 }
+
+func AssertChannelBuffer(obj Object, msg string) *ChannelBuffer {
+	switch c := obj.(type) {
+	case *ChannelBuffer:
+		return c
+	default:
+		if msg == "" {
+			msg = fmt.Sprintf("Expected %s, got %s", "ChannelBuffer", obj.GetType().ToString(false))
+		}
+		panic(RT.NewError(msg))
+	}
+}
+
+func EnsureChannelBuffer(args []Object, index int) *ChannelBuffer {
+	switch c := args[index].(type) {
+	case *ChannelBuffer:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "ChannelBuffer"))
+	}
+}
+
+func AssertFuture(obj Object, msg string) *Future {
+	switch c := obj.(type) {
+	case *Future:
+		return c
+	default:
+		if msg == "" {
+			msg = fmt.Sprintf("Expected %s, got %s", "Future", obj.GetType().ToString(false))
+		}
+		panic(RT.NewError(msg))
+	}
+}
+
+func EnsureFuture(args []Object, index int) *Future {
+	switch c := args[index].(type) {
+	case *Future:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "Future"))
+	}
+}
+
+func AssertBytes(obj Object, msg string) *Bytes {
+	switch c := obj.(type) {
+	case *Bytes:
+		return c
+	default:
+		if msg == "" {
+			msg = fmt.Sprintf("Expected %s, got %s", "Bytes", obj.GetType().ToString(false))
+		}
+		panic(RT.NewError(msg))
+	}
+}
+
+func EnsureBytes(args []Object, index int) *Bytes {
+	switch c := args[index].(type) {
+	case *Bytes:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "Bytes"))
+	}
+}
+
+func AssertUUID(obj Object, msg string) UUID {
+	switch c := obj.(type) {
+	case UUID:
+		return c
+	default:
+		if msg == "" {
+			msg = fmt.Sprintf("Expected %s, got %s", "UUID", obj.GetType().ToString(false))
+		}
+		panic(RT.NewError(msg))
+	}
+}
+
+func EnsureUUID(args []Object, index int) UUID {
+	switch c := args[index].(type) {
+	case UUID:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "UUID"))
+	}
+}
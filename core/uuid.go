@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+type (
+	// UUID is a 128-bit universally unique identifier (RFC 4122).
+	UUID struct {
+		U [16]byte
+	}
+)
+
+const uuidHashMask uint32 = 0x510e527f
+
+func MakeUUID(u [16]byte) UUID {
+	return UUID{U: u}
+}
+
+// ParseUUID parses s in the canonical 8-4-4-4-12 hexadecimal form,
+// as in #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+func ParseUUID(s string) (UUID, error) {
+	var res UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return res, errors.New("Invalid UUID string: " + s)
+	}
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(res.U[:], []byte(digits)); err != nil {
+		return res, errors.New("Invalid UUID string: " + s)
+	}
+	return res, nil
+}
+
+// RandomUUID returns a new version 4 (random) UUID.
+func RandomUUID() UUID {
+	var res UUID
+	_, err := rand.Read(res.U[:])
+	PanicOnErr(err)
+	res.U[6] = (res.U[6] & 0x0f) | 0x40
+	res.U[8] = (res.U[8] & 0x3f) | 0x80
+	return res
+}
+
+func (u UUID) String() string {
+	s := hex.EncodeToString(u.U[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+func (u UUID) ToString(escape bool) string {
+	if escape {
+		return "#uuid \"" + u.String() + "\""
+	}
+	return u.String()
+}
+
+func (u UUID) Equals(other interface{}) bool {
+	switch other := other.(type) {
+	case UUID:
+		return u.U == other.U
+	default:
+		return false
+	}
+}
+
+func (u UUID) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (u UUID) GetType() *Type {
+	return TYPE.UUID
+}
+
+func (u UUID) Hash() uint32 {
+	h := getHash()
+	h.Write(u.U[:])
+	return h.Sum32() ^ uuidHashMask
+}
+
+func (u UUID) WithInfo(info *ObjectInfo) Object {
+	return u
+}
+
+func (u UUID) Native() interface{} {
+	return u.String()
+}
+
+func (u UUID) Compare(other Object) int {
+	u2 := AssertUUID(other, "Cannot compare UUID and "+other.GetType().ToString(false))
+	return bytes.Compare(u.U[:], u2.U[:])
+}
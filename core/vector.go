@@ -419,7 +419,7 @@ func (v *Vector) Rseq() Seq {
 	return &VectorRSeq{vector: v, index: v.count - 1}
 }
 
-func (v *Vector) Call(args []Object) Object {
+func (v *Vector) Call(rt *Runtime, args []Object) Object {
 	CheckArity(args, 1, 1)
 	i := assertInteger(args[0])
 	return v.at(i)
@@ -455,10 +455,13 @@ func (v *Vector) Empty() Collection {
 	return EmptyVector()
 }
 
-func (v *Vector) kvreduce(c Callable, init Object) Object {
+func (v *Vector) kvreduce(rt *Runtime, c Callable, init Object) Object {
 	res := init
 	for i := 0; i < v.Count(); i++ {
-		res = c.Call([]Object{res, Int{I: i}, v.Nth(i)})
+		res = c.Call(rt, []Object{res, Int{I: i}, v.Nth(i)})
+		if r, ok := res.(*Reduced); ok {
+			return r.value
+		}
 	}
 	return res
 }
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	dapMsg map[string]interface{}
+
+	dapRequest struct {
+		Seq       int             `json:"seq"`
+		Command   string          `json:"command"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+
+	dapServer struct {
+		in       *bufio.Reader
+		out      io.Writer
+		outMutex sync.Mutex
+		seq      int
+		// program is sent the filename to run once the client is done
+		// with configuration.
+		program     chan string
+		programName string
+		stopOnEntry bool
+		terminated  sync.Once
+
+		// Fields below are only used while the program is paused.
+		pausedMutex sync.Mutex
+		paused      *DebugEvent
+		// calls are run by the paused goroutine, since evaluating
+		// (and even printing lazy sequences) must happen there.
+		calls  chan func()
+		resume chan DebugAction
+		// refs holds *DebugFrame (for scopes) and Object (for expandable values)
+		// referenced by variablesReference, which is index + 1.
+		refs []interface{}
+	}
+
+	dapOutput struct {
+		s        *dapServer
+		category string
+	}
+)
+
+// dap runs a Debug Adapter Protocol server on stdin and stdout.
+// The program to debug is given by the launch request.
+func dap(rt *Runtime) {
+	s := &dapServer{
+		in:      bufio.NewReader(os.Stdin),
+		out:     os.Stdout,
+		program: make(chan string, 1),
+		calls:   make(chan func()),
+		resume:  make(chan DebugAction),
+	}
+	// Stdin and stdout carry the protocol, so the program
+	// gets empty input and its output is sent as output events.
+	Stdout = &dapOutput{s, "stdout"}
+	Stderr = &dapOutput{s, "stderr"}
+	GLOBAL_ENV.SetStdIO(MakeBufferedReader(strings.NewReader("")), MakeIOWriter(Stdout), MakeIOWriter(Stderr))
+	OnExit(s.terminate)
+	SetDebugger(s)
+
+	go s.serve()
+	filename := <-s.program
+	if s.stopOnEntry {
+		RequestPause()
+	}
+	exitCode := 0
+	if err := processFile(rt, filename, EVAL); err != nil {
+		exitCode = 1
+	}
+	s.event("exited", dapMsg{"exitCode": exitCode})
+	s.terminate()
+	// Wait for the client to disconnect.
+	select {}
+}
+
+func (s *dapServer) serve() {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			ExitJoker(0)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading DAP message: %s\n", err.Error())
+			ExitJoker(1)
+		}
+		var req dapRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing DAP message: %s\n", err.Error())
+			continue
+		}
+		s.handle(&req)
+	}
+}
+
+func (s *dapServer) read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if i := strings.Index(line, ":"); i > 0 && strings.EqualFold(line[:i], "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(s.in, body)
+	return body, err
+}
+
+func (s *dapServer) send(msg dapMsg) {
+	s.outMutex.Lock()
+	defer s.outMutex.Unlock()
+	s.seq++
+	msg["seq"] = s.seq
+	body, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *dapServer) reply(req *dapRequest, body interface{}) {
+	s.send(dapMsg{"type": "response", "request_seq": req.Seq, "command": req.Command, "success": true, "body": body})
+}
+
+func (s *dapServer) fail(req *dapRequest, message string) {
+	s.send(dapMsg{"type": "response", "request_seq": req.Seq, "command": req.Command, "success": false, "message": message})
+}
+
+func (s *dapServer) event(name string, body interface{}) {
+	s.send(dapMsg{"type": "event", "event": name, "body": body})
+}
+
+func (s *dapServer) terminate() {
+	s.terminated.Do(func() { s.event("terminated", dapMsg{}) })
+}
+
+func (o *dapOutput) Write(p []byte) (int, error) {
+	o.s.event("output", dapMsg{"category": o.category, "output": string(p)})
+	return len(p), nil
+}
+
+func (s *dapServer) handle(req *dapRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(req, fmt.Sprint(r))
+		}
+	}()
+	switch req.Command {
+	case "initialize":
+		s.reply(req, dapMsg{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+		})
+		s.event("initialized", dapMsg{})
+	case "launch":
+		var args struct {
+			Program     string `json:"program"`
+			StopOnEntry bool   `json:"stopOnEntry"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+		if args.Program == "" {
+			s.fail(req, "Missing program to launch")
+			return
+		}
+		s.programName, s.stopOnEntry = args.Program, args.StopOnEntry
+		s.reply(req, nil)
+	case "setBreakpoints":
+		var args struct {
+			Source struct {
+				Path string `json:"path"`
+			} `json:"source"`
+			Breakpoints []struct {
+				Line int `json:"line"`
+			} `json:"breakpoints"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+		var lines []int
+		var res []dapMsg
+		for _, b := range args.Breakpoints {
+			lines = append(lines, b.Line)
+			res = append(res, dapMsg{"verified": true, "line": b.Line})
+		}
+		SetFileBreakpoints(args.Source.Path, lines)
+		s.reply(req, dapMsg{"breakpoints": res})
+	case "setExceptionBreakpoints":
+		s.reply(req, nil)
+	case "configurationDone":
+		s.reply(req, nil)
+		if s.programName == "" {
+			s.event("output", dapMsg{"category": "stderr", "output": "No program to debug (launch request is required)\n"})
+			s.terminate()
+			return
+		}
+		s.program <- s.programName
+	case "threads":
+		s.reply(req, dapMsg{"threads": []dapMsg{{"id": 1, "name": "main"}}})
+	case "stackTrace":
+		s.whilePaused(req, s.stackTrace)
+	case "scopes":
+		s.whilePaused(req, s.scopes)
+	case "variables":
+		s.whilePaused(req, s.variables)
+	case "evaluate":
+		s.whilePaused(req, s.evaluate)
+	case "continue":
+		s.doResume(req, DEBUG_CONTINUE, dapMsg{"allThreadsContinued": true})
+	case "next":
+		s.doResume(req, DEBUG_STEP_OVER, nil)
+	case "stepIn":
+		s.doResume(req, DEBUG_STEP_IN, nil)
+	case "stepOut":
+		s.doResume(req, DEBUG_STEP_OUT, nil)
+	case "pause":
+		RequestPause()
+		s.reply(req, nil)
+	case "disconnect", "terminate":
+		s.reply(req, nil)
+		ExitJoker(0)
+	default:
+		s.fail(req, "Unsupported request: "+req.Command)
+	}
+}
+
+// Paused implements Debugger. It reports the stop to the client
+// and runs requests that need the paused goroutine until the client
+// resumes evaluation.
+func (s *dapServer) Paused(ev *DebugEvent) DebugAction {
+	s.pausedMutex.Lock()
+	s.paused = ev
+	s.refs = nil
+	s.pausedMutex.Unlock()
+	reason := ev.Reason
+	if reason == "break" {
+		reason = "breakpoint"
+	}
+	s.event("stopped", dapMsg{"reason": reason, "threadId": 1, "allThreadsStopped": true})
+	for {
+		select {
+		case f := <-s.calls:
+			f()
+		case action := <-s.resume:
+			s.pausedMutex.Lock()
+			s.paused = nil
+			s.pausedMutex.Unlock()
+			return action
+		}
+	}
+}
+
+func (s *dapServer) isPaused() bool {
+	s.pausedMutex.Lock()
+	defer s.pausedMutex.Unlock()
+	return s.paused != nil
+}
+
+// whilePaused runs the handler on the paused goroutine and waits for it to finish.
+func (s *dapServer) whilePaused(req *dapRequest, handler func(req *dapRequest)) {
+	if !s.isPaused() {
+		s.fail(req, "Program is not paused")
+		return
+	}
+	done := make(chan bool)
+	s.calls <- func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				s.fail(req, fmt.Sprint(r))
+			}
+		}()
+		handler(req)
+	}
+	<-done
+}
+
+func (s *dapServer) doResume(req *dapRequest, action DebugAction, body interface{}) {
+	if !s.isPaused() {
+		s.fail(req, "Program is not paused")
+		return
+	}
+	s.reply(req, body)
+	s.resume <- action
+}
+
+func (s *dapServer) ref(v interface{}) int {
+	s.refs = append(s.refs, v)
+	return len(s.refs)
+}
+
+func (s *dapServer) deref(ref int) interface{} {
+	if ref < 1 || ref > len(s.refs) {
+		panic(fmt.Sprintf("Invalid variables reference: %d", ref))
+	}
+	return s.refs[ref-1]
+}
+
+func (s *dapServer) frame(id int) *DebugFrame {
+	if id < 1 || id > len(s.paused.Frames) {
+		return s.paused.Frames[0]
+	}
+	return s.paused.Frames[id-1]
+}
+
+func (s *dapServer) stackTrace(req *dapRequest) {
+	var frames []dapMsg
+	for i, f := range s.paused.Frames {
+		frame := dapMsg{"id": i + 1, "name": f.Name, "line": f.Pos.StartLine(), "column": f.Pos.StartColumn()}
+		if filename := f.Pos.Filename(); !strings.HasPrefix(filename, "<") {
+			frame["source"] = dapMsg{"name": filepath.Base(filename), "path": filename}
+		}
+		frames = append(frames, frame)
+	}
+	s.reply(req, dapMsg{"stackFrames": frames, "totalFrames": len(frames)})
+}
+
+func (s *dapServer) scopes(req *dapRequest) {
+	var args struct {
+		FrameID int `json:"frameId"`
+	}
+	json.Unmarshal(req.Arguments, &args)
+	ref := s.ref(s.frame(args.FrameID))
+	s.reply(req, dapMsg{"scopes": []dapMsg{{"name": "Locals", "variablesReference": ref, "expensive": false}}})
+}
+
+// valueRef returns the variables reference for obj if it's a collection
+// the client can expand, or 0 otherwise.
+func (s *dapServer) valueRef(obj Object) int {
+	switch obj.(type) {
+	case Map, *Vector, Set, *List:
+		if obj.(Counted).Count() > 0 {
+			return s.ref(obj)
+		}
+	}
+	return 0
+}
+
+func (s *dapServer) variable(name string, obj Object) dapMsg {
+	return dapMsg{
+		"name":               name,
+		"value":              obj.ToString(true),
+		"type":               obj.GetType().ToString(false),
+		"variablesReference": s.valueRef(obj),
+	}
+}
+
+func (s *dapServer) variables(req *dapRequest) {
+	var args struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	json.Unmarshal(req.Arguments, &args)
+	vars := []dapMsg{}
+	switch v := s.deref(args.VariablesReference).(type) {
+	case *DebugFrame:
+		for _, l := range v.Locals() {
+			vars = append(vars, s.variable(l.Name, l.Value))
+		}
+	case Map:
+		for iter := v.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			vars = append(vars, s.variable(p.Key.ToString(true), p.Value))
+		}
+	case Seqable:
+		i := 0
+		for seq := v.Seq(); !seq.IsEmpty(); seq = seq.Rest() {
+			vars = append(vars, s.variable(strconv.Itoa(i), seq.First()))
+			i++
+		}
+	}
+	s.reply(req, dapMsg{"variables": vars})
+}
+
+func (s *dapServer) evaluate(req *dapRequest) {
+	var args struct {
+		Expression string `json:"expression"`
+		FrameID    int    `json:"frameId"`
+	}
+	json.Unmarshal(req.Arguments, &args)
+	obj, err := s.frame(args.FrameID).Eval(args.Expression)
+	if err != nil {
+		s.fail(req, err.Error())
+		return
+	}
+	s.reply(req, dapMsg{"result": obj.ToString(true), "variablesReference": s.valueRef(obj)})
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/candid82/joker/core"
+)
+
+var errorFormats = []string{"text", "json"}
+
+// printErrorJSON prints err as a single line of JSON,
+// for tools that run Joker programs (see --error-format).
+func printErrorJSON(err error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if e := enc.Encode(NewErrorInfo(err)); e != nil {
+		fmt.Fprintln(Stderr, err)
+		return
+	}
+	Stderr.Write(b.Bytes())
+}
@@ -0,0 +1,891 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	// textEdit replaces text between byte offsets start and end.
+	textEdit struct {
+		start int
+		end   int
+		text  string
+	}
+
+	// location is a (1-based) line and column in a source file.
+	location struct {
+		line   int
+		column int
+	}
+
+	// sourceFile is the text of a file being fixed
+	// along with the forms read from it.
+	sourceFile struct {
+		name  string
+		text  string
+		lines []int // byte offsets of line starts
+		forms []Object
+		rt    *Runtime // runtime the forms are read in
+	}
+
+	// fixer holds back problems found in a file until it's linted,
+	// fixes the ones it can and passes the rest on to the report.
+	fixer struct {
+		rt      *Runtime
+		report  *lintReport
+		dryRun  bool
+		out     io.Writer
+		pending []*Problem
+	}
+)
+
+// Macros that rewrite their arguments, so that removing
+// a do form inside them may change the meaning of the code.
+var threadingMacros = map[string]bool{
+	"->": true, "->>": true, "some->": true, "some->>": true, "cond->": true,
+	"cond->>": true, "as->": true, "doto": true, "..": true,
+}
+
+func newFixer(rt *Runtime, report *lintReport, dryRun bool) *fixer {
+	return &fixer{
+		rt:     rt,
+		report: report,
+		dryRun: dryRun,
+		out:    Stdout,
+	}
+}
+
+func (f *fixer) add(p *Problem) {
+	f.pending = append(f.pending, p)
+}
+
+// flush reports all pending problems.
+func (f *fixer) flush() {
+	for _, p := range f.pending {
+		f.report.add(p)
+	}
+	f.pending = nil
+}
+
+// fix rewrites filename (or prints the diff in dry-run mode)
+// to fix the problems found in it. Problems that are fixed
+// are not reported.
+func (f *fixer) fix(filename string) {
+	var problems []*Problem
+	for _, p := range f.pending {
+		if p.Pos.Filename() == filename {
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		if src, err := readSourceFile(f.rt, filename); err == nil {
+			fixed, edits := src.fixes(problems)
+			if len(edits) > 0 {
+				f.apply(src, edits)
+				if !f.dryRun {
+					f.report.fixed += len(fixed)
+					pending := f.pending[:0]
+					for _, p := range f.pending {
+						if !fixed[p] {
+							pending = append(pending, p)
+						}
+					}
+					f.pending = pending
+				}
+			}
+		}
+	}
+	f.flush()
+}
+
+func (f *fixer) apply(src *sourceFile, edits []textEdit) {
+	text := applyEdits(src.text, edits, 0)
+	if f.dryRun {
+		writeDiff(f.out, src, edits)
+		return
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(src.name); err == nil {
+		mode = info.Mode()
+	}
+	if err := ioutil.WriteFile(src.name, []byte(text), mode); err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+	}
+}
+
+func readSourceFile(rt *Runtime, filename string) (*sourceFile, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newSourceFile(rt, filename, string(b))
+}
+
+func newSourceFile(rt *Runtime, filename string, text string) (*sourceFile, error) {
+	src := &sourceFile{
+		name:  filename,
+		text:  text,
+		lines: []int{0},
+		rt:    rt,
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			src.lines = append(src.lines, i+1)
+		}
+	}
+	// The file has already been linted, so don't report
+	// the problems reader finds again.
+	handler, count := ProblemHandler, PROBLEM_COUNT
+	ProblemHandler = func(p *Problem) {}
+	defer func() {
+		ProblemHandler, PROBLEM_COUNT = handler, count
+	}()
+	reader := NewReader(strings.NewReader(src.text), filename)
+	for {
+		obj, err := TryRead(rt, reader)
+		if err == io.EOF {
+			return src, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		src.forms = append(src.forms, obj)
+	}
+}
+
+// offset returns byte offset of the character at (1-based) line and column.
+func (src *sourceFile) offset(line int, column int) int {
+	if line < 1 || line > len(src.lines) || column < 1 {
+		return -1
+	}
+	res := src.lines[line-1]
+	for i := 1; i < column && res < len(src.text); i++ {
+		_, size := utf8.DecodeRuneInString(src.text[res:])
+		res += size
+	}
+	return res
+}
+
+// location returns the line and column of the character at offset.
+func (src *sourceFile) location(offset int) location {
+	line := sort.Search(len(src.lines), func(i int) bool { return src.lines[i] > offset }) - 1
+	return location{line: line + 1, column: utf8.RuneCountInString(src.text[src.lines[line]:offset]) + 1}
+}
+
+func startOf(obj Object) location {
+	if info := obj.GetInfo(); info != nil {
+		return location{line: info.StartLine(), column: info.StartColumn()}
+	}
+	return location{}
+}
+
+// span returns byte offsets of the start and the end (exclusive) of obj.
+func (src *sourceFile) span(obj Object) (start int, end int, ok bool) {
+	info := obj.GetInfo()
+	if info == nil || info.Filename() != src.name {
+		return 0, 0, false
+	}
+	start = src.offset(info.StartLine(), info.StartColumn())
+	end = src.offset(info.EndLine(), info.EndColumn())
+	if start < 0 || end < start || end >= len(src.text) {
+		return 0, 0, false
+	}
+	_, size := utf8.DecodeRuneInString(src.text[end:])
+	return start, end + size, true
+}
+
+func (src *sourceFile) textOf(obj Object) string {
+	start, end, _ := src.span(obj)
+	return src.text[start:end]
+}
+
+func children(obj Object) []Object {
+	var res []Object
+	switch obj := obj.(type) {
+	case *List, *Vector:
+		for s := obj.(Seqable).Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, s.First())
+		}
+	}
+	return res
+}
+
+func headName(obj Object) string {
+	if l, ok := obj.(*List); ok && !l.IsEmpty() {
+		switch head := l.First().(type) {
+		case Symbol:
+			return head.Name()
+		case Keyword:
+			return ":" + head.Name()
+		}
+	}
+	return ""
+}
+
+// find returns the outermost form starting at loc that satisfies
+// pred along with its parent form (nil for top level forms).
+func (src *sourceFile) find(loc location, pred func(obj Object) bool) (obj Object, parent Object) {
+	var visit func(forms []Object, parent Object) (Object, Object)
+	visit = func(forms []Object, parent Object) (Object, Object) {
+		for _, form := range forms {
+			if form.GetInfo() != nil && startOf(form) == loc && pred(form) {
+				if _, _, ok := src.span(form); ok {
+					return form, parent
+				}
+			}
+			if obj, parent := visit(children(form), form); obj != nil {
+				return obj, parent
+			}
+		}
+		return nil, nil
+	}
+	return visit(src.forms, nil)
+}
+
+// sameForm tells whether a and b are the same form read from the source.
+func sameForm(a Object, b Object) bool {
+	return a.GetInfo() != nil && a.GetInfo() == b.GetInfo()
+}
+
+func indexOf(forms []Object, obj Object) int {
+	for i, form := range forms {
+		if sameForm(form, obj) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// deleteForms returns the edit that deletes forms from i to j (inclusive)
+// of parent along with the whitespace around them.
+func (src *sourceFile) deleteForms(parent Object, i int, j int) (textEdit, bool) {
+	forms := children(parent)
+	start, _, ok1 := src.span(forms[i])
+	_, end, ok2 := src.span(forms[j])
+	if !ok1 || !ok2 {
+		return textEdit{}, false
+	}
+	if i > 0 {
+		if _, prevEnd, ok := src.span(forms[i-1]); ok && isBlank(src.text[prevEnd:start]) {
+			return textEdit{start: prevEnd, end: end}, true
+		}
+	}
+	s := start
+	for s > 0 && (src.text[s-1] == ' ' || src.text[s-1] == '\t') {
+		s--
+	}
+	e := end
+	for e < len(src.text) && (src.text[e] == ' ' || src.text[e] == '\t') {
+		e++
+	}
+	if (s == 0 || src.text[s-1] == '\n') && (e == len(src.text) || src.text[e] == '\n' || src.text[e] == '\r') {
+		// The forms are on their own line(s).
+		if nl := strings.IndexByte(src.text[e:], '\n'); nl >= 0 {
+			e += nl + 1
+		}
+		return textEdit{start: s, end: e}, true
+	}
+	return textEdit{start: start, end: e}, true
+}
+
+// Rules whose fixes reorder forms. Problems at the reordered
+// forms can't be fixed after that, so these rules are fixed last.
+var reorderingRules = map[string]bool{
+	"unsorted-required-namespaces": true,
+}
+
+// fixes returns the problems that can be fixed and the edits that fix them.
+// Fixes that overlap with the ones before them are made to the text
+// fixed so far, until there is nothing left to fix.
+func (src *sourceFile) fixes(problems []*Problem) (map[*Problem]bool, []textEdit) {
+	fixed := map[*Problem]bool{}
+	locs := map[*Problem]location{}
+	for _, p := range problems {
+		locs[p] = location{line: p.Pos.StartLine(), column: p.Pos.StartColumn()}
+	}
+	// origins[i] is the offset in src.text of the character at
+	// offset i of the text fixed so far (-1 for inserted characters).
+	origins := make([]int, len(src.text))
+	for i := range origins {
+		origins[i] = i
+	}
+	cur := src
+	for {
+		done, edits := cur.fixRound(problems, locs, false)
+		if len(done) == 0 {
+			done, edits = cur.fixRound(problems, locs, true)
+		}
+		if len(done) == 0 {
+			break
+		}
+		next, err := newSourceFile(src.rt, src.name, applyEdits(cur.text, edits, 0))
+		if err != nil {
+			break
+		}
+		for p, loc := range locs {
+			if done[p] {
+				fixed[p] = true
+				delete(locs, p)
+				continue
+			}
+			offset := cur.offset(loc.line, loc.column)
+			if offset < 0 {
+				delete(locs, p)
+				continue
+			}
+			if e := editAt(edits, offset); e != nil {
+				// A problem in the code removed by another fix is fixed too.
+				// If the code was replaced, the problem is just reported.
+				if e.text == "" {
+					fixed[p] = true
+				}
+				delete(locs, p)
+				continue
+			}
+			locs[p] = next.location(mapOffset(edits, offset))
+		}
+		origins = mapOrigins(origins, edits)
+		cur = next
+	}
+	return fixed, originalEdits(src.text, cur.text, origins)
+}
+
+// fixRound returns the problems at locs that can be fixed together
+// (those of reordering rules or all the others) and the edits that fix them.
+func (src *sourceFile) fixRound(problems []*Problem, locs map[*Problem]location, reordering bool) (map[*Problem]bool, []textEdit) {
+	done := map[*Problem]bool{}
+	var edits []textEdit
+	unusedReferred := map[location]bool{}
+	for _, p := range problems {
+		if loc, ok := locs[p]; ok && p.Rule == "unused-referred-var" {
+			unusedReferred[loc] = true
+		}
+	}
+	for _, p := range problems {
+		loc, ok := locs[p]
+		if !ok || reorderingRules[p.Rule] != reordering {
+			continue
+		}
+		var fix []textEdit
+		switch p.Rule {
+		case "redundant-do":
+			fix = src.fixRedundantDo(loc)
+		case "if-with-nil-else":
+			fix = src.fixIfWithNilElse(loc)
+		case "unused-namespace":
+			fix = src.fixUnusedNamespace(loc)
+		case "unused-referred-var":
+			fix = src.fixUnusedReferredVar(loc, unusedReferred)
+		case "unsorted-required-namespaces":
+			fix = src.fixUnsortedRequires(loc)
+		}
+		if fix == nil {
+			continue
+		}
+		if added, ok := addEdits(edits, fix); ok {
+			edits = added
+			done[p] = true
+		}
+	}
+	return done, edits
+}
+
+// editAt returns the edit that removes or replaces the character at offset.
+func editAt(edits []textEdit, offset int) *textEdit {
+	for i := range edits {
+		if edits[i].start <= offset && offset < edits[i].end {
+			return &edits[i]
+		}
+	}
+	return nil
+}
+
+// mapOffset returns the offset that the character at offset
+// (not changed by edits) has after edits are applied.
+func mapOffset(edits []textEdit, offset int) int {
+	res := offset
+	for _, e := range edits {
+		if e.end <= offset {
+			res += len(e.text) - (e.end - e.start)
+		}
+	}
+	return res
+}
+
+// mapOrigins returns the origins of the characters of the text
+// after edits are applied, given the origins of the text before.
+func mapOrigins(origins []int, edits []textEdit) []int {
+	sorted := make([]textEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+	var res []int
+	pos := 0
+	for _, e := range sorted {
+		res = append(res, origins[pos:e.start]...)
+		for i := 0; i < len(e.text); i++ {
+			res = append(res, -1)
+		}
+		pos = e.end
+	}
+	return append(res, origins[pos:]...)
+}
+
+// originalEdits returns the edits of the original text that turn
+// it into text, given the origins of the characters of text.
+func originalEdits(original string, text string, origins []int) []textEdit {
+	var res []textEdit
+	i, prev := 0, 0
+	for {
+		j := i
+		for j < len(text) && origins[j] < 0 {
+			j++
+		}
+		next := len(original)
+		if j < len(text) {
+			next = origins[j]
+		}
+		if j > i || next > prev {
+			res = append(res, textEdit{start: prev, end: next, text: text[i:j]})
+		}
+		if j == len(text) {
+			return res
+		}
+		// Skip the characters that are kept.
+		for i = j; i < len(text) && origins[i] == next; i++ {
+			next++
+		}
+		prev = next
+	}
+}
+
+// addEdits adds fix to edits unless they overlap.
+// A fix that is already there (e.g. removing all the referred vars,
+// which is the fix for each of them) is not added again.
+func addEdits(edits []textEdit, fix []textEdit) ([]textEdit, bool) {
+	if containsEdits(edits, fix) {
+		return edits, true
+	}
+	for _, e := range fix {
+		for _, other := range edits {
+			if e.start < other.end && other.start < e.end {
+				return edits, false
+			}
+		}
+	}
+	return append(edits, fix...), true
+}
+
+func containsEdits(edits []textEdit, fix []textEdit) bool {
+	for _, e := range fix {
+		found := false
+		for _, other := range edits {
+			if e == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// (do x y) -> x y
+func (src *sourceFile) fixRedundantDo(loc location) []textEdit {
+	obj, parent := src.find(loc, func(obj Object) bool { return headName(obj) == "do" })
+	if obj == nil || threadingMacros[headName(parent)] {
+		return nil
+	}
+	forms := children(obj)
+	if len(forms) < 2 {
+		return nil
+	}
+	start, end, _ := src.span(obj)
+	// The body of #(do x) is also the fn literal itself,
+	// so removing the do would leave #x.
+	if !strings.HasPrefix(src.text[start:], "(do") || (start > 0 && src.text[start-1] == '#') {
+		return nil
+	}
+	_, doEnd, ok1 := src.span(forms[0])
+	bodyStart, _, ok2 := src.span(forms[1])
+	_, bodyEnd, ok3 := src.span(forms[len(forms)-1])
+	if !ok1 || !ok2 || !ok3 || !isBlank(src.text[doEnd:bodyStart]) || !isBlank(src.text[bodyEnd:end-1]) {
+		return nil
+	}
+	// The body takes the place of the do form, so its lines
+	// are moved to the left as much as its first line is.
+	shift := src.location(bodyStart).column - src.location(start).column
+	edits := []textEdit{{start: start, end: bodyStart}}
+	edits = append(edits, src.unindent(bodyStart, bodyEnd, shift)...)
+	return append(edits, textEdit{start: bodyEnd, end: end})
+}
+
+// unindent returns the edits that remove up to shift whitespace characters
+// from the start of the lines between start and end (except the first one).
+// Lines inside strings are left alone.
+func (src *sourceFile) unindent(start int, end int, shift int) []textEdit {
+	if shift <= 0 {
+		return nil
+	}
+	var res []textEdit
+	inString := false
+	for i := start; i < end; i++ {
+		c := src.text[i]
+		switch {
+		case c == '\\':
+			// Escaped character in a string or a character literal.
+			i++
+			continue
+		case c == '"':
+			inString = !inString
+			continue
+		case c == ';' && !inString:
+			for i+1 < end && src.text[i+1] != '\n' {
+				i++
+			}
+			continue
+		case c != '\n' || inString:
+			continue
+		}
+		j := i + 1
+		for j < end && j-i <= shift && (src.text[j] == ' ' || src.text[j] == '\t') {
+			j++
+		}
+		if j > i+1 {
+			res = append(res, textEdit{start: i + 1, end: j})
+		}
+	}
+	return res
+}
+
+// (if x y nil) -> (when x y)
+func (src *sourceFile) fixIfWithNilElse(loc location) []textEdit {
+	obj, _ := src.find(loc, func(obj Object) bool { return headName(obj) == "if" })
+	if obj == nil {
+		return nil
+	}
+	forms := children(obj)
+	if len(forms) != 4 || !forms[3].Equals(NIL) {
+		return nil
+	}
+	ifStart, ifEnd, ok1 := src.span(forms[0])
+	_, thenEnd, ok2 := src.span(forms[2])
+	_, nilEnd, ok3 := src.span(forms[3])
+	if !ok1 || !ok2 || !ok3 || !isBlank(src.text[thenEnd:nilEnd-len("nil")]) {
+		return nil
+	}
+	return []textEdit{
+		{start: ifStart, end: ifEnd, text: "when"},
+		{start: thenEnd, end: nilEnd},
+	}
+}
+
+func isRequireClause(obj Object, parent Object) bool {
+	name := headName(obj)
+	return (name == ":require" || name == ":use") && headName(parent) == "ns"
+}
+
+// findLibspec returns the libspec of ns form (either a symbol or
+// a vector starting with a symbol) that names the namespace at loc
+// along with the :require clause it's in.
+func (src *sourceFile) findLibspec(loc location) (libspec Object, clause Object, ns Object) {
+	sym, parent := src.find(loc, func(obj Object) bool {
+		_, ok := obj.(Symbol)
+		return ok
+	})
+	if sym == nil {
+		return nil, nil, nil
+	}
+	libspec, clause = sym, parent
+	if _, ok := parent.(*Vector); ok && indexOf(children(parent), sym) == 0 {
+		libspec = parent
+		_, clause = src.find(startOf(libspec), func(obj Object) bool { return sameForm(obj, libspec) })
+	}
+	if clause == nil {
+		return nil, nil, nil
+	}
+	_, ns = src.find(startOf(clause), func(obj Object) bool { return sameForm(obj, clause) })
+	if !isRequireClause(clause, ns) {
+		return nil, nil, nil
+	}
+	return libspec, clause, ns
+}
+
+func (src *sourceFile) fixUnusedNamespace(loc location) []textEdit {
+	libspec, clause, ns := src.findLibspec(loc)
+	if libspec == nil {
+		return nil
+	}
+	libspecs := children(clause)
+	if len(libspecs) == 2 {
+		i := indexOf(children(ns), clause)
+		if e, ok := src.deleteForms(ns, i, i); ok {
+			return []textEdit{e}
+		}
+		return nil
+	}
+	i := indexOf(libspecs, libspec)
+	if e, ok := src.deleteForms(clause, i, i); ok {
+		return []textEdit{e}
+	}
+	return nil
+}
+
+// [foo :refer [a b]] -> [foo :refer [b]]
+func (src *sourceFile) fixUnusedReferredVar(loc location, unused map[location]bool) []textEdit {
+	sym, refers := src.find(loc, func(obj Object) bool {
+		_, ok := obj.(Symbol)
+		return ok
+	})
+	if _, ok := refers.(*Vector); !ok {
+		return nil
+	}
+	_, libspec := src.find(startOf(refers), func(obj Object) bool { return sameForm(obj, refers) })
+	if _, ok := libspec.(*Vector); !ok {
+		return nil
+	}
+	opts := children(libspec)
+	i := indexOf(opts, refers)
+	if i < 1 {
+		return nil
+	}
+	if k, ok := opts[i-1].(Keyword); !ok || (k.Name() != "refer" && k.Name() != "only") {
+		return nil
+	}
+	allUnused := true
+	for _, s := range children(refers) {
+		if !unused[startOf(s)] {
+			allUnused = false
+		}
+	}
+	var e textEdit
+	var ok bool
+	if allUnused {
+		e, ok = src.deleteForms(libspec, i-1, i)
+	} else {
+		j := indexOf(children(refers), sym)
+		e, ok = src.deleteForms(refers, j, j)
+	}
+	if !ok {
+		return nil
+	}
+	return []textEdit{e}
+}
+
+func libName(libspec Object) string {
+	switch libspec := libspec.(type) {
+	case Symbol:
+		return libspec.ToString(false)
+	case *Vector, *List:
+		if forms := children(libspec); len(forms) > 0 {
+			if sym, ok := forms[0].(Symbol); ok {
+				return sym.ToString(false)
+			}
+		}
+	}
+	return ""
+}
+
+// trailingComment returns the end of the comment that follows
+// the form ending at end on the same line (or end if there is none).
+func (src *sourceFile) trailingComment(end int) int {
+	i := end
+	for i < len(src.text) && (src.text[i] == ' ' || src.text[i] == '\t') {
+		i++
+	}
+	if i == len(src.text) || src.text[i] != ';' {
+		return end
+	}
+	for i < len(src.text) && src.text[i] != '\n' && src.text[i] != '\r' {
+		i++
+	}
+	return i
+}
+
+// endsLine tells whether only whitespace follows offset on its line.
+func (src *sourceFile) endsLine(offset int) bool {
+	rest := src.text[offset:]
+	if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+		rest = rest[:nl]
+	}
+	return isBlank(rest)
+}
+
+// Sorts libspecs of the :require clause by namespace name.
+// Comments that follow libspecs on the same line move with them.
+func (src *sourceFile) fixUnsortedRequires(loc location) []textEdit {
+	_, clause := src.find(loc, func(obj Object) bool { return libName(obj) != "" })
+	if clause == nil {
+		return nil
+	}
+	_, ns := src.find(startOf(clause), func(obj Object) bool { return sameForm(obj, clause) })
+	if !isRequireClause(clause, ns) {
+		return nil
+	}
+	libspecs := children(clause)[1:]
+	type chunk struct {
+		start     int
+		end       int
+		commented bool
+	}
+	chunks := map[Object]chunk{}
+	for _, libspec := range libspecs {
+		start, end, ok := src.span(libspec)
+		if !ok || libName(libspec) == "" {
+			return nil
+		}
+		commentEnd := src.trailingComment(end)
+		chunks[libspec] = chunk{start: start, end: commentEnd, commented: commentEnd > end}
+	}
+	sorted := make([]Object, len(libspecs))
+	copy(sorted, libspecs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return libName(sorted[i]) < libName(sorted[j])
+	})
+	res := []textEdit{}
+	for i, libspec := range libspecs {
+		if sameForm(sorted[i], libspec) {
+			continue
+		}
+		slot, c := chunks[libspec], chunks[sorted[i]]
+		e := textEdit{start: slot.start, end: slot.end, text: src.text[c.start:c.end]}
+		if c.commented && !slot.commented && !src.endsLine(slot.end) {
+			// Keep the comment from swallowing the rest of the line:
+			// closing parens go before it, anything else to the next line.
+			_, libspecEnd, _ := src.span(sorted[i])
+			lineEnd := slot.end
+			for lineEnd < len(src.text) && src.text[lineEnd] != '\n' && src.text[lineEnd] != '\r' {
+				lineEnd++
+			}
+			if rest := src.text[slot.end:lineEnd]; strings.Trim(rest, ")] \t") == "" {
+				e.end = lineEnd
+				e.text = src.text[c.start:libspecEnd] + strings.TrimRight(rest, " \t") + src.text[libspecEnd:c.end]
+			} else {
+				e.text += "\n" + strings.Repeat(" ", src.location(slot.start).column-1)
+			}
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// applyEdits applies edits to text that starts at
+// the given offset of the source file.
+func applyEdits(text string, edits []textEdit, offset int) string {
+	sorted := make([]textEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+	var b strings.Builder
+	pos := 0
+	for _, e := range sorted {
+		b.WriteString(text[pos : e.start-offset])
+		b.WriteString(e.text)
+		pos = e.end - offset
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeDiffLine(w io.Writer, prefix string, line string) {
+	fmt.Fprint(w, prefix+line)
+	if !strings.HasSuffix(line, "\n") {
+		fmt.Fprint(w, "\n\\ No newline at end of file\n")
+	}
+}
+
+// writeDiff prints edits of src in unified diff format.
+func writeDiff(w io.Writer, src *sourceFile, edits []textEdit) {
+	const context = 3
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	lineOf := func(offset int) int {
+		return sort.Search(len(src.lines), func(i int) bool { return src.lines[i] > offset }) - 1
+	}
+	lines := splitLines(src.text)
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", src.name, src.name)
+	delta := 0
+	for i := 0; i < len(edits); {
+		// Group edits which are close enough to share context lines.
+		first := lineOf(edits[i].start)
+		last := lineOf(edits[i].end - 1)
+		j := i + 1
+		for j < len(edits) && lineOf(edits[j].start)-context <= last+context {
+			if l := lineOf(edits[j].end - 1); l > last {
+				last = l
+			}
+			j++
+		}
+		from := first - context
+		if from < 0 {
+			from = 0
+		}
+		to := last + context
+		if to >= len(lines) {
+			to = len(lines) - 1
+		}
+		old := lines[from : to+1]
+		offset := src.lines[from]
+		changed := splitLines(applyEdits(strings.Join(old, ""), edits[i:j], offset))
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", from+1, len(old), from+1+delta, len(changed))
+		writeLineDiff(w, old, changed)
+		delta += len(changed) - len(old)
+		i = j
+	}
+}
+
+// writeLineDiff prints lines of a hunk, marking the ones
+// that are not in the longest common subsequence as changed.
+func writeLineDiff(w io.Writer, old []string, changed []string) {
+	// lcs[i][j] is the length of the longest common subsequence
+	// of old[i:] and changed[j:].
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(changed)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(changed) - 1; j >= 0; j-- {
+			if old[i] == changed[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(old) || j < len(changed) {
+		switch {
+		case i < len(old) && j < len(changed) && old[i] == changed[j]:
+			writeDiffLine(w, " ", old[i])
+			i++
+			j++
+		case j == len(changed) || (i < len(old) && lcs[i+1][j] >= lcs[i][j+1]):
+			writeDiffLine(w, "-", old[i])
+			i++
+		default:
+			writeDiffLine(w, "+", changed[j])
+			j++
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+var formatExtensions = []string{".clj", ".cljs", ".cljc", ".joke", ".edn"}
+
+func isFormattable(path string) bool {
+	for _, ext := range formatExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFile formats the code in filename and rewrites the file
+// if its formatting changes. When checking, the name of the file
+// is printed instead. Returns false if the file can't be formatted
+// or (when checking) isn't formatted.
+func formatFile(rt *Runtime, filename string, workingDir string, check bool) bool {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+		return false
+	}
+	ReadConfig(rt, filename, workingDir)
+	text := string(b)
+	formatted, err := Format(NewReader(strings.NewReader(text), filename))
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return false
+	}
+	if formatted == text {
+		return true
+	}
+	if check {
+		fmt.Fprintln(Stdout, filename)
+		return false
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode()
+	}
+	if err := ioutil.WriteFile(filename, []byte(formatted), mode); err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+		return false
+	}
+	return true
+}
+
+// formatStdin writes the code read from stdin formatted to stdout,
+// or only checks that it is formatted.
+func formatStdin(rt *Runtime, workingDir string, check bool) bool {
+	b, err := ioutil.ReadAll(bufio.NewReader(os.Stdin))
+	if err != nil {
+		fmt.Fprintln(Stderr, "Error: ", err)
+		return false
+	}
+	ReadConfig(rt, "-", workingDir)
+	text := string(b)
+	formatted, err := Format(NewReader(strings.NewReader(text), "<stdin>"))
+	if err != nil {
+		fmt.Fprintln(Stderr, err)
+		return false
+	}
+	if check {
+		return formatted == text
+	}
+	fmt.Fprint(Stdout, formatted)
+	return true
+}
+
+// formatPaths formats files and directories (recursively) in paths.
+// Returns false if any of the files can't be formatted or
+// (when checking) isn't formatted.
+func formatPaths(rt *Runtime, paths []string, workingDir string, check bool) bool {
+	ok := true
+	for _, path := range paths {
+		if path == "-" {
+			ok = formatStdin(rt, workingDir, check) && ok
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintln(Stderr, "Error: ", err)
+			ok = false
+			continue
+		}
+		if !info.IsDir() {
+			ok = formatFile(rt, path, workingDir, check) && ok
+			continue
+		}
+		filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				fmt.Fprintln(Stderr, "Error: ", err)
+				ok = false
+				return nil
+			}
+			if !info.IsDir() && isFormattable(path) {
+				ok = formatFile(rt, path, workingDir, check) && ok
+			}
+			return nil
+		})
+	}
+	return ok
+}
@@ -1,12 +1,29 @@
 module github.com/candid82/joker
 
-go 1.14
+go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/candid82/liner v1.4.0
+	github.com/go-sql-driver/mysql v1.8.1
 	github.com/jcburley/go-spew v1.3.0
+	github.com/lib/pq v1.10.9
 	github.com/pkg/profile v1.2.1
 	go.etcd.io/bbolt v1.3.3
-	golang.org/x/sys v0.0.0-20200302150141-5c8b2ff67527 // indirect
 	gopkg.in/yaml.v2 v2.2.2
+	modernc.org/sqlite v1.34.5
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-runewidth v0.0.3 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	// lintReport collects the problems found by the linter and
+	// writes them out in one of the supported formats.
+	lintReport struct {
+		format   string
+		problems []*Problem
+		counts   map[string]int // number of problems per rule
+		errors   int
+		fixed    int
+	}
+
+	lintJSONProblem struct {
+		File      string `json:"file"`
+		Line      int    `json:"line"`
+		Column    int    `json:"column"`
+		EndLine   int    `json:"endLine"`
+		EndColumn int    `json:"endColumn"`
+		Severity  string `json:"severity"`
+		Rule      string `json:"rule"`
+		Message   string `json:"message"`
+	}
+
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+
+	checkstyleReport struct {
+		XMLName xml.Name          `xml:"checkstyle"`
+		Version string            `xml:"version,attr"`
+		Files   []*checkstyleFile `xml:"file"`
+	}
+)
+
+var lintFormats = []string{"text", "json", "sarif", "checkstyle"}
+
+var lintFailLevels = []string{"warning", "error", "none"}
+
+func newLintReport(format string) *lintReport {
+	return &lintReport{
+		format: format,
+		counts: map[string]int{},
+	}
+}
+
+func (r *lintReport) add(p *Problem) {
+	r.problems = append(r.problems, p)
+	r.counts[p.Rule]++
+	if p.IsError() {
+		r.errors++
+	}
+	if r.format == "text" {
+		fmt.Fprintln(Stderr, p.String())
+	}
+}
+
+// fails tells whether linting should exit with non-zero code,
+// given the least severe kind of problem that fails it.
+func (r *lintReport) fails(level string) bool {
+	switch level {
+	case "none":
+		return false
+	case "error":
+		return r.errors > 0
+	}
+	return PROBLEM_COUNT > r.fixed
+}
+
+func severity(p *Problem) string {
+	if p.IsError() {
+		return "error"
+	}
+	return "warning"
+}
+
+// endPosition returns the (inclusive) end of p, which is
+// the same as its start if the end is unknown.
+func endPosition(p *Problem) (line int, column int) {
+	if p.Pos.EndLine() == 0 {
+		return p.Pos.StartLine(), p.Pos.StartColumn()
+	}
+	return p.Pos.EndLine(), p.Pos.EndColumn()
+}
+
+func (r *lintReport) write(w io.Writer) {
+	switch r.format {
+	case "json":
+		r.writeJSON(w)
+	case "sarif":
+		r.writeSARIF(w)
+	case "checkstyle":
+		r.writeCheckstyle(w)
+	}
+}
+
+func (r *lintReport) writeJSON(w io.Writer) {
+	problems := []lintJSONProblem{}
+	for _, p := range r.problems {
+		endLine, endColumn := endPosition(p)
+		problems = append(problems, lintJSONProblem{
+			File:      p.Pos.Filename(),
+			Line:      p.Pos.StartLine(),
+			Column:    p.Pos.StartColumn(),
+			EndLine:   endLine,
+			EndColumn: endColumn,
+			Severity:  severity(p),
+			Rule:      p.Rule,
+			Message:   p.Message,
+		})
+	}
+	writeIndentedJSON(w, map[string]interface{}{
+		"problems": problems,
+		"counts":   r.counts,
+	})
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func artifactURI(filename string) string {
+	if filepath.IsAbs(filename) {
+		return "file://" + filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(filename)
+}
+
+func (r *lintReport) writeSARIF(w io.Writer) {
+	var rules []string
+	for rule := range r.counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	ruleIndex := map[string]int{}
+	sarifRules := []interface{}{}
+	for i, rule := range rules {
+		ruleIndex[rule] = i
+		sarifRules = append(sarifRules, map[string]interface{}{"id": rule})
+	}
+	results := []interface{}{}
+	for _, p := range r.problems {
+		endLine, endColumn := endPosition(p)
+		region := map[string]interface{}{
+			"startLine":   p.Pos.StartLine(),
+			"startColumn": p.Pos.StartColumn(),
+			"endLine":     endLine,
+			"endColumn":   endColumn + 1, // SARIF end column is exclusive
+		}
+		if p.Pos.StartLine() < 1 {
+			// SARIF regions must start at line 1 or later.
+			region = map[string]interface{}{"startLine": 1}
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId":    p.Rule,
+			"ruleIndex": ruleIndex[p.Rule],
+			"level":     severity(p),
+			"message":   map[string]interface{}{"text": p.Message},
+			"locations": []interface{}{
+				map[string]interface{}{
+					"physicalLocation": map[string]interface{}{
+						"artifactLocation": map[string]interface{}{"uri": artifactURI(p.Pos.Filename())},
+						"region":           region,
+					},
+				},
+			},
+		})
+	}
+	writeIndentedJSON(w, map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "joker",
+						"version":        VERSION,
+						"informationUri": "https://github.com/candid82/joker",
+						"rules":          sarifRules,
+					},
+				},
+				"results": results,
+				"properties": map[string]interface{}{
+					"counts": r.counts,
+				},
+			},
+		},
+	})
+}
+
+func (r *lintReport) writeCheckstyle(w io.Writer) {
+	report := checkstyleReport{Version: "4.3"}
+	files := map[string]*checkstyleFile{}
+	for _, p := range r.problems {
+		name := p.Pos.Filename()
+		f := files[name]
+		if f == nil {
+			f = &checkstyleFile{Name: name}
+			files[name] = f
+			report.Files = append(report.Files, f)
+		}
+		f.Errors = append(f.Errors, checkstyleError{
+			Line:     p.Pos.StartLine(),
+			Column:   p.Pos.StartColumn(),
+			Severity: severity(p),
+			Message:  p.Message,
+			Source:   "joker." + p.Rule,
+		})
+	}
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintln(w, xml.Header+string(out))
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	lspMsg map[string]interface{}
+
+	lspRequest struct {
+		ID     *json.RawMessage `json:"id"`
+		Method string           `json:"method"`
+		Params json.RawMessage  `json:"params"`
+	}
+
+	lspError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	lspPosition struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+
+	lspTextDocumentPosition struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Position lspPosition `json:"position"`
+		Context  struct {
+			IncludeDeclaration bool `json:"includeDeclaration"`
+		} `json:"context"`
+	}
+
+	lspDocument struct {
+		path string
+		text string
+		ns   *Namespace // namespace the document was in at the end of linting
+	}
+
+	lspServer struct {
+		rt         *Runtime // runtime documents are linted in
+		in         *bufio.Reader
+		out        io.Writer
+		dialect    Dialect
+		root       string
+		configured bool
+		shutdown   bool
+		docs       map[string]*lspDocument // open documents by path
+		// Var references found in every linted file,
+		// by filename and then by qualified var name.
+		refs map[string]map[string][]Position
+	}
+)
+
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInternalError  = -32603
+
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+
+	lspSymbolFunction = 12
+	lspSymbolVariable = 13
+
+	lspCompletionFunction = 3
+	lspCompletionVariable = 6
+	lspCompletionModule   = 9
+)
+
+// lsp runs a Language Server Protocol server on stdin and stdout.
+// Documents are linted as if by --lint, with dialect either given
+// explicitly or inferred from the first opened document.
+func lsp(rt *Runtime, dialect Dialect, workingDir string) {
+	// Stdout carries the protocol, so anything else goes to stderr.
+	Stdout = Stderr
+	stdin, _, stderr := GLOBAL_ENV.StdIO(rt)
+	GLOBAL_ENV.SetStdIO(stdin, stderr, stderr)
+
+	s := &lspServer{
+		rt:      rt,
+		in:      bufio.NewReader(os.Stdin),
+		out:     os.Stdout,
+		dialect: dialect,
+		root:    workingDir,
+		docs:    map[string]*lspDocument{},
+		refs:    map[string]map[string][]Position{},
+	}
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(Stderr, "Error reading LSP message: %s\n", err.Error())
+			ExitJoker(1)
+		}
+		var req lspRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.send(lspMsg{"id": nil, "error": lspError{lspParseError, err.Error()}})
+			continue
+		}
+		s.handle(&req)
+	}
+}
+
+func (s *lspServer) read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if i := strings.Index(line, ":"); i > 0 && strings.EqualFold(line[:i], "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(s.in, body)
+	return body, err
+}
+
+func (s *lspServer) send(msg lspMsg) {
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *lspServer) reply(req *lspRequest, result interface{}) {
+	s.send(lspMsg{"id": req.ID, "result": result})
+}
+
+func (s *lspServer) notify(method string, params interface{}) {
+	s.send(lspMsg{"method": method, "params": params})
+}
+
+func (s *lspServer) handle(req *lspRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			ProblemHandler = nil
+			VarRefHandler = nil
+			if req.ID != nil {
+				s.send(lspMsg{"id": req.ID, "error": lspError{lspInternalError, fmt.Sprint(r)}})
+			}
+		}
+	}()
+	switch req.Method {
+	case "initialize":
+		s.initialize(req)
+	case "shutdown":
+		s.shutdown = true
+		s.reply(req, nil)
+	case "exit":
+		if s.shutdown {
+			ExitJoker(0)
+		}
+		ExitJoker(1)
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		json.Unmarshal(req.Params, &params)
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.close(params.TextDocument.URI)
+	case "textDocument/definition":
+		s.reply(req, s.definition(s.positionParams(req)))
+	case "textDocument/references":
+		s.reply(req, s.references(s.positionParams(req)))
+	case "textDocument/hover":
+		s.reply(req, s.hover(s.positionParams(req)))
+	case "textDocument/completion":
+		s.reply(req, s.completion(s.positionParams(req)))
+	case "textDocument/documentSymbol":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.reply(req, s.documentSymbols(uriToPath(params.TextDocument.URI)))
+	default:
+		// Notifications that aren't supported are silently ignored.
+		if req.ID != nil {
+			s.send(lspMsg{"id": req.ID, "error": lspError{lspMethodNotFound, "Unsupported method: " + req.Method}})
+		}
+	}
+}
+
+func (s *lspServer) initialize(req *lspRequest) {
+	var params struct {
+		RootURI  string `json:"rootUri"`
+		RootPath string `json:"rootPath"`
+	}
+	json.Unmarshal(req.Params, &params)
+	if params.RootURI != "" {
+		s.root = uriToPath(params.RootURI)
+	} else if params.RootPath != "" {
+		s.root = params.RootPath
+	}
+	s.reply(req, lspMsg{
+		"capabilities": lspMsg{
+			"textDocumentSync": lspMsg{
+				"openClose": true,
+				"change":    1, // full document text
+			},
+			"definitionProvider":     true,
+			"referencesProvider":     true,
+			"hoverProvider":          true,
+			"documentSymbolProvider": true,
+			"completionProvider": lspMsg{
+				"triggerCharacters": []string{"/"},
+			},
+		},
+		"serverInfo": lspMsg{
+			"name":    "joker",
+			"version": VERSION,
+		},
+	})
+}
+
+// configure sets up linter mode on the first opened document and
+// then lints the whole workspace, so that vars defined in other files
+// can be found.
+func (s *lspServer) configure(path string) bool {
+	if s.configured {
+		return true
+	}
+	dialect := s.dialect
+	if dialect == UNKNOWN {
+		dialect = detectDialect(path)
+		if dialect == EDN {
+			// Wait for a source file to pick the dialect.
+			return false
+		}
+	}
+	s.dialect = dialect
+	s.configured = true
+	ReadConfig(s.rt, path, s.root)
+	configureLinterMode(s.rt, dialect, path, s.root)
+	if s.root != "" {
+		s.index()
+	}
+	return true
+}
+
+func (s *lspServer) index() {
+	filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != s.root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if s.isLinted(path) {
+			if text, err := ioutil.ReadFile(path); err == nil {
+				s.lint(path, string(text))
+			}
+		}
+		return nil
+	})
+}
+
+func isSourceFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".clj", ".cljc", ".cljs", ".joke", ".edn":
+		return true
+	}
+	return false
+}
+
+func (s *lspServer) isLinted(path string) bool {
+	return isSourceFile(path) && detectDialect(path) == s.dialect && !isIgnored(path)
+}
+
+func (s *lspServer) update(uri string, text string) {
+	path := uriToPath(uri)
+	doc := &lspDocument{path: path, text: text}
+	s.docs[path] = doc
+	diagnostics := []interface{}{}
+	if s.configure(path) && s.isLinted(path) {
+		diagnostics, doc.ns = s.lint(path, text)
+	}
+	s.notify("textDocument/publishDiagnostics", lspMsg{"uri": uri, "diagnostics": diagnostics})
+}
+
+func (s *lspServer) close(uri string) {
+	path := uriToPath(uri)
+	delete(s.docs, path)
+	// Forget unsaved changes.
+	if s.configured && s.isLinted(path) {
+		if text, err := ioutil.ReadFile(path); err == nil {
+			s.lint(path, string(text))
+		}
+	}
+	s.notify("textDocument/publishDiagnostics", lspMsg{"uri": uri, "diagnostics": []interface{}{}})
+}
+
+// lint lints text as the content of the file at path, replacing
+// whatever was known about that file before.
+func (s *lspServer) lint(path string, text string) (diagnostics []interface{}, ns *Namespace) {
+	for _, n := range GLOBAL_ENV.AllNamespaces() {
+		if n != GLOBAL_ENV.CoreNamespace {
+			n.UnmapDefinedIn(path)
+		}
+	}
+	ResetFileLinterSettings(path)
+	diagnostics = []interface{}{}
+	refs := map[string][]Position{}
+	ProblemHandler = func(p *Problem) {
+		if p.Pos.Filename() == path {
+			diagnostics = append(diagnostics, lspDiagnostic(p))
+		}
+	}
+	VarRefHandler = func(vr *Var, pos Position) {
+		if pos.Filename() == path {
+			refs[vr.Name()] = append(refs[vr.Name()], pos)
+		}
+	}
+	defer func() {
+		ProblemHandler = nil
+		VarRefHandler = nil
+	}()
+
+	currentNs := GLOBAL_ENV.CurrentNamespace(s.rt)
+	phase := PARSE
+	if s.dialect == EDN {
+		phase = READ
+	}
+	GLOBAL_ENV.ResetLoadedLibs()
+	if ProcessReader(s.rt, NewReader(strings.NewReader(text), path), path, phase) == nil {
+		WarnOnUnusedNamespaces(s.rt)
+		WarnOnUnusedVars()
+	}
+	ns = GLOBAL_ENV.CurrentNamespace(s.rt)
+	ResetUsage()
+	GLOBAL_ENV.SetCurrentNamespace(s.rt, currentNs)
+	s.refs[path] = refs
+	return diagnostics, ns
+}
+
+func lspDiagnostic(p *Problem) lspMsg {
+	severity := lspSeverityWarning
+	if p.IsError() {
+		severity = lspSeverityError
+	}
+	return lspMsg{
+		"range":    lspRange(p.Pos),
+		"severity": severity,
+		"source":   "joker",
+		"code":     p.Rule,
+		"message":  p.Message,
+	}
+}
+
+// lspRange converts Joker's 1-based positions (with inclusive end
+// column) to an LSP range.
+func lspRange(pos Position) lspMsg {
+	start := lspPosition{Line: pos.StartLine() - 1, Character: pos.StartColumn() - 1}
+	if start.Line < 0 || start.Character < 0 {
+		start = lspPosition{}
+	}
+	end := start
+	if pos.EndLine() > 0 {
+		end = lspPosition{Line: pos.EndLine() - 1, Character: pos.EndColumn()}
+	}
+	return lspMsg{"start": start, "end": end}
+}
+
+func lspLocation(pos Position) lspMsg {
+	return lspMsg{"uri": pathToURI(pos.Filename()), "range": lspRange(pos)}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+func (s *lspServer) positionParams(req *lspRequest) *lspTextDocumentPosition {
+	var params lspTextDocumentPosition
+	json.Unmarshal(req.Params, &params)
+	return &params
+}
+
+func isSymbolRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune("()[]{}\"',;`~@^\\", r)
+}
+
+// symbolAt returns the symbol around the position p in doc,
+// and the part of it that precedes p.
+func (doc *lspDocument) symbolAt(p lspPosition) (sym string, prefix string) {
+	lines := strings.Split(doc.text, "\n")
+	if p.Line < 0 || p.Line >= len(lines) {
+		return "", ""
+	}
+	line := []rune(lines[p.Line])
+	pos := p.Character
+	if pos > len(line) {
+		pos = len(line)
+	}
+	start, end := pos, pos
+	for start > 0 && isSymbolRune(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isSymbolRune(line[end]) {
+		end++
+	}
+	for start < pos && line[start] == '#' {
+		start++
+	}
+	if start < end && line[start] == ':' {
+		return "", ""
+	}
+	return string(line[start:end]), string(line[start:pos])
+}
+
+func (s *lspServer) documentNs(doc *lspDocument) *Namespace {
+	if doc.ns != nil {
+		return doc.ns
+	}
+	return GLOBAL_ENV.CurrentNamespace(s.rt)
+}
+
+func (s *lspServer) varAt(params *lspTextDocumentPosition) *Var {
+	doc := s.docs[uriToPath(params.TextDocument.URI)]
+	if doc == nil {
+		return nil
+	}
+	sym, _ := doc.symbolAt(params.Position)
+	return resolveVar(sym, s.documentNs(doc))
+}
+
+// definitionPos returns the position of vr's def form,
+// if it was read from a file.
+func definitionPos(vr *Var) (Position, bool) {
+	info := vr.GetInfo()
+	if info == nil || !filepath.IsAbs(info.Filename()) {
+		return Position{}, false
+	}
+	return info.Pos(), true
+}
+
+func (s *lspServer) definition(params *lspTextDocumentPosition) interface{} {
+	if vr := s.varAt(params); vr != nil {
+		if pos, ok := definitionPos(vr); ok {
+			return lspLocation(pos)
+		}
+	}
+	return nil
+}
+
+func (s *lspServer) references(params *lspTextDocumentPosition) interface{} {
+	res := []interface{}{}
+	vr := s.varAt(params)
+	if vr == nil {
+		return res
+	}
+	if params.Context.IncludeDeclaration {
+		if pos, ok := definitionPos(vr); ok {
+			res = append(res, lspLocation(pos))
+		}
+	}
+	var files []string
+	for f := range s.refs {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		for _, pos := range s.refs[f][vr.Name()] {
+			res = append(res, lspLocation(pos))
+		}
+	}
+	return res
+}
+
+func (s *lspServer) hover(params *lspTextDocumentPosition) interface{} {
+	vr := s.varAt(params)
+	if vr == nil {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("```clojure\n" + vr.Name() + "\n")
+	if m := vr.GetMeta(); m != nil {
+		if ok, arglists := m.Get(MakeKeyword("arglists")); ok {
+			if arglists, ok := arglists.(Seqable); ok {
+				for s := arglists.Seq(); !s.IsEmpty(); s = s.Rest() {
+					b.WriteString(s.First().ToString(true) + "\n")
+				}
+			}
+		}
+		b.WriteString("```\n")
+		if doc, ok := metaString(m, "doc"); ok {
+			b.WriteString("\n" + doc + "\n")
+		}
+	} else {
+		b.WriteString("```\n")
+	}
+	return lspMsg{"contents": lspMsg{"kind": "markdown", "value": b.String()}}
+}
+
+func (s *lspServer) completion(params *lspTextDocumentPosition) interface{} {
+	items := []interface{}{}
+	doc := s.docs[uriToPath(params.TextDocument.URI)]
+	if doc == nil {
+		return items
+	}
+	_, prefix := doc.symbolAt(params.Position)
+	for _, c := range completions(s.rt, prefix, s.documentNs(doc)) {
+		kind := lspCompletionVariable
+		switch c.typ {
+		case "function", "macro":
+			kind = lspCompletionFunction
+		case "namespace":
+			kind = lspCompletionModule
+		}
+		items = append(items, lspMsg{"label": c.candidate, "kind": kind, "detail": c.typ})
+	}
+	return items
+}
+
+func (s *lspServer) documentSymbols(path string) interface{} {
+	var vars []*Var
+	for _, ns := range GLOBAL_ENV.AllNamespaces() {
+		for _, vr := range ns.Mappings() {
+			if pos, ok := definitionPos(vr); ok && vr.Namespace() == ns && pos.Filename() == path {
+				vars = append(vars, vr)
+			}
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		pi, pj := vars[i].GetInfo(), vars[j].GetInfo()
+		if pi.StartLine() != pj.StartLine() {
+			return pi.StartLine() < pj.StartLine()
+		}
+		return pi.StartColumn() < pj.StartColumn()
+	})
+	res := []interface{}{}
+	for _, vr := range vars {
+		kind := lspSymbolVariable
+		if varType(s.rt, vr) != "var" {
+			kind = lspSymbolFunction
+		}
+		ns, name := splitVarName(vr)
+		res = append(res, lspMsg{
+			"name":          name,
+			"kind":          kind,
+			"location":      lspLocation(vr.GetInfo().Pos()),
+			"containerName": ns,
+		})
+	}
+	return res
+}
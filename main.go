@@ -14,10 +14,14 @@ import (
 	"strings"
 
 	. "github.com/candid82/joker/core"
+	_ "github.com/candid82/joker/std/archive"
 	_ "github.com/candid82/joker/std/base64"
 	_ "github.com/candid82/joker/std/bolt"
+	_ "github.com/candid82/joker/std/compress"
 	_ "github.com/candid82/joker/std/crypto"
 	_ "github.com/candid82/joker/std/csv"
+	_ "github.com/candid82/joker/std/debug"
+	_ "github.com/candid82/joker/std/edn"
 	_ "github.com/candid82/joker/std/filepath"
 	_ "github.com/candid82/joker/std/hex"
 	_ "github.com/candid82/joker/std/html"
@@ -25,12 +29,17 @@ import (
 	_ "github.com/candid82/joker/std/io"
 	_ "github.com/candid82/joker/std/json"
 	_ "github.com/candid82/joker/std/math"
+	_ "github.com/candid82/joker/std/net"
 	_ "github.com/candid82/joker/std/os"
+	_ "github.com/candid82/joker/std/sql"
 	_ "github.com/candid82/joker/std/strconv"
 	_ "github.com/candid82/joker/std/string"
 	_ "github.com/candid82/joker/std/time"
+	_ "github.com/candid82/joker/std/toml"
 	_ "github.com/candid82/joker/std/url"
 	_ "github.com/candid82/joker/std/uuid"
+	_ "github.com/candid82/joker/std/websocket"
+	_ "github.com/candid82/joker/std/xml"
 	_ "github.com/candid82/joker/std/yaml"
 	"github.com/pkg/profile"
 )
@@ -63,11 +72,11 @@ type (
 	}
 )
 
-func NewReplContext(env *Env) *ReplContext {
-	first, _ := env.Resolve(MakeSymbol("joker.core/*1"))
-	second, _ := env.Resolve(MakeSymbol("joker.core/*2"))
-	third, _ := env.Resolve(MakeSymbol("joker.core/*3"))
-	exc, _ := env.Resolve(MakeSymbol("joker.core/*e"))
+func NewReplContext(rt *Runtime, env *Env) *ReplContext {
+	first, _ := env.Resolve(rt, MakeSymbol("joker.core/*1"))
+	second, _ := env.Resolve(rt, MakeSymbol("joker.core/*2"))
+	third, _ := env.Resolve(rt, MakeSymbol("joker.core/*3"))
+	exc, _ := env.Resolve(rt, MakeSymbol("joker.core/*e"))
 	first.Value = NIL
 	second.Value = NIL
 	third.Value = NIL
@@ -80,17 +89,22 @@ func NewReplContext(env *Env) *ReplContext {
 	}
 }
 
-func (ctx *ReplContext) PushValue(obj Object) {
-	ctx.third.Value = ctx.second.Value
-	ctx.second.Value = ctx.first.Value
-	ctx.first.Value = obj
+func (ctx *ReplContext) PushValue(rt *Runtime, obj Object) {
+	ctx.third.Set(rt, ctx.second.Resolve(rt))
+	ctx.second.Set(rt, ctx.first.Resolve(rt))
+	ctx.first.Set(rt, obj)
 }
 
-func (ctx *ReplContext) PushException(exc Object) {
-	ctx.exc.Value = exc
+func (ctx *ReplContext) PushException(rt *Runtime, exc Object) {
+	ctx.exc.Set(rt, exc)
 }
 
-func processFile(filename string, phase Phase) error {
+// Vars returns the vars ctx updates, which are *1, *2, *3 and *e.
+func (ctx *ReplContext) Vars() []*Var {
+	return []*Var{ctx.first, ctx.second, ctx.third, ctx.exc}
+}
+
+func processFile(rt *Runtime, filename string, phase Phase) error {
 	var reader *Reader
 	if filename == "-" {
 		reader = NewReader(bufio.NewReader(Stdin), "<stdin>")
@@ -110,7 +124,7 @@ func processFile(filename string, phase Phase) error {
 	if saveForRepl {
 		reader = NewReader(&replayable{reader}, "<replay>")
 	}
-	return ProcessReader(reader, filename, phase)
+	return ProcessReader(rt, reader, filename, phase)
 }
 
 func skipRestOfLine(reader *Reader) {
@@ -122,19 +136,21 @@ func skipRestOfLine(reader *Reader) {
 	}
 }
 
-func processReplCommand(reader *Reader, phase Phase, parseContext *ParseContext, replContext *ReplContext) (exit bool) {
+func processReplCommand(rt *Runtime, reader *Reader, phase Phase, parseContext *ParseContext, replContext *ReplContext) (exit bool) {
 
+	saved := rt.Save()
 	defer func() {
 		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
 			switch r := r.(type) {
 			case *ParseError:
-				replContext.PushException(r)
+				replContext.PushException(rt, r)
 				fmt.Fprintln(Stderr, r)
 			case *EvalError:
-				replContext.PushException(r)
+				replContext.PushException(rt, r)
 				fmt.Fprintln(Stderr, r)
 			case Error:
-				replContext.PushException(r)
+				replContext.PushException(rt, r)
 				fmt.Fprintln(Stderr, r)
 				// case *runtime.TypeAssertionError:
 				// 	fmt.Fprintln(Stderr, r)
@@ -144,7 +160,7 @@ func processReplCommand(reader *Reader, phase Phase, parseContext *ParseContext,
 		}
 	}()
 
-	obj, err := TryRead(reader)
+	obj, err := TryRead(rt, reader)
 	if err == io.EOF {
 		return true
 	}
@@ -165,14 +181,14 @@ func processReplCommand(reader *Reader, phase Phase, parseContext *ParseContext,
 		return false
 	}
 
-	res := Eval(expr, nil)
-	replContext.PushValue(res)
-	PrintObject(res, Stdout)
+	res := rt.Eval(expr, nil)
+	replContext.PushValue(rt, res)
+	PrintObject(rt, res, Stdout)
 	fmt.Fprintln(Stdout, "")
 	return false
 }
 
-func srepl(port string, phase Phase) {
+func srepl(rt *Runtime, port string, phase Phase) {
 	ProcessReplData()
 	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.FindNamespace(MakeSymbol("joker.repl")))
 	l, err := net.Listen("tcp", replSocket)
@@ -194,7 +210,7 @@ func srepl(port string, phase Phase) {
 	oldStdIn := Stdin
 	oldStdOut := Stdout
 	oldStdErr := Stderr
-	oldStdinValue, oldStdoutValue, oldStderrValue := GLOBAL_ENV.StdIO()
+	oldStdinValue, oldStdoutValue, oldStderrValue := GLOBAL_ENV.StdIO(rt)
 	Stdin = conn
 	Stdout = conn
 	Stderr = conn
@@ -215,8 +231,8 @@ func srepl(port string, phase Phase) {
 
 	/* The rest of this code comes from repl(), below: */
 
-	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
-	replContext := NewReplContext(parseContext.GlobalEnv)
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
+	replContext := NewReplContext(rt, parseContext.GlobalEnv)
 
 	reader := NewReader(runeReader, "<srepl>")
 
@@ -224,8 +240,8 @@ func srepl(port string, phase Phase) {
 		VERSION, conn.RemoteAddr())
 
 	for {
-		fmt.Fprint(Stdout, GLOBAL_ENV.CurrentNamespace().Name.ToString(false)+"=> ")
-		if processReplCommand(reader, phase, parseContext, replContext) {
+		fmt.Fprint(Stdout, GLOBAL_ENV.CurrentNamespace(rt).Name.ToString(false)+"=> ")
+		if processReplCommand(rt, reader, phase, parseContext, replContext) {
 			return
 		}
 	}
@@ -244,13 +260,13 @@ func makeDialectKeyword(dialect Dialect) Keyword {
 	}
 }
 
-func configureLinterMode(dialect Dialect, filename string, workingDir string) {
-	ProcessLinterFiles(dialect, filename, workingDir)
+func configureLinterMode(rt *Runtime, dialect Dialect, filename string, workingDir string) {
+	ProcessLinterFiles(rt, dialect, filename, workingDir)
 	ProcessLinterData(dialect)
 	GLOBAL_ENV.CoreNamespace.Resolve("*loaded-libs*").Value = EmptySet()
 	LINTER_MODE = true
 	DIALECT = dialect
-	lm, _ := GLOBAL_ENV.Resolve(MakeSymbol("joker.core/*linter-mode*"))
+	lm, _ := GLOBAL_ENV.Resolve(rt, MakeSymbol("joker.core/*linter-mode*"))
 	lm.Value = Boolean{B: true}
 	GLOBAL_ENV.Features = GLOBAL_ENV.Features.Disjoin(MakeKeyword("joker")).Conj(makeDialectKeyword(dialect)).(Set)
 }
@@ -267,17 +283,20 @@ func detectDialect(filename string) Dialect {
 	return CLJ
 }
 
-func lintFile(filename string, dialect Dialect, workingDir string) {
+func lintFile(rt *Runtime, filename string, dialect Dialect, workingDir string) {
 	phase := PARSE
 	if dialect == EDN {
 		phase = READ
 	}
-	ReadConfig(filename, workingDir)
-	configureLinterMode(dialect, filename, workingDir)
-	if processFile(filename, phase) == nil {
-		WarnOnUnusedNamespaces()
+	ReadConfig(rt, filename, workingDir)
+	configureLinterMode(rt, dialect, filename, workingDir)
+	if processFile(rt, filename, phase) == nil {
+		WarnOnUnusedNamespaces(rt)
 		WarnOnUnusedVars()
 	}
+	if lintFixer != nil {
+		lintFixer.fix(filename)
+	}
 }
 
 func matchesDialect(path string, dialect Dialect) bool {
@@ -305,15 +324,15 @@ func isIgnored(path string) bool {
 	return false
 }
 
-func lintDir(dirname string, dialect Dialect, reportGloballyUnused bool) {
+func lintDir(rt *Runtime, dirname string, dialect Dialect, reportGloballyUnused bool) {
 	var processErr error
 	phase := PARSE
 	if dialect == EDN {
 		phase = READ
 	}
-	ns := GLOBAL_ENV.CurrentNamespace()
-	ReadConfig("", dirname)
-	configureLinterMode(dialect, "", dirname)
+	ns := GLOBAL_ENV.CurrentNamespace(rt)
+	ReadConfig(rt, "", dirname)
+	configureLinterMode(rt, dialect, "", dirname)
 	filepath.Walk(dirname, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			fmt.Fprintln(Stderr, "Error: ", err)
@@ -321,13 +340,16 @@ func lintDir(dirname string, dialect Dialect, reportGloballyUnused bool) {
 		}
 		if !info.IsDir() && matchesDialect(path, dialect) && !isIgnored(path) {
 			GLOBAL_ENV.CoreNamespace.Resolve("*loaded-libs*").Value = EmptySet()
-			processErr = processFile(path, phase)
+			processErr = processFile(rt, path, phase)
 			if processErr == nil {
-				WarnOnUnusedNamespaces()
+				WarnOnUnusedNamespaces(rt)
 				WarnOnUnusedVars()
 			}
+			if lintFixer != nil {
+				lintFixer.fix(path)
+			}
 			ResetUsage()
-			GLOBAL_ENV.SetCurrentNamespace(ns)
+			GLOBAL_ENV.SetCurrentNamespace(rt, ns)
 		}
 		return nil
 	})
@@ -356,10 +378,18 @@ func usage(out io.Writer) {
 	fmt.Fprintln(out, "Usage: joker [args] [-- <repl-args>]                starts a repl")
 	fmt.Fprintln(out, "   or: joker [args] --repl [<socket>] [-- <repl-args>]")
 	fmt.Fprintln(out, "                                                    starts a repl (on optional network socket)")
+	fmt.Fprintln(out, "   or: joker [args] --nrepl <port>                  starts an nREPL server")
 	fmt.Fprintln(out, "   or: joker [args] --eval <expr> [-- <expr-args>]  evaluate <expr>, print if non-nil")
 	fmt.Fprintln(out, "   or: joker [args] [--file] <filename> [<script-args>]")
 	fmt.Fprintln(out, "                                                    input from file")
 	fmt.Fprintln(out, "   or: joker [args] --lint <filename>               lint the code in file")
+	fmt.Fprintln(out, "   or: joker [args] --lsp                           starts a Language Server Protocol server on stdio")
+	fmt.Fprintln(out, "   or: joker [args] --format <path>...              format the code in files and directories")
+	fmt.Fprintln(out, "   or: joker [args] --dap                           starts a Debug Adapter Protocol server on stdio")
+	fmt.Fprintln(out, "   or: joker [args] --compile <ns-or-dir>... [-o <dir>]")
+	fmt.Fprintln(out, "                                                    compile namespaces into packed .jokec files")
+	fmt.Fprintln(out, "   or: joker [args] --bundle <filename> -o <executable>")
+	fmt.Fprintln(out, "                                                    build an executable running the script in file")
 	fmt.Fprintln(out, "\nNotes:")
 	fmt.Fprintln(out, "  -e is a synonym for --eval.")
 	fmt.Fprintln(out, "  '-' for <filename> means read from standard input (stdin).")
@@ -367,6 +397,20 @@ func usage(out io.Writer) {
 	fmt.Fprintln(out, "    in <repl-args>, <expr-args>, or <script-args> (TBD).")
 	fmt.Fprintln(out, "  <socket> is passed to Go's net.Listen() function. If multiple --*repl options are specified,")
 	fmt.Fprintln(out, "    the final one specified \"wins\".")
+	fmt.Fprintln(out, "  <port> is a port number (the server listens on localhost) or an address passed to net.Listen().")
+	fmt.Fprintln(out, "    The port the server listens on is written to the .nrepl-port file in the current directory.")
+	fmt.Fprintln(out, "  --lsp lints documents as --lint does, with the dialect set by --dialect or inferred from")
+	fmt.Fprintln(out, "    the first opened document. --working-dir sets the workspace if the client doesn't provide one.")
+	fmt.Fprintln(out, "  --format rewrites files in place; '-' for <path> formats standard input to standard output.")
+	fmt.Fprintln(out, "    Directories are searched for .clj, .cljs, .cljc, .joke and .edn files.")
+	fmt.Fprintln(out, "  --dap debugs the program given by the client's launch request. Its output is sent")
+	fmt.Fprintln(out, "    to the client and its standard input is empty.")
+	fmt.Fprintln(out, "  --compile may be repeated. A directory stands for all the namespaces in its .joke files")
+	fmt.Fprintln(out, "    and is added to the classpath. Namespaces they load are compiled too. Packed files are")
+	fmt.Fprintln(out, "    loaded instead of the sources as long as neither the sources nor the version of Joker change.")
+	fmt.Fprintln(out, "  --bundle evaluates the script, which must define a main function, and writes the Joker runtime")
+	fmt.Fprintln(out, "    with the script and the namespaces it loads appended to <executable>. Running <executable>")
+	fmt.Fprintln(out, "    evaluates the script and calls main with the command line arguments.")
 
 	fmt.Fprintln(out, "\nOptions (<args>):")
 	fmt.Fprintln(out, "  --help, -h")
@@ -383,14 +427,39 @@ func usage(out io.Writer) {
 	fmt.Fprintln(out, "    After successfully processing --eval or --file, drop into repl instead of exiting.")
 	fmt.Fprintln(out, "  --error-to-repl [<socket>]")
 	fmt.Fprintln(out, "    After failure processing --eval or --file, drop into repl instead of exiting.")
+	fmt.Fprintln(out, "  --error-format <format>")
+	fmt.Fprintln(out, "    Set format (\"text\", \"json\") of the error that stops evaluation of --eval or <filename>;")
+	fmt.Fprintln(out, "    default is \"text\". Errors are printed to stderr, one JSON object per line with the \"json\" format.")
+	fmt.Fprintln(out, "  --debug-break <file>:<line>")
+	fmt.Fprintln(out, "    Pause evaluation at the given line and start the debugger, which reads commands")
+	fmt.Fprintln(out, "    from stdin (enter help at the debug> prompt for the list). May be repeated.")
+	fmt.Fprintln(out, "  --output, -o <path>")
+	fmt.Fprintln(out, "    Write the files compiled by --compile to directory <path>, which should then be added to the classpath")
+	fmt.Fprintln(out, "    (by default, they are written next to the source files), or the executable built by --bundle to <path>.")
+	fmt.Fprintln(out, "  --runtime <executable>")
+	fmt.Fprintln(out, "    Build the --bundle executable from the given Joker executable instead of the running one,")
+	fmt.Fprintln(out, "    e.g. one built for another platform with GOOS and GOARCH (requires --bundle).")
 	fmt.Fprintln(out, "  --no-readline")
 	fmt.Fprintln(out, "    Disable readline functionality in the repl. Useful when using rlwrap.")
 	fmt.Fprintln(out, "  --no-repl-history")
 	fmt.Fprintln(out, "    Do not read or save repl command history to a file.")
 	fmt.Fprintln(out, "  --working-dir <directory>")
-	fmt.Fprintln(out, "    Specify directory to lint or working directory for lint configuration if linting single file (requires --lint).")
+	fmt.Fprintln(out, "    Specify directory to lint or working directory for lint configuration if linting or formatting files (requires --lint, --lsp or --format).")
 	fmt.Fprintln(out, "  --report-globally-unused")
 	fmt.Fprintln(out, "    Report globally unused namespaces and public vars when linting directories (requires --lint and --working-dir).")
+	fmt.Fprintln(out, "  --lint-format <format>")
+	fmt.Fprintln(out, "    Set output format (\"text\", \"json\", \"sarif\", \"checkstyle\") of linter problems;")
+	fmt.Fprintln(out, "    default is \"text\", which is printed to stderr, whereas the other formats are printed to stdout.")
+	fmt.Fprintln(out, "  --lint-fail-on <level>")
+	fmt.Fprintln(out, "    Exit with non-zero code when linting finds problems of at least the given severity")
+	fmt.Fprintln(out, "    (\"warning\", \"error\") or never (\"none\"); default is \"warning\".")
+	fmt.Fprintln(out, "  --fix")
+	fmt.Fprintln(out, "    Rewrite linted files in place to fix problems that have mechanical fixes (requires --lint).")
+	fmt.Fprintln(out, "  --fix-dry-run")
+	fmt.Fprintln(out, "    Print the changes --fix would make as a diff to stdout instead of rewriting files (requires --lint).")
+	fmt.Fprintln(out, "  --check")
+	fmt.Fprintln(out, "    Print names of files that are not formatted instead of rewriting them, and exit with")
+	fmt.Fprintln(out, "    non-zero code if there are any (requires --format).")
 	fmt.Fprintln(out, "  --dialect <dialect>")
 	fmt.Fprintln(out, "    Set input dialect (\"clj\", \"cljs\", \"joker\", \"edn\") for linting;")
 	fmt.Fprintln(out, "    default is inferred from <filename> suffix, if any.")
@@ -418,10 +487,26 @@ var (
 	workingDir               string
 	lintFlag                 bool
 	reportGloballyUnusedFlag bool
+	lintFormat               string = "text"
+	lintFailOn               string = "warning"
+	errorFormat              string = "text"
+	fixFlag                  bool
+	fixDryRunFlag            bool
+	lintFixer                *fixer
+	formatFlag               bool
+	formatCheckFlag          bool
 	dialect                  Dialect = UNKNOWN
 	eval                     string
 	replFlag                 bool
 	replSocket               string
+	nreplPort                string
+	lspFlag                  bool
+	dapFlag                  bool
+	debugBreakpoints         []string
+	compileTargets           []string
+	outputPath               string
+	bundleFile               string
+	runtimeFile              string
 	classPath                string
 	filename                 string
 	remainingArgs            []string
@@ -441,6 +526,15 @@ func isNumber(s string) bool {
 	return err == nil
 }
 
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
 func notOption(arg string) bool {
 	return arg == "-" || !strings.HasPrefix(arg, "-") || isNumber(arg[1:])
 }
@@ -533,6 +627,39 @@ func parseArgs(args []string) {
 		case "--lintedn":
 			lintFlag = true
 			dialect = EDN
+		case "--lint-format":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				lintFormat = strings.ToLower(args[i])
+				if !isOneOf(lintFormat, lintFormats) {
+					fmt.Fprintf(Stderr, "Error: Unrecognized lint format '%s' (use one of: %s)\n", args[i], strings.Join(lintFormats, ", "))
+					ExitJoker(24)
+				}
+			} else {
+				missing = true
+			}
+		case "--error-format":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				errorFormat = strings.ToLower(args[i])
+				if !isOneOf(errorFormat, errorFormats) {
+					fmt.Fprintf(Stderr, "Error: Unrecognized error format '%s' (use one of: %s)\n", args[i], strings.Join(errorFormats, ", "))
+					ExitJoker(33)
+				}
+			} else {
+				missing = true
+			}
+		case "--lint-fail-on":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				lintFailOn = strings.ToLower(args[i])
+				if !isOneOf(lintFailOn, lintFailLevels) {
+					fmt.Fprintf(Stderr, "Error: Unrecognized lint failure level '%s' (use one of: %s)\n", args[i], strings.Join(lintFailLevels, ", "))
+					ExitJoker(25)
+				}
+			} else {
+				missing = true
+			}
 		case "--dialect":
 			if i < length-1 && notOption(args[i+1]) {
 				i += 1 // shift
@@ -570,6 +697,56 @@ func parseArgs(args []string) {
 				i += 1 // shift
 				replSocket = args[i]
 			}
+		case "--lsp":
+			lspFlag = true
+		case "--dap":
+			dapFlag = true
+		case "--debug-break":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				if j := strings.LastIndex(args[i], ":"); j <= 0 || !isNumber(args[i][j+1:]) {
+					fmt.Fprintf(Stderr, "Error: Invalid breakpoint '%s' (use <file>:<line>)\n", args[i])
+					ExitJoker(31)
+				}
+				debugBreakpoints = append(debugBreakpoints, args[i])
+			} else {
+				missing = true
+			}
+		case "--compile":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				compileTargets = append(compileTargets, args[i])
+			} else {
+				missing = true
+			}
+		case "--bundle":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				bundleFile = args[i]
+			} else {
+				missing = true
+			}
+		case "--runtime":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				runtimeFile = args[i]
+			} else {
+				missing = true
+			}
+		case "-o", "--output":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				outputPath = args[i]
+			} else {
+				missing = true
+			}
+		case "--nrepl":
+			if i < length-1 && notOption(args[i+1]) {
+				i += 1 // shift
+				nreplPort = args[i]
+			} else {
+				missing = true
+			}
 		case "-c", "--classpath":
 			if i < length-1 && notOption(args[i+1]) {
 				i += 1 // shift
@@ -577,6 +754,14 @@ func parseArgs(args []string) {
 			} else {
 				missing = true
 			}
+		case "--fix":
+			fixFlag = true
+		case "--fix-dry-run":
+			fixDryRunFlag = true
+		case "--format":
+			formatFlag = true
+		case "--check":
+			formatCheckFlag = true
 		case "--no-readline":
 			noReadline = true
 		case "--no-repl-history":
@@ -686,12 +871,16 @@ func main() {
 	OnExit(finish)
 
 	GLOBAL_ENV.InitEnv(Stdin, Stdout, Stderr, os.Args[1:])
+	rt := NewRuntime()
+
+	if runBundle(rt) {
+		return
+	}
 
 	parseArgs(os.Args) // Do this early enough so --verbose can show joker.core being processed.
 
 	saveForRepl = saveForRepl && (exitToRepl || errorToRepl) // don't bother saving stuff if no repl
 
-	RT.GIL.Lock()
 	ProcessCoreData()
 
 	GLOBAL_ENV.ReferCoreToUser()
@@ -706,11 +895,26 @@ func main() {
 		fmt.Fprintf(debugOut, "lintFlag=%v\n", lintFlag)
 		fmt.Fprintf(debugOut, "reportGloballyUnusedFlag=%v\n", reportGloballyUnusedFlag)
 		fmt.Fprintf(debugOut, "dialect=%v\n", dialect)
+		fmt.Fprintf(debugOut, "lintFormat=%v\n", lintFormat)
+		fmt.Fprintf(debugOut, "lintFailOn=%v\n", lintFailOn)
+		fmt.Fprintf(debugOut, "errorFormat=%v\n", errorFormat)
+		fmt.Fprintf(debugOut, "fixFlag=%v\n", fixFlag)
+		fmt.Fprintf(debugOut, "fixDryRunFlag=%v\n", fixDryRunFlag)
+		fmt.Fprintf(debugOut, "formatFlag=%v\n", formatFlag)
+		fmt.Fprintf(debugOut, "formatCheckFlag=%v\n", formatCheckFlag)
 		fmt.Fprintf(debugOut, "workingDir=%v\n", workingDir)
 		fmt.Fprintf(debugOut, "HASHMAP_THRESHOLD=%v\n", HASHMAP_THRESHOLD)
 		fmt.Fprintf(debugOut, "eval=%v\n", eval)
 		fmt.Fprintf(debugOut, "replFlag=%v\n", replFlag)
 		fmt.Fprintf(debugOut, "replSocket=%v\n", replSocket)
+		fmt.Fprintf(debugOut, "nreplPort=%v\n", nreplPort)
+		fmt.Fprintf(debugOut, "lspFlag=%v\n", lspFlag)
+		fmt.Fprintf(debugOut, "dapFlag=%v\n", dapFlag)
+		fmt.Fprintf(debugOut, "debugBreakpoints=%v\n", debugBreakpoints)
+		fmt.Fprintf(debugOut, "compileTargets=%v\n", compileTargets)
+		fmt.Fprintf(debugOut, "outputPath=%v\n", outputPath)
+		fmt.Fprintf(debugOut, "bundleFile=%v\n", bundleFile)
+		fmt.Fprintf(debugOut, "runtimeFile=%v\n", runtimeFile)
 		fmt.Fprintf(debugOut, "classPath=%v\n", classPath)
 		fmt.Fprintf(debugOut, "noReadline=%v\n", noReadline)
 		fmt.Fprintf(debugOut, "noReplHistory=%v\n", noReplHistory)
@@ -731,7 +935,7 @@ func main() {
 		return
 	}
 
-	if len(remainingArgs) > 0 {
+	if len(remainingArgs) > 0 && !formatFlag {
 		if lintFlag {
 			fmt.Fprintf(Stderr, "Error: Cannot provide arguments to code while linting it.\n")
 			ExitJoker(4)
@@ -742,6 +946,34 @@ func main() {
 		}
 	}
 
+	if errorFormat == "json" {
+		ErrorHandler = printErrorJSON
+	}
+
+	for _, b := range debugBreakpoints {
+		i := strings.LastIndex(b, ":")
+		line, _ := strconv.Atoi(b[i+1:])
+		UseTerminalDebugger()
+		SetBreakpoint(b[:i], line)
+	}
+
+	if !lintFlag && !lspFlag && !formatFlag {
+		dir := "."
+		if filename != "" && filename != "-" {
+			dir = filepath.Dir(filename)
+		} else if bundleFile != "" {
+			dir = filepath.Dir(bundleFile)
+		}
+		if err := GLOBAL_ENV.LoadDataReaders(rt, dir); err != nil {
+			if ErrorHandler != nil {
+				ErrorHandler(err)
+			} else {
+				fmt.Fprintln(Stderr, err)
+			}
+			ExitJoker(1)
+		}
+	}
+
 	/* Set up profiling. */
 
 	if cpuProfileName != "" {
@@ -774,6 +1006,51 @@ func main() {
 		defer finish()
 	}
 
+	if dapFlag {
+		if eval != "" || lintFlag || lspFlag || formatFlag || replFlag || nreplPort != "" || filename != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --dap and --eval/-e, --lint, --lsp, --format, --repl, --nrepl or a <filename> argument.\n")
+			ExitJoker(32)
+		}
+		dap(rt)
+		return
+	}
+
+	if outputPath != "" && len(compileTargets) == 0 && bundleFile == "" {
+		fmt.Fprintf(Stderr, "Error: --output/-o requires --compile or --bundle.\n")
+		ExitJoker(35)
+	}
+
+	if runtimeFile != "" && bundleFile == "" {
+		fmt.Fprintf(Stderr, "Error: --runtime requires --bundle.\n")
+		ExitJoker(38)
+	}
+
+	if bundleFile != "" {
+		if eval != "" || lintFlag || lspFlag || formatFlag || replFlag || nreplPort != "" || filename != "" || len(compileTargets) > 0 {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --bundle and --eval/-e, --lint, --lsp, --format, --repl, --nrepl, --compile or a <filename> argument.\n")
+			ExitJoker(36)
+		}
+		if outputPath == "" {
+			fmt.Fprintf(Stderr, "Error: --bundle requires --output/-o.\n")
+			ExitJoker(37)
+		}
+		if !bundle(rt, bundleFile, runtimeFile, outputPath) {
+			ExitJoker(1)
+		}
+		return
+	}
+
+	if len(compileTargets) > 0 {
+		if eval != "" || lintFlag || lspFlag || formatFlag || replFlag || nreplPort != "" || filename != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --compile and --eval/-e, --lint, --lsp, --format, --repl, --nrepl or a <filename> argument.\n")
+			ExitJoker(34)
+		}
+		if !compileLibs(rt, compileTargets, outputPath) {
+			ExitJoker(1)
+		}
+		return
+	}
+
 	if eval != "" {
 		if lintFlag {
 			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --lint.\n")
@@ -783,6 +1060,18 @@ func main() {
 			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --repl.\n")
 			ExitJoker(7)
 		}
+		if nreplPort != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --nrepl.\n")
+			ExitJoker(18)
+		}
+		if lspFlag {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --lsp.\n")
+			ExitJoker(20)
+		}
+		if formatFlag {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --format.\n")
+			ExitJoker(29)
+		}
 		if workingDir != "" {
 			fmt.Fprintf(Stderr, "Error: Cannot combine --eval/-e and --working-dir.\n")
 			ExitJoker(8)
@@ -799,7 +1088,7 @@ func main() {
 		if saveForRepl {
 			reader = NewReader(&replayable{reader}, "<replay>")
 		}
-		if err := ProcessReader(reader, "", phase); err != nil {
+		if err := ProcessReader(rt, reader, "", phase); err != nil {
 			if !errorToRepl {
 				ExitJoker(1)
 			}
@@ -810,11 +1099,44 @@ func main() {
 		}
 	}
 
+	if formatCheckFlag && !formatFlag {
+		fmt.Fprintf(Stderr, "Error: --check requires --format.\n")
+		ExitJoker(27)
+	}
+
+	if formatFlag {
+		if lintFlag || lspFlag || replFlag || nreplPort != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --format and --lint, --lsp, --repl or --nrepl.\n")
+			ExitJoker(28)
+		}
+		if filename == "" {
+			fmt.Fprintf(Stderr, "Error: Missing <path> argument.\n")
+			ExitJoker(30)
+		}
+		if !formatPaths(rt, append([]string{filename}, remainingArgs...), workingDir, formatCheckFlag) {
+			ExitJoker(1)
+		}
+		return
+	}
+
+	if (fixFlag || fixDryRunFlag) && !lintFlag {
+		fmt.Fprintf(Stderr, "Error: --fix and --fix-dry-run require --lint.\n")
+		ExitJoker(26)
+	}
+
 	if lintFlag {
 		if replFlag {
 			fmt.Fprintf(Stderr, "Error: Cannot combine --lint and --repl.\n")
 			ExitJoker(10)
 		}
+		if nreplPort != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --lint and --nrepl.\n")
+			ExitJoker(19)
+		}
+		if lspFlag {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --lint and --lsp.\n")
+			ExitJoker(21)
+		}
 		if exitToRepl {
 			fmt.Fprintf(Stderr, "Error: Cannot combine --lint and --exit-to-repl.\n")
 			ExitJoker(14)
@@ -826,27 +1148,50 @@ func main() {
 		if dialect == UNKNOWN {
 			dialect = detectDialect(filename)
 		}
+		report := newLintReport(lintFormat)
+		ProblemHandler = report.add
+		if fixFlag || fixDryRunFlag {
+			lintFixer = newFixer(rt, report, fixDryRunFlag)
+			ProblemHandler = lintFixer.add
+		}
 		if filename != "" {
-			lintFile(filename, dialect, workingDir)
+			lintFile(rt, filename, dialect, workingDir)
 		} else if workingDir != "" {
-			lintDir(workingDir, dialect, reportGloballyUnusedFlag)
+			lintDir(rt, workingDir, dialect, reportGloballyUnusedFlag)
 		} else {
 			fmt.Fprintf(Stderr, "Error: Missing --file or --working-dir argument.\n")
 			ExitJoker(16)
 		}
-		if PROBLEM_COUNT > 0 {
+		if lintFixer != nil {
+			lintFixer.flush()
+		}
+		report.write(Stdout)
+		if report.fails(lintFailOn) {
 			ExitJoker(1)
 		}
 		return
 	}
 
+	if lspFlag {
+		if replFlag || nreplPort != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --lsp and --repl or --nrepl.\n")
+			ExitJoker(22)
+		}
+		if filename != "" {
+			fmt.Fprintf(Stderr, "Error: Cannot combine --lsp and a <filename> argument.\n")
+			ExitJoker(23)
+		}
+		lsp(rt, dialect, workingDir)
+		return
+	}
+
 	if workingDir != "" {
 		fmt.Fprintf(Stderr, "Error: Cannot specify --working-dir option when not linting.\n")
 		ExitJoker(11)
 	}
 
 	if filename != "" {
-		if err := processFile(filename, phase); err != nil {
+		if err := processFile(rt, filename, phase); err != nil {
 			if !errorToRepl {
 				ExitJoker(1)
 			}
@@ -857,12 +1202,17 @@ func main() {
 		}
 	}
 
+	if nreplPort != "" {
+		nrepl(rt, nreplPort)
+		return
+	}
+
 	if replSocket != "" {
-		srepl(replSocket, phase)
+		srepl(rt, replSocket, phase)
 		return
 	}
 
-	repl(phase)
+	repl(rt, phase)
 	return
 }
 
@@ -0,0 +1,688 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	nreplMsg map[string]interface{}
+
+	nreplTransport struct {
+		conn net.Conn
+		mu   sync.Mutex // serializes writes to conn
+	}
+
+	// nreplWriter sends everything written to it as out or err
+	// responses to the message being evaluated.
+	nreplWriter struct {
+		t   *nreplTransport
+		msg nreplMsg
+		key string
+	}
+
+	nreplRequest struct {
+		t   *nreplTransport
+		msg nreplMsg
+	}
+
+	nreplSession struct {
+		id       string
+		state    map[*Var]Object // session values of *ns*, *1, *2, *3 and *e
+		requests chan nreplRequest
+		closed   chan struct{}
+		// Guards the fields below, which describe the evaluation in progress.
+		mu          sync.Mutex
+		evalId      string
+		rt          *Runtime
+		interrupted bool
+	}
+
+	nreplServer struct {
+		replContext *ReplContext
+		nsVar       *Var
+		mu          sync.Mutex // guards sessions
+		sessions    map[string]*nreplSession
+	}
+)
+
+var nreplOps = []string{
+	"clone", "close", "complete", "completions", "describe", "eldoc", "eval",
+	"info", "interrupt", "load-file", "lookup", "ls-sessions",
+}
+
+func (msg nreplMsg) str(key string) string {
+	if s, ok := msg[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func (t *nreplTransport) send(msg nreplMsg, resp nreplMsg) {
+	if id, ok := msg["id"]; ok {
+		resp["id"] = id
+	}
+	if session, ok := msg["session"]; ok {
+		if _, ok := resp["session"]; !ok {
+			resp["session"] = session
+		}
+	}
+	var buf bytes.Buffer
+	if err := bencode(&buf, map[string]interface{}(resp)); err != nil {
+		panic(err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conn.Write(buf.Bytes())
+}
+
+func (t *nreplTransport) sendStatus(msg nreplMsg, status ...string) {
+	t.send(msg, nreplMsg{"status": status})
+}
+
+func (w *nreplWriter) Write(p []byte) (int, error) {
+	w.t.send(w.msg, nreplMsg{w.key: string(p)})
+	return len(p), nil
+}
+
+func newSessionId() string {
+	var b [16]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func nrepl(rt *Runtime, port string) {
+	ProcessReplData()
+	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.FindNamespace(MakeSymbol("joker.repl")))
+	if isNumber(port) {
+		port = "127.0.0.1:" + port
+	}
+	l, err := net.Listen("tcp", port)
+	if err != nil {
+		fmt.Fprintf(Stderr, "Cannot start nREPL server listening on %s: %s\n", port, err.Error())
+		ExitJoker(12)
+	}
+	defer l.Close()
+
+	addr := l.Addr().(*net.TCPAddr)
+	portFile := ".nrepl-port"
+	if err := ioutil.WriteFile(portFile, []byte(fmt.Sprint(addr.Port)), 0666); err == nil {
+		OnExit(func() { os.Remove(portFile) })
+		defer os.Remove(portFile)
+	}
+	fmt.Printf("nREPL server started on port %d on host %s - nrepl://%s\n", addr.Port, addr.IP, addr)
+
+	server := newNreplServer(rt)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Fprintf(Stderr, "Cannot accept nREPL connection on %s: %s\n", addr, err.Error())
+			ExitJoker(13)
+		}
+		rt.Go(func(rt *Runtime) {
+			server.serve(rt, conn)
+		})
+	}
+}
+
+func newNreplServer(rt *Runtime) *nreplServer {
+	nsVar, _ := GLOBAL_ENV.Resolve(rt, MakeSymbol("joker.core/*ns*"))
+	return &nreplServer{
+		replContext: NewReplContext(rt, GLOBAL_ENV),
+		nsVar:       nsVar,
+		sessions:    map[string]*nreplSession{},
+	}
+}
+
+func (s *nreplServer) serve(rt *Runtime, conn net.Conn) {
+	defer conn.Close()
+	t := &nreplTransport{conn: conn}
+	r := bufio.NewReader(conn)
+	for {
+		v, err := bdecode(r)
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(Stderr, "Closing nREPL connection from %s: %s\n", conn.RemoteAddr(), err.Error())
+			}
+			return
+		}
+		msg, ok := v.(map[string]interface{})
+		if !ok {
+			fmt.Fprintf(Stderr, "Closing nREPL connection from %s: message must be a dictionary\n", conn.RemoteAddr())
+			return
+		}
+		s.handle(rt, t, msg)
+	}
+}
+
+// newSession returns a new session, copying the state of from, if any.
+// The session evaluates code in a runtime forked from rt.
+func (s *nreplServer) newSession(rt *Runtime, from *nreplSession) *nreplSession {
+	sess := &nreplSession{
+		id:       newSessionId(),
+		rt:       rt.Fork(),
+		state:    map[*Var]Object{s.nsVar: GLOBAL_ENV.FindNamespace(MakeSymbol("user"))},
+		requests: make(chan nreplRequest, 16),
+		closed:   make(chan struct{}),
+	}
+	for _, v := range s.replContext.Vars() {
+		sess.state[v] = NIL
+	}
+	if from != nil {
+		from.mu.Lock()
+		for v, val := range from.state {
+			sess.state[v] = val
+		}
+		from.mu.Unlock()
+	}
+	return sess
+}
+
+func (s *nreplServer) addSession(sess *nreplSession) {
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	go s.runSession(sess)
+}
+
+// runSession evaluates the session's requests one at a time.
+func (s *nreplServer) runSession(sess *nreplSession) {
+	for {
+		select {
+		case req := <-sess.requests:
+			s.evalRequest(sess, req.t, req.msg)
+		case <-sess.closed:
+			return
+		}
+	}
+}
+
+// session returns the session msg refers to, or nil, in which case
+// an error is reported.
+func (s *nreplServer) session(t *nreplTransport, msg nreplMsg) *nreplSession {
+	s.mu.Lock()
+	sess := s.sessions[msg.str("session")]
+	s.mu.Unlock()
+	if sess == nil {
+		t.sendStatus(msg, "done", "error", "unknown-session")
+	}
+	return sess
+}
+
+func (s *nreplServer) closeSession(sess *nreplSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions[sess.id] == sess {
+		delete(s.sessions, sess.id)
+		close(sess.closed)
+	}
+}
+
+func (s *nreplServer) handle(rt *Runtime, t *nreplTransport, msg nreplMsg) {
+	switch msg.str("op") {
+	case "clone":
+		var from *nreplSession
+		if msg.str("session") != "" {
+			if from = s.session(t, msg); from == nil {
+				return
+			}
+		}
+		sess := s.newSession(rt, from)
+		s.addSession(sess)
+		t.send(msg, nreplMsg{"new-session": sess.id, "status": []string{"done"}})
+	case "close":
+		if sess := s.session(t, msg); sess != nil {
+			s.closeSession(sess)
+			t.sendStatus(msg, "done", "session-closed")
+		}
+	case "ls-sessions":
+		s.mu.Lock()
+		ids := make([]string, 0, len(s.sessions))
+		for id := range s.sessions {
+			ids = append(ids, id)
+		}
+		s.mu.Unlock()
+		sort.Strings(ids)
+		t.send(msg, nreplMsg{"sessions": ids, "status": []string{"done"}})
+	case "describe":
+		ops := map[string]interface{}{}
+		for _, op := range nreplOps {
+			ops[op] = map[string]interface{}{}
+		}
+		t.send(msg, nreplMsg{
+			"ops": ops,
+			"versions": map[string]interface{}{
+				"joker": map[string]interface{}{"version-string": VERSION},
+			},
+			"status": []string{"done"},
+		})
+	case "eval", "load-file":
+		if msg.str("session") == "" {
+			// Evaluate in a new session, which is discarded afterwards.
+			go s.evalRequest(s.newSession(rt, nil), t, msg)
+			return
+		}
+		if sess := s.session(t, msg); sess != nil {
+			select {
+			case sess.requests <- nreplRequest{t: t, msg: msg}:
+			case <-sess.closed:
+				t.sendStatus(msg, "done", "error", "unknown-session")
+			}
+		}
+	case "interrupt":
+		if sess := s.session(t, msg); sess != nil {
+			sess.interrupt(t, msg)
+		}
+	case "complete", "completions":
+		prefix := msg.str("prefix")
+		if prefix == "" {
+			prefix = msg.str("symbol")
+		}
+		t.send(msg, nreplMsg{
+			"completions": nreplCompletions(rt, prefix, s.namespace(msg)),
+			"status":      []string{"done"},
+		})
+	case "info", "lookup":
+		info := nreplInfo(rt, nreplSymbol(msg), s.namespace(msg))
+		if info == nil {
+			t.sendStatus(msg, "done", "no-info")
+			return
+		}
+		if msg.str("op") == "lookup" {
+			info = nreplMsg{"info": map[string]interface{}(info)}
+		}
+		info["status"] = []string{"done"}
+		t.send(msg, info)
+	case "eldoc":
+		eldoc := nreplEldoc(rt, nreplSymbol(msg), s.namespace(msg))
+		if eldoc == nil {
+			t.sendStatus(msg, "done", "no-eldoc")
+			return
+		}
+		eldoc["status"] = []string{"done"}
+		t.send(msg, eldoc)
+	default:
+		t.sendStatus(msg, "done", "error", "unknown-op")
+	}
+}
+
+// namespace returns the namespace named by the ns field of msg,
+// the current namespace of msg's session, or user.
+func (s *nreplServer) namespace(msg nreplMsg) *Namespace {
+	if name := msg.str("ns"); name != "" {
+		if ns := GLOBAL_ENV.FindNamespace(MakeSymbol(name)); ns != nil {
+			return ns
+		}
+	}
+	s.mu.Lock()
+	sess := s.sessions[msg.str("session")]
+	s.mu.Unlock()
+	if sess != nil {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if ns, ok := sess.state[s.nsVar].(*Namespace); ok {
+			return ns
+		}
+	}
+	return GLOBAL_ENV.FindNamespace(MakeSymbol("user"))
+}
+
+func (sess *nreplSession) interrupt(t *nreplTransport, msg nreplMsg) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	switch id := msg.str("interrupt-id"); {
+	case sess.evalId == "":
+		t.sendStatus(msg, "done", "session-idle")
+	case id != "" && id != sess.evalId:
+		t.sendStatus(msg, "done", "error", "interrupt-id-mismatch")
+	default:
+		sess.interrupted = true
+		sess.rt.Interrupt()
+		t.sendStatus(msg, "done", "interrupted")
+	}
+}
+
+func (sess *nreplSession) startEval(msg nreplMsg) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.evalId = msg.str("id")
+	if sess.evalId == "" {
+		sess.evalId = "unknown"
+	}
+	sess.rt.ClearInterrupt()
+	sess.interrupted = false
+}
+
+func (sess *nreplSession) isInterrupted() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.interrupted
+}
+
+func (sess *nreplSession) finishEval() (interrupted bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.rt.ClearInterrupt()
+	sess.evalId = ""
+	return sess.interrupted
+}
+
+func (s *nreplServer) evalRequest(sess *nreplSession, t *nreplTransport, msg nreplMsg) {
+	rt := sess.rt
+	code, filename, onlyLast := msg.str("code"), "<nrepl>", false
+	if msg.str("op") == "load-file" {
+		code, filename, onlyLast = msg.str("file"), msg.str("file-path"), true
+		if filename == "" {
+			filename = msg.str("file-name")
+		}
+	}
+
+	bindings := EmptyArrayMap()
+	sess.mu.Lock()
+	for v, val := range sess.state {
+		bindings.Add(v, val)
+	}
+	sess.mu.Unlock()
+	if name := msg.str("ns"); name != "" {
+		if ns := GLOBAL_ENV.FindNamespace(MakeSymbol(name)); ns != nil {
+			bindings.Set(s.nsVar, ns)
+		}
+	}
+	out := &nreplWriter{t: t, msg: msg, key: "out"}
+	errOut := &nreplWriter{t: t, msg: msg, key: "err"}
+	for name, val := range map[string]Object{
+		"joker.core/*out*":  MakeIOWriter(out),
+		"joker.core/*err*":  MakeIOWriter(errOut),
+		"joker.core/*in*":   MakeBufferedReader(strings.NewReader("")),
+		"joker.core/*file*": MakeString(filename),
+	} {
+		v, _ := GLOBAL_ENV.Resolve(rt, MakeSymbol(name))
+		bindings.Set(v, val)
+	}
+
+	sess.startEval(msg)
+	rt.PushThreadBindings(bindings)
+	var last Object
+	var exc error
+	ok := true
+	reader := NewReader(strings.NewReader(code), filename)
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV, Runtime: rt}
+	for ok {
+		var res Object
+		res, exc, ok = s.evalNext(rt, reader, parseContext)
+		if !ok && !sess.isInterrupted() {
+			fmt.Fprintln(errOut, exc)
+		}
+		if res == nil {
+			break
+		}
+		if onlyLast {
+			last = res
+		} else {
+			t.send(msg, nreplMsg{"value": printToString(rt, res), "ns": GLOBAL_ENV.CurrentNamespace(rt).Name.Name()})
+		}
+	}
+	if ok && last != nil {
+		t.send(msg, nreplMsg{"value": printToString(rt, last), "ns": GLOBAL_ENV.CurrentNamespace(rt).Name.Name()})
+	}
+	sess.mu.Lock()
+	for v := range sess.state {
+		sess.state[v] = v.Resolve(rt)
+	}
+	sess.mu.Unlock()
+	rt.PopThreadBindings()
+
+	switch {
+	case sess.finishEval():
+		t.sendStatus(msg, "interrupted")
+	case exc != nil:
+		t.send(msg, nreplMsg{"ex": errorType(exc), "status": []string{"eval-error"}})
+	}
+	t.sendStatus(msg, "done")
+}
+
+// evalNext reads and evaluates the next form. Returns nil result
+// at the end of input, and false if there was an error.
+func (s *nreplServer) evalNext(rt *Runtime, reader *Reader, parseContext *ParseContext) (res Object, exc error, ok bool) {
+	saved := rt.Save()
+	defer func() {
+		if r := recover(); r != nil {
+			rt.Recovered(r, saved)
+			switch r := r.(type) {
+			case Error:
+				s.replContext.PushException(rt, r)
+				res, exc, ok = nil, r, false
+			default:
+				panic(r)
+			}
+		}
+	}()
+	obj, err := TryRead(rt, reader)
+	if err == io.EOF {
+		return nil, nil, true
+	}
+	if err != nil {
+		return nil, err, false
+	}
+	res = rt.Eval(Parse(obj, parseContext), nil)
+	s.replContext.PushValue(rt, res)
+	return res, nil, true
+}
+
+func errorType(err error) string {
+	if obj, ok := err.(Object); ok {
+		return obj.GetType().ToString(false)
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", err), "core.")
+}
+
+func printToString(rt *Runtime, obj Object) string {
+	var b bytes.Buffer
+	PrintObject(rt, obj, &b)
+	return b.String()
+}
+
+func nreplSymbol(msg nreplMsg) string {
+	if sym := msg.str("sym"); sym != "" {
+		return sym
+	}
+	return msg.str("symbol")
+}
+
+func varType(rt *Runtime, vr *Var) string {
+	if vr.IsMacro() {
+		return "macro"
+	}
+	switch vr.Resolve(rt).(type) {
+	case *Fn, Proc:
+		return "function"
+	}
+	// Vars defined while linting are never evaluated.
+	if _, ok := vr.Expr().(*FnExpr); ok {
+		return "function"
+	}
+	return "var"
+}
+
+type completion struct {
+	candidate string
+	typ       string
+}
+
+// completions returns the vars, aliases and namespaces visible from ns
+// that start with prefix, sorted by name.
+func completions(rt *Runtime, prefix string, ns *Namespace) []completion {
+	var res []completion
+	add := func(candidate, typ string) {
+		res = append(res, completion{candidate, typ})
+	}
+	if i := strings.Index(prefix, "/"); i > 0 {
+		qualifier, name := prefix[:i], prefix[i+1:]
+		target := GLOBAL_ENV.NamespaceFor(ns, MakeSymbol(prefix))
+		if target == nil {
+			return nil
+		}
+		for k, v := range target.Mappings() {
+			if strings.HasPrefix(*k, name) && v.Namespace() == target && !v.IsPrivate() {
+				add(qualifier+"/"+*k, varType(rt, v))
+			}
+		}
+	} else {
+		for k, v := range ns.Mappings() {
+			if strings.HasPrefix(*k, prefix) {
+				add(*k, varType(rt, v))
+			}
+		}
+		for k := range ns.Aliases() {
+			if strings.HasPrefix(*k, prefix) {
+				add(*k, "namespace")
+			}
+		}
+		for _, n := range GLOBAL_ENV.AllNamespaces() {
+			if name := n.Name.Name(); strings.HasPrefix(name, prefix) {
+				add(name, "namespace")
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].candidate < res[j].candidate
+	})
+	return res
+}
+
+func nreplCompletions(rt *Runtime, prefix string, ns *Namespace) []interface{} {
+	res := []interface{}{}
+	for _, c := range completions(rt, prefix, ns) {
+		res = append(res, map[string]interface{}{"candidate": c.candidate, "type": c.typ})
+	}
+	return res
+}
+
+// splitVarName returns the namespace and the name of vr.
+func splitVarName(vr *Var) (ns string, name string) {
+	s := vr.Name()
+	i := strings.Index(s, "/")
+	return s[:i], s[i+1:]
+}
+
+func resolveVar(sym string, ns *Namespace) *Var {
+	if sym == "" {
+		return nil
+	}
+	vr, ok := GLOBAL_ENV.ResolveIn(ns, MakeSymbol(sym))
+	if !ok {
+		return nil
+	}
+	return vr
+}
+
+func metaString(m Map, key string) (string, bool) {
+	if ok, v := m.Get(MakeKeyword(key)); ok && !v.Equals(NIL) {
+		if s, ok := v.(String); ok {
+			return s.S, true
+		}
+		return v.ToString(false), true
+	}
+	return "", false
+}
+
+func nreplInfo(rt *Runtime, sym string, ns *Namespace) nreplMsg {
+	if vr := resolveVar(sym, ns); vr != nil {
+		m := vr.GetMeta()
+		vns, name := splitVarName(vr)
+		info := nreplMsg{
+			"ns":   vns,
+			"name": name,
+		}
+		for _, k := range []string{"doc", "file", "added"} {
+			if s, ok := metaString(m, k); ok {
+				info[k] = s
+			}
+		}
+		for _, k := range []string{"line", "column"} {
+			if ok, v := m.Get(MakeKeyword(k)); ok {
+				if n, ok := v.(Int); ok {
+					info[k] = n.I
+				}
+			}
+		}
+		if ok, v := m.Get(MakeKeyword("arglists")); ok {
+			if s, ok := v.(Seqable); ok {
+				var lists []string
+				for s := s.Seq(); !s.IsEmpty(); s = s.Rest() {
+					lists = append(lists, s.First().ToString(true))
+				}
+				info["arglists-str"] = strings.Join(lists, "\n")
+			}
+		}
+		if varType(rt, vr) == "macro" {
+			info["macro"] = "true"
+		}
+		return info
+	}
+	if n := GLOBAL_ENV.FindNamespace(MakeSymbol(sym)); n != nil {
+		info := nreplMsg{"ns": n.Name.Name()}
+		if s, ok := metaString(n.GetMeta(), "doc"); ok {
+			info["doc"] = s
+		}
+		return info
+	}
+	return nil
+}
+
+func nreplEldoc(rt *Runtime, sym string, ns *Namespace) nreplMsg {
+	vr := resolveVar(sym, ns)
+	if vr == nil {
+		return nil
+	}
+	m := vr.GetMeta()
+	ok, arglists := m.Get(MakeKeyword("arglists"))
+	if !ok {
+		return nil
+	}
+	s, ok := arglists.(Seqable)
+	if !ok {
+		return nil
+	}
+	eldoc := []interface{}{}
+	for s := s.Seq(); !s.IsEmpty(); s = s.Rest() {
+		var params []string
+		if args, ok := s.First().(Seqable); ok {
+			for a := args.Seq(); !a.IsEmpty(); a = a.Rest() {
+				params = append(params, a.First().ToString(true))
+			}
+		}
+		eldoc = append(eldoc, params)
+	}
+	typ := "function"
+	if varType(rt, vr) == "var" {
+		typ = "variable"
+	} else if varType(rt, vr) == "macro" {
+		typ = "macro"
+	}
+	vns, name := splitVarName(vr)
+	res := nreplMsg{
+		"eldoc": eldoc,
+		"name":  name,
+		"ns":    vns,
+		"type":  typ,
+	}
+	if doc, ok := metaString(m, "doc"); ok {
+		res["docstring"] = doc
+	}
+	return res
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+func TestMain(m *testing.M) {
+	GLOBAL_ENV.InitEnv(os.Stdin, os.Stdout, os.Stderr, nil)
+	ProcessCoreData()
+	GLOBAL_ENV.ReferCoreToUser()
+	ProcessReplData()
+	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.FindNamespace(MakeSymbol("joker.repl")))
+	os.Exit(m.Run())
+}
+
+// nreplClient talks to an nREPL server running in the test process
+// over an in-memory connection.
+type nreplClient struct {
+	t         *testing.T
+	conn      net.Conn
+	responses chan nreplMsg
+	pending   map[string][]nreplMsg // responses received while waiting for others
+	nextId    int
+}
+
+func newNreplClient(t *testing.T) *nreplClient {
+	server, client := net.Pipe()
+	rt := NewRuntime()
+	srv := newNreplServer(rt)
+	rt.Go(func(rt *Runtime) {
+		srv.serve(rt, server)
+	})
+	c := &nreplClient{
+		t:         t,
+		conn:      client,
+		responses: make(chan nreplMsg, 100),
+		pending:   map[string][]nreplMsg{},
+	}
+	go func() {
+		r := bufio.NewReader(client)
+		for {
+			v, err := bdecode(r)
+			if err != nil {
+				close(c.responses)
+				return
+			}
+			c.responses <- nreplMsg(v.(map[string]interface{}))
+		}
+	}()
+	t.Cleanup(func() { client.Close() })
+	return c
+}
+
+// send sends msg, giving it a fresh id, and returns the id.
+func (c *nreplClient) send(msg nreplMsg) string {
+	c.nextId++
+	id := fmt.Sprint(c.nextId)
+	msg["id"] = id
+	if err := bencode(c.conn, map[string]interface{}(msg)); err != nil {
+		c.t.Fatal(err)
+	}
+	return id
+}
+
+// receive returns the responses to the message with the given id,
+// up to and including the one with the done status.
+func (c *nreplClient) receive(id string) []nreplMsg {
+	res := c.pending[id]
+	delete(c.pending, id)
+	for len(res) == 0 || !hasStatus(res[len(res)-1], "done") {
+		select {
+		case resp, ok := <-c.responses:
+			if !ok {
+				c.t.Fatalf("Connection closed while waiting for responses to %s", id)
+			}
+			if resp.str("id") == id {
+				res = append(res, resp)
+			} else {
+				c.pending[resp.str("id")] = append(c.pending[resp.str("id")], resp)
+			}
+		case <-time.After(10 * time.Second):
+			c.t.Fatalf("Timed out waiting for responses to %s, got %v", id, res)
+		}
+	}
+	return res
+}
+
+func (c *nreplClient) call(msg nreplMsg) []nreplMsg {
+	return c.receive(c.send(msg))
+}
+
+func (c *nreplClient) clone() string {
+	resp := c.call(nreplMsg{"op": "clone"})
+	return resp[0].str("new-session")
+}
+
+func hasStatus(msg nreplMsg, status string) bool {
+	l, _ := msg["status"].([]interface{})
+	for _, s := range l {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the concatenation of the given field of responses.
+func field(responses []nreplMsg, key string) string {
+	var b strings.Builder
+	for _, r := range responses {
+		b.WriteString(r.str(key))
+	}
+	return b.String()
+}
+
+func TestNreplDescribe(t *testing.T) {
+	c := newNreplClient(t)
+	resp := c.call(nreplMsg{"op": "describe"})
+	ops, _ := resp[0]["ops"].(map[string]interface{})
+	for _, op := range nreplOps {
+		if _, ok := ops[op]; !ok {
+			t.Errorf("describe doesn't list %s", op)
+		}
+	}
+	if resp := c.call(nreplMsg{"op": "no-such-op"}); !hasStatus(resp[0], "unknown-op") {
+		t.Errorf("Expected unknown-op status, got %v", resp)
+	}
+}
+
+func TestNreplEval(t *testing.T) {
+	c := newNreplClient(t)
+	sess := c.clone()
+	resp := c.call(nreplMsg{"op": "eval", "session": sess, "code": `(println "hello") (+ 1 2) (def x 42)`})
+	if out := field(resp, "out"); out != "hello\n" {
+		t.Errorf("Expected output hello, got %q", out)
+	}
+	if values := field(resp, "value"); values != "nil3#'user/x" {
+		t.Errorf("Unexpected values %q", values)
+	}
+	resp = c.call(nreplMsg{"op": "eval", "session": sess, "code": "(/ 1 0)"})
+	if field(resp, "ex") == "" || !hasStatus(resp[len(resp)-2], "eval-error") {
+		t.Errorf("Expected eval error, got %v", resp)
+	}
+	resp = c.call(nreplMsg{"op": "eval", "session": sess, "code": "*e"})
+	if !strings.Contains(field(resp, "value"), "Division by zero") {
+		t.Errorf("Expected *e to be the last error, got %v", resp)
+	}
+}
+
+func TestNreplSessionState(t *testing.T) {
+	c := newNreplClient(t)
+	sess := c.clone()
+	c.call(nreplMsg{"op": "eval", "session": sess, "code": "(ns nrepl.test.session) (def y 1) (+ y 1)"})
+	resp := c.call(nreplMsg{"op": "eval", "session": sess, "code": "[*ns* *1]"})
+	if value := field(resp, "value"); value != "[nrepl.test.session 2]" {
+		t.Errorf("Unexpected session state %q", value)
+	}
+	if ns := resp[0].str("ns"); ns != "nrepl.test.session" {
+		t.Errorf("Expected ns nrepl.test.session, got %q", ns)
+	}
+	other := c.clone()
+	resp = c.call(nreplMsg{"op": "eval", "session": other, "code": "(str *ns*)"})
+	if value := field(resp, "value"); value != `"user"` {
+		t.Errorf("New session should start in user, got %s", value)
+	}
+	resp = c.call(nreplMsg{"op": "ls-sessions"})
+	if sessions, _ := resp[0]["sessions"].([]interface{}); len(sessions) != 2 {
+		t.Errorf("Expected 2 sessions, got %v", sessions)
+	}
+	if resp := c.call(nreplMsg{"op": "close", "session": other}); !hasStatus(resp[0], "session-closed") {
+		t.Errorf("Expected session-closed status, got %v", resp)
+	}
+	if resp := c.call(nreplMsg{"op": "eval", "session": other, "code": "1"}); !hasStatus(resp[0], "unknown-session") {
+		t.Errorf("Expected unknown-session status, got %v", resp)
+	}
+}
+
+func TestNreplCompletionsAndInfo(t *testing.T) {
+	c := newNreplClient(t)
+	resp := c.call(nreplMsg{"op": "completions", "prefix": "joker.string/split-l"})
+	completions, _ := resp[0]["completions"].([]interface{})
+	if len(completions) != 1 || completions[0].(map[string]interface{})["candidate"] != "joker.string/split-lines" {
+		t.Errorf("Unexpected completions %v", completions)
+	}
+	resp = c.call(nreplMsg{"op": "info", "sym": "map", "ns": "user"})
+	if resp[0].str("ns") != "joker.core" || resp[0].str("name") != "map" || resp[0].str("doc") == "" {
+		t.Errorf("Unexpected info %v", resp[0])
+	}
+	resp = c.call(nreplMsg{"op": "eldoc", "sym": "no-such-var"})
+	if !hasStatus(resp[0], "no-eldoc") {
+		t.Errorf("Expected no-eldoc status, got %v", resp)
+	}
+}
+
+// testNreplInterrupt starts evaluating code in a session, interrupts it
+// and checks that the session can still evaluate code.
+func testNreplInterrupt(t *testing.T, code string) {
+	c := newNreplClient(t)
+	sess := c.clone()
+	evalId := c.send(nreplMsg{"op": "eval", "session": sess, "code": code})
+	// Give the evaluation time to start.
+	time.Sleep(100 * time.Millisecond)
+	resp := c.call(nreplMsg{"op": "interrupt", "session": sess, "interrupt-id": evalId})
+	if !hasStatus(resp[0], "interrupted") {
+		t.Fatalf("Expected interrupted status, got %v", resp)
+	}
+	resp = c.receive(evalId)
+	if !hasStatus(resp[len(resp)-2], "interrupted") {
+		t.Errorf("Expected the evaluation to be interrupted, got %v", resp)
+	}
+	resp = c.call(nreplMsg{"op": "eval", "session": sess, "code": "(+ 1 1)"})
+	if value := field(resp, "value"); value != "2" {
+		t.Errorf("Session doesn't evaluate code after interrupt, got %v", resp)
+	}
+	resp = c.call(nreplMsg{"op": "interrupt", "session": sess})
+	if !hasStatus(resp[0], "session-idle") {
+		t.Errorf("Expected session-idle status, got %v", resp)
+	}
+}
+
+func TestNreplInterruptLoop(t *testing.T) {
+	testNreplInterrupt(t, "(loop [] (recur))")
+}
+
+func TestNreplInterruptChannelTake(t *testing.T) {
+	testNreplInterrupt(t, "(<! (chan))")
+}
+
+func TestNreplInterruptChannelPut(t *testing.T) {
+	testNreplInterrupt(t, "(>! (chan) 1)")
+}
+
+func TestNreplInterruptAlts(t *testing.T) {
+	testNreplInterrupt(t, "(alts! [(chan) (chan)])")
+}
+
+func TestNreplInterruptFuture(t *testing.T) {
+	testNreplInterrupt(t, "@(future (<! (chan)))")
+}
+
+func TestNreplInterruptSleep(t *testing.T) {
+	testNreplInterrupt(t, "(joker.time/sleep (* 60 joker.time/second))")
+}
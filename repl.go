@@ -18,7 +18,7 @@ import (
 var qualifiedSymbolRe *regexp.Regexp = regexp.MustCompile(`([0-9A-Za-z_\-\+\*\'\.]+)/([0-9A-Za-z_\-\+\*\']*$)`)
 var callRe *regexp.Regexp = regexp.MustCompile(`\(\s*([0-9A-Za-z_\-\+\*\'\.]*$)`)
 
-func completer(line string, pos int) (head string, c []string, tail string) {
+func completer(rt *Runtime, line string, pos int) (head string, c []string, tail string) {
 	head = line[:pos]
 	tail = line[pos:]
 	var match []string
@@ -28,10 +28,10 @@ func completer(line string, pos int) (head string, c []string, tail string) {
 	if match = qualifiedSymbolRe.FindStringSubmatch(head); match != nil {
 		nsName := match[1]
 		prefix = match[2]
-		ns = GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(), MakeSymbol(nsName+"/"+prefix))
+		ns = GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(rt), MakeSymbol(nsName+"/"+prefix))
 	} else if match = callRe.FindStringSubmatch(head); match != nil {
 		prefix = match[1]
-		ns = GLOBAL_ENV.CurrentNamespace()
+		ns = GLOBAL_ENV.CurrentNamespace(rt)
 		addNamespaces = true
 	}
 	if ns == nil {
@@ -70,12 +70,12 @@ func saveReplHistory(rl *liner.State, filename string) {
 	}
 }
 
-func repl(phase Phase) {
+func repl(rt *Runtime, phase Phase) {
 	ProcessReplData()
 	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.FindNamespace(MakeSymbol("joker.repl")))
 	fmt.Printf("Welcome to joker %s. Use '(exit)', %s to exit.\n", VERSION, EXITERS)
 	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
-	replContext := NewReplContext(parseContext.GlobalEnv)
+	replContext := NewReplContext(rt, parseContext.GlobalEnv)
 
 	var runeReader io.RuneReader
 	var rl *liner.State
@@ -100,7 +100,9 @@ func repl(phase Phase) {
 		})
 		defer rl.Close()
 		rl.SetCtrlCAborts(true)
-		rl.SetWordCompleter(completer)
+		rl.SetWordCompleter(func(line string, pos int) (string, []string, string) {
+			return completer(rt, line, pos)
+		})
 		rl.SetTabCompletionStyle(liner.TabPrints)
 
 		if !noReplHistory {
@@ -124,11 +126,11 @@ func repl(phase Phase) {
 
 	for {
 		if noReadline {
-			print(GLOBAL_ENV.CurrentNamespace().Name.ToString(false) + "=> ")
+			print(GLOBAL_ENV.CurrentNamespace(rt).Name.ToString(false) + "=> ")
 		} else {
-			runeReader.(*LineRuneReader).Prompt = (GLOBAL_ENV.CurrentNamespace().Name.ToString(false) + "=> ")
+			runeReader.(*LineRuneReader).Prompt = (GLOBAL_ENV.CurrentNamespace(rt).Name.ToString(false) + "=> ")
 		}
-		if processReplCommand(reader, phase, parseContext, replContext) {
+		if processReplCommand(rt, reader, phase, parseContext, replContext) {
 			saveReplHistory(rl, historyFilename)
 			return
 		}
@@ -8,20 +8,20 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
-func repl(phase Phase) {
+func repl(rt *Runtime, phase Phase) {
 	ProcessReplData()
 	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.FindNamespace(MakeSymbol("joker.repl")))
 	fmt.Printf("Welcome to joker %s. Use '(exit)', %s to exit.\n", VERSION, EXITERS)
 	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
-	replContext := NewReplContext(parseContext.GlobalEnv)
+	replContext := NewReplContext(rt, parseContext.GlobalEnv)
 
 	var runeReader io.RuneReader
 	runeReader = bufio.NewReader(Stdin)
 	reader := NewReader(runeReader, "<repl>")
 
 	for {
-		print(GLOBAL_ENV.CurrentNamespace().Name.ToString(false) + "=> ")
-		if processReplCommand(reader, phase, parseContext, replContext) {
+		print(GLOBAL_ENV.CurrentNamespace(rt).Name.ToString(false) + "=> ")
+		if processReplCommand(rt, reader, phase, parseContext, replContext) {
 			return
 		}
 	}
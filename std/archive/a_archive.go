@@ -0,0 +1,91 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package archive
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __create__P ProcFn = __create_
+var create_ Proc = Proc{Fn: __create__P, Name: "create_", Package: "std/archive"}
+
+func __create_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		filename := ExtractString(_args, 0)
+		paths := ExtractSeqable(_args, 1)
+		_res := create(filename, paths, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		filename := ExtractString(_args, 0)
+		paths := ExtractSeqable(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := create(filename, paths, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __extract__P ProcFn = __extract_
+var extract_ Proc = Proc{Fn: __extract__P, Name: "extract_", Package: "std/archive"}
+
+func __extract_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		filename := ExtractString(_args, 0)
+		dir := ExtractString(_args, 1)
+		_res := extract(filename, dir, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		filename := ExtractString(_args, 0)
+		dir := ExtractString(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := extract(filename, dir, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __list__P ProcFn = __list_
+var list_ Proc = Proc{Fn: __list__P, Name: "list_", Package: "std/archive"}
+
+func __list_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		filename := ExtractString(_args, 0)
+		_res := list(filename, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		filename := ExtractString(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := list(filename, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var archiveNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.archive"))
+
+func init() {
+	archiveNamespace.Lazy = Init
+}
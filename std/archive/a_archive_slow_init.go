@@ -0,0 +1,62 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package archive
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of archive.InternsOrThunks().")
+	}
+	archiveNamespace.ResetMeta(MakeMeta(nil, `Lists, extracts and creates zip and tar (optionally gzipped) archives.
+
+         Archive format is determined by the file name extension (.zip, .tar, .tar.gz or .tgz)
+         unless :format option (:zip, :tar, :tar.gz or :tgz) is passed.
+         Archive entries are described by maps with the following keys:
+         :name - name of the entry (String; directory names end with /)
+         :size - size in bytes (Int)
+         :mode - mode (Int)
+         :mtime - modification time (Time)
+         :dir? - true if the entry is a directory (Boolean)
+         :link - target of the link (String; only present for symbolic and hard links).`, "1.0"))
+
+	archiveNamespace.InternVar("create", create_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("filename"), MakeSymbol("paths")), NewVectorFrom(MakeSymbol("filename"), MakeSymbol("paths"), MakeSymbol("opts"))),
+			`Creates archive file filename containing files and directories
+  (which are added recursively) with the given paths.
+  Paths must be relative and are resolved against the current directory
+  or the :dir option, if passed. Entry names are the paths themselves
+  (with / as the separator). Symbolic links are stored as links.
+  Returns a vector of maps describing the added entries.
+  Optional opts map may have the following keys:
+  :format - archive format,
+  :dir - base directory that paths are relative to.
+
+  Example:
+
+  user=> (joker.archive/create "release.tar.gz" ["joker" "LICENSE"])`, "1.0"))
+
+	archiveNamespace.InternVar("extract", extract_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("filename"), MakeSymbol("dir")), NewVectorFrom(MakeSymbol("filename"), MakeSymbol("dir"), MakeSymbol("opts"))),
+			`Extracts archive file filename into directory dir, creating it if necessary.
+  Existing files are overwritten. Regular files, directories and links are extracted,
+  other entries are skipped.
+  Throws an error (leaving the entries extracted so far in place) if the archive has an entry
+  with an absolute path, a path or a link target pointing outside of dir,
+  or a path going through a symbolic link that points outside of dir.
+  Returns a vector of maps describing the extracted entries.
+  Optional opts map may have :format key.`, "1.0"))
+
+	archiveNamespace.InternVar("list", list_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("filename")), NewVectorFrom(MakeSymbol("filename"), MakeSymbol("opts"))),
+			`Returns a vector of maps describing the entries of archive file filename.
+  Optional opts map may have :format key.`, "1.0"))
+
+}
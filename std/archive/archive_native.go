@@ -0,0 +1,390 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+const (
+	formatZip   = "zip"
+	formatTar   = "tar"
+	formatTarGz = "tar.gz"
+)
+
+func archiveFormat(filename string, opts Map) string {
+	if ok, f := opts.Get(MakeKeyword("format")); ok && !f.Equals(NIL) {
+		switch f := AssertKeyword(f, "format must be a keyword").ToString(false)[1:]; f {
+		case formatZip, formatTar, formatTarGz:
+			return f
+		case "tgz":
+			return formatTarGz
+		default:
+			panic(RT.NewError("Unknown archive format: " + f))
+		}
+	}
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return formatZip
+	case strings.HasSuffix(name, ".tar"):
+		return formatTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return formatTarGz
+	}
+	panic(RT.NewError("Cannot determine archive format from file name, use :format option: " + filename))
+}
+
+func entryMap(name string, info os.FileInfo, link string) Map {
+	m := EmptyArrayMap()
+	m.Add(MakeKeyword("name"), MakeString(name))
+	size := info.Size()
+	if info.IsDir() {
+		size = 0
+	}
+	m.Add(MakeKeyword("size"), MakeInt(int(size)))
+	m.Add(MakeKeyword("mode"), MakeInt(int(info.Mode())))
+	m.Add(MakeKeyword("mtime"), MakeTime(info.ModTime()))
+	m.Add(MakeKeyword("dir?"), MakeBoolean(info.IsDir()))
+	if link != "" {
+		m.Add(MakeKeyword("link"), MakeString(link))
+	}
+	return m
+}
+
+// within returns true if path p is base or is located inside base.
+// Both paths must be clean.
+func within(base, p string) bool {
+	rel, err := filepath.Rel(base, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func zipLink(f *zip.File) string {
+	if f.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	r, err := f.Open()
+	PanicOnErr(err)
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	PanicOnErr(err)
+	return string(b)
+}
+
+// walkTar calls f for each entry of tar (or tar.gz, if gzipped is true) archive filename.
+func walkTar(filename string, gzipped bool, f func(hdr *tar.Header, r io.Reader)) {
+	file, err := os.Open(filename)
+	PanicOnErr(err)
+	defer file.Close()
+	var r io.Reader = file
+	if gzipped {
+		gr, err := gzip.NewReader(file)
+		PanicOnErr(err)
+		defer gr.Close()
+		r = gr
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		PanicOnErr(err)
+		f(hdr, tr)
+	}
+}
+
+func list(filename string, opts Map) Object {
+	res := EmptyVector()
+	format := archiveFormat(filename, opts)
+	if format == formatZip {
+		zr, err := zip.OpenReader(filename)
+		PanicOnErr(err)
+		defer zr.Close()
+		for _, f := range zr.File {
+			res = res.Conjoin(entryMap(f.Name, f.FileInfo(), zipLink(f)))
+		}
+		return res
+	}
+	walkTar(filename, format == formatTarGz, func(hdr *tar.Header, r io.Reader) {
+		res = res.Conjoin(entryMap(hdr.Name, hdr.FileInfo(), hdr.Linkname))
+	})
+	return res
+}
+
+type extractor struct {
+	dir     string
+	realDir string
+}
+
+func newExtractor(dir string) *extractor {
+	dir, err := filepath.Abs(dir)
+	PanicOnErr(err)
+	PanicOnErr(os.MkdirAll(dir, 0777))
+	realDir, err := filepath.EvalSymlinks(dir)
+	PanicOnErr(err)
+	return &extractor{dir: dir, realDir: realDir}
+}
+
+// path returns the destination path of archive entry name.
+// Entries with absolute paths or paths pointing outside of the
+// destination directory are rejected.
+func (e *extractor) path(name string) string {
+	n := filepath.FromSlash(name)
+	p := filepath.Join(e.dir, n)
+	if name == "" || filepath.IsAbs(n) || strings.HasPrefix(name, "/") || !within(e.dir, p) {
+		panic(RT.NewError("Illegal file path in archive: " + name))
+	}
+	return p
+}
+
+// prepare checks that the parent directory of p doesn't resolve (e.g. via symlinks
+// extracted earlier) to a location outside of the destination directory, creates it
+// if necessary and removes existing file p, so that it's not followed if it's a symlink.
+func (e *extractor) prepare(p string) {
+	d := filepath.Dir(p)
+	for {
+		if _, err := os.Lstat(d); err == nil || d == e.dir {
+			break
+		}
+		d = filepath.Dir(d)
+	}
+	real, err := filepath.EvalSymlinks(d)
+	PanicOnErr(err)
+	if !within(e.realDir, real) {
+		panic(RT.NewError("Illegal file path in archive, " + d + " points outside of " + e.dir))
+	}
+	PanicOnErr(os.MkdirAll(filepath.Dir(p), 0777))
+	if info, err := os.Lstat(p); err == nil && !info.IsDir() {
+		PanicOnErr(os.Remove(p))
+	}
+}
+
+func (e *extractor) mkdir(p string, mode os.FileMode) {
+	e.prepare(p)
+	PanicOnErr(os.MkdirAll(p, mode.Perm()|0700))
+}
+
+func (e *extractor) writeFile(p string, r io.Reader, info os.FileInfo) {
+	e.prepare(p)
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	PanicOnErr(err)
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		panic(RT.NewError(err.Error()))
+	}
+	PanicOnErr(f.Close())
+	PanicOnErr(os.Chtimes(p, info.ModTime(), info.ModTime()))
+}
+
+func (e *extractor) symlink(name string, p string, target string) {
+	t := filepath.FromSlash(target)
+	if filepath.IsAbs(t) || !within(e.dir, filepath.Join(filepath.Dir(p), t)) {
+		panic(RT.NewError("Illegal link in archive: " + name + " -> " + target))
+	}
+	e.prepare(p)
+	PanicOnErr(os.Symlink(t, p))
+	if real, err := filepath.EvalSymlinks(p); err == nil && !within(e.realDir, real) {
+		os.Remove(p)
+		panic(RT.NewError("Illegal link in archive: " + name + " -> " + target))
+	}
+}
+
+func (e *extractor) link(name string, p string, target string) {
+	t := e.path(target)
+	real, err := filepath.EvalSymlinks(t)
+	PanicOnErr(err)
+	if !within(e.realDir, real) {
+		panic(RT.NewError("Illegal link in archive: " + name + " -> " + target))
+	}
+	e.prepare(p)
+	PanicOnErr(os.Link(t, p))
+}
+
+func extract(filename string, dir string, opts Map) Object {
+	res := EmptyVector()
+	e := newExtractor(dir)
+	format := archiveFormat(filename, opts)
+	if format == formatZip {
+		zr, err := zip.OpenReader(filename)
+		PanicOnErr(err)
+		defer zr.Close()
+		for _, f := range zr.File {
+			p := e.path(f.Name)
+			info := f.FileInfo()
+			link := ""
+			switch {
+			case info.IsDir():
+				e.mkdir(p, info.Mode())
+			case info.Mode()&os.ModeSymlink != 0:
+				link = zipLink(f)
+				e.symlink(f.Name, p, link)
+			case info.Mode().IsRegular():
+				r, err := f.Open()
+				PanicOnErr(err)
+				func() {
+					defer r.Close()
+					e.writeFile(p, r, info)
+				}()
+			default:
+				continue
+			}
+			res = res.Conjoin(entryMap(f.Name, info, link))
+		}
+		return res
+	}
+	walkTar(filename, format == formatTarGz, func(hdr *tar.Header, r io.Reader) {
+		p := e.path(hdr.Name)
+		info := hdr.FileInfo()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			e.mkdir(p, info.Mode())
+		case tar.TypeReg:
+			e.writeFile(p, r, info)
+		case tar.TypeSymlink:
+			e.symlink(hdr.Name, p, hdr.Linkname)
+		case tar.TypeLink:
+			e.link(hdr.Name, p, hdr.Linkname)
+		default:
+			return
+		}
+		res = res.Conjoin(entryMap(hdr.Name, info, hdr.Linkname))
+	})
+	return res
+}
+
+// archiveWriter adds files to zip or tar archive. Directory names must end with a slash.
+type archiveWriter interface {
+	add(name string, path string, info os.FileInfo, link string)
+	close()
+}
+
+type zipWriter struct {
+	*zip.Writer
+}
+
+func (w zipWriter) add(name string, path string, info os.FileInfo, link string) {
+	hdr, err := zip.FileInfoHeader(info)
+	PanicOnErr(err)
+	hdr.Name = name
+	if !info.IsDir() {
+		hdr.Method = zip.Deflate
+	}
+	fw, err := w.CreateHeader(hdr)
+	PanicOnErr(err)
+	switch {
+	case link != "":
+		_, err = io.WriteString(fw, link)
+		PanicOnErr(err)
+	case info.Mode().IsRegular():
+		copyFile(fw, path)
+	}
+}
+
+func (w zipWriter) close() {
+	PanicOnErr(w.Close())
+}
+
+type tarWriter struct {
+	*tar.Writer
+	gw *gzip.Writer
+}
+
+func (w tarWriter) add(name string, path string, info os.FileInfo, link string) {
+	hdr, err := tar.FileInfoHeader(info, link)
+	PanicOnErr(err)
+	hdr.Name = name
+	PanicOnErr(w.WriteHeader(hdr))
+	if info.Mode().IsRegular() {
+		copyFile(w, path)
+	}
+}
+
+func (w tarWriter) close() {
+	PanicOnErr(w.Close())
+	if w.gw != nil {
+		PanicOnErr(w.gw.Close())
+	}
+}
+
+func copyFile(w io.Writer, path string) {
+	f, err := os.Open(path)
+	PanicOnErr(err)
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	PanicOnErr(err)
+}
+
+func create(filename string, paths Seqable, opts Map) Object {
+	format := archiveFormat(filename, opts)
+	base := "."
+	if ok, dir := opts.Get(MakeKeyword("dir")); ok && !dir.Equals(NIL) {
+		base = AssertString(dir, "dir must be a string").S
+	}
+	file, err := os.Create(filename)
+	PanicOnErr(err)
+	defer func() {
+		if r := recover(); r != nil {
+			file.Close()
+			os.Remove(filename)
+			panic(r)
+		}
+	}()
+	var w archiveWriter
+	switch format {
+	case formatZip:
+		w = zipWriter{zip.NewWriter(file)}
+	case formatTar:
+		w = tarWriter{Writer: tar.NewWriter(file)}
+	case formatTarGz:
+		gw := gzip.NewWriter(file)
+		w = tarWriter{Writer: tar.NewWriter(gw), gw: gw}
+	}
+	entries := EmptyVector()
+	for s := paths.Seq(); !s.IsEmpty(); s = s.Rest() {
+		path := filepath.Clean(AssertString(s.First(), "paths must be strings").S)
+		if filepath.IsAbs(path) || !within(".", path) {
+			panic(RT.NewError("Path must be relative to the base directory: " + path))
+		}
+		root := filepath.Join(base, path)
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(filepath.Join(path, rel))
+			if name == "." {
+				return nil
+			}
+			if info.IsDir() {
+				name += "/"
+			}
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(p); err != nil {
+					return err
+				}
+			} else if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+			w.add(name, p, info, link)
+			entries = entries.Conjoin(entryMap(name, info, link))
+			return nil
+		})
+		PanicOnErr(err)
+	}
+	w.close()
+	PanicOnErr(file.Close())
+	return entries
+}
@@ -6,10 +6,27 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
+var __decode_bytes__P ProcFn = __decode_bytes_
+var decode_bytes_ Proc = Proc{Fn: __decode_bytes__P, Name: "decode_bytes_", Package: "std/base64"}
+
+func __decode_bytes_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		s := ExtractString(_args, 0)
+		_res := decode(s)
+		return MakeBytes(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 var __decode_string__P ProcFn = __decode_string_
 var decode_string_ Proc = Proc{Fn: __decode_string__P, Name: "decode_string_", Package: "std/base64"}
 
-func __decode_string_(_args []Object) Object {
+func __decode_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -26,11 +43,11 @@ func __decode_string_(_args []Object) Object {
 var __encode_string__P ProcFn = __encode_string_
 var encode_string_ Proc = Proc{Fn: __encode_string__P, Name: "encode_string_", Package: "std/base64"}
 
-func __encode_string_(_args []Object) Object {
+func __encode_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
-		s := ExtractString(_args, 0)
+		s := ExtractBinary(_args, 0)
 		_res := encodeString(s)
 		return MakeString(_res)
 
@@ -14,6 +14,11 @@ func InternsOrThunks() {
 	}
 	base64Namespace.ResetMeta(MakeMeta(nil, `Implements base64 encoding as specified by RFC 4648.`, "1.0"))
 
+	base64Namespace.InternVar("decode-bytes", decode_bytes_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
+			`Like decode-string, but returns Bytes.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Bytes"}))
+
 	base64Namespace.InternVar("decode-string", decode_string_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
@@ -22,6 +27,6 @@ func InternsOrThunks() {
 	base64Namespace.InternVar("encode-string", encode_string_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
-			`Returns the base64 encoding of s.`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
+			`Returns the base64 encoding of s (String or Bytes).`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
 
 }
@@ -6,14 +6,18 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
-func decodeString(s string) string {
+func decode(s string) []byte {
 	decoded, err := base64.StdEncoding.DecodeString(s)
 	if err != nil {
 		panic(RT.NewError("Invalid base64 string: " + err.Error()))
 	}
-	return string(decoded)
+	return decoded
+}
+
+func decodeString(s string) string {
+	return string(decode(s))
 }
 
-func encodeString(s string) string {
-	return base64.StdEncoding.EncodeToString([]byte(s))
+func encodeString(s []byte) string {
+	return base64.StdEncoding.EncodeToString(s)
 }
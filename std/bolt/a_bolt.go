@@ -6,17 +6,53 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
+var __backup__P ProcFn = __backup_
+var backup_ Proc = Proc{Fn: __backup__P, Name: "backup_", Package: "std/bolt"}
+
+func __backup_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		w := ExtractIOWriter(_args, 1)
+		_res := backup(db, w)
+		return MakeInt(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __bucket_stats__P ProcFn = __bucket_stats_
+var bucket_stats_ Proc = Proc{Fn: __bucket_stats__P, Name: "bucket_stats_", Package: "std/bolt"}
+
+func __bucket_stats_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := bucketStats(db, bucket)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 var __by_prefix__P ProcFn = __by_prefix_
 var by_prefix_ Proc = Proc{Fn: __by_prefix__P, Name: "by_prefix_", Package: "std/bolt"}
 
-func __by_prefix_(_args []Object) Object {
+func __by_prefix_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
-		db := ExtractBoltDB(_args, 0)
-		bucket := ExtractString(_args, 1)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
 		prefix := ExtractString(_args, 2)
-		_res := byPrefix(db, bucket, prefix)
+		_res := byPrefix(_rt, db, bucket, prefix)
 		return _res
 
 	default:
@@ -28,7 +64,7 @@ func __by_prefix_(_args []Object) Object {
 var __close__P ProcFn = __close_
 var close_ Proc = Proc{Fn: __close__P, Name: "close_", Package: "std/bolt"}
 
-func __close_(_args []Object) Object {
+func __close_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -45,13 +81,13 @@ func __close_(_args []Object) Object {
 var __create_bucket__P ProcFn = __create_bucket_
 var create_bucket_ Proc = Proc{Fn: __create_bucket__P, Name: "create_bucket_", Package: "std/bolt"}
 
-func __create_bucket_(_args []Object) Object {
+func __create_bucket_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
-		db := ExtractBoltDB(_args, 0)
-		name := ExtractString(_args, 1)
-		_res := createBucket(db, name)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := createBucket(db, bucket)
 		return _res
 
 	default:
@@ -63,13 +99,13 @@ func __create_bucket_(_args []Object) Object {
 var __create_bucket_if_not_exists__P ProcFn = __create_bucket_if_not_exists_
 var create_bucket_if_not_exists_ Proc = Proc{Fn: __create_bucket_if_not_exists__P, Name: "create_bucket_if_not_exists_", Package: "std/bolt"}
 
-func __create_bucket_if_not_exists_(_args []Object) Object {
+func __create_bucket_if_not_exists_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
-		db := ExtractBoltDB(_args, 0)
-		name := ExtractString(_args, 1)
-		_res := createBucketIfNotExists(db, name)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := createBucketIfNotExists(db, bucket)
 		return _res
 
 	default:
@@ -81,12 +117,12 @@ func __create_bucket_if_not_exists_(_args []Object) Object {
 var __delete__P ProcFn = __delete_
 var delete_ Proc = Proc{Fn: __delete__P, Name: "delete_", Package: "std/bolt"}
 
-func __delete_(_args []Object) Object {
+func __delete_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
-		db := ExtractBoltDB(_args, 0)
-		bucket := ExtractString(_args, 1)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
 		key := ExtractString(_args, 2)
 		_res := delete(db, bucket, key)
 		return _res
@@ -100,13 +136,13 @@ func __delete_(_args []Object) Object {
 var __delete_bucket__P ProcFn = __delete_bucket_
 var delete_bucket_ Proc = Proc{Fn: __delete_bucket__P, Name: "delete_bucket_", Package: "std/bolt"}
 
-func __delete_bucket_(_args []Object) Object {
+func __delete_bucket_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
-		db := ExtractBoltDB(_args, 0)
-		name := ExtractString(_args, 1)
-		_res := deleteBucket(db, name)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := deleteBucket(db, bucket)
 		return _res
 
 	default:
@@ -118,14 +154,37 @@ func __delete_bucket_(_args []Object) Object {
 var __get__P ProcFn = __get_
 var get_ Proc = Proc{Fn: __get__P, Name: "get_", Package: "std/bolt"}
 
-func __get_(_args []Object) Object {
+func __get_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
-		db := ExtractBoltDB(_args, 0)
-		bucket := ExtractString(_args, 1)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
 		key := ExtractString(_args, 2)
-		_res := get(db, bucket, key)
+		_res := get(_rt, db, bucket, key)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __list_buckets__P ProcFn = __list_buckets_
+var list_buckets_ Proc = Proc{Fn: __list_buckets__P, Name: "list_buckets_", Package: "std/bolt"}
+
+func __list_buckets_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		db := ExtractObject(_args, 0)
+		_res := listBuckets(db, NIL)
+		return _res
+
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := listBuckets(db, bucket)
 		return _res
 
 	default:
@@ -137,12 +196,12 @@ func __get_(_args []Object) Object {
 var __next_sequence__P ProcFn = __next_sequence_
 var next_sequence_ Proc = Proc{Fn: __next_sequence__P, Name: "next_sequence_", Package: "std/bolt"}
 
-func __next_sequence_(_args []Object) Object {
+func __next_sequence_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
-		db := ExtractBoltDB(_args, 0)
-		bucket := ExtractString(_args, 1)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
 		_res := nextSequence(db, bucket)
 		return _res
 
@@ -155,14 +214,21 @@ func __next_sequence_(_args []Object) Object {
 var __open__P ProcFn = __open_
 var open_ Proc = Proc{Fn: __open__P, Name: "open_", Package: "std/bolt"}
 
-func __open_(_args []Object) Object {
+func __open_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
 		filename := ExtractString(_args, 0)
 		mode := ExtractInt(_args, 1)
-		_res := open(filename, mode)
-		return MakeBoltDB(_res)
+		_res := open(filename, mode, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		filename := ExtractString(_args, 0)
+		mode := ExtractInt(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := open(filename, mode, opts)
+		return _res
 
 	default:
 		PanicArity(_c)
@@ -173,14 +239,14 @@ func __open_(_args []Object) Object {
 var __put__P ProcFn = __put_
 var put_ Proc = Proc{Fn: __put__P, Name: "put_", Package: "std/bolt"}
 
-func __put_(_args []Object) Object {
+func __put_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 4:
-		db := ExtractBoltDB(_args, 0)
-		bucket := ExtractString(_args, 1)
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
 		key := ExtractString(_args, 2)
-		value := ExtractString(_args, 3)
+		value := ExtractObject(_args, 3)
 		_res := put(db, bucket, key, value)
 		return _res
 
@@ -190,6 +256,118 @@ func __put_(_args []Object) Object {
 	return NIL
 }
 
+var __range__P ProcFn = __range_
+var range_ Proc = Proc{Fn: __range__P, Name: "range_", Package: "std/bolt"}
+
+func __range_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		_res := rangeKV(_rt, db, bucket, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := rangeKV(_rt, db, bucket, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __seek__P ProcFn = __seek_
+var seek_ Proc = Proc{Fn: __seek__P, Name: "seek_", Package: "std/bolt"}
+
+func __seek_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 3:
+		db := ExtractObject(_args, 0)
+		bucket := ExtractObject(_args, 1)
+		key := ExtractString(_args, 2)
+		_res := seek(_rt, db, bucket, key)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __update__P ProcFn = __update_
+var update_ Proc = Proc{Fn: __update__P, Name: "update_", Package: "std/bolt"}
+
+func __update_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractBoltDB(_args, 0)
+		f := ExtractCallable(_args, 1)
+		_res := runInTx(_rt, db, true, f)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __view__P ProcFn = __view_
+var view_ Proc = Proc{Fn: __view__P, Name: "view_", Package: "std/bolt"}
+
+func __view_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractBoltDB(_args, 0)
+		f := ExtractCallable(_args, 1)
+		_res := runInTx(_rt, db, false, f)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __with_update__P ProcFn = __with_update_
+var with_update_ Proc = Proc{Fn: __with_update__P, Name: "with_update_", Package: "std/bolt"}
+
+func __with_update_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case true:
+		_res := txMacro(_args, "update")
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __with_view__P ProcFn = __with_view_
+var with_view_ Proc = Proc{Fn: __with_view__P, Name: "with_view_", Package: "std/bolt"}
+
+func __with_view_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case true:
+		_res := txMacro(_args, "view")
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 func Init() {
 
 	InternsOrThunks()
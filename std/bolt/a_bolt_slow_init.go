@@ -27,7 +27,40 @@ func InternsOrThunks() {
          user=> (joker.bolt/put db "users" (str id) (joker.json/write-string {:id id :name "Joe Black"}))
          nil
          user=> (joker.json/read-string (joker.bolt/get db "users" (str id)))
-         {"id" 1, "name" "Joe Black"}`, "1.0"))
+         {"id" 1, "name" "Joe Black"}
+
+         Functions that read or write data take either a database (BoltDB)
+         or a transaction (BoltTx, see with-update and with-view) as the first
+         argument. In the former case each call runs in its own transaction.
+         bucket argument is either a bucket name or a vector of names, which
+         is the path to a nested bucket, e.g. ["users" "archived"].
+
+         Values are strings by default. If the database is opened
+         with {:codec :edn} option, values can be arbitrary Joker values,
+         which are stored as EDN (via pr-str and read-string):
+
+         user=> (def db (joker.bolt/open "bolt.db" 0600 {:codec :edn}))
+         #'user/db
+         user=> (joker.bolt/with-update [tx db]
+                  (joker.bolt/create-bucket-if-not-exists tx "users")
+                  (joker.bolt/put tx "users" "1" {:id 1 :name "Joe Black"}))
+         nil
+         user=> (joker.bolt/get db "users" "1")
+         {:id 1, :name "Joe Black"}`, "1.0"))
+
+	boltNamespace.InternVar("backup", backup_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("w"))),
+			`Writes consistent copy of the entire database to w (IOWriter, e.g.
+  as returned by joker.os/create). Returns the number of bytes written.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Int"}))
+
+	boltNamespace.InternVar("bucket-stats", bucket_stats_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
+			`Returns a map with statistics of the bucket (including its nested
+  buckets) with the following keys: :key-count, :depth, :bucket-count,
+  :inline-bucket-count, :branch-page-count, :leaf-page-count,
+  :branch-in-use and :leaf-in-use (bytes).`, "1.0"))
 
 	boltNamespace.InternVar("by-prefix", by_prefix_,
 		MakeMeta(
@@ -46,13 +79,14 @@ func InternsOrThunks() {
 
 	boltNamespace.InternVar("create-bucket", create_bucket_,
 		MakeMeta(
-			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("name"))),
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
 			`Creates a new bucket. Throws an error if the bucket already exists,
-  if the bucket name is blank, or if the bucket name is too long.`, "1.0"))
+  if the bucket name is blank, or if the bucket name is too long.
+  Parent buckets of a nested bucket must exist.`, "1.0"))
 
 	boltNamespace.InternVar("create-bucket-if-not-exists", create_bucket_if_not_exists_,
 		MakeMeta(
-			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("name"))),
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
 			`Creates a new bucket if it doesn't already exist.
    Throws an error if the bucket name is blank, or if the bucket name is too long.`, "1.0"))
 
@@ -63,8 +97,9 @@ func InternsOrThunks() {
 
 	boltNamespace.InternVar("delete-bucket", delete_bucket_,
 		MakeMeta(
-			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("name"))),
-			`Deletes a bucket. Throws an error if the bucket doesn't exist.`, "1.0"))
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
+			`Deletes a bucket (including its nested buckets).
+  Throws an error if the bucket doesn't exist.`, "1.0"))
 
 	boltNamespace.InternVar("get", get_,
 		MakeMeta(
@@ -72,6 +107,12 @@ func InternsOrThunks() {
 			`Retrieves the value for a key in the bucket.
   Returns nil if the key does not exist.`, "1.0"))
 
+	boltNamespace.InternVar("list-buckets", list_buckets_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db")), NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
+			`Returns a vector of names of top level buckets or,
+  if bucket is provided, of buckets nested in it.`, "1.0"))
+
 	boltNamespace.InternVar("next-sequence", next_sequence_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"))),
@@ -79,11 +120,16 @@ func InternsOrThunks() {
 
 	boltNamespace.InternVar("open", open_,
 		MakeMeta(
-			NewListFrom(NewVectorFrom(MakeSymbol("filename"), MakeSymbol("mode"))),
+			NewListFrom(NewVectorFrom(MakeSymbol("filename"), MakeSymbol("mode")), NewVectorFrom(MakeSymbol("filename"), MakeSymbol("mode"), MakeSymbol("opts"))),
 			`Creates and opens a database at the given path.
   If the file does not exist then it will be created automatically
   with mode perm (before umask).
-  mode is normally passed as an octal literal, e.g. 0600`, "1.0").Plus(MakeKeyword("tag"), String{S: "BoltDB"}))
+  mode is normally passed as an octal literal, e.g. 0600
+  Optional opts map may have the following keys:
+  - codec (:string (default) or :edn; see namespace docstring)
+  - timeout (int, how long to wait for the file lock in nanoseconds;
+    by default waits indefinitely)
+  - read-only? (boolean, opens the database in read-only mode).`, "1.0"))
 
 	boltNamespace.InternVar("put", put_,
 		MakeMeta(
@@ -92,4 +138,52 @@ func InternsOrThunks() {
   If the key exist then its previous value will be overwritten.
   Throws an error if the key is blank, if the key is too large, or if the value is too large.`, "1.0"))
 
+	boltNamespace.InternVar("range", range_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket")), NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"), MakeSymbol("opts"))),
+			`Retrieves key/value pairs of bucket in the order of keys.
+  Returns a vector of [key value] tuples.
+  Optional opts map may have the following keys:
+  - start (string, the first key, inclusive)
+  - end (string, the last key, exclusive)
+  - reverse? (boolean, if true, pairs are returned in reverse order,
+    starting from the key before end)
+  - limit (int, maximum number of pairs to return).`, "1.0"))
+
+	boltNamespace.InternVar("seek", seek_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("bucket"), MakeSymbol("key"))),
+			`Returns [key value] tuple for the first key in bucket
+  that is equal to or greater than key, or nil if there is no such key.`, "1.0"))
+
+	boltNamespace.InternVar("update", update_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("f"))),
+			`Calls f with a new read-write transaction (BoltTx) of db and
+  returns the result. The transaction is committed when f returns
+  or rolled back if f throws. See also with-update.`, "1.0"))
+
+	boltNamespace.InternVar("view", view_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("f"))),
+			`Calls f with a new read-only transaction (BoltTx) of db and
+  returns the result. See also with-view.`, "1.0"))
+
+	boltNamespace.InternVar("with-update", with_update_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("binding"), MakeSymbol("&"), MakeSymbol("body"))),
+			`Evaluates body with tx bound to a new read-write transaction of db
+  and returns the value of the last expression. The transaction
+  is committed if body completes normally and rolled back if it throws.
+  tx can be passed to the functions of this namespace instead of db.
+
+  Example:
+
+  (with-update [tx db]
+    (put tx "accounts" "a" "90")
+    (put tx "accounts" "b" "110"))`, "1.0").Plus(MakeKeyword("macro"), Boolean{B: true})).SetMacro()
+	boltNamespace.InternVar("with-view", with_view_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("binding"), MakeSymbol("&"), MakeSymbol("body"))),
+			`Like with-update, but the transaction is read-only.`, "1.0").Plus(MakeKeyword("macro"), Boolean{B: true})).SetMacro()
 }
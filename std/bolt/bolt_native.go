@@ -2,24 +2,44 @@ package bolt
 
 import (
 	"bytes"
-	. "github.com/candid82/joker/core"
-	bolt "go.etcd.io/bbolt"
+	"io"
 	"os"
+	"strings"
+	"time"
 	"unsafe"
+
+	. "github.com/candid82/joker/core"
+	bolt "go.etcd.io/bbolt"
 )
 
 type (
 	// TODO: wrapper types like this can probably be auto generated
 	BoltDB struct {
 		*bolt.DB
-		hash uint32
+		codec string
+		hash  uint32
+	}
+	// BoltTx is a transaction started by update or view.
+	// It can only be used until the function passed to them returns.
+	BoltTx struct {
+		tx    *bolt.Tx
+		codec string
+		hash  uint32
+	}
+	// bucketParent is either a transaction or a bucket.
+	bucketParent interface {
+		Bucket(name []byte) *bolt.Bucket
+		CreateBucket(name []byte) (*bolt.Bucket, error)
+		CreateBucketIfNotExists(name []byte) (*bolt.Bucket, error)
+		DeleteBucket(name []byte) error
 	}
 )
 
 var boltDBType *Type
+var boltTxType *Type
 
 func MakeBoltDB(db *bolt.DB) BoltDB {
-	res := BoltDB{db, 0}
+	res := BoltDB{db, "", 0}
 	res.hash = HashPtr(uintptr(unsafe.Pointer(db)))
 	return res
 }
@@ -60,113 +80,389 @@ func EnsureBoltDB(args []Object, index int) BoltDB {
 	}
 }
 
-func ExtractBoltDB(args []Object, index int) *bolt.DB {
-	return EnsureBoltDB(args, index).DB
+func ExtractBoltDB(args []Object, index int) BoltDB {
+	return EnsureBoltDB(args, index)
+}
+
+func (tx *BoltTx) ToString(escape bool) string {
+	return "#object[BoltTx]"
+}
+
+func (tx *BoltTx) Equals(other interface{}) bool {
+	return tx == other
+}
+
+func (tx *BoltTx) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (tx *BoltTx) GetType() *Type {
+	return boltTxType
+}
+
+func (tx *BoltTx) Hash() uint32 {
+	return tx.hash
+}
+
+func (tx *BoltTx) WithInfo(info *ObjectInfo) Object {
+	return tx
 }
 
-func open(filename string, mode int) *bolt.DB {
-	db, err := bolt.Open(filename, os.FileMode(mode), nil)
+func open(filename string, mode int, opts Map) Object {
+	options := &bolt.Options{}
+	if ok, t := opts.Get(MakeKeyword("timeout")); ok {
+		options.Timeout = time.Duration(AssertInt(t, "timeout must be an integer").I)
+	}
+	if ok, r := opts.Get(MakeKeyword("read-only?")); ok {
+		options.ReadOnly = ToBool(r)
+	}
+	codec := ""
+	if ok, c := opts.Get(MakeKeyword("codec")); ok && !c.Equals(NIL) {
+		switch c.ToString(false) {
+		case ":edn":
+			codec = "edn"
+		case ":string":
+		default:
+			panic(RT.NewError("codec must be :string or :edn, got " + c.ToString(true)))
+		}
+	}
+	db, err := bolt.Open(filename, os.FileMode(mode), options)
 	PanicOnErr(err)
-	return db
+	res := MakeBoltDB(db)
+	res.codec = codec
+	return res
 }
 
-func close(db *bolt.DB) Nil {
+func close(db BoltDB) Nil {
 	err := db.Close()
 	PanicOnErr(err)
 	return NIL
 }
 
-func createBucket(db *bolt.DB, name string) Nil {
-	db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucket([]byte(name))
-		PanicOnErr(err)
+// runTx calls f with the transaction dbOrTx if it's BoltTx,
+// or with a new transaction of dbOrTx if it's BoltDB.
+func runTx(dbOrTx Object, writable bool, f func(tx *bolt.Tx, codec string)) {
+	switch o := dbOrTx.(type) {
+	case BoltDB:
+		fn := func(tx *bolt.Tx) error {
+			f(tx, o.codec)
+			return nil
+		}
+		if writable {
+			PanicOnErr(o.Update(fn))
+		} else {
+			PanicOnErr(o.View(fn))
+		}
+	case *BoltTx:
+		if o.tx == nil {
+			panic(RT.NewError("Transaction is closed"))
+		}
+		if writable && !o.tx.Writable() {
+			panic(RT.NewError("Transaction is read-only"))
+		}
+		f(o.tx, o.codec)
+	default:
+		panic(RT.NewError("Expected BoltDB or BoltTx, got " + dbOrTx.GetType().ToString(false)))
+	}
+}
+
+// runInTx calls f with a new transaction of db, which is committed
+// when f returns or rolled back if it throws.
+func runInTx(rt *Runtime, db BoltDB, writable bool, f Callable) (res Object) {
+	btx := &BoltTx{codec: db.codec}
+	btx.hash = HashPtr(uintptr(unsafe.Pointer(btx)))
+	fn := func(tx *bolt.Tx) error {
+		btx.tx = tx
+		defer func() { btx.tx = nil }()
+		res = f.Call(rt, []Object{btx})
 		return nil
+	}
+	if writable {
+		PanicOnErr(db.Update(fn))
+	} else {
+		PanicOnErr(db.View(fn))
+	}
+	return
+}
+
+// txMacro expands (with-update [tx db] body...) into
+// (joker.bolt/update db (fn [tx] body...)), and similarly for with-view.
+// args start with &form and &env.
+func txMacro(args []Object, name string) Object {
+	if len(args) < 3 {
+		PanicArity(len(args) - 2)
+	}
+	binding := AssertVector(args[2], "with-"+name+" requires a vector for its binding")
+	if binding.Count() != 2 {
+		panic(RT.NewError("with-" + name + " binding must have exactly two forms"))
+	}
+	fn := append([]Object{MakeSymbol("joker.core/fn"), NewVectorFrom(binding.Nth(0))}, args[3:]...)
+	return NewListFrom(MakeSymbol("joker.bolt/"+name), binding.Nth(1), NewListFrom(fn...))
+}
+
+func bucketPath(bucket Object) [][]byte {
+	var res [][]byte
+	switch b := bucket.(type) {
+	case String:
+		res = append(res, []byte(b.S))
+	case *Vector:
+		for i := 0; i < b.Count(); i++ {
+			res = append(res, []byte(AssertString(b.Nth(i), "Bucket path must consist of strings").S))
+		}
+	default:
+		panic(RT.NewError("Bucket must be a string or a vector of strings, got " + bucket.GetType().ToString(false)))
+	}
+	if len(res) == 0 {
+		panic(RT.NewError("Bucket path must not be empty"))
+	}
+	return res
+}
+
+func bucketName(bucket Object) string {
+	if s, ok := bucket.(String); ok {
+		return s.S
+	}
+	return bucket.ToString(true)
+}
+
+// walkBuckets returns the bucket at path (or tx if path is empty).
+func walkBuckets(tx *bolt.Tx, path [][]byte, bucket Object) bucketParent {
+	var parent bucketParent = tx
+	for _, name := range path {
+		b := parent.Bucket(name)
+		if b == nil {
+			panic(RT.NewError("Bucket doesn't exists: " + bucketName(bucket)))
+		}
+		parent = b
+	}
+	return parent
+}
+
+func getBucket(tx *bolt.Tx, bucket Object) *bolt.Bucket {
+	return walkBuckets(tx, bucketPath(bucket), bucket).(*bolt.Bucket)
+}
+
+// getParent returns the parent of bucket and bucket's own name.
+func getParent(tx *bolt.Tx, bucket Object) (bucketParent, []byte) {
+	path := bucketPath(bucket)
+	return walkBuckets(tx, path[:len(path)-1], bucket), path[len(path)-1]
+}
+
+func encode(codec string, value Object) []byte {
+	if codec == "edn" {
+		return []byte(value.ToString(true))
+	}
+	return []byte(AssertString(value, "Value must be a string, got "+value.GetType().ToString(false)).S)
+}
+
+func decode(rt *Runtime, codec string, value []byte) Object {
+	if value == nil {
+		return NIL
+	}
+	if codec == "edn" {
+		obj, err := TryRead(rt, NewReader(strings.NewReader(string(value)), "<bolt>"))
+		PanicOnErr(err)
+		return obj
+	}
+	return MakeString(string(value))
+}
+
+func createBucket(dbOrTx Object, bucket Object) Nil {
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		parent, name := getParent(tx, bucket)
+		_, err := parent.CreateBucket(name)
+		PanicOnErr(err)
 	})
 	return NIL
 }
 
-func createBucketIfNotExists(db *bolt.DB, name string) Nil {
-	db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(name))
+func createBucketIfNotExists(dbOrTx Object, bucket Object) Nil {
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		parent, name := getParent(tx, bucket)
+		_, err := parent.CreateBucketIfNotExists(name)
 		PanicOnErr(err)
-		return nil
 	})
 	return NIL
 }
 
-func deleteBucket(db *bolt.DB, name string) Nil {
-	db.Update(func(tx *bolt.Tx) error {
-		err := tx.DeleteBucket([]byte(name))
+func deleteBucket(dbOrTx Object, bucket Object) Nil {
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		parent, name := getParent(tx, bucket)
+		err := parent.DeleteBucket(name)
 		PanicOnErr(err)
-		return nil
 	})
 	return NIL
 }
 
-func getBucket(tx *bolt.Tx, bucket string) *bolt.Bucket {
-	b := tx.Bucket([]byte(bucket))
-	if b == nil {
-		panic(RT.NewError("Bucket doesn't exists: " + bucket))
-	}
-	return b
+func listBuckets(dbOrTx Object, bucket Object) *Vector {
+	res := EmptyVector()
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		if bucket.Equals(NIL) {
+			tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				res = res.Conjoin(MakeString(string(name)))
+				return nil
+			})
+			return
+		}
+		getBucket(tx, bucket).ForEach(func(k, v []byte) error {
+			if v == nil {
+				res = res.Conjoin(MakeString(string(k)))
+			}
+			return nil
+		})
+	})
+	return res
+}
+
+func bucketStats(dbOrTx Object, bucket Object) Map {
+	res := EmptyArrayMap()
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		stats := getBucket(tx, bucket).Stats()
+		res.Add(MakeKeyword("key-count"), MakeInt(stats.KeyN))
+		res.Add(MakeKeyword("depth"), MakeInt(stats.Depth))
+		res.Add(MakeKeyword("bucket-count"), MakeInt(stats.BucketN))
+		res.Add(MakeKeyword("inline-bucket-count"), MakeInt(stats.InlineBucketN))
+		res.Add(MakeKeyword("branch-page-count"), MakeInt(stats.BranchPageN))
+		res.Add(MakeKeyword("leaf-page-count"), MakeInt(stats.LeafPageN))
+		res.Add(MakeKeyword("branch-in-use"), MakeInt(stats.BranchInuse))
+		res.Add(MakeKeyword("leaf-in-use"), MakeInt(stats.LeafInuse))
+	})
+	return res
 }
 
-func nextSequence(db *bolt.DB, bucket string) Int {
+func nextSequence(dbOrTx Object, bucket Object) Int {
 	var id uint64
-	db.Update(func(tx *bolt.Tx) error {
-		b := getBucket(tx, bucket)
-		id, _ = b.NextSequence()
-		return nil
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		var err error
+		id, err = getBucket(tx, bucket).NextSequence()
+		PanicOnErr(err)
 	})
 	return MakeInt(int(id))
 }
 
-func put(db *bolt.DB, bucket, key, value string) Nil {
-	db.Update(func(tx *bolt.Tx) error {
-		b := getBucket(tx, bucket)
-		err := b.Put([]byte(key), []byte(value))
+func put(dbOrTx Object, bucket Object, key string, value Object) Nil {
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		err := getBucket(tx, bucket).Put([]byte(key), encode(codec, value))
 		PanicOnErr(err)
-		return nil
 	})
 	return NIL
 }
 
-func delete(db *bolt.DB, bucket, key string) Nil {
-	db.Update(func(tx *bolt.Tx) error {
-		b := getBucket(tx, bucket)
-		err := b.Delete([]byte(key))
+func delete(dbOrTx Object, bucket Object, key string) Nil {
+	runTx(dbOrTx, true, func(tx *bolt.Tx, codec string) {
+		err := getBucket(tx, bucket).Delete([]byte(key))
 		PanicOnErr(err)
-		return nil
 	})
 	return NIL
 }
 
-func get(db *bolt.DB, bucket, key string) Object {
-	var v []byte
-	db.View(func(tx *bolt.Tx) error {
-		b := getBucket(tx, bucket)
-		v = b.Get([]byte(key))
-		return nil
+func get(rt *Runtime, dbOrTx Object, bucket Object, key string) (res Object) {
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		res = decode(rt, codec, getBucket(tx, bucket).Get([]byte(key)))
 	})
-	if v == nil {
-		return NIL
-	}
-	return MakeString(string(v))
+	return
+}
+
+func kv(rt *Runtime, codec string, k, v []byte) *Vector {
+	return NewVectorFrom(MakeString(string(k)), decode(rt, codec, v))
 }
 
-func byPrefix(db *bolt.DB, bucket, prefix string) *Vector {
+func byPrefix(rt *Runtime, dbOrTx Object, bucket Object, prefix string) *Vector {
 	res := EmptyVector()
-	db.View(func(tx *bolt.Tx) error {
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
 		c := getBucket(tx, bucket).Cursor()
 		pr := []byte(prefix)
 		for k, v := c.Seek(pr); k != nil && bytes.HasPrefix(k, pr); k, v = c.Next() {
-			res = res.Conjoin(NewVectorFrom(MakeString(string(k)), MakeString(string(v))))
+			if v != nil {
+				res = res.Conjoin(kv(rt, codec, k, v))
+			}
 		}
-		return nil
 	})
 	return res
 }
 
+func getKey(opts Map, k string) []byte {
+	if ok, v := opts.Get(MakeKeyword(k)); ok && !v.Equals(NIL) {
+		return []byte(AssertString(v, k+" must be a string").S)
+	}
+	return nil
+}
+
+func rangeKV(rt *Runtime, dbOrTx Object, bucket Object, opts Map) *Vector {
+	start := getKey(opts, "start")
+	end := getKey(opts, "end")
+	reverse := false
+	if ok, r := opts.Get(MakeKeyword("reverse?")); ok {
+		reverse = ToBool(r)
+	}
+	limit := -1
+	if ok, l := opts.Get(MakeKeyword("limit")); ok {
+		limit = AssertInt(l, "limit must be an integer").I
+	}
+	res := EmptyVector()
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		c := getBucket(tx, bucket).Cursor()
+		var k, v []byte
+		inRange := func() bool {
+			if k == nil || res.Count() == limit {
+				return false
+			}
+			if reverse {
+				return start == nil || bytes.Compare(k, start) >= 0
+			}
+			return end == nil || bytes.Compare(k, end) < 0
+		}
+		next := c.Next
+		switch {
+		case reverse:
+			next = c.Prev
+			if end == nil {
+				k, v = c.Last()
+			} else if k, v = c.Seek(end); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		case start != nil:
+			k, v = c.Seek(start)
+		default:
+			k, v = c.First()
+		}
+		for ; inRange(); k, v = next() {
+			if v != nil {
+				res = res.Conjoin(kv(rt, codec, k, v))
+			}
+		}
+	})
+	return res
+}
+
+func seek(rt *Runtime, dbOrTx Object, bucket Object, key string) (res Object) {
+	res = NIL
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		c := getBucket(tx, bucket).Cursor()
+		for k, v := c.Seek([]byte(key)); k != nil; k, v = c.Next() {
+			if v != nil {
+				res = kv(rt, codec, k, v)
+				return
+			}
+		}
+	})
+	return
+}
+
+func backup(dbOrTx Object, w io.Writer) int {
+	var n int64
+	runTx(dbOrTx, false, func(tx *bolt.Tx, codec string) {
+		var err error
+		n, err = tx.WriteTo(w)
+		PanicOnErr(err)
+	})
+	// TODO: 32-bit issue
+	return int(n)
+}
+
 func init() {
 	boltDBType = RegType("BoltDB", (*BoltDB)(nil), "Wraps Bolt DB type")
+	boltTxType = RegRefType("BoltTx", (*BoltTx)(nil), "Wraps Bolt transaction")
 }
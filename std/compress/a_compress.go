@@ -0,0 +1,138 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package compress
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __flate_reader__P ProcFn = __flate_reader_
+var flate_reader_ Proc = Proc{Fn: __flate_reader__P, Name: "flate_reader_", Package: "std/compress"}
+
+func __flate_reader_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		rdr := ExtractIOReader(_args, 0)
+		_res := flateReader(rdr)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __flate_writer__P ProcFn = __flate_writer_
+var flate_writer_ Proc = Proc{Fn: __flate_writer__P, Name: "flate_writer_", Package: "std/compress"}
+
+func __flate_writer_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		w := ExtractIOWriter(_args, 0)
+		_res := flateWriter(w, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		w := ExtractIOWriter(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := flateWriter(w, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __gzip_reader__P ProcFn = __gzip_reader_
+var gzip_reader_ Proc = Proc{Fn: __gzip_reader__P, Name: "gzip_reader_", Package: "std/compress"}
+
+func __gzip_reader_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		rdr := ExtractIOReader(_args, 0)
+		_res := gzipReader(rdr)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __gzip_writer__P ProcFn = __gzip_writer_
+var gzip_writer_ Proc = Proc{Fn: __gzip_writer__P, Name: "gzip_writer_", Package: "std/compress"}
+
+func __gzip_writer_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		w := ExtractIOWriter(_args, 0)
+		_res := gzipWriter(w, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		w := ExtractIOWriter(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := gzipWriter(w, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __zlib_reader__P ProcFn = __zlib_reader_
+var zlib_reader_ Proc = Proc{Fn: __zlib_reader__P, Name: "zlib_reader_", Package: "std/compress"}
+
+func __zlib_reader_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		rdr := ExtractIOReader(_args, 0)
+		_res := zlibReader(rdr)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __zlib_writer__P ProcFn = __zlib_writer_
+var zlib_writer_ Proc = Proc{Fn: __zlib_writer__P, Name: "zlib_writer_", Package: "std/compress"}
+
+func __zlib_writer_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		w := ExtractIOWriter(_args, 0)
+		_res := zlibWriter(w, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		w := ExtractIOWriter(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := zlibWriter(w, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var compressNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.compress"))
+
+func init() {
+	compressNamespace.Lazy = Init
+}
@@ -0,0 +1,59 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package compress
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of compress.InternsOrThunks().")
+	}
+	compressNamespace.ResetMeta(MakeMeta(nil, `Implements reading and writing of compressed data in gzip, zlib and DEFLATE formats.
+
+         Readers and writers returned by this namespace wrap the IOReader or IOWriter
+         passed to them. Closing them (with joker.io/close) doesn't close the wrapped object.
+         Writers must be closed to flush any buffered data and write the format's trailer.`, "1.0"))
+
+	compressNamespace.InternVar("flate-reader", flate_reader_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("rdr"))),
+			`Returns IOReader that decompresses raw DEFLATE data read from rdr.`, "1.0"))
+
+	compressNamespace.InternVar("flate-writer", flate_writer_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("w")), NewVectorFrom(MakeSymbol("w"), MakeSymbol("opts"))),
+			`Returns IOWriter that writes raw DEFLATE-compressed data to w.
+  Optional opts map may have :level key (see gzip-writer).`, "1.0"))
+
+	compressNamespace.InternVar("gzip-reader", gzip_reader_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("rdr"))),
+			`Returns IOReader that decompresses gzip data read from rdr.`, "1.0"))
+
+	compressNamespace.InternVar("gzip-writer", gzip_writer_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("w")), NewVectorFrom(MakeSymbol("w"), MakeSymbol("opts"))),
+			`Returns IOWriter that writes gzip-compressed data to w.
+  Optional opts map may have the following keys:
+  :level - compression level (see below),
+  :name - file name stored in the gzip header,
+  :comment - comment stored in the gzip header.
+  Compression level can be an Int from 0 (no compression) to 9 (best compression)
+  or one of the keywords :none, :best-speed, :best-compression, :default, :huffman-only.`, "1.0"))
+
+	compressNamespace.InternVar("zlib-reader", zlib_reader_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("rdr"))),
+			`Returns IOReader that decompresses zlib data read from rdr.`, "1.0"))
+
+	compressNamespace.InternVar("zlib-writer", zlib_writer_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("w")), NewVectorFrom(MakeSymbol("w"), MakeSymbol("opts"))),
+			`Returns IOWriter that writes zlib-compressed data to w.
+  Optional opts map may have :level key (see gzip-writer).`, "1.0"))
+
+}
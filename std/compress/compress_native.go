@@ -0,0 +1,74 @@
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	. "github.com/candid82/joker/core"
+)
+
+var levels = map[string]int{
+	"none":             flate.NoCompression,
+	"best-speed":       flate.BestSpeed,
+	"best-compression": flate.BestCompression,
+	"default":          flate.DefaultCompression,
+	"huffman-only":     flate.HuffmanOnly,
+}
+
+func level(opts Map) int {
+	ok, l := opts.Get(MakeKeyword("level"))
+	if !ok || l.Equals(NIL) {
+		return flate.DefaultCompression
+	}
+	switch l := l.(type) {
+	case Int:
+		return l.I
+	case Keyword:
+		if res, ok := levels[l.ToString(false)[1:]]; ok {
+			return res
+		}
+	}
+	panic(RT.NewError("Invalid compression level: " + l.ToString(true)))
+}
+
+func gzipReader(rdr io.Reader) Object {
+	r, err := gzip.NewReader(rdr)
+	PanicOnErr(err)
+	return MakeIOReader(r)
+}
+
+func gzipWriter(w io.Writer, opts Map) Object {
+	gw, err := gzip.NewWriterLevel(w, level(opts))
+	PanicOnErr(err)
+	if ok, name := opts.Get(MakeKeyword("name")); ok && !name.Equals(NIL) {
+		gw.Name = AssertString(name, "name must be a string").S
+	}
+	if ok, comment := opts.Get(MakeKeyword("comment")); ok && !comment.Equals(NIL) {
+		gw.Comment = AssertString(comment, "comment must be a string").S
+	}
+	return MakeIOWriter(gw)
+}
+
+func zlibReader(rdr io.Reader) Object {
+	r, err := zlib.NewReader(rdr)
+	PanicOnErr(err)
+	return MakeIOReader(r)
+}
+
+func zlibWriter(w io.Writer, opts Map) Object {
+	zw, err := zlib.NewWriterLevel(w, level(opts))
+	PanicOnErr(err)
+	return MakeIOWriter(zw)
+}
+
+func flateReader(rdr io.Reader) Object {
+	return MakeIOReader(flate.NewReader(rdr))
+}
+
+func flateWriter(w io.Writer, opts Map) Object {
+	fw, err := flate.NewWriter(w, level(opts))
+	PanicOnErr(err)
+	return MakeIOWriter(fw)
+}
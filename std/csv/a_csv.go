@@ -9,18 +9,18 @@ import (
 var __csv_seq__P ProcFn = __csv_seq_
 var csv_seq_ Proc = Proc{Fn: __csv_seq__P, Name: "csv_seq_", Package: "std/csv"}
 
-func __csv_seq_(_args []Object) Object {
+func __csv_seq_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
 		rdr := ExtractObject(_args, 0)
-		_res := csvSeqOpts(rdr, EmptyArrayMap())
+		_res := csvSeqOpts(_rt, rdr, EmptyArrayMap())
 		return _res
 
 	case _c == 2:
 		rdr := ExtractObject(_args, 0)
 		opts := ExtractMap(_args, 1)
-		_res := csvSeqOpts(rdr, opts)
+		_res := csvSeqOpts(_rt, rdr, opts)
 		return _res
 
 	default:
@@ -32,7 +32,7 @@ func __csv_seq_(_args []Object) Object {
 var __write__P ProcFn = __write_
 var write_ Proc = Proc{Fn: __write__P, Name: "write_", Package: "std/csv"}
 
-func __write_(_args []Object) Object {
+func __write_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -57,7 +57,7 @@ func __write_(_args []Object) Object {
 var __write_string__P ProcFn = __write_string_
 var write_string_ Proc = Proc{Fn: __write_string__P, Name: "write_string_", Package: "std/csv"}
 
-func __write_string_(_args []Object) Object {
+func __write_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
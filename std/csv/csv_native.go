@@ -8,19 +8,19 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
-func csvLazySeq(rdr *csv.Reader) *LazySeq {
-	var c = func(args []Object) Object {
+func csvLazySeq(rt *Runtime, rdr *csv.Reader) *LazySeq {
+	var c = func(rt *Runtime, args []Object) Object {
 		t, err := rdr.Read()
 		if err == io.EOF {
 			return EmptyList
 		}
 		PanicOnErr(err)
-		return NewConsSeq(MakeStringVector(t), csvLazySeq(rdr))
+		return NewConsSeq(MakeStringVector(t), csvLazySeq(rt, rdr))
 	}
-	return NewLazySeq(Proc{Fn: c})
+	return NewLazySeq(rt, Proc{Fn: c})
 }
 
-func csvSeqOpts(src Object, opts Map) Object {
+func csvSeqOpts(rt *Runtime, src Object, opts Map) Object {
 	var rdr io.Reader
 	switch src := src.(type) {
 	case String:
@@ -28,7 +28,7 @@ func csvSeqOpts(src Object, opts Map) Object {
 	case io.Reader:
 		rdr = src
 	default:
-		panic(RT.NewError("src must be a string or io.Reader"))
+		panic(rt.NewError("src must be a string or io.Reader"))
 	}
 	csvReader := csv.NewReader(rdr)
 	csvReader.ReuseRecord = true
@@ -47,7 +47,7 @@ func csvSeqOpts(src Object, opts Map) Object {
 	if ok, c := opts.Get(MakeKeyword("trim-leading-space")); ok {
 		csvReader.TrimLeadingSpace = AssertBoolean(c, "trim-leading-space must be a boolean").B
 	}
-	return csvLazySeq(csvReader)
+	return csvLazySeq(rt, csvReader)
 }
 
 func sliceOfStrings(obj Object) (res []string) {
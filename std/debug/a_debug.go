@@ -0,0 +1,94 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package debug
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __break_BANG__P ProcFn = __break_BANG_
+var break_BANG_ Proc = Proc{Fn: __break_BANG__P, Name: "break_BANG_", Package: "std/debug"}
+
+func __break_BANG_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 0:
+		DebugBreak(_rt)
+		_res := NIL
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __breakpoints__P ProcFn = __breakpoints_
+var breakpoints_ Proc = Proc{Fn: __breakpoints__P, Name: "breakpoints_", Package: "std/debug"}
+
+func __breakpoints_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 0:
+		_res := breakpoints()
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __clear_breakpoints__P ProcFn = __clear_breakpoints_
+var clear_breakpoints_ Proc = Proc{Fn: __clear_breakpoints__P, Name: "clear_breakpoints_", Package: "std/debug"}
+
+func __clear_breakpoints_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 0:
+		ClearBreakpoints("")
+		_res := NIL
+		return _res
+
+	case _c == 1:
+		filename := ExtractString(_args, 0)
+		ClearBreakpoints(filename)
+		_res := NIL
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __set_breakpoint__P ProcFn = __set_breakpoint_
+var set_breakpoint_ Proc = Proc{Fn: __set_breakpoint__P, Name: "set_breakpoint_", Package: "std/debug"}
+
+func __set_breakpoint_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		filename := ExtractString(_args, 0)
+		line := ExtractInt(_args, 1)
+		UseTerminalDebugger()
+		SetBreakpoint(filename, line)
+		_res := NIL
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var debugNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.debug"))
+
+func init() {
+	debugNamespace.Lazy = Init
+}
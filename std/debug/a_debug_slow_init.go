@@ -0,0 +1,46 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package debug
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of debug.InternsOrThunks().")
+	}
+	debugNamespace.ResetMeta(MakeMeta(nil, `Provides a step debugger for Joker programs.
+
+         Evaluation pauses at breakpoints (set with set-breakpoint or
+         the --debug-break command line option) and at calls to break!.
+         When paused, the debugger reads commands from stdin and prints
+         to stderr. Enter help at the debug> prompt for the list of commands.`, "1.0"))
+
+	debugNamespace.InternVar("break!", break_BANG_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom()),
+			`Pauses evaluation and starts the debugger at the point of the call.
+  Returns nil once evaluation is resumed.`, "1.0"))
+
+	debugNamespace.InternVar("breakpoints", breakpoints_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom()),
+			`Returns a map of absolute filenames to vectors of line numbers with breakpoints.`, "1.0"))
+
+	debugNamespace.InternVar("clear-breakpoints", clear_breakpoints_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(), NewVectorFrom(MakeSymbol("filename"))),
+			`Removes all breakpoints in the file, or all breakpoints if file is not specified.
+  Returns nil.`, "1.0"))
+
+	debugNamespace.InternVar("set-breakpoint", set_breakpoint_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("filename"), MakeSymbol("line"))),
+			`Sets a breakpoint at the given line of the file. Evaluation pauses
+  before evaluating the first expression that starts on that line.
+  Returns nil.`, "1.0"))
+
+}
@@ -0,0 +1,17 @@
+package debug
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+func breakpoints() Map {
+	res := EmptyArrayMap()
+	for filename, lines := range Breakpoints() {
+		v := EmptyVector()
+		for _, line := range lines {
+			v = v.Conjoin(MakeInt(line))
+		}
+		res.Add(MakeString(filename), v)
+	}
+	return res
+}
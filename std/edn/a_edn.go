@@ -0,0 +1,41 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package edn
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __read_string__P ProcFn = __read_string_
+var read_string_ Proc = Proc{Fn: __read_string__P, Name: "read_string_", Package: "std/edn"}
+
+func __read_string_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		s := ExtractString(_args, 0)
+		_res := readString(_rt, s, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		s := ExtractString(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := readString(_rt, s, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var ednNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.edn"))
+
+func init() {
+	ednNamespace.Lazy = Init
+}
@@ -0,0 +1,43 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package edn
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of edn.InternsOrThunks().")
+	}
+	ednNamespace.ResetMeta(MakeMeta(nil, `Reads data in EDN format (https://github.com/edn-format/edn).
+
+         Unlike joker.core/read-string, tagged literals are read with the functions
+         passed in opts rather than with *default-data-readers*, so reading
+         untrusted data doesn't depend on (and doesn't affect) global state.`, "1.0"))
+
+	ednNamespace.InternVar("read-string", read_string_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s")), NewVectorFrom(MakeSymbol("s"), MakeSymbol("opts"))),
+			`Reads one object from the string s. Returns nil (or the value of :eof option)
+  if s contains no objects.
+  Optional opts map may have the following keys:
+  - readers (map of tag symbols to functions of one argument, the tagged value,
+    which return the value the tagged literal is read as)
+  - default (function of two arguments, the tag symbol and the tagged value,
+    called for tags that have no reader function; if not provided, an error
+    is thrown for such tags)
+  - eof (value to return if s contains no objects).
+  #inst (read as Time), #uuid (read as UUID) and #bytes (read as Bytes) tags
+  are supported by default and can be overridden with readers.
+
+  Example:
+
+  user=> (joker.edn/read-string "#point [1 2]" {:readers {'point (fn [[x y]] {:x x :y y})}})
+  {:x 1, :y 2}
+  user=> (joker.edn/read-string "#unknown 1" {:default (fn [tag value] [tag value])})
+  [unknown 1]`, "1.0"))
+
+}
@@ -0,0 +1,65 @@
+package edn
+
+import (
+	"io"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+func readInst(obj Object) Object {
+	s := AssertString(obj, "#inst literal must be a string").S
+	if t, ok := ParseInst(s); ok {
+		return t
+	}
+	panic(RT.NewError("Invalid #inst literal: " + s))
+}
+
+func readUUID(obj Object) Object {
+	s := AssertString(obj, "#uuid literal must be a string").S
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(RT.NewError("Invalid #uuid literal: " + s))
+	}
+	return u
+}
+
+func readString(rt *Runtime, s string, opts Map) Object {
+	reader := NewReader(strings.NewReader(s), "<edn>")
+	readers := Map(EmptyArrayMap())
+	if ok, r := opts.Get(MakeKeyword("readers")); ok && !r.Equals(NIL) {
+		readers = AssertMap(r, "readers must be a map")
+	}
+	var defaultReader Callable
+	if ok, d := opts.Get(MakeKeyword("default")); ok && !d.Equals(NIL) {
+		defaultReader = AssertCallable(d, "default must be a function")
+	}
+	reader.SetTagReader(func(tag Symbol, obj Object) Object {
+		if ok, f := readers.Get(tag); ok {
+			return AssertCallable(f, "Reader function for tag "+tag.ToString(false)+" must be callable").Call(rt, []Object{obj})
+		}
+		switch tag.ToString(false) {
+		case "inst":
+			return readInst(obj)
+		case "uuid":
+			return readUUID(obj)
+		case "bytes":
+			b, err := ParseBytesLiteral(AssertString(obj, "#bytes literal must be a string").S)
+			PanicOnErr(err)
+			return b
+		}
+		if defaultReader != nil {
+			return defaultReader.Call(rt, []Object{tag, obj})
+		}
+		panic(MakeReadError(reader, "No reader function for tag "+tag.ToString(false)))
+	})
+	obj, err := TryRead(rt, reader)
+	if err == io.EOF {
+		if ok, eof := opts.Get(MakeKeyword("eof")); ok {
+			return eof
+		}
+		return NIL
+	}
+	PanicOnErr(err)
+	return obj
+}
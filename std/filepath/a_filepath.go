@@ -12,7 +12,7 @@ var separator_ String
 var __abs__P ProcFn = __abs_
 var abs_ Proc = Proc{Fn: __abs__P, Name: "abs_", Package: "std/filepath"}
 
-func __abs_(_args []Object) Object {
+func __abs_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -30,7 +30,7 @@ func __abs_(_args []Object) Object {
 var __isabs__P ProcFn = __isabs_
 var isabs_ Proc = Proc{Fn: __isabs__P, Name: "isabs_", Package: "std/filepath"}
 
-func __isabs_(_args []Object) Object {
+func __isabs_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -47,7 +47,7 @@ func __isabs_(_args []Object) Object {
 var __base__P ProcFn = __base_
 var base_ Proc = Proc{Fn: __base__P, Name: "base_", Package: "std/filepath"}
 
-func __base_(_args []Object) Object {
+func __base_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -64,7 +64,7 @@ func __base_(_args []Object) Object {
 var __clean__P ProcFn = __clean_
 var clean_ Proc = Proc{Fn: __clean__P, Name: "clean_", Package: "std/filepath"}
 
-func __clean_(_args []Object) Object {
+func __clean_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -81,7 +81,7 @@ func __clean_(_args []Object) Object {
 var __dir__P ProcFn = __dir_
 var dir_ Proc = Proc{Fn: __dir__P, Name: "dir_", Package: "std/filepath"}
 
-func __dir_(_args []Object) Object {
+func __dir_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -98,7 +98,7 @@ func __dir_(_args []Object) Object {
 var __eval_symlinks__P ProcFn = __eval_symlinks_
 var eval_symlinks_ Proc = Proc{Fn: __eval_symlinks__P, Name: "eval_symlinks_", Package: "std/filepath"}
 
-func __eval_symlinks_(_args []Object) Object {
+func __eval_symlinks_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -116,7 +116,7 @@ func __eval_symlinks_(_args []Object) Object {
 var __ext__P ProcFn = __ext_
 var ext_ Proc = Proc{Fn: __ext__P, Name: "ext_", Package: "std/filepath"}
 
-func __ext_(_args []Object) Object {
+func __ext_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -133,7 +133,7 @@ func __ext_(_args []Object) Object {
 var __file_seq__P ProcFn = __file_seq_
 var file_seq_ Proc = Proc{Fn: __file_seq__P, Name: "file_seq_", Package: "std/filepath"}
 
-func __file_seq_(_args []Object) Object {
+func __file_seq_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -150,7 +150,7 @@ func __file_seq_(_args []Object) Object {
 var __from_slash__P ProcFn = __from_slash_
 var from_slash_ Proc = Proc{Fn: __from_slash__P, Name: "from_slash_", Package: "std/filepath"}
 
-func __from_slash_(_args []Object) Object {
+func __from_slash_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -167,7 +167,7 @@ func __from_slash_(_args []Object) Object {
 var __glob__P ProcFn = __glob_
 var glob_ Proc = Proc{Fn: __glob__P, Name: "glob_", Package: "std/filepath"}
 
-func __glob_(_args []Object) Object {
+func __glob_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -185,7 +185,7 @@ func __glob_(_args []Object) Object {
 var __join__P ProcFn = __join_
 var join_ Proc = Proc{Fn: __join__P, Name: "join_", Package: "std/filepath"}
 
-func __join_(_args []Object) Object {
+func __join_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case true:
@@ -203,7 +203,7 @@ func __join_(_args []Object) Object {
 var __ismatches__P ProcFn = __ismatches_
 var ismatches_ Proc = Proc{Fn: __ismatches__P, Name: "ismatches_", Package: "std/filepath"}
 
-func __ismatches_(_args []Object) Object {
+func __ismatches_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -222,7 +222,7 @@ func __ismatches_(_args []Object) Object {
 var __rel__P ProcFn = __rel_
 var rel_ Proc = Proc{Fn: __rel__P, Name: "rel_", Package: "std/filepath"}
 
-func __rel_(_args []Object) Object {
+func __rel_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -241,7 +241,7 @@ func __rel_(_args []Object) Object {
 var __split__P ProcFn = __split_
 var split_ Proc = Proc{Fn: __split__P, Name: "split_", Package: "std/filepath"}
 
-func __split_(_args []Object) Object {
+func __split_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -259,7 +259,7 @@ func __split_(_args []Object) Object {
 var __split_list__P ProcFn = __split_list_
 var split_list_ Proc = Proc{Fn: __split_list__P, Name: "split_list_", Package: "std/filepath"}
 
-func __split_list_(_args []Object) Object {
+func __split_list_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -276,7 +276,7 @@ func __split_list_(_args []Object) Object {
 var __to_slash__P ProcFn = __to_slash_
 var to_slash_ Proc = Proc{Fn: __to_slash__P, Name: "to_slash_", Package: "std/filepath"}
 
-func __to_slash_(_args []Object) Object {
+func __to_slash_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -293,7 +293,7 @@ func __to_slash_(_args []Object) Object {
 var __volume_name__P ProcFn = __volume_name_
 var volume_name_ Proc = Proc{Fn: __volume_name__P, Name: "volume_name_", Package: "std/filepath"}
 
-func __volume_name_(_args []Object) Object {
+func __volume_name_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -7,10 +7,28 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
+var __decode_bytes__P ProcFn = __decode_bytes_
+var decode_bytes_ Proc = Proc{Fn: __decode_bytes__P, Name: "decode_bytes_", Package: "std/hex"}
+
+func __decode_bytes_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		s := ExtractString(_args, 0)
+		_res, err := hex.DecodeString(s)
+		PanicOnErr(err)
+		return MakeBytes(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 var __decode_string__P ProcFn = __decode_string_
 var decode_string_ Proc = Proc{Fn: __decode_string__P, Name: "decode_string_", Package: "std/hex"}
 
-func __decode_string_(_args []Object) Object {
+func __decode_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -29,12 +47,12 @@ func __decode_string_(_args []Object) Object {
 var __encode_string__P ProcFn = __encode_string_
 var encode_string_ Proc = Proc{Fn: __encode_string__P, Name: "encode_string_", Package: "std/hex"}
 
-func __encode_string_(_args []Object) Object {
+func __encode_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
-		s := ExtractString(_args, 0)
-		_res := hex.EncodeToString([]byte(s))
+		s := ExtractBinary(_args, 0)
+		_res := hex.EncodeToString(s)
 		return MakeString(_res)
 
 	default:
@@ -14,6 +14,11 @@ func InternsOrThunks() {
 	}
 	hexNamespace.ResetMeta(MakeMeta(nil, `Implements hexadecimal encoding and decoding.`, "1.0"))
 
+	hexNamespace.InternVar("decode-bytes", decode_bytes_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
+			`Like decode-string, but returns Bytes.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Bytes"}))
+
 	hexNamespace.InternVar("decode-string", decode_string_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
@@ -22,6 +27,6 @@ func InternsOrThunks() {
 	hexNamespace.InternVar("encode-string", encode_string_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
-			`Returns the hexadecimal encoding of s.`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
+			`Returns the hexadecimal encoding of s (String or Bytes).`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
 
 }
@@ -10,7 +10,7 @@ import (
 var __escape__P ProcFn = __escape_
 var escape_ Proc = Proc{Fn: __escape__P, Name: "escape_", Package: "std/html"}
 
-func __escape_(_args []Object) Object {
+func __escape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -27,7 +27,7 @@ func __escape_(_args []Object) Object {
 var __unescape__P ProcFn = __unescape_
 var unescape_ Proc = Proc{Fn: __unescape__P, Name: "unescape_", Package: "std/html"}
 
-func __unescape_(_args []Object) Object {
+func __unescape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
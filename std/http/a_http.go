@@ -6,15 +6,55 @@ import (
 	. "github.com/candid82/joker/core"
 )
 
+var __local_address__P ProcFn = __local_address_
+var local_address_ Proc = Proc{Fn: __local_address__P, Name: "local_address_", Package: "std/http"}
+
+func __local_address_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		server := ExtractHTTPServer(_args, 0)
+		_res := serverAddr(server)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 var __send__P ProcFn = __send_
 var send_ Proc = Proc{Fn: __send__P, Name: "send_", Package: "std/http"}
 
-func __send_(_args []Object) Object {
+func __send_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
 		request := ExtractMap(_args, 0)
-		_res := sendRequest(request)
+		_res := sendRequest(_rt, request)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __shutdown__P ProcFn = __shutdown_
+var shutdown_ Proc = Proc{Fn: __shutdown__P, Name: "shutdown_", Package: "std/http"}
+
+func __shutdown_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		server := ExtractHTTPServer(_args, 0)
+		_res := shutdownServer(server, 0)
+		return _res
+
+	case _c == 2:
+		server := ExtractHTTPServer(_args, 0)
+		timeout := ExtractInt(_args, 1)
+		_res := shutdownServer(server, timeout)
 		return _res
 
 	default:
@@ -26,7 +66,7 @@ func __send_(_args []Object) Object {
 var __start_file_server__P ProcFn = __start_file_server_
 var start_file_server_ Proc = Proc{Fn: __start_file_server__P, Name: "start_file_server_", Package: "std/http"}
 
-func __start_file_server_(_args []Object) Object {
+func __start_file_server_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -44,13 +84,37 @@ func __start_file_server_(_args []Object) Object {
 var __start_server__P ProcFn = __start_server_
 var start_server_ Proc = Proc{Fn: __start_server__P, Name: "start_server_", Package: "std/http"}
 
-func __start_server_(_args []Object) Object {
+func __start_server_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
 		addr := ExtractString(_args, 0)
 		handler := ExtractCallable(_args, 1)
-		_res := startServer(addr, handler)
+		_res := startServer(_rt, addr, handler, nil)
+		return _res
+
+	case _c == 3:
+		addr := ExtractString(_args, 0)
+		handler := ExtractCallable(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := startServer(_rt, addr, handler, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __stop__P ProcFn = __stop_
+var stop_ Proc = Proc{Fn: __stop__P, Name: "stop_", Package: "std/http"}
+
+func __stop_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		server := ExtractHTTPServer(_args, 0)
+		_res := stopServer(server)
 		return _res
 
 	default:
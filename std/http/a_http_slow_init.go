@@ -14,6 +14,13 @@ func InternsOrThunks() {
 	}
 	httpNamespace.ResetMeta(MakeMeta(nil, `Provides HTTP client and server implementations.`, "1.0"))
 
+	httpNamespace.InternVar("local-address", local_address_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("server"))),
+			`Returns the network address server started with start-server listens on.
+  Useful when the port in the address passed to start-server is 0,
+  in which case a free port is chosen.`, "1.0"))
+
 	httpNamespace.InternVar("send", send_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("request"))),
@@ -21,16 +28,39 @@ func InternsOrThunks() {
   request is a map with the following keys:
   - url (string)
   - method (string, keyword or symbol, defaults to :get)
-  - body (string)
+  - body (string, Bytes or IOReader, which is closed after sending if closable)
+  - form-params (map, sent as URL-encoded form body instead of body)
+  - query-params (map, added to url's query string)
   - host (string, overrides Host header if provided)
-  - headers (map).
+  - headers (map)
+  - basic-auth (vector [user password] or string "user:password")
+  - timeout (int, in milliseconds, defaults to no timeout)
+  - follow-redirects (boolean, defaults to true)
+  - max-redirects (int, number of consecutive requests after which redirects
+    are no longer followed and an error is thrown, defaults to 10)
+  - insecure? (boolean, skips TLS certificate verification if true)
+  - ca-file (string, path to PEM file with CA certificates to verify the server with)
+  - cert-file, key-file (strings, paths to PEM files with client certificate and key;
+    key-file defaults to cert-file)
+  - as (:string, :bytes, :stream or :json, defaults to :string).
   All keys except for url are optional.
+  Values of form-params and query-params may be vectors to send multiple values.
   response is a map with the following keys:
   - status (int)
-  - body (string)
+  - body (string, Bytes if :as is :bytes, IOReader if :as is :stream, or data decoded from JSON
+    with keys converted to keywords if :as is :json;
+    IOReader body must be closed with joker.io/close)
   - headers (map)
   - content-length (int)`, "1.0"))
 
+	httpNamespace.InternVar("shutdown", shutdown_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("server")), NewVectorFrom(MakeSymbol("server"), MakeSymbol("timeout"))),
+			`Gracefully shuts down server started with start-server: closes its
+  listeners and waits for active connections to become idle, but no longer
+  than timeout (in nanoseconds) if provided. Throws an error if
+  the timeout expires first.`, "1.0"))
+
 	httpNamespace.InternVar("start-file-server", start_file_server_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("addr"), MakeSymbol("root"))),
@@ -39,7 +69,40 @@ func InternsOrThunks() {
 
 	httpNamespace.InternVar("start-server", start_server_,
 		MakeMeta(
-			NewListFrom(NewVectorFrom(MakeSymbol("addr"), MakeSymbol("handler"))),
-			`Starts HTTP server on the TCP network address addr.`, "1.0"))
+			NewListFrom(NewVectorFrom(MakeSymbol("addr"), MakeSymbol("handler")), NewVectorFrom(MakeSymbol("addr"), MakeSymbol("handler"), MakeSymbol("opts"))),
+			`Starts HTTP server on the TCP network address addr and returns the server,
+  which can be stopped with stop or shutdown.
+  handler is called with a request map with the following keys:
+  - request-method (keyword)
+  - uri (string)
+  - query-string (string)
+  - body (IOReader)
+  - headers (map with lower case header names)
+  - server-name, server-port, remote-addr, protocol (strings)
+  - scheme (:http or :https).
+  handler must return a response map with the following keys:
+  - status (int, defaults to 200)
+  - headers (map, values are strings or seqs of strings)
+  - body (string, Bytes, seq whose elements are written as strings and
+    flushed one by one, IOReader or File; IOReader and File are closed
+    after being written).
+  If response map has websocket key, the connection is upgraded to websocket
+  (see joker.websocket) instead, and its value, which must be a function,
+  is called with the websocket connection. websocket-opts key may provide
+  options map for the connection (buffer, ping-interval, max-message-size;
+  see joker.websocket/connect). Requests that are not websocket handshake
+  requests get 400 response in this case.
+  Optional opts map may have the following keys:
+  - join? (boolean, defaults to true; if true, start-server blocks
+    until the server is stopped, otherwise it returns immediately)
+  - read-timeout, write-timeout, idle-timeout (ints, durations in nanoseconds)
+  - cert-file, key-file (strings, paths to PEM files with TLS certificate and key;
+    key-file defaults to cert-file). The server uses HTTPS if cert-file is provided.`, "1.0"))
+
+	httpNamespace.InternVar("stop", stop_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("server"))),
+			`Immediately closes all listeners and connections of server
+  started with start-server.`, "1.0"))
 
 }
@@ -1,17 +1,39 @@
 package http
 
 import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	. "github.com/candid82/joker/core"
 )
 
-var client = &http.Client{}
+type clientOptions struct {
+	timeout         int
+	followRedirects bool
+	maxRedirects    int
+	insecure        bool
+	caFile          string
+	certFile        string
+	keyFile         string
+}
+
+var (
+	client = &http.Client{}
+
+	// Clients for requests with client options, reused so that
+	// their connections are kept alive and reused as well.
+	clients   = map[clientOptions]*http.Client{}
+	clientsMu sync.Mutex
+)
 
 func extractMethod(request Map) string {
 	if ok, m := request.Get(MakeKeyword("method")); ok {
@@ -36,15 +58,95 @@ func getOrPanic(m Map, k Object, errMsg string) Object {
 	panic(RT.NewError(errMsg))
 }
 
+func paramName(obj Object) string {
+	switch obj := obj.(type) {
+	case String:
+		return obj.S
+	case Keyword:
+		return obj.ToString(false)[1:]
+	case Symbol:
+		return obj.ToString(false)
+	default:
+		panic(RT.NewError("param name must be a string, keyword or symbol, got " + obj.GetType().ToString(false)))
+	}
+}
+
+// mapToValues encodes params map. Values that are vectors or
+// other sequences produce multiple values for the same name.
+func mapToValues(params Map, values url.Values) url.Values {
+	for iter := params.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		name := paramName(p.Key)
+		switch v := p.Value.(type) {
+		case String:
+			values.Add(name, v.S)
+		case Keyword:
+			values.Add(name, v.ToString(false)[1:])
+		case Seqable:
+			for s := v.Seq(); !s.IsEmpty(); s = s.Rest() {
+				values.Add(name, s.First().ToString(false))
+			}
+		default:
+			values.Add(name, v.ToString(false))
+		}
+	}
+	return values
+}
+
+func extractBody(b Object) io.Reader {
+	switch b := b.(type) {
+	case String:
+		return strings.NewReader(b.S)
+	case *Bytes:
+		return bytes.NewReader(b.B)
+	case io.Reader:
+		return b
+	default:
+		panic(RT.NewError("body must be a string, Bytes or IOReader, got " + b.GetType().ToString(false)))
+	}
+}
+
+func extractBasicAuth(auth Object) (string, string) {
+	switch auth := auth.(type) {
+	case String:
+		if i := strings.IndexByte(auth.S, ':'); i != -1 {
+			return auth.S[:i], auth.S[i+1:]
+		}
+	case *Vector:
+		if auth.Count() == 2 {
+			return AssertString(auth.Nth(0), "basic-auth user must be a string").S, AssertString(auth.Nth(1), "basic-auth password must be a string").S
+		}
+	}
+	panic(RT.NewError("basic-auth must be a vector [user password] or a string \"user:password\""))
+}
+
 func mapToReq(request Map) *http.Request {
 	method := strings.ToUpper(extractMethod(request))
-	url := AssertString(getOrPanic(request, MakeKeyword("url"), ":url key must be present in request map"), "url must be a string").S
+	reqURL := AssertString(getOrPanic(request, MakeKeyword("url"), ":url key must be present in request map"), "url must be a string").S
 	var reqBody io.Reader
 	if ok, b := request.Get(MakeKeyword("body")); ok {
-		reqBody = strings.NewReader(AssertString(b, "body must be a string").S)
+		reqBody = extractBody(b)
 	}
-	req, err := http.NewRequest(method, url, reqBody)
+	ok, formParams := request.Get(MakeKeyword("form-params"))
+	if ok {
+		if reqBody != nil {
+			panic(RT.NewError("request cannot have both body and form-params"))
+		}
+		form := mapToValues(AssertMap(formParams, "form-params must be a map"), url.Values{})
+		reqBody = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, reqURL, reqBody)
 	PanicOnErr(err)
+	if ok, queryParams := request.Get(MakeKeyword("query-params")); ok {
+		query := mapToValues(AssertMap(queryParams, "query-params must be a map"), req.URL.Query())
+		req.URL.RawQuery = query.Encode()
+	}
+	if formParams != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if ok, auth := request.Get(MakeKeyword("basic-auth")); ok {
+		req.SetBasicAuth(extractBasicAuth(auth))
+	}
 	if ok, headers := request.Get(MakeKeyword("headers")); ok {
 		h := AssertMap(headers, "headers must be a map")
 		for iter := h.Iter(); iter.HasNext(); {
@@ -58,20 +160,17 @@ func mapToReq(request Map) *http.Request {
 	return req
 }
 
-func reqToMap(host String, port String, req *http.Request) Map {
-	defer req.Body.Close()
+func reqToMap(host String, port String, scheme Keyword, req *http.Request) Map {
 	res := EmptyArrayMap()
-	body, err := ioutil.ReadAll(req.Body)
-	PanicOnErr(err)
 	res.Add(MakeKeyword("request-method"), MakeKeyword(strings.ToLower(req.Method)))
-	res.Add(MakeKeyword("body"), MakeString(string(body)))
+	res.Add(MakeKeyword("body"), MakeIOReader(req.Body))
 	res.Add(MakeKeyword("uri"), MakeString(req.URL.Path))
 	res.Add(MakeKeyword("query-string"), MakeString(req.URL.RawQuery))
 	res.Add(MakeKeyword("server-name"), host)
 	res.Add(MakeKeyword("server-port"), port)
 	res.Add(MakeKeyword("remote-addr"), MakeString(req.RemoteAddr[:strings.LastIndexByte(req.RemoteAddr, byte(':'))]))
 	res.Add(MakeKeyword("protocol"), MakeString(req.Proto))
-	res.Add(MakeKeyword("scheme"), MakeKeyword("http"))
+	res.Add(MakeKeyword("scheme"), scheme)
 	headers := EmptyArrayMap()
 	for k, v := range req.Header {
 		headers.Add(MakeString(strings.ToLower(k)), MakeString(strings.Join(v, ",")))
@@ -80,12 +179,30 @@ func reqToMap(host String, port String, req *http.Request) Map {
 	return res
 }
 
-func respToMap(resp *http.Response) Map {
-	defer resp.Body.Close()
+func decodeJSON(rt *Runtime, body []byte) Object {
+	readString := GLOBAL_ENV.FindNamespace(MakeSymbol("joker.json")).Resolve("read-string")
+	opts := EmptyArrayMap()
+	opts.Add(MakeKeyword("keywords?"), Boolean{B: true})
+	return readString.Call(rt, []Object{MakeString(string(body)), opts})
+}
+
+func respToMap(rt *Runtime, resp *http.Response, as string) Map {
 	res := EmptyArrayMap()
-	body, err := ioutil.ReadAll(resp.Body)
-	PanicOnErr(err)
-	res.Add(MakeKeyword("body"), MakeString(string(body)))
+	if as == "stream" {
+		res.Add(MakeKeyword("body"), MakeIOReader(resp.Body))
+	} else {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		PanicOnErr(err)
+		switch as {
+		case "json":
+			res.Add(MakeKeyword("body"), decodeJSON(rt, body))
+		case "bytes":
+			res.Add(MakeKeyword("body"), MakeBytes(body))
+		default:
+			res.Add(MakeKeyword("body"), MakeString(string(body)))
+		}
+	}
 	res.Add(MakeKeyword("status"), MakeInt(resp.StatusCode))
 	respHeaders := EmptyArrayMap()
 	for k, v := range resp.Header {
@@ -102,10 +219,6 @@ func mapToResp(response Map, w http.ResponseWriter) {
 	if ok, s := response.Get(MakeKeyword("status")); ok {
 		status = AssertInt(s, "HTTP response status must be an integer").I
 	}
-	body := ""
-	if ok, b := response.Get(MakeKeyword("body")); ok {
-		body = AssertString(b, "HTTP response body must be a string").S
-	}
 	if ok, headers := response.Get(MakeKeyword("headers")); ok {
 		header := w.Header()
 		h := AssertMap(headers, "HTTP response headers must be a map")
@@ -129,42 +242,155 @@ func mapToResp(response Map, w http.ResponseWriter) {
 	if status != 0 {
 		w.WriteHeader(status)
 	}
-	io.WriteString(w, body)
+	if ok, b := response.Get(MakeKeyword("body")); ok {
+		writeBody(b, w)
+	}
 }
 
-func sendRequest(request Map) Map {
-	req := mapToReq(request)
-	RT.GIL.Unlock()
-	resp, err := client.Do(req)
-	RT.GIL.Lock()
-	PanicOnErr(err)
-	return respToMap(resp)
-}
-
-func startServer(addr string, handler Callable) Object {
-	i := strings.LastIndexByte(addr, byte(':'))
-	host, port := MakeString(addr), MakeString("")
-	if i != -1 {
-		host = MakeString(addr[:i])
-		port = MakeString(addr[i+1:])
-	}
-	RT.GIL.Unlock()
-	defer RT.GIL.Lock()
-	err := http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		RT.GIL.Lock()
-		defer func() {
-			RT.GIL.Unlock()
-			if r := recover(); r != nil {
-				w.WriteHeader(500)
-				io.WriteString(w, "Internal server error")
-				fmt.Fprintln(os.Stderr, r)
+// writeBody writes HTTP response body, which can be a string, Bytes,
+// a seq (whose elements are written as strings and flushed one by one),
+// an IOReader or a File (which is closed afterwards).
+func writeBody(body Object, w http.ResponseWriter) {
+	switch body := body.(type) {
+	case Nil:
+	case String:
+		io.WriteString(w, body.S)
+	case *Bytes:
+		w.Write(body.B)
+	case io.Reader:
+		if c, ok := body.(io.Closer); ok {
+			defer c.Close()
+		}
+		_, err := io.Copy(w, body)
+		PanicOnErr(err)
+	case Seqable:
+		flusher, _ := w.(http.Flusher)
+		for s := body.Seq(); !s.IsEmpty(); s = s.Rest() {
+			io.WriteString(w, s.First().ToString(false))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	default:
+		panic(RT.NewError("HTTP response body must be a string, Bytes, seq, IOReader or File, got " + body.GetType().ToString(false)))
+	}
+}
+
+func extractAs(request Map) string {
+	if ok, as := request.Get(MakeKeyword("as")); ok {
+		if k, ok := as.(Keyword); ok {
+			switch name := k.ToString(false)[1:]; name {
+			case "string", "bytes", "stream", "json":
+				return name
 			}
-		}()
-		response := handler.Call([]Object{reqToMap(host, port, req)})
-		mapToResp(AssertMap(response, "HTTP response must be a map"), w)
-	}))
+		}
+		panic(RT.NewError("as must be :string, :bytes, :stream or :json, got " + as.ToString(true)))
+	}
+	return "string"
+}
+
+func getBool(m Map, k string, def bool) bool {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return ToBool(v)
+	}
+	return def
+}
+
+func getString(m Map, k string) string {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return AssertString(v, k+" must be a string").S
+	}
+	return ""
+}
+
+func tlsConfig(opts clientOptions) *tls.Config {
+	if !opts.insecure && opts.caFile == "" && opts.certFile == "" {
+		return nil
+	}
+	config := &tls.Config{InsecureSkipVerify: opts.insecure}
+	if opts.caFile != "" {
+		pem, err := ioutil.ReadFile(opts.caFile)
+		PanicOnErr(err)
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			panic(RT.NewError("No certificates found in " + opts.caFile))
+		}
+	}
+	if opts.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.certFile, opts.keyFile)
+		PanicOnErr(err)
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config
+}
+
+func extractClientOptions(request Map) clientOptions {
+	opts := clientOptions{
+		followRedirects: getBool(request, "follow-redirects", true),
+		maxRedirects:    10,
+		insecure:        getBool(request, "insecure?", false),
+		caFile:          getString(request, "ca-file"),
+		certFile:        getString(request, "cert-file"),
+		keyFile:         getString(request, "key-file"),
+	}
+	if ok, t := request.Get(MakeKeyword("timeout")); ok {
+		opts.timeout = AssertInt(t, "timeout must be an integer").I
+	}
+	if ok, m := request.Get(MakeKeyword("max-redirects")); ok {
+		opts.maxRedirects = AssertInt(m, "max-redirects must be an integer").I
+	}
+	if opts.keyFile == "" {
+		opts.keyFile = opts.certFile
+	}
+	return opts
+}
+
+func newClient(opts clientOptions) *http.Client {
+	c := &http.Client{Timeout: time.Duration(opts.timeout) * time.Millisecond}
+	if !opts.followRedirects {
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	} else if opts.maxRedirects != 10 {
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= opts.maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.maxRedirects)
+			}
+			return nil
+		}
+	}
+	if config := tlsConfig(opts); config != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config
+		c.Transport = transport
+	}
+	return c
+}
+
+// requestClient returns the client to send request with,
+// which is the shared one unless request has client options.
+// Clients are created once for each set of options.
+func requestClient(request Map) *http.Client {
+	opts := extractClientOptions(request)
+	if opts == (clientOptions{followRedirects: true, maxRedirects: 10}) {
+		return client
+	}
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	c, ok := clients[opts]
+	if !ok {
+		c = newClient(opts)
+		clients[opts] = c
+	}
+	return c
+}
+
+func sendRequest(rt *Runtime, request Map) Map {
+	as := extractAs(request)
+	req := mapToReq(request)
+	resp, err := requestClient(request).Do(req)
 	PanicOnErr(err)
-	return NIL
+	return respToMap(rt, resp, as)
 }
 
 func startFileServer(addr string, root string) Object {
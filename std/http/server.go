@@ -0,0 +1,227 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	. "github.com/candid82/joker/core"
+	"github.com/candid82/joker/std/websocket"
+)
+
+type (
+	HTTPServer struct {
+		*http.Server
+		hash uint32
+	}
+	// responseWriter records whether the response has been started,
+	// after which an error can no longer be reported with a 500 response.
+	responseWriter struct {
+		http.ResponseWriter
+		started bool
+	}
+)
+
+var httpServerType *Type
+
+func MakeHTTPServer(server *http.Server) HTTPServer {
+	res := HTTPServer{server, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(server)))
+	return res
+}
+
+func (server HTTPServer) ToString(escape bool) string {
+	return "#object[HTTPServer]"
+}
+
+func (server HTTPServer) Equals(other interface{}) bool {
+	if otherServer, ok := other.(HTTPServer); ok {
+		return server.Server == otherServer.Server
+	}
+	return false
+}
+
+func (server HTTPServer) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (server HTTPServer) GetType() *Type {
+	return httpServerType
+}
+
+func (server HTTPServer) Hash() uint32 {
+	return server.hash
+}
+
+func (server HTTPServer) WithInfo(info *ObjectInfo) Object {
+	return server
+}
+
+func EnsureHTTPServer(args []Object, index int) HTTPServer {
+	switch c := args[index].(type) {
+	case HTTPServer:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "HTTPServer"))
+	}
+}
+
+func ExtractHTTPServer(args []Object, index int) *http.Server {
+	return EnsureHTTPServer(args, index).Server
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.started = true
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	w.started = true
+	return hijacker.Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func getDuration(m Map, k string) time.Duration {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return time.Duration(AssertInt(v, k+" must be an integer").I)
+	}
+	return 0
+}
+
+func startServer(rt *Runtime, addr string, handler Callable, opts Map) Object {
+	i := strings.LastIndexByte(addr, byte(':'))
+	host, port := MakeString(addr), MakeString("")
+	if i != -1 {
+		host = MakeString(addr[:i])
+		port = MakeString(addr[i+1:])
+	}
+	if opts == nil {
+		opts = EmptyArrayMap()
+	}
+	certFile := getString(opts, "cert-file")
+	keyFile := getString(opts, "key-file")
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	scheme := MakeKeyword("http")
+	if certFile != "" {
+		scheme = MakeKeyword("https")
+	}
+	// Requests are handled in runtimes forked from this one,
+	// so that they get the bindings in effect when the server starts.
+	base := rt.Fork()
+	server := &http.Server{
+		Addr:         addr,
+		ReadTimeout:  getDuration(opts, "read-timeout"),
+		WriteTimeout: getDuration(opts, "write-timeout"),
+		IdleTimeout:  getDuration(opts, "idle-timeout"),
+		Handler: http.HandlerFunc(func(hw http.ResponseWriter, req *http.Request) {
+			w := &responseWriter{ResponseWriter: hw}
+			rt := base.Fork()
+			saved := rt.Save()
+			defer func() {
+				if r := recover(); r != nil {
+					rt.Recovered(r, saved)
+					_, _, stderr := GLOBAL_ENV.StdIO(rt)
+					fmt.Fprintln(Assertio_Writer(stderr, ""), r)
+					if w.started {
+						// Too late to change the status: drop the connection
+						// so that the client doesn't take the response as complete.
+						panic(http.ErrAbortHandler)
+					}
+					http.Error(w, "Internal server error", 500)
+				}
+			}()
+			response := AssertMap(handler.Call(rt, []Object{reqToMap(host, port, scheme, req)}), "HTTP response must be a map")
+			if ok, onOpen := response.Get(MakeKeyword("websocket")); ok {
+				var wsOpts Map
+				if ok, o := response.Get(MakeKeyword("websocket-opts")); ok {
+					wsOpts = AssertMap(o, "websocket-opts must be a map")
+				}
+				websocket.Upgrade(rt, w, req, AssertCallable(onOpen, "websocket must be a function"), wsOpts)
+				return
+			}
+			mapToResp(response, w)
+		}),
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		PanicOnErr(err)
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	// Listen before returning, so that the server is ready
+	// to accept connections when it is not joined.
+	ln, err := net.Listen("tcp", addr)
+	PanicOnErr(err)
+	// Port 0 in addr means a free port is chosen, see local-address.
+	server.Addr = ln.Addr().String()
+	serve := func() error {
+		if certFile != "" {
+			return server.ServeTLS(ln, "", "")
+		}
+		return server.Serve(ln)
+	}
+	res := MakeHTTPServer(server)
+	if getBool(opts, "join?", true) {
+		if err := serve(); err != http.ErrServerClosed {
+			PanicOnErr(err)
+		}
+		return res
+	}
+	go func() {
+		if err := serve(); err != http.ErrServerClosed {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	return res
+}
+
+func serverAddr(server *http.Server) Object {
+	return MakeString(server.Addr)
+}
+
+func stopServer(server *http.Server) Object {
+	PanicOnErr(server.Close())
+	return NIL
+}
+
+func shutdownServer(server *http.Server, timeout int) Object {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout))
+		defer cancel()
+	}
+	PanicOnErr(server.Shutdown(ctx))
+	return NIL
+}
+
+func init() {
+	httpServerType = RegType("HTTPServer", (*HTTPServer)(nil), "Wraps HTTP server")
+}
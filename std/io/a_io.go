@@ -10,7 +10,7 @@ import (
 var __close__P ProcFn = __close_
 var close_ Proc = Proc{Fn: __close__P, Name: "close_", Package: "std/io"}
 
-func __close_(_args []Object) Object {
+func __close_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -27,7 +27,7 @@ func __close_(_args []Object) Object {
 var __copy__P ProcFn = __copy_
 var copy_ Proc = Proc{Fn: __copy__P, Name: "copy_", Package: "std/io"}
 
-func __copy_(_args []Object) Object {
+func __copy_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -47,7 +47,7 @@ func __copy_(_args []Object) Object {
 var __pipe__P ProcFn = __pipe_
 var pipe_ Proc = Proc{Fn: __pipe__P, Name: "pipe_", Package: "std/io"}
 
-func __pipe_(_args []Object) Object {
+func __pipe_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -60,6 +60,44 @@ func __pipe_(_args []Object) Object {
 	return NIL
 }
 
+var __read_bytes__P ProcFn = __read_bytes_
+var read_bytes_ Proc = Proc{Fn: __read_bytes__P, Name: "read_bytes_", Package: "std/io"}
+
+func __read_bytes_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		rdr := ExtractIOReader(_args, 0)
+		n := ExtractInt(_args, 1)
+		_res := readBytes(rdr, n)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __write__P ProcFn = __write_
+var write_ Proc = Proc{Fn: __write__P, Name: "write_", Package: "std/io"}
+
+func __write_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		w := ExtractIOWriter(_args, 0)
+		data := ExtractBinary(_args, 1)
+		n, err := w.Write(data)
+		PanicOnErr(err)
+		_res := n
+		return MakeInt(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 func Init() {
 
 	InternsOrThunks()
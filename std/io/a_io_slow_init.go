@@ -34,4 +34,17 @@ func InternsOrThunks() {
   with code expecting an IOWriter.
   Returns a vector [reader, writer].`, "1.0"))
 
+	ioNamespace.InternVar("read-bytes", read_bytes_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("rdr"), MakeSymbol("n"))),
+			`Reads up to n bytes from rdr and returns them as Bytes.
+  Blocks until at least one byte is available.
+  Returns nil if rdr is at EOF.`, "1.0"))
+
+	ioNamespace.InternVar("write", write_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("w"), MakeSymbol("data"))),
+			`Writes data (String or Bytes) to w.
+  Returns the number of bytes written or throws an error.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Int"}))
+
 }
@@ -13,6 +13,23 @@ func pipe() Object {
 	return res
 }
 
+func readBytes(rdr io.Reader, n int) Object {
+	if n < 0 {
+		panic(RT.NewError("n must not be negative"))
+	}
+	b := make([]byte, n)
+	for {
+		c, err := rdr.Read(b)
+		if c > 0 || n == 0 {
+			return MakeBytes(b[:c])
+		}
+		if err == io.EOF {
+			return NIL
+		}
+		PanicOnErr(err)
+	}
+}
+
 func close(f Object) Nil {
 	if c, ok := f.(io.Closer); ok {
 		if err := c.Close(); err != nil {
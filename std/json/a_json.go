@@ -9,7 +9,7 @@ import (
 var __read_string__P ProcFn = __read_string_
 var read_string_ Proc = Proc{Fn: __read_string__P, Name: "read_string_", Package: "std/json"}
 
-func __read_string_(_args []Object) Object {
+func __read_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -32,7 +32,7 @@ func __read_string_(_args []Object) Object {
 var __write_string__P ProcFn = __write_string_
 var write_string_ Proc = Proc{Fn: __write_string__P, Name: "write_string_", Package: "std/json"}
 
-func __write_string_(_args []Object) Object {
+func __write_string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
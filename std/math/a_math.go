@@ -23,7 +23,7 @@ var sqrt_of_pi_ Double
 var __abs__P ProcFn = __abs_
 var abs_ Proc = Proc{Fn: __abs__P, Name: "abs_", Package: "std/math"}
 
-func __abs_(_args []Object) Object {
+func __abs_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -40,7 +40,7 @@ func __abs_(_args []Object) Object {
 var __ceil__P ProcFn = __ceil_
 var ceil_ Proc = Proc{Fn: __ceil__P, Name: "ceil_", Package: "std/math"}
 
-func __ceil_(_args []Object) Object {
+func __ceil_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -57,7 +57,7 @@ func __ceil_(_args []Object) Object {
 var __copy_sign__P ProcFn = __copy_sign_
 var copy_sign_ Proc = Proc{Fn: __copy_sign__P, Name: "copy_sign_", Package: "std/math"}
 
-func __copy_sign_(_args []Object) Object {
+func __copy_sign_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -75,7 +75,7 @@ func __copy_sign_(_args []Object) Object {
 var __cos__P ProcFn = __cos_
 var cos_ Proc = Proc{Fn: __cos__P, Name: "cos_", Package: "std/math"}
 
-func __cos_(_args []Object) Object {
+func __cos_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -92,7 +92,7 @@ func __cos_(_args []Object) Object {
 var __cube_root__P ProcFn = __cube_root_
 var cube_root_ Proc = Proc{Fn: __cube_root__P, Name: "cube_root_", Package: "std/math"}
 
-func __cube_root_(_args []Object) Object {
+func __cube_root_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -109,7 +109,7 @@ func __cube_root_(_args []Object) Object {
 var __dim__P ProcFn = __dim_
 var dim_ Proc = Proc{Fn: __dim__P, Name: "dim_", Package: "std/math"}
 
-func __dim_(_args []Object) Object {
+func __dim_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -127,7 +127,7 @@ func __dim_(_args []Object) Object {
 var __exp__P ProcFn = __exp_
 var exp_ Proc = Proc{Fn: __exp__P, Name: "exp_", Package: "std/math"}
 
-func __exp_(_args []Object) Object {
+func __exp_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -144,7 +144,7 @@ func __exp_(_args []Object) Object {
 var __exp_2__P ProcFn = __exp_2_
 var exp_2_ Proc = Proc{Fn: __exp_2__P, Name: "exp_2_", Package: "std/math"}
 
-func __exp_2_(_args []Object) Object {
+func __exp_2_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -161,7 +161,7 @@ func __exp_2_(_args []Object) Object {
 var __exp_minus_1__P ProcFn = __exp_minus_1_
 var exp_minus_1_ Proc = Proc{Fn: __exp_minus_1__P, Name: "exp_minus_1_", Package: "std/math"}
 
-func __exp_minus_1_(_args []Object) Object {
+func __exp_minus_1_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -178,7 +178,7 @@ func __exp_minus_1_(_args []Object) Object {
 var __floor__P ProcFn = __floor_
 var floor_ Proc = Proc{Fn: __floor__P, Name: "floor_", Package: "std/math"}
 
-func __floor_(_args []Object) Object {
+func __floor_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -195,7 +195,7 @@ func __floor_(_args []Object) Object {
 var __hypot__P ProcFn = __hypot_
 var hypot_ Proc = Proc{Fn: __hypot__P, Name: "hypot_", Package: "std/math"}
 
-func __hypot_(_args []Object) Object {
+func __hypot_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -213,7 +213,7 @@ func __hypot_(_args []Object) Object {
 var __inf__P ProcFn = __inf_
 var inf_ Proc = Proc{Fn: __inf__P, Name: "inf_", Package: "std/math"}
 
-func __inf_(_args []Object) Object {
+func __inf_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -230,7 +230,7 @@ func __inf_(_args []Object) Object {
 var __isinf__P ProcFn = __isinf_
 var isinf_ Proc = Proc{Fn: __isinf__P, Name: "isinf_", Package: "std/math"}
 
-func __isinf_(_args []Object) Object {
+func __isinf_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -248,7 +248,7 @@ func __isinf_(_args []Object) Object {
 var __log__P ProcFn = __log_
 var log_ Proc = Proc{Fn: __log__P, Name: "log_", Package: "std/math"}
 
-func __log_(_args []Object) Object {
+func __log_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -265,7 +265,7 @@ func __log_(_args []Object) Object {
 var __log_10__P ProcFn = __log_10_
 var log_10_ Proc = Proc{Fn: __log_10__P, Name: "log_10_", Package: "std/math"}
 
-func __log_10_(_args []Object) Object {
+func __log_10_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -282,7 +282,7 @@ func __log_10_(_args []Object) Object {
 var __log_2__P ProcFn = __log_2_
 var log_2_ Proc = Proc{Fn: __log_2__P, Name: "log_2_", Package: "std/math"}
 
-func __log_2_(_args []Object) Object {
+func __log_2_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -299,7 +299,7 @@ func __log_2_(_args []Object) Object {
 var __log_binary__P ProcFn = __log_binary_
 var log_binary_ Proc = Proc{Fn: __log_binary__P, Name: "log_binary_", Package: "std/math"}
 
-func __log_binary_(_args []Object) Object {
+func __log_binary_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -316,7 +316,7 @@ func __log_binary_(_args []Object) Object {
 var __log_plus_1__P ProcFn = __log_plus_1_
 var log_plus_1_ Proc = Proc{Fn: __log_plus_1__P, Name: "log_plus_1_", Package: "std/math"}
 
-func __log_plus_1_(_args []Object) Object {
+func __log_plus_1_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -333,7 +333,7 @@ func __log_plus_1_(_args []Object) Object {
 var __modf__P ProcFn = __modf_
 var modf_ Proc = Proc{Fn: __modf__P, Name: "modf_", Package: "std/math"}
 
-func __modf_(_args []Object) Object {
+func __modf_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -350,7 +350,7 @@ func __modf_(_args []Object) Object {
 var __nan__P ProcFn = __nan_
 var nan_ Proc = Proc{Fn: __nan__P, Name: "nan_", Package: "std/math"}
 
-func __nan_(_args []Object) Object {
+func __nan_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -366,7 +366,7 @@ func __nan_(_args []Object) Object {
 var __isnan__P ProcFn = __isnan_
 var isnan_ Proc = Proc{Fn: __isnan__P, Name: "isnan_", Package: "std/math"}
 
-func __isnan_(_args []Object) Object {
+func __isnan_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -383,7 +383,7 @@ func __isnan_(_args []Object) Object {
 var __next_after__P ProcFn = __next_after_
 var next_after_ Proc = Proc{Fn: __next_after__P, Name: "next_after_", Package: "std/math"}
 
-func __next_after_(_args []Object) Object {
+func __next_after_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -401,7 +401,7 @@ func __next_after_(_args []Object) Object {
 var __pow__P ProcFn = __pow_
 var pow_ Proc = Proc{Fn: __pow__P, Name: "pow_", Package: "std/math"}
 
-func __pow_(_args []Object) Object {
+func __pow_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -419,7 +419,7 @@ func __pow_(_args []Object) Object {
 var __pow_10__P ProcFn = __pow_10_
 var pow_10_ Proc = Proc{Fn: __pow_10__P, Name: "pow_10_", Package: "std/math"}
 
-func __pow_10_(_args []Object) Object {
+func __pow_10_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -436,7 +436,7 @@ func __pow_10_(_args []Object) Object {
 var __round__P ProcFn = __round_
 var round_ Proc = Proc{Fn: __round__P, Name: "round_", Package: "std/math"}
 
-func __round_(_args []Object) Object {
+func __round_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -453,7 +453,7 @@ func __round_(_args []Object) Object {
 var __round_to_even__P ProcFn = __round_to_even_
 var round_to_even_ Proc = Proc{Fn: __round_to_even__P, Name: "round_to_even_", Package: "std/math"}
 
-func __round_to_even_(_args []Object) Object {
+func __round_to_even_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -470,7 +470,7 @@ func __round_to_even_(_args []Object) Object {
 var __sign_bit__P ProcFn = __sign_bit_
 var sign_bit_ Proc = Proc{Fn: __sign_bit__P, Name: "sign_bit_", Package: "std/math"}
 
-func __sign_bit_(_args []Object) Object {
+func __sign_bit_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -487,7 +487,7 @@ func __sign_bit_(_args []Object) Object {
 var __sin__P ProcFn = __sin_
 var sin_ Proc = Proc{Fn: __sin__P, Name: "sin_", Package: "std/math"}
 
-func __sin_(_args []Object) Object {
+func __sin_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -504,7 +504,7 @@ func __sin_(_args []Object) Object {
 var __sqrt__P ProcFn = __sqrt_
 var sqrt_ Proc = Proc{Fn: __sqrt__P, Name: "sqrt_", Package: "std/math"}
 
-func __sqrt_(_args []Object) Object {
+func __sqrt_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -521,7 +521,7 @@ func __sqrt_(_args []Object) Object {
 var __trunc__P ProcFn = __trunc_
 var trunc_ Proc = Proc{Fn: __trunc__P, Name: "trunc_", Package: "std/math"}
 
-func __trunc_(_args []Object) Object {
+func __trunc_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
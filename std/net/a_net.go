@@ -0,0 +1,279 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package net
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __accept__P ProcFn = __accept_
+var accept_ Proc = Proc{Fn: __accept__P, Name: "accept_", Package: "std/net"}
+
+func __accept_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		listener := ExtractNetListener(_args, 0)
+		_res := accept(listener)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __close_write__P ProcFn = __close_write_
+var close_write_ Proc = Proc{Fn: __close_write__P, Name: "close_write_", Package: "std/net"}
+
+func __close_write_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractNetConn(_args, 0)
+		_res := closeWrite(conn)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __dial__P ProcFn = __dial_
+var dial_ Proc = Proc{Fn: __dial__P, Name: "dial_", Package: "std/net"}
+
+func __dial_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		network := ExtractString(_args, 0)
+		address := ExtractString(_args, 1)
+		_res := dial(network, address, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		network := ExtractString(_args, 0)
+		address := ExtractString(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := dial(network, address, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __listen__P ProcFn = __listen_
+var listen_ Proc = Proc{Fn: __listen__P, Name: "listen_", Package: "std/net"}
+
+func __listen_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		network := ExtractString(_args, 0)
+		address := ExtractString(_args, 1)
+		_res := listen(network, address)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __listen_packet__P ProcFn = __listen_packet_
+var listen_packet_ Proc = Proc{Fn: __listen_packet__P, Name: "listen_packet_", Package: "std/net"}
+
+func __listen_packet_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		network := ExtractString(_args, 0)
+		address := ExtractString(_args, 1)
+		_res := listenPacket(network, address)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __local_address__P ProcFn = __local_address_
+var local_address_ Proc = Proc{Fn: __local_address__P, Name: "local_address_", Package: "std/net"}
+
+func __local_address_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractObject(_args, 0)
+		_res := localAddr(conn)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __lookup_host__P ProcFn = __lookup_host_
+var lookup_host_ Proc = Proc{Fn: __lookup_host__P, Name: "lookup_host_", Package: "std/net"}
+
+func __lookup_host_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		host := ExtractString(_args, 0)
+		_res := lookupHost(host)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __lookup_srv__P ProcFn = __lookup_srv_
+var lookup_srv_ Proc = Proc{Fn: __lookup_srv__P, Name: "lookup_srv_", Package: "std/net"}
+
+func __lookup_srv_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 3:
+		service := ExtractString(_args, 0)
+		proto := ExtractString(_args, 1)
+		name := ExtractString(_args, 2)
+		_res := lookupSRV(service, proto, name)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __receive_from__P ProcFn = __receive_from_
+var receive_from_ Proc = Proc{Fn: __receive_from__P, Name: "receive_from_", Package: "std/net"}
+
+func __receive_from_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractPacketConn(_args, 0)
+		_res := receiveFrom(conn, 65536)
+		return _res
+
+	case _c == 2:
+		conn := ExtractPacketConn(_args, 0)
+		size := ExtractInt(_args, 1)
+		_res := receiveFrom(conn, size)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __remote_address__P ProcFn = __remote_address_
+var remote_address_ Proc = Proc{Fn: __remote_address__P, Name: "remote_address_", Package: "std/net"}
+
+func __remote_address_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractNetConn(_args, 0)
+		_res := addrString(conn.RemoteAddr())
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __send_to__P ProcFn = __send_to_
+var send_to_ Proc = Proc{Fn: __send_to__P, Name: "send_to_", Package: "std/net"}
+
+func __send_to_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 3:
+		conn := ExtractPacketConn(_args, 0)
+		data := ExtractBinary(_args, 1)
+		address := ExtractString(_args, 2)
+		_res := sendTo(conn, data, address)
+		return MakeInt(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __set_deadline__P ProcFn = __set_deadline_
+var set_deadline_ Proc = Proc{Fn: __set_deadline__P, Name: "set_deadline_", Package: "std/net"}
+
+func __set_deadline_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		conn := ExtractObject(_args, 0)
+		t := ExtractObject(_args, 1)
+		_res := setDeadline(conn, "", t)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __set_read_deadline__P ProcFn = __set_read_deadline_
+var set_read_deadline_ Proc = Proc{Fn: __set_read_deadline__P, Name: "set_read_deadline_", Package: "std/net"}
+
+func __set_read_deadline_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		conn := ExtractObject(_args, 0)
+		t := ExtractObject(_args, 1)
+		_res := setDeadline(conn, "read", t)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __set_write_deadline__P ProcFn = __set_write_deadline_
+var set_write_deadline_ Proc = Proc{Fn: __set_write_deadline__P, Name: "set_write_deadline_", Package: "std/net"}
+
+func __set_write_deadline_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		conn := ExtractObject(_args, 0)
+		t := ExtractObject(_args, 1)
+		_res := setDeadline(conn, "write", t)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var netNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.net"))
+
+func init() {
+	netNamespace.Lazy = Init
+}
@@ -0,0 +1,125 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package net
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of net.InternsOrThunks().")
+	}
+	netNamespace.ResetMeta(MakeMeta(nil, `Provides TCP, UDP and Unix domain socket clients and servers and DNS lookups.
+
+         Stream connections (NetConn) are IOReaders and IOWriters, so functions
+         like joker.io/copy, line-seq, slurp and spit work on them.
+         Connections, listeners and packet connections can be closed with joker.io/close.
+
+         network argument is one of "tcp", "tcp4", "tcp6", "unix" and
+         "unixpacket" for stream connections and one of "udp", "udp4", "udp6",
+         "ip", "ip4", "ip6" and "unixgram" for packet connections.
+         For TCP and UDP networks address has the form "host:port",
+         for Unix networks it's a file system path.
+
+         Example:
+
+         user=> (def conn (joker.net/dial "tcp" "localhost:6379" {:timeout (* 5 joker.time/second)}))
+         #'user/conn
+         user=> (spit conn "PING\r\n")
+         nil
+         user=> (first (line-seq conn))
+         "+PONG"
+         user=> (joker.io/close conn)
+         nil`, "1.0"))
+
+	netNamespace.InternVar("accept", accept_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("listener"))),
+			`Waits for and returns the next connection to listener.`, "1.0"))
+
+	netNamespace.InternVar("close-write", close_write_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"))),
+			`Shuts down the writing side of TCP or Unix connection conn,
+  so that the peer reads EOF, while the connection can still be read from.`, "1.0"))
+
+	netNamespace.InternVar("dial", dial_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("network"), MakeSymbol("address")), NewVectorFrom(MakeSymbol("network"), MakeSymbol("address"), MakeSymbol("opts"))),
+			`Connects to address on the named network and returns the connection.
+  Optional opts map may have the following keys:
+  - timeout (int, connection timeout in nanoseconds)
+  - local-address (string, local address to use).`, "1.0"))
+
+	netNamespace.InternVar("listen", listen_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("network"), MakeSymbol("address"))),
+			`Listens on address of the named stream-oriented network
+  and returns the listener. Port 0 in the address means that a free port
+  is chosen (see local-address).`, "1.0"))
+
+	netNamespace.InternVar("listen-packet", listen_packet_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("network"), MakeSymbol("address"))),
+			`Listens on address of the named packet-oriented network
+  and returns the packet connection.`, "1.0"))
+
+	netNamespace.InternVar("local-address", local_address_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"))),
+			`Returns local address of conn (NetConn, NetListener or PacketConn).`, "1.0"))
+
+	netNamespace.InternVar("lookup-host", lookup_host_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("host"))),
+			`Looks up host using the local resolver. Returns a vector of its addresses.`, "1.0"))
+
+	netNamespace.InternVar("lookup-srv", lookup_srv_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("service"), MakeSymbol("proto"), MakeSymbol("name"))),
+			`Looks up SRV records of service (e.g. "xmpp-server") over proto
+  (e.g. "tcp") for domain name. Returns a vector of maps with
+  :target, :port, :priority and :weight keys, sorted by priority
+  and randomized by weight within a priority.
+  If service and proto are empty strings, looks up name directly.`, "1.0"))
+
+	netNamespace.InternVar("receive-from", receive_from_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn")), NewVectorFrom(MakeSymbol("conn"), MakeSymbol("size"))),
+			`Waits for a packet on packet connection conn and returns a map with
+  :data (Bytes) and :address (sender's address) keys.
+  Packets longer than size bytes (defaults to 65536) are truncated.`, "1.0"))
+
+	netNamespace.InternVar("remote-address", remote_address_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"))),
+			`Returns remote address of conn.`, "1.0"))
+
+	netNamespace.InternVar("send-to", send_to_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"), MakeSymbol("data"), MakeSymbol("address"))),
+			`Sends data (String or Bytes) to address via packet connection conn.
+  Returns the number of bytes sent.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Int"}))
+
+	netNamespace.InternVar("set-deadline", set_deadline_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"), MakeSymbol("t"))),
+			`Sets read and write deadline of conn (NetConn or PacketConn).
+  Once the deadline is exceeded, reads and writes throw an error.
+  t is either a Time, a duration from now (int, in nanoseconds), or nil,
+  which means that reads and writes don't time out.`, "1.0"))
+
+	netNamespace.InternVar("set-read-deadline", set_read_deadline_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"), MakeSymbol("t"))),
+			`Like set-deadline, but only sets read deadline.`, "1.0"))
+
+	netNamespace.InternVar("set-write-deadline", set_write_deadline_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"), MakeSymbol("t"))),
+			`Like set-deadline, but only sets write deadline.`, "1.0"))
+
+}
@@ -0,0 +1,332 @@
+package net
+
+import (
+	"net"
+	"strings"
+	"time"
+	"unsafe"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	NetConn struct {
+		net.Conn
+		hash uint32
+	}
+	NetListener struct {
+		net.Listener
+		hash uint32
+	}
+	PacketConn struct {
+		net.PacketConn
+		hash uint32
+	}
+	deadliner interface {
+		SetDeadline(t time.Time) error
+		SetReadDeadline(t time.Time) error
+		SetWriteDeadline(t time.Time) error
+	}
+)
+
+var netConnType *Type
+var netListenerType *Type
+var packetConnType *Type
+
+func MakeNetConn(conn net.Conn) *NetConn {
+	res := &NetConn{conn, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (conn *NetConn) ToString(escape bool) string {
+	return "#object[NetConn]"
+}
+
+func (conn *NetConn) Equals(other interface{}) bool {
+	return conn == other
+}
+
+func (conn *NetConn) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (conn *NetConn) GetType() *Type {
+	return netConnType
+}
+
+func (conn *NetConn) Hash() uint32 {
+	return conn.hash
+}
+
+func (conn *NetConn) WithInfo(info *ObjectInfo) Object {
+	return conn
+}
+
+func EnsureNetConn(args []Object, index int) *NetConn {
+	switch c := args[index].(type) {
+	case *NetConn:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "NetConn"))
+	}
+}
+
+func ExtractNetConn(args []Object, index int) net.Conn {
+	return EnsureNetConn(args, index).Conn
+}
+
+func MakeNetListener(l net.Listener) *NetListener {
+	res := &NetListener{l, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (l *NetListener) ToString(escape bool) string {
+	return "#object[NetListener]"
+}
+
+func (l *NetListener) Equals(other interface{}) bool {
+	return l == other
+}
+
+func (l *NetListener) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (l *NetListener) GetType() *Type {
+	return netListenerType
+}
+
+func (l *NetListener) Hash() uint32 {
+	return l.hash
+}
+
+func (l *NetListener) WithInfo(info *ObjectInfo) Object {
+	return l
+}
+
+func EnsureNetListener(args []Object, index int) *NetListener {
+	switch c := args[index].(type) {
+	case *NetListener:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "NetListener"))
+	}
+}
+
+func ExtractNetListener(args []Object, index int) net.Listener {
+	return EnsureNetListener(args, index).Listener
+}
+
+func MakePacketConn(conn net.PacketConn) *PacketConn {
+	res := &PacketConn{conn, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (conn *PacketConn) ToString(escape bool) string {
+	return "#object[PacketConn]"
+}
+
+func (conn *PacketConn) Equals(other interface{}) bool {
+	return conn == other
+}
+
+func (conn *PacketConn) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (conn *PacketConn) GetType() *Type {
+	return packetConnType
+}
+
+func (conn *PacketConn) Hash() uint32 {
+	return conn.hash
+}
+
+func (conn *PacketConn) WithInfo(info *ObjectInfo) Object {
+	return conn
+}
+
+func EnsurePacketConn(args []Object, index int) *PacketConn {
+	switch c := args[index].(type) {
+	case *PacketConn:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "PacketConn"))
+	}
+}
+
+func ExtractPacketConn(args []Object, index int) net.PacketConn {
+	return EnsurePacketConn(args, index).PacketConn
+}
+
+func dial(network, address string, opts Map) Object {
+	dialer := &net.Dialer{}
+	if ok, t := opts.Get(MakeKeyword("timeout")); ok {
+		dialer.Timeout = time.Duration(AssertInt(t, "timeout must be an integer").I)
+	}
+	if ok, a := opts.Get(MakeKeyword("local-address")); ok {
+		addr, err := resolveAddr(network, AssertString(a, "local-address must be a string").S)
+		PanicOnErr(err)
+		dialer.LocalAddr = addr
+	}
+	conn, err := dialer.Dial(network, address)
+	PanicOnErr(err)
+	return MakeNetConn(conn)
+}
+
+func listen(network, address string) Object {
+	l, err := net.Listen(network, address)
+	PanicOnErr(err)
+	return MakeNetListener(l)
+}
+
+func accept(l net.Listener) Object {
+	conn, err := l.Accept()
+	PanicOnErr(err)
+	return MakeNetConn(conn)
+}
+
+func listenPacket(network, address string) Object {
+	conn, err := net.ListenPacket(network, address)
+	PanicOnErr(err)
+	return MakePacketConn(conn)
+}
+
+func resolveAddr(network, address string) (net.Addr, error) {
+	switch {
+	case strings.HasPrefix(network, "tcp"):
+		return net.ResolveTCPAddr(network, address)
+	case strings.HasPrefix(network, "udp"):
+		return net.ResolveUDPAddr(network, address)
+	case strings.HasPrefix(network, "unix"):
+		return net.ResolveUnixAddr(network, address)
+	case strings.HasPrefix(network, "ip"):
+		return net.ResolveIPAddr(network, address)
+	}
+	return nil, net.UnknownNetworkError(network)
+}
+
+func sendTo(conn net.PacketConn, data []byte, address string) int {
+	addr, err := resolveAddr(conn.LocalAddr().Network(), address)
+	PanicOnErr(err)
+	n, err := conn.WriteTo(data, addr)
+	PanicOnErr(err)
+	return n
+}
+
+func receiveFrom(conn net.PacketConn, size int) Object {
+	b := make([]byte, size)
+	n, addr, err := conn.ReadFrom(b)
+	PanicOnErr(err)
+	res := EmptyArrayMap()
+	res.Add(MakeKeyword("data"), MakeBytes(append([]byte(nil), b[:n]...)))
+	if addr != nil {
+		res.Add(MakeKeyword("address"), MakeString(addr.String()))
+	} else {
+		res.Add(MakeKeyword("address"), NIL)
+	}
+	return res
+}
+
+func closeWrite(conn net.Conn) Object {
+	cw, ok := conn.(interface{ CloseWrite() error })
+	if !ok {
+		panic(RT.NewError("Connection doesn't support closing its write side"))
+	}
+	PanicOnErr(cw.CloseWrite())
+	return NIL
+}
+
+func toDeadline(t Object) time.Time {
+	switch t := t.(type) {
+	case Nil:
+		return time.Time{}
+	case Int:
+		return time.Now().Add(time.Duration(t.I))
+	case Time:
+		return t.T
+	default:
+		panic(RT.NewError("Deadline must be nil, Int (duration) or Time, got " + t.GetType().ToString(false)))
+	}
+}
+
+func toDeadliner(conn Object) deadliner {
+	switch conn := conn.(type) {
+	case *NetConn:
+		return conn.Conn
+	case *PacketConn:
+		return conn.PacketConn
+	default:
+		panic(RT.NewError("Expected NetConn or PacketConn, got " + conn.GetType().ToString(false)))
+	}
+}
+
+func setDeadline(conn Object, kind string, t Object) Object {
+	d := toDeadliner(conn)
+	deadline := toDeadline(t)
+	var err error
+	switch kind {
+	case "read":
+		err = d.SetReadDeadline(deadline)
+	case "write":
+		err = d.SetWriteDeadline(deadline)
+	default:
+		err = d.SetDeadline(deadline)
+	}
+	PanicOnErr(err)
+	return NIL
+}
+
+func addrString(addr net.Addr) Object {
+	if addr == nil {
+		return NIL
+	}
+	return MakeString(addr.String())
+}
+
+func localAddr(obj Object) Object {
+	switch obj := obj.(type) {
+	case *NetConn:
+		return addrString(obj.LocalAddr())
+	case *NetListener:
+		return addrString(obj.Addr())
+	case *PacketConn:
+		return addrString(obj.LocalAddr())
+	default:
+		panic(RT.NewError("Expected NetConn, NetListener or PacketConn, got " + obj.GetType().ToString(false)))
+	}
+}
+
+func lookupHost(host string) Object {
+	addrs, err := net.LookupHost(host)
+	PanicOnErr(err)
+	res := EmptyVector()
+	for _, addr := range addrs {
+		res = res.Conjoin(MakeString(addr))
+	}
+	return res
+}
+
+func lookupSRV(service, proto, name string) Object {
+	_, srvs, err := net.LookupSRV(service, proto, name)
+	PanicOnErr(err)
+	res := EmptyVector()
+	for _, srv := range srvs {
+		m := EmptyArrayMap()
+		m.Add(MakeKeyword("target"), MakeString(srv.Target))
+		m.Add(MakeKeyword("port"), MakeInt(int(srv.Port)))
+		m.Add(MakeKeyword("priority"), MakeInt(int(srv.Priority)))
+		m.Add(MakeKeyword("weight"), MakeInt(int(srv.Weight)))
+		res = res.Conjoin(m)
+	}
+	return res
+}
+
+func init() {
+	netConnType = RegRefType("NetConn", (*NetConn)(nil), "Wraps network connection")
+	netListenerType = RegRefType("NetListener", (*NetListener)(nil), "Wraps network listener")
+	packetConnType = RegRefType("PacketConn", (*PacketConn)(nil), "Wraps packet-oriented network connection")
+}
@@ -11,7 +11,7 @@ import (
 var __args__P ProcFn = __args_
 var args_ Proc = Proc{Fn: __args__P, Name: "args_", Package: "std/os"}
 
-func __args_(_args []Object) Object {
+func __args_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -27,7 +27,7 @@ func __args_(_args []Object) Object {
 var __chdir__P ProcFn = __chdir_
 var chdir_ Proc = Proc{Fn: __chdir__P, Name: "chdir_", Package: "std/os"}
 
-func __chdir_(_args []Object) Object {
+func __chdir_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -44,7 +44,7 @@ func __chdir_(_args []Object) Object {
 var __close__P ProcFn = __close_
 var close_ Proc = Proc{Fn: __close__P, Name: "close_", Package: "std/os"}
 
-func __close_(_args []Object) Object {
+func __close_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -63,7 +63,7 @@ func __close_(_args []Object) Object {
 var __create__P ProcFn = __create_
 var create_ Proc = Proc{Fn: __create__P, Name: "create_", Package: "std/os"}
 
-func __create_(_args []Object) Object {
+func __create_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -81,7 +81,7 @@ func __create_(_args []Object) Object {
 var __create_temp__P ProcFn = __create_temp_
 var create_temp_ Proc = Proc{Fn: __create_temp__P, Name: "create_temp_", Package: "std/os"}
 
-func __create_temp_(_args []Object) Object {
+func __create_temp_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -100,7 +100,7 @@ func __create_temp_(_args []Object) Object {
 var __cwd__P ProcFn = __cwd_
 var cwd_ Proc = Proc{Fn: __cwd__P, Name: "cwd_", Package: "std/os"}
 
-func __cwd_(_args []Object) Object {
+func __cwd_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -116,7 +116,7 @@ func __cwd_(_args []Object) Object {
 var __env__P ProcFn = __env_
 var env_ Proc = Proc{Fn: __env__P, Name: "env_", Package: "std/os"}
 
-func __env_(_args []Object) Object {
+func __env_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -132,13 +132,13 @@ func __env_(_args []Object) Object {
 var __exec__P ProcFn = __exec_
 var exec_ Proc = Proc{Fn: __exec__P, Name: "exec_", Package: "std/os"}
 
-func __exec_(_args []Object) Object {
+func __exec_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
 		name := ExtractString(_args, 0)
 		opts := ExtractMap(_args, 1)
-		_res := execute(name, opts)
+		_res := execute(_rt, name, opts)
 		return _res
 
 	default:
@@ -150,7 +150,7 @@ func __exec_(_args []Object) Object {
 var __isexists__P ProcFn = __isexists_
 var isexists_ Proc = Proc{Fn: __isexists__P, Name: "isexists_", Package: "std/os"}
 
-func __isexists_(_args []Object) Object {
+func __isexists_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -167,7 +167,7 @@ func __isexists_(_args []Object) Object {
 var __exit__P ProcFn = __exit_
 var exit_ Proc = Proc{Fn: __exit__P, Name: "exit_", Package: "std/os"}
 
-func __exit_(_args []Object) Object {
+func __exit_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -190,7 +190,7 @@ func __exit_(_args []Object) Object {
 var __get_env__P ProcFn = __get_env_
 var get_env_ Proc = Proc{Fn: __get_env__P, Name: "get_env_", Package: "std/os"}
 
-func __get_env_(_args []Object) Object {
+func __get_env_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -207,7 +207,7 @@ func __get_env_(_args []Object) Object {
 var __ls__P ProcFn = __ls_
 var ls_ Proc = Proc{Fn: __ls__P, Name: "ls_", Package: "std/os"}
 
-func __ls_(_args []Object) Object {
+func __ls_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -224,7 +224,7 @@ func __ls_(_args []Object) Object {
 var __mkdir__P ProcFn = __mkdir_
 var mkdir_ Proc = Proc{Fn: __mkdir__P, Name: "mkdir_", Package: "std/os"}
 
-func __mkdir_(_args []Object) Object {
+func __mkdir_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -242,7 +242,7 @@ func __mkdir_(_args []Object) Object {
 var __mkdir_temp__P ProcFn = __mkdir_temp_
 var mkdir_temp_ Proc = Proc{Fn: __mkdir_temp__P, Name: "mkdir_temp_", Package: "std/os"}
 
-func __mkdir_temp_(_args []Object) Object {
+func __mkdir_temp_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -261,7 +261,7 @@ func __mkdir_temp_(_args []Object) Object {
 var __open__P ProcFn = __open_
 var open_ Proc = Proc{Fn: __open__P, Name: "open_", Package: "std/os"}
 
-func __open_(_args []Object) Object {
+func __open_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -276,10 +276,50 @@ func __open_(_args []Object) Object {
 	return NIL
 }
 
+var __parse_env__P ProcFn = __parse_env_
+var parse_env_ Proc = Proc{Fn: __parse_env__P, Name: "parse_env_", Package: "std/os"}
+
+func __parse_env_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		s := ExtractString(_args, 0)
+		_res := parseEnv(s)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __read_env_file__P ProcFn = __read_env_file_
+var read_env_file_ Proc = Proc{Fn: __read_env_file__P, Name: "read_env_file_", Package: "std/os"}
+
+func __read_env_file_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		filename := ExtractString(_args, 0)
+		_res := readEnvFile(filename, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		filename := ExtractString(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := readEnvFile(filename, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
 var __remove__P ProcFn = __remove_
 var remove_ Proc = Proc{Fn: __remove__P, Name: "remove_", Package: "std/os"}
 
-func __remove_(_args []Object) Object {
+func __remove_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -298,7 +338,7 @@ func __remove_(_args []Object) Object {
 var __remove_all__P ProcFn = __remove_all_
 var remove_all_ Proc = Proc{Fn: __remove_all__P, Name: "remove_all_", Package: "std/os"}
 
-func __remove_all_(_args []Object) Object {
+func __remove_all_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -317,7 +357,7 @@ func __remove_all_(_args []Object) Object {
 var __set_env__P ProcFn = __set_env_
 var set_env_ Proc = Proc{Fn: __set_env__P, Name: "set_env_", Package: "std/os"}
 
-func __set_env_(_args []Object) Object {
+func __set_env_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -335,7 +375,7 @@ func __set_env_(_args []Object) Object {
 var __sh__P ProcFn = __sh_
 var sh_ Proc = Proc{Fn: __sh__P, Name: "sh_", Package: "std/os"}
 
-func __sh_(_args []Object) Object {
+func __sh_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case true:
@@ -354,7 +394,7 @@ func __sh_(_args []Object) Object {
 var __sh_from__P ProcFn = __sh_from_
 var sh_from_ Proc = Proc{Fn: __sh_from__P, Name: "sh_from_", Package: "std/os"}
 
-func __sh_from_(_args []Object) Object {
+func __sh_from_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case true:
@@ -374,7 +414,7 @@ func __sh_from_(_args []Object) Object {
 var __stat__P ProcFn = __stat_
 var stat_ Proc = Proc{Fn: __stat__P, Name: "stat_", Package: "std/os"}
 
-func __stat_(_args []Object) Object {
+func __stat_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -391,7 +431,7 @@ func __stat_(_args []Object) Object {
 var __temp_dir__P ProcFn = __temp_dir_
 var temp_dir_ Proc = Proc{Fn: __temp_dir__P, Name: "temp_dir_", Package: "std/os"}
 
-func __temp_dir_(_args []Object) Object {
+func __temp_dir_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -62,8 +62,8 @@ func InternsOrThunks() {
 			`Executes the named program with the given arguments. opts is a map with the following keys (all optional):
   :args - vector of arguments (all arguments must be strings),
   :dir - if specified, working directory will be set to this value before executing the program,
-  :stdin - if specified, provides stdin for the program. Can be either a string or an IOReader.
-  If it's a string, the string's content will serve as stdin for the program. IOReader can be, for example,
+  :stdin - if specified, provides stdin for the program. Can be a string, Bytes or an IOReader.
+  If it's a string or Bytes, its content will serve as stdin for the program. IOReader can be, for example,
   *in* (in which case Joker's stdin will be redirected to the program's stdin) or the value returned by (joker.os/open).
   :stdout - if specified, must be an IOWriter. It can be, for example, *out* (in which case the program's stdout will be redirected
   to Joker's stdout) or the value returned by (joker.os/create).
@@ -123,6 +123,28 @@ func InternsOrThunks() {
 			`Opens the named file for reading. If successful, the file can be used for reading;
   the associated file descriptor has mode O_RDONLY.`, "1.0").Plus(MakeKeyword("tag"), String{S: "File"}))
 
+	osNamespace.InternVar("parse-env", parse_env_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s"))),
+			`Parses s in .env file format and returns a map of variable names to values.
+  Each line is either blank, a # comment or KEY=VALUE, optionally prefixed with export.
+  Values may be unquoted (trailing # comments are stripped), 'single-quoted'
+  (taken literally) or "double-quoted" (may span multiple lines and contain
+  escape sequences \n, \r, \t, \", \\ and \$).
+  Unquoted and double-quoted values may reference variables as $NAME or ${NAME},
+  which are looked up among previously defined keys and then in the environment;
+  $$ stands for a literal dollar sign.`, "1.0"))
+
+	osNamespace.InternVar("read-env-file", read_env_file_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("filename")), NewVectorFrom(MakeSymbol("filename"), MakeSymbol("opts"))),
+			`Reads .env file (see parse-env for the format) and returns a map of
+  variable names to values.
+  Optional opts map may have the following keys:
+  - load? (boolean, if true, sets the variables in the environment of the current process)
+  - override? (boolean, if true, variables already set in the environment are
+    overwritten when loading; by default they are kept).`, "1.0"))
+
 	osNamespace.InternVar("remove", remove_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom(MakeSymbol("name"))),
@@ -0,0 +1,239 @@
+package os
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	. "github.com/candid82/joker/core"
+)
+
+// dotenvParser parses .env files: lines of the form KEY=VALUE
+// (optionally prefixed with "export"), blank lines and # comments.
+// Values may be unquoted, 'single-quoted' (taken literally) or "double-quoted"
+// (may span multiple lines and contain escape sequences).
+// Unquoted and double-quoted values may reference variables as $NAME or ${NAME},
+// which are looked up among previously defined keys and then in the environment;
+// $$ (or \$ in double-quoted values) stands for a literal dollar sign.
+type dotenvParser struct {
+	s    string
+	pos  int
+	line int
+	vars map[string]string
+	keys []string
+}
+
+func (p *dotenvParser) error(msg string) {
+	panic(RT.NewError(".env parse error on line " + strconv.Itoa(p.line) + ": " + msg))
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.s[p.pos]
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.s[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+	for !p.eof() {
+		c := rune(p.peek())
+		if !(c == '_' || c == '.' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			break
+		}
+		p.next()
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *dotenvParser) lookup(name string) string {
+	if v, ok := p.vars[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+func (p *dotenvParser) readSingleQuoted() string {
+	start := p.pos
+	for {
+		if p.eof() {
+			p.error("unterminated single-quoted value")
+		}
+		if p.next() == '\'' {
+			return p.s[start : p.pos-1]
+		}
+	}
+}
+
+func (p *dotenvParser) readDoubleQuoted() string {
+	var b strings.Builder
+	for {
+		if p.eof() {
+			p.error("unterminated double-quoted value")
+		}
+		c := p.next()
+		switch c {
+		case '"':
+			return b.String()
+		case '\\':
+			if p.eof() {
+				p.error("unterminated double-quoted value")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '$':
+				// Escaped dollar sign is not expanded (see expandDollars).
+				b.WriteString("$$")
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *dotenvParser) readUnquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == '#' && p.pos > start && (p.s[p.pos-1] == ' ' || p.s[p.pos-1] == '\t') {
+			break
+		}
+		p.next()
+	}
+	return strings.TrimSpace(strings.TrimSuffix(p.s[start:p.pos], "\r"))
+}
+
+func (p *dotenvParser) readValue() string {
+	if p.eof() {
+		return ""
+	}
+	var v string
+	switch p.peek() {
+	case '\'':
+		p.next()
+		v = p.readSingleQuoted()
+	case '"':
+		p.next()
+		v = expandDollars(p.readDoubleQuoted(), p.lookup)
+	default:
+		return expandDollars(p.readUnquoted(), p.lookup)
+	}
+	p.skipSpaces()
+	if !p.eof() && p.peek() != '\n' && p.peek() != '\r' && p.peek() != '#' {
+		p.error("unexpected characters after quoted value")
+	}
+	return v
+}
+
+// expandDollars is like os.Expand, except that $$ stands for a literal dollar sign.
+func expandDollars(s string, lookup func(string) string) string {
+	parts := strings.Split(s, "$$")
+	for i, part := range parts {
+		parts[i] = os.Expand(part, lookup)
+	}
+	return strings.Join(parts, "$")
+}
+
+func (p *dotenvParser) parse() {
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return
+		}
+		switch p.peek() {
+		case '\n', '\r':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+		key := p.readKey()
+		if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+			p.skipSpaces()
+			key = p.readKey()
+		}
+		if key == "" {
+			p.error("invalid variable name")
+		}
+		p.skipSpaces()
+		if p.eof() || p.next() != '=' {
+			p.error("expected = after " + key)
+		}
+		p.skipSpaces()
+		value := p.readValue()
+		if _, ok := p.vars[key]; !ok {
+			p.keys = append(p.keys, key)
+		}
+		p.vars[key] = value
+		p.skipLine()
+	}
+}
+
+func parseDotenv(s string) ([]string, map[string]string) {
+	p := &dotenvParser{s: s, line: 1, vars: map[string]string{}}
+	p.parse()
+	return p.keys, p.vars
+}
+
+func parseEnv(s string) Object {
+	keys, vars := parseDotenv(s)
+	res := EmptyArrayMap()
+	for _, k := range keys {
+		res.Add(MakeString(k), MakeString(vars[k]))
+	}
+	return res
+}
+
+func readEnvFile(filename string, opts Map) Object {
+	b, err := ioutil.ReadFile(filename)
+	PanicOnErr(err)
+	keys, vars := parseDotenv(string(b))
+	load := false
+	if ok, l := opts.Get(MakeKeyword("load?")); ok {
+		load = ToBool(l)
+	}
+	override := false
+	if ok, o := opts.Get(MakeKeyword("override?")); ok {
+		override = ToBool(o)
+	}
+	res := EmptyArrayMap()
+	for _, k := range keys {
+		res.Add(MakeString(k), MakeString(vars[k]))
+		if load {
+			if _, exists := os.LookupEnv(k); !exists || override {
+				PanicOnErr(os.Setenv(k, vars[k]))
+			}
+		}
+	}
+	return res
+}
@@ -1,6 +1,7 @@
 package os
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
 	"os"
@@ -41,7 +42,7 @@ func commandArgs() Object {
 
 const defaultFailedCode = 127 // seen from 'sh no-such-file' on OS X and Ubuntu
 
-func execute(name string, opts Map) Object {
+func execute(rt *Runtime, name string, opts Map) Object {
 	var dir string
 	var args []string
 	var stdin io.Reader
@@ -60,7 +61,7 @@ func execute(name string, opts Map) Object {
 		// Check if the intent was to pipe stdin into the program being called and
 		// use Stdin directly rather than GLOBAL_ENV.stdin.Value, which is a buffered wrapper.
 		// TODO: this won't work correctly if GLOBAL_ENV.stdin is bound to something other than Stdin
-		if GLOBAL_ENV.IsStdIn(stdinObj) {
+		if GLOBAL_ENV.IsStdIn(rt, stdinObj) {
 			stdin = Stdin
 		} else {
 			switch s := stdinObj.(type) {
@@ -71,8 +72,10 @@ func execute(name string, opts Map) Object {
 				stdin = s
 			case String:
 				stdin = strings.NewReader(s.S)
+			case *Bytes:
+				stdin = bytes.NewReader(s.B)
 			default:
-				panic(RT.NewError("stdin option must be either an IOReader or a string, got " + stdinObj.GetType().ToString(false)))
+				panic(rt.NewError("stdin option must be an IOReader, a string or Bytes, got " + stdinObj.GetType().ToString(false)))
 			}
 		}
 	}
@@ -84,7 +87,7 @@ func execute(name string, opts Map) Object {
 		case io.Writer:
 			stdout = s
 		default:
-			panic(RT.NewError("stdout option must be an IOWriter, got " + stdoutObj.GetType().ToString(false)))
+			panic(rt.NewError("stdout option must be an IOWriter, got " + stdoutObj.GetType().ToString(false)))
 		}
 	}
 	if ok, stderrObj := opts.Get(MakeKeyword("stderr")); ok {
@@ -95,7 +98,7 @@ func execute(name string, opts Map) Object {
 		case io.Writer:
 			stderr = s
 		default:
-			panic(RT.NewError("stderr option must be an IOWriter, got " + stderrObj.GetType().ToString(false)))
+			panic(rt.NewError("stderr option must be an IOWriter, got " + stderrObj.GetType().ToString(false)))
 		}
 	}
 	return sh(dir, stdin, stdout, stderr, name, args)
@@ -31,9 +31,7 @@ func sh(dir string, stdin io.Reader, stdout io.Writer, stderr io.Writer, name st
 	err := cmd.Start()
 	PanicOnErr(err)
 
-	RT.GIL.Unlock()
 	err = cmd.Wait()
-	RT.GIL.Lock()
 
 	res := EmptyArrayMap()
 	res.Add(MakeKeyword("success"), Boolean{B: err == nil})
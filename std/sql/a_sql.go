@@ -0,0 +1,181 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package sql
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __close__P ProcFn = __close_
+var close_ Proc = Proc{Fn: __close__P, Name: "close_", Package: "std/sql"}
+
+func __close_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		db := ExtractObject(_args, 0)
+		_res := closeObj(db)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __drivers__P ProcFn = __drivers_
+var drivers_ Proc = Proc{Fn: __drivers__P, Name: "drivers_", Package: "std/sql"}
+
+func __drivers_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 0:
+		_res := driverNames()
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __execute_BANG__P ProcFn = __execute_BANG_
+var execute_BANG_ Proc = Proc{Fn: __execute_BANG__P, Name: "execute_BANG_", Package: "std/sql"}
+
+func __execute_BANG_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		db := ExtractObject(_args, 0)
+		_res := execute(db, NIL)
+		return _res
+
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		sql := ExtractObject(_args, 1)
+		_res := execute(db, sql)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __open__P ProcFn = __open_
+var open_ Proc = Proc{Fn: __open__P, Name: "open_", Package: "std/sql"}
+
+func __open_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		driver := ExtractString(_args, 0)
+		dsn := ExtractString(_args, 1)
+		_res := open(driver, dsn, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		driver := ExtractString(_args, 0)
+		dsn := ExtractString(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := open(driver, dsn, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __prepare__P ProcFn = __prepare_
+var prepare_ Proc = Proc{Fn: __prepare__P, Name: "prepare_", Package: "std/sql"}
+
+func __prepare_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		sql := ExtractString(_args, 1)
+		_res := prepare(db, sql)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __query__P ProcFn = __query_
+var query_ Proc = Proc{Fn: __query__P, Name: "query_", Package: "std/sql"}
+
+func __query_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		db := ExtractObject(_args, 0)
+		_res := query(db, NIL)
+		return _res
+
+	case _c == 2:
+		db := ExtractObject(_args, 0)
+		sql := ExtractObject(_args, 1)
+		_res := query(db, sql)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __transact__P ProcFn = __transact_
+var transact_ Proc = Proc{Fn: __transact__P, Name: "transact_", Package: "std/sql"}
+
+func __transact_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		db := ExtractSQLDB(_args, 0)
+		f := ExtractCallable(_args, 1)
+		_res := transact(_rt, db, f, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		db := ExtractSQLDB(_args, 0)
+		f := ExtractCallable(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := transact(_rt, db, f, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __with_transaction__P ProcFn = __with_transaction_
+var with_transaction_ Proc = Proc{Fn: __with_transaction__P, Name: "with_transaction_", Package: "std/sql"}
+
+func __with_transaction_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case true:
+		_res := txMacro(_args)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var sqlNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.sql"))
+
+func init() {
+	sqlNamespace.Lazy = Init
+}
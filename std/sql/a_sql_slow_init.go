@@ -0,0 +1,116 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package sql
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of sql.InternsOrThunks().")
+	}
+	sqlNamespace.ResetMeta(MakeMeta(nil, `Provides access to SQL databases via Go's database/sql package.
+
+         SQLite driver (pure Go, registered as "sqlite") is always available.
+         PostgreSQL ("postgres") and MySQL ("mysql") drivers can be compiled in
+         with sql_postgres and sql_mysql build tags respectively.
+         See also drivers.
+
+         Functions that run SQL take a database (SQLDB), a transaction
+         (SQLTx, see with-transaction) or a prepared statement (SQLStmt, see prepare)
+         as the first argument. sql argument is either SQL string or a vector
+         of SQL string followed by the values of its parameters, e.g.
+         ["SELECT * FROM users WHERE id = ?" 1] (placeholder syntax depends on the driver).
+
+         Parameters can be nil, Int, Double, String, Char, Boolean, Time,
+         Bytes or Buffer (for byte data). Column values are returned as nil, Int,
+         Double, String, Boolean, Time or Bytes (for binary columns).
+
+         Example:
+
+         user=> (def db (joker.sql/open "sqlite" "app.db"))
+         #'user/db
+         user=> (joker.sql/execute! db "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+         {:rows-affected 0, :last-insert-id 0}
+         user=> (joker.sql/execute! db ["INSERT INTO users (name) VALUES (?)" "Joe Black"])
+         {:rows-affected 1, :last-insert-id 1}
+         user=> (joker.sql/query db ["SELECT * FROM users WHERE name = ?" "Joe Black"])
+         [{:id 1, :name "Joe Black"}]`, "1.0"))
+
+	sqlNamespace.InternVar("close", close_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"))),
+			`Closes a database (SQLDB) or a prepared statement (SQLStmt).`, "1.0"))
+
+	sqlNamespace.InternVar("drivers", drivers_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom()),
+			`Returns a vector of names of available drivers.`, "1.0"))
+
+	sqlNamespace.InternVar("execute!", execute_BANG_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db")), NewVectorFrom(MakeSymbol("db"), MakeSymbol("sql"))),
+			`Executes SQL statement that doesn't return rows (e.g. INSERT or UPDATE).
+  Returns a map with keys :rows-affected and :last-insert-id
+  (which is nil if the driver doesn't support it).
+  If db is a prepared statement, the second argument (if provided)
+  is a vector of parameters rather than SQL.`, "1.0"))
+
+	sqlNamespace.InternVar("open", open_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("driver"), MakeSymbol("dsn")), NewVectorFrom(MakeSymbol("driver"), MakeSymbol("dsn"), MakeSymbol("opts"))),
+			`Opens a database specified by driver name and data source name (dsn),
+  whose format depends on the driver. For SQLite dsn is a file name
+  or ":memory:", optionally followed by URI parameters, e.g.
+  "app.db?_pragma=foreign_keys(1)".
+  Optional opts map may have the following keys:
+  - max-open-conns (int, maximum number of open connections)
+  - max-idle-conns (int, maximum number of idle connections)
+  - conn-max-lifetime (int, maximum amount of time in nanoseconds
+    a connection may be reused).`, "1.0"))
+
+	sqlNamespace.InternVar("prepare", prepare_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("sql"))),
+			`Creates a prepared statement for later queries or executions
+  on a database or in a transaction. Statements prepared in a transaction
+  can't be used after the transaction is done. Statements should be
+  closed when no longer needed.`, "1.0"))
+
+	sqlNamespace.InternVar("query", query_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db")), NewVectorFrom(MakeSymbol("db"), MakeSymbol("sql"))),
+			`Executes SQL query and returns a vector of rows, each row being
+  a map from column names (as keywords) to values.
+  If db is a prepared statement, the second argument (if provided)
+  is a vector of parameters rather than SQL.`, "1.0"))
+
+	sqlNamespace.InternVar("transact", transact_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("db"), MakeSymbol("f")), NewVectorFrom(MakeSymbol("db"), MakeSymbol("f"), MakeSymbol("opts"))),
+			`Calls f with a new transaction (SQLTx) of db and returns the result.
+  The transaction is committed when f returns or rolled back if f throws.
+  Optional opts map may have the following keys:
+  - read-only? (boolean)
+  - isolation (one of :default, :read-uncommitted, :read-committed,
+    :write-committed, :repeatable-read, :snapshot, :serializable and
+    :linearizable; not all drivers support all levels).
+  See also with-transaction.`, "1.0"))
+
+	sqlNamespace.InternVar("with-transaction", with_transaction_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("binding"), MakeSymbol("&"), MakeSymbol("body"))),
+			`Evaluates body with tx bound to a new transaction of db
+  and returns the value of the last expression. The transaction
+  is committed if body completes normally and rolled back if it throws.
+  binding is [tx db] or [tx db opts], where opts is as in transact.
+
+  Example:
+
+  (with-transaction [tx db]
+    (execute! tx ["UPDATE accounts SET balance = balance - ? WHERE id = ?" 10 1])
+    (execute! tx ["UPDATE accounts SET balance = balance + ? WHERE id = ?" 10 2]))`, "1.0").Plus(MakeKeyword("macro"), Boolean{B: true})).SetMacro()
+}
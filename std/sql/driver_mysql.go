@@ -0,0 +1,12 @@
+//go:build sql_mysql
+// +build sql_mysql
+
+package sql
+
+import (
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	RegisterDriver("mysql", "mysql")
+}
@@ -0,0 +1,12 @@
+//go:build sql_postgres
+// +build sql_postgres
+
+package sql
+
+import (
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	RegisterDriver("postgres", "postgres")
+}
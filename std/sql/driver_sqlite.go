@@ -0,0 +1,9 @@
+package sql
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	RegisterDriver("sqlite", "sqlite")
+}
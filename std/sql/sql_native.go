@@ -0,0 +1,452 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	SQLDB struct {
+		*sql.DB
+		hash uint32
+	}
+	// SQLTx is a transaction started by transact.
+	// It can only be used until the function passed to transact returns.
+	SQLTx struct {
+		*sql.Tx
+		hash uint32
+	}
+	SQLStmt struct {
+		*sql.Stmt
+		hash uint32
+	}
+	// queryer is either a database or a transaction.
+	queryer interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		Prepare(query string) (*sql.Stmt, error)
+	}
+)
+
+var sqlDBType *Type
+var sqlTxType *Type
+var sqlStmtType *Type
+
+// drivers maps driver names accepted by open to names
+// of database/sql drivers.
+var drivers = map[string]string{}
+
+// RegisterDriver makes database/sql driver registered as driverName
+// available to joker.sql/open as name.
+func RegisterDriver(name string, driverName string) {
+	drivers[name] = driverName
+}
+
+func MakeSQLDB(db *sql.DB) SQLDB {
+	res := SQLDB{db, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(db)))
+	return res
+}
+
+func (db SQLDB) ToString(escape bool) string {
+	return "#object[SQLDB]"
+}
+
+func (db SQLDB) Equals(other interface{}) bool {
+	if otherDb, ok := other.(SQLDB); ok {
+		return db.DB == otherDb.DB
+	}
+	return false
+}
+
+func (db SQLDB) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (db SQLDB) GetType() *Type {
+	return sqlDBType
+}
+
+func (db SQLDB) Hash() uint32 {
+	return db.hash
+}
+
+func (db SQLDB) WithInfo(info *ObjectInfo) Object {
+	return db
+}
+
+func EnsureSQLDB(args []Object, index int) SQLDB {
+	switch c := args[index].(type) {
+	case SQLDB:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "SQLDB"))
+	}
+}
+
+func ExtractSQLDB(args []Object, index int) SQLDB {
+	return EnsureSQLDB(args, index)
+}
+
+func MakeSQLTx(tx *sql.Tx) *SQLTx {
+	res := &SQLTx{tx, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (tx *SQLTx) ToString(escape bool) string {
+	return "#object[SQLTx]"
+}
+
+func (tx *SQLTx) Equals(other interface{}) bool {
+	return tx == other
+}
+
+func (tx *SQLTx) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (tx *SQLTx) GetType() *Type {
+	return sqlTxType
+}
+
+func (tx *SQLTx) Hash() uint32 {
+	return tx.hash
+}
+
+func (tx *SQLTx) WithInfo(info *ObjectInfo) Object {
+	return tx
+}
+
+func MakeSQLStmt(stmt *sql.Stmt) *SQLStmt {
+	res := &SQLStmt{stmt, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(res)))
+	return res
+}
+
+func (stmt *SQLStmt) ToString(escape bool) string {
+	return "#object[SQLStmt]"
+}
+
+func (stmt *SQLStmt) Equals(other interface{}) bool {
+	return stmt == other
+}
+
+func (stmt *SQLStmt) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (stmt *SQLStmt) GetType() *Type {
+	return sqlStmtType
+}
+
+func (stmt *SQLStmt) Hash() uint32 {
+	return stmt.hash
+}
+
+func (stmt *SQLStmt) WithInfo(info *ObjectInfo) Object {
+	return stmt
+}
+
+func driverNames() Object {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := EmptyVector()
+	for _, name := range names {
+		res = res.Conjoin(MakeString(name))
+	}
+	return res
+}
+
+func getInt(m Map, k string) (int, bool) {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return AssertInt(v, k+" must be an integer").I, true
+	}
+	return 0, false
+}
+
+func open(driver string, dsn string, opts Map) Object {
+	driverName, ok := drivers[driver]
+	if !ok {
+		panic(RT.NewError("Unknown SQL driver: " + driver + ". Available drivers: " + driverNames().ToString(false)))
+	}
+	db, err := sql.Open(driverName, dsn)
+	PanicOnErr(err)
+	if n, ok := getInt(opts, "max-open-conns"); ok {
+		db.SetMaxOpenConns(n)
+	}
+	if n, ok := getInt(opts, "max-idle-conns"); ok {
+		db.SetMaxIdleConns(n)
+	}
+	if n, ok := getInt(opts, "conn-max-lifetime"); ok {
+		db.SetConnMaxLifetime(time.Duration(n))
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		panic(RT.NewError(err.Error()))
+	}
+	return MakeSQLDB(db)
+}
+
+func closeObj(obj Object) Nil {
+	switch o := obj.(type) {
+	case SQLDB:
+		PanicOnErr(o.Close())
+	case *SQLStmt:
+		PanicOnErr(o.Close())
+	default:
+		panic(RT.NewError("Expected SQLDB or SQLStmt, got " + obj.GetType().ToString(false)))
+	}
+	return NIL
+}
+
+func toQueryer(obj Object) queryer {
+	switch o := obj.(type) {
+	case SQLDB:
+		return o.DB
+	case *SQLTx:
+		return o.Tx
+	default:
+		panic(RT.NewError("Expected SQLDB or SQLTx, got " + obj.GetType().ToString(false)))
+	}
+}
+
+// toParam converts Joker value to a value that can be passed to SQL driver.
+func toParam(obj Object) interface{} {
+	switch o := obj.(type) {
+	case Nil:
+		return nil
+	case Int:
+		return int64(o.I)
+	case *BigInt:
+		if !o.BigInt().IsInt64() {
+			panic(RT.NewError("SQL parameter is out of int64 range: " + o.ToString(false)))
+		}
+		return o.BigInt().Int64()
+	case Double:
+		return o.D
+	case String:
+		return o.S
+	case Char:
+		return string(o.Ch)
+	case Boolean:
+		return o.B
+	case Time:
+		return o.T
+	case *Bytes:
+		return o.B
+	case *Buffer:
+		return o.Bytes()
+	default:
+		panic(RT.NewError("Unsupported SQL parameter type: " + obj.GetType().ToString(false)))
+	}
+}
+
+func toParams(params Object) []interface{} {
+	if params.Equals(NIL) {
+		return nil
+	}
+	var res []interface{}
+	for s := AssertSeqable(params, "SQL parameters must be a sequence").Seq(); !s.IsEmpty(); s = s.Rest() {
+		res = append(res, toParam(s.First()))
+	}
+	return res
+}
+
+// splitSQLParams splits sqlParams, which is either a string or a vector
+// of SQL string and parameters, into SQL string and parameters.
+func splitSQLParams(sqlParams Object) (string, []interface{}) {
+	switch o := sqlParams.(type) {
+	case String:
+		return o.S, nil
+	case *Vector:
+		if o.Count() == 0 {
+			panic(RT.NewError("SQL vector must not be empty"))
+		}
+		return AssertString(o.Nth(0), "SQL must be a string").S, toParams(o.Seq().Rest())
+	default:
+		panic(RT.NewError("Expected SQL string or vector of SQL string and parameters, got " + sqlParams.GetType().ToString(false)))
+	}
+}
+
+func isBinary(ct *sql.ColumnType) bool {
+	name := strings.ToUpper(ct.DatabaseTypeName())
+	return name == "" || strings.Contains(name, "BLOB") || strings.Contains(name, "BINARY") || name == "BYTEA"
+}
+
+// fromColumn converts value returned by SQL driver to Joker value.
+func fromColumn(v interface{}, ct *sql.ColumnType) Object {
+	switch v := v.(type) {
+	case nil:
+		return NIL
+	case int64:
+		// SQLite stores booleans as integers.
+		if name := strings.ToUpper(ct.DatabaseTypeName()); name == "BOOLEAN" || name == "BOOL" {
+			return MakeBoolean(v != 0)
+		}
+		return MakeInt(int(v))
+	case float64:
+		return MakeDouble(v)
+	case bool:
+		return MakeBoolean(v)
+	case string:
+		return MakeString(v)
+	case time.Time:
+		return MakeTime(v)
+	case []byte:
+		if isBinary(ct) {
+			return MakeBytes(append([]byte(nil), v...))
+		}
+		return MakeString(string(v))
+	default:
+		panic(RT.NewError("Unsupported SQL value type in column " + ct.Name()))
+	}
+}
+
+func readRows(rows *sql.Rows) Object {
+	defer rows.Close()
+	cts, err := rows.ColumnTypes()
+	PanicOnErr(err)
+	keys := make([]Object, len(cts))
+	for i, ct := range cts {
+		keys[i] = MakeKeyword(ct.Name())
+	}
+	values := make([]interface{}, len(cts))
+	ptrs := make([]interface{}, len(cts))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	res := EmptyVector()
+	for rows.Next() {
+		PanicOnErr(rows.Scan(ptrs...))
+		row := EmptyArrayMap()
+		for i, v := range values {
+			row.Set(keys[i], fromColumn(v, cts[i]))
+		}
+		res = res.Conjoin(row)
+	}
+	PanicOnErr(rows.Err())
+	return res
+}
+
+func makeResult(r sql.Result) Object {
+	res := EmptyArrayMap()
+	n, err := r.RowsAffected()
+	PanicOnErr(err)
+	res.Add(MakeKeyword("rows-affected"), MakeInt(int(n)))
+	// Not all drivers support last insert id.
+	if id, err := r.LastInsertId(); err == nil {
+		res.Add(MakeKeyword("last-insert-id"), MakeInt(int(id)))
+	} else {
+		res.Add(MakeKeyword("last-insert-id"), NIL)
+	}
+	return res
+}
+
+func execute(obj Object, sqlParams Object) Object {
+	var r sql.Result
+	var err error
+	if stmt, ok := obj.(*SQLStmt); ok {
+		r, err = stmt.Exec(toParams(sqlParams)...)
+	} else {
+		query, params := splitSQLParams(sqlParams)
+		r, err = toQueryer(obj).Exec(query, params...)
+	}
+	PanicOnErr(err)
+	return makeResult(r)
+}
+
+func query(obj Object, sqlParams Object) Object {
+	var rows *sql.Rows
+	var err error
+	if stmt, ok := obj.(*SQLStmt); ok {
+		rows, err = stmt.Query(toParams(sqlParams)...)
+	} else {
+		query, params := splitSQLParams(sqlParams)
+		rows, err = toQueryer(obj).Query(query, params...)
+	}
+	PanicOnErr(err)
+	return readRows(rows)
+}
+
+func prepare(obj Object, query string) Object {
+	stmt, err := toQueryer(obj).Prepare(query)
+	PanicOnErr(err)
+	return MakeSQLStmt(stmt)
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	":default":          sql.LevelDefault,
+	":read-uncommitted": sql.LevelReadUncommitted,
+	":read-committed":   sql.LevelReadCommitted,
+	":write-committed":  sql.LevelWriteCommitted,
+	":repeatable-read":  sql.LevelRepeatableRead,
+	":snapshot":         sql.LevelSnapshot,
+	":serializable":     sql.LevelSerializable,
+	":linearizable":     sql.LevelLinearizable,
+}
+
+func txOptions(opts Map) *sql.TxOptions {
+	res := &sql.TxOptions{}
+	if ok, r := opts.Get(MakeKeyword("read-only?")); ok {
+		res.ReadOnly = ToBool(r)
+	}
+	if ok, l := opts.Get(MakeKeyword("isolation")); ok {
+		level, ok := isolationLevels[l.ToString(false)]
+		if !ok {
+			panic(RT.NewError("Unknown isolation level: " + l.ToString(true)))
+		}
+		res.Isolation = level
+	}
+	return res
+}
+
+// transact calls f with a new transaction of db, which is committed
+// when f returns or rolled back if it throws.
+func transact(rt *Runtime, db SQLDB, f Callable, opts Map) Object {
+	tx, err := db.BeginTx(context.Background(), txOptions(opts))
+	PanicOnErr(err)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	res := f.Call(rt, []Object{MakeSQLTx(tx)})
+	PanicOnErr(tx.Commit())
+	return res
+}
+
+// txMacro expands (with-transaction [tx db opts?] body...) into
+// (joker.sql/transact db (fn [tx] body...) opts?).
+// args start with &form and &env.
+func txMacro(args []Object) Object {
+	if len(args) < 3 {
+		PanicArity(len(args) - 2)
+	}
+	binding := AssertVector(args[2], "with-transaction requires a vector for its binding")
+	if binding.Count() != 2 && binding.Count() != 3 {
+		panic(RT.NewError("with-transaction binding must have two or three forms"))
+	}
+	fn := append([]Object{MakeSymbol("joker.core/fn"), NewVectorFrom(binding.Nth(0))}, args[3:]...)
+	res := []Object{MakeSymbol("joker.sql/transact"), binding.Nth(1), NewListFrom(fn...)}
+	if binding.Count() == 3 {
+		res = append(res, binding.Nth(2))
+	}
+	return NewListFrom(res...)
+}
+
+func init() {
+	sqlDBType = RegType("SQLDB", (*SQLDB)(nil), "Wraps SQL database handle")
+	sqlTxType = RegRefType("SQLTx", (*SQLTx)(nil), "Wraps SQL transaction")
+	sqlStmtType = RegRefType("SQLStmt", (*SQLStmt)(nil), "Wraps SQL prepared statement")
+}
@@ -10,7 +10,7 @@ import (
 var __atoi__P ProcFn = __atoi_
 var atoi_ Proc = Proc{Fn: __atoi__P, Name: "atoi_", Package: "std/strconv"}
 
-func __atoi_(_args []Object) Object {
+func __atoi_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -28,7 +28,7 @@ func __atoi_(_args []Object) Object {
 var __iscan_backquote__P ProcFn = __iscan_backquote_
 var iscan_backquote_ Proc = Proc{Fn: __iscan_backquote__P, Name: "iscan_backquote_", Package: "std/strconv"}
 
-func __iscan_backquote_(_args []Object) Object {
+func __iscan_backquote_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -45,7 +45,7 @@ func __iscan_backquote_(_args []Object) Object {
 var __format_bool__P ProcFn = __format_bool_
 var format_bool_ Proc = Proc{Fn: __format_bool__P, Name: "format_bool_", Package: "std/strconv"}
 
-func __format_bool_(_args []Object) Object {
+func __format_bool_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -62,7 +62,7 @@ func __format_bool_(_args []Object) Object {
 var __format_double__P ProcFn = __format_double_
 var format_double_ Proc = Proc{Fn: __format_double__P, Name: "format_double_", Package: "std/strconv"}
 
-func __format_double_(_args []Object) Object {
+func __format_double_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 4:
@@ -82,7 +82,7 @@ func __format_double_(_args []Object) Object {
 var __format_int__P ProcFn = __format_int_
 var format_int_ Proc = Proc{Fn: __format_int__P, Name: "format_int_", Package: "std/strconv"}
 
-func __format_int_(_args []Object) Object {
+func __format_int_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -100,7 +100,7 @@ func __format_int_(_args []Object) Object {
 var __isgraphic__P ProcFn = __isgraphic_
 var isgraphic_ Proc = Proc{Fn: __isgraphic__P, Name: "isgraphic_", Package: "std/strconv"}
 
-func __isgraphic_(_args []Object) Object {
+func __isgraphic_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -117,7 +117,7 @@ func __isgraphic_(_args []Object) Object {
 var __itoa__P ProcFn = __itoa_
 var itoa_ Proc = Proc{Fn: __itoa__P, Name: "itoa_", Package: "std/strconv"}
 
-func __itoa_(_args []Object) Object {
+func __itoa_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -134,7 +134,7 @@ func __itoa_(_args []Object) Object {
 var __parse_bool__P ProcFn = __parse_bool_
 var parse_bool_ Proc = Proc{Fn: __parse_bool__P, Name: "parse_bool_", Package: "std/strconv"}
 
-func __parse_bool_(_args []Object) Object {
+func __parse_bool_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -152,7 +152,7 @@ func __parse_bool_(_args []Object) Object {
 var __parse_double__P ProcFn = __parse_double_
 var parse_double_ Proc = Proc{Fn: __parse_double__P, Name: "parse_double_", Package: "std/strconv"}
 
-func __parse_double_(_args []Object) Object {
+func __parse_double_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -170,7 +170,7 @@ func __parse_double_(_args []Object) Object {
 var __parse_int__P ProcFn = __parse_int_
 var parse_int_ Proc = Proc{Fn: __parse_int__P, Name: "parse_int_", Package: "std/strconv"}
 
-func __parse_int_(_args []Object) Object {
+func __parse_int_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
@@ -191,7 +191,7 @@ func __parse_int_(_args []Object) Object {
 var __isprintable__P ProcFn = __isprintable_
 var isprintable_ Proc = Proc{Fn: __isprintable__P, Name: "isprintable_", Package: "std/strconv"}
 
-func __isprintable_(_args []Object) Object {
+func __isprintable_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -208,7 +208,7 @@ func __isprintable_(_args []Object) Object {
 var __quote__P ProcFn = __quote_
 var quote_ Proc = Proc{Fn: __quote__P, Name: "quote_", Package: "std/strconv"}
 
-func __quote_(_args []Object) Object {
+func __quote_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -225,7 +225,7 @@ func __quote_(_args []Object) Object {
 var __quote_char__P ProcFn = __quote_char_
 var quote_char_ Proc = Proc{Fn: __quote_char__P, Name: "quote_char_", Package: "std/strconv"}
 
-func __quote_char_(_args []Object) Object {
+func __quote_char_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -242,7 +242,7 @@ func __quote_char_(_args []Object) Object {
 var __quote_char_to_ascii__P ProcFn = __quote_char_to_ascii_
 var quote_char_to_ascii_ Proc = Proc{Fn: __quote_char_to_ascii__P, Name: "quote_char_to_ascii_", Package: "std/strconv"}
 
-func __quote_char_to_ascii_(_args []Object) Object {
+func __quote_char_to_ascii_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -259,7 +259,7 @@ func __quote_char_to_ascii_(_args []Object) Object {
 var __quote_char_to_graphic__P ProcFn = __quote_char_to_graphic_
 var quote_char_to_graphic_ Proc = Proc{Fn: __quote_char_to_graphic__P, Name: "quote_char_to_graphic_", Package: "std/strconv"}
 
-func __quote_char_to_graphic_(_args []Object) Object {
+func __quote_char_to_graphic_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -276,7 +276,7 @@ func __quote_char_to_graphic_(_args []Object) Object {
 var __quote_to_ascii__P ProcFn = __quote_to_ascii_
 var quote_to_ascii_ Proc = Proc{Fn: __quote_to_ascii__P, Name: "quote_to_ascii_", Package: "std/strconv"}
 
-func __quote_to_ascii_(_args []Object) Object {
+func __quote_to_ascii_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -293,7 +293,7 @@ func __quote_to_ascii_(_args []Object) Object {
 var __quote_to_graphic__P ProcFn = __quote_to_graphic_
 var quote_to_graphic_ Proc = Proc{Fn: __quote_to_graphic__P, Name: "quote_to_graphic_", Package: "std/strconv"}
 
-func __quote_to_graphic_(_args []Object) Object {
+func __quote_to_graphic_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -310,7 +310,7 @@ func __quote_to_graphic_(_args []Object) Object {
 var __unquote__P ProcFn = __unquote_
 var unquote_ Proc = Proc{Fn: __unquote__P, Name: "unquote_", Package: "std/strconv"}
 
-func __unquote_(_args []Object) Object {
+func __unquote_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
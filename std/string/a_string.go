@@ -12,7 +12,7 @@ import (
 var __isblank__P ProcFn = __isblank_
 var isblank_ Proc = Proc{Fn: __isblank__P, Name: "isblank_", Package: "std/string"}
 
-func __isblank_(_args []Object) Object {
+func __isblank_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -29,7 +29,7 @@ func __isblank_(_args []Object) Object {
 var __capitalize__P ProcFn = __capitalize_
 var capitalize_ Proc = Proc{Fn: __capitalize__P, Name: "capitalize_", Package: "std/string"}
 
-func __capitalize_(_args []Object) Object {
+func __capitalize_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -46,7 +46,7 @@ func __capitalize_(_args []Object) Object {
 var __isends_with__P ProcFn = __isends_with_
 var isends_with_ Proc = Proc{Fn: __isends_with__P, Name: "isends_with_", Package: "std/string"}
 
-func __isends_with_(_args []Object) Object {
+func __isends_with_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -64,13 +64,13 @@ func __isends_with_(_args []Object) Object {
 var __escape__P ProcFn = __escape_
 var escape_ Proc = Proc{Fn: __escape__P, Name: "escape_", Package: "std/string"}
 
-func __escape_(_args []Object) Object {
+func __escape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
 		s := ExtractString(_args, 0)
 		cmap := ExtractCallable(_args, 1)
-		_res := escape(s, cmap)
+		_res := escape(_rt, s, cmap)
 		return MakeString(_res)
 
 	default:
@@ -82,7 +82,7 @@ func __escape_(_args []Object) Object {
 var __isincludes__P ProcFn = __isincludes_
 var isincludes_ Proc = Proc{Fn: __isincludes__P, Name: "isincludes_", Package: "std/string"}
 
-func __isincludes_(_args []Object) Object {
+func __isincludes_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -100,7 +100,7 @@ func __isincludes_(_args []Object) Object {
 var __index_of__P ProcFn = __index_of_
 var index_of_ Proc = Proc{Fn: __index_of__P, Name: "index_of_", Package: "std/string"}
 
-func __index_of_(_args []Object) Object {
+func __index_of_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -125,7 +125,7 @@ func __index_of_(_args []Object) Object {
 var __join__P ProcFn = __join_
 var join_ Proc = Proc{Fn: __join__P, Name: "join_", Package: "std/string"}
 
-func __join_(_args []Object) Object {
+func __join_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -148,7 +148,7 @@ func __join_(_args []Object) Object {
 var __last_index_of__P ProcFn = __last_index_of_
 var last_index_of_ Proc = Proc{Fn: __last_index_of__P, Name: "last_index_of_", Package: "std/string"}
 
-func __last_index_of_(_args []Object) Object {
+func __last_index_of_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -173,7 +173,7 @@ func __last_index_of_(_args []Object) Object {
 var __lower_case__P ProcFn = __lower_case_
 var lower_case_ Proc = Proc{Fn: __lower_case__P, Name: "lower_case_", Package: "std/string"}
 
-func __lower_case_(_args []Object) Object {
+func __lower_case_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -190,7 +190,7 @@ func __lower_case_(_args []Object) Object {
 var __pad_left__P ProcFn = __pad_left_
 var pad_left_ Proc = Proc{Fn: __pad_left__P, Name: "pad_left_", Package: "std/string"}
 
-func __pad_left_(_args []Object) Object {
+func __pad_left_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
@@ -209,7 +209,7 @@ func __pad_left_(_args []Object) Object {
 var __pad_right__P ProcFn = __pad_right_
 var pad_right_ Proc = Proc{Fn: __pad_right__P, Name: "pad_right_", Package: "std/string"}
 
-func __pad_right_(_args []Object) Object {
+func __pad_right_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
@@ -228,7 +228,7 @@ func __pad_right_(_args []Object) Object {
 var __re_quote__P ProcFn = __re_quote_
 var re_quote_ Proc = Proc{Fn: __re_quote__P, Name: "re_quote_", Package: "std/string"}
 
-func __re_quote_(_args []Object) Object {
+func __re_quote_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -245,7 +245,7 @@ func __re_quote_(_args []Object) Object {
 var __replace__P ProcFn = __replace_
 var replace_ Proc = Proc{Fn: __replace__P, Name: "replace_", Package: "std/string"}
 
-func __replace_(_args []Object) Object {
+func __replace_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
@@ -264,7 +264,7 @@ func __replace_(_args []Object) Object {
 var __replace_first__P ProcFn = __replace_first_
 var replace_first_ Proc = Proc{Fn: __replace_first__P, Name: "replace_first_", Package: "std/string"}
 
-func __replace_first_(_args []Object) Object {
+func __replace_first_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 3:
@@ -283,7 +283,7 @@ func __replace_first_(_args []Object) Object {
 var __reverse__P ProcFn = __reverse_
 var reverse_ Proc = Proc{Fn: __reverse__P, Name: "reverse_", Package: "std/string"}
 
-func __reverse_(_args []Object) Object {
+func __reverse_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -300,7 +300,7 @@ func __reverse_(_args []Object) Object {
 var __split__P ProcFn = __split_
 var split_ Proc = Proc{Fn: __split__P, Name: "split_", Package: "std/string"}
 
-func __split_(_args []Object) Object {
+func __split_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -325,7 +325,7 @@ func __split_(_args []Object) Object {
 var __split_lines__P ProcFn = __split_lines_
 var split_lines_ Proc = Proc{Fn: __split_lines__P, Name: "split_lines_", Package: "std/string"}
 
-func __split_lines_(_args []Object) Object {
+func __split_lines_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -342,7 +342,7 @@ func __split_lines_(_args []Object) Object {
 var __isstarts_with__P ProcFn = __isstarts_with_
 var isstarts_with_ Proc = Proc{Fn: __isstarts_with__P, Name: "isstarts_with_", Package: "std/string"}
 
-func __isstarts_with_(_args []Object) Object {
+func __isstarts_with_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -360,7 +360,7 @@ func __isstarts_with_(_args []Object) Object {
 var __trim__P ProcFn = __trim_
 var trim_ Proc = Proc{Fn: __trim__P, Name: "trim_", Package: "std/string"}
 
-func __trim_(_args []Object) Object {
+func __trim_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -377,7 +377,7 @@ func __trim_(_args []Object) Object {
 var __trim_left__P ProcFn = __trim_left_
 var trim_left_ Proc = Proc{Fn: __trim_left__P, Name: "trim_left_", Package: "std/string"}
 
-func __trim_left_(_args []Object) Object {
+func __trim_left_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -394,7 +394,7 @@ func __trim_left_(_args []Object) Object {
 var __trim_newline__P ProcFn = __trim_newline_
 var trim_newline_ Proc = Proc{Fn: __trim_newline__P, Name: "trim_newline_", Package: "std/string"}
 
-func __trim_newline_(_args []Object) Object {
+func __trim_newline_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -411,7 +411,7 @@ func __trim_newline_(_args []Object) Object {
 var __trim_right__P ProcFn = __trim_right_
 var trim_right_ Proc = Proc{Fn: __trim_right__P, Name: "trim_right_", Package: "std/string"}
 
-func __trim_right_(_args []Object) Object {
+func __trim_right_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -428,7 +428,7 @@ func __trim_right_(_args []Object) Object {
 var __triml__P ProcFn = __triml_
 var triml_ Proc = Proc{Fn: __triml__P, Name: "triml_", Package: "std/string"}
 
-func __triml_(_args []Object) Object {
+func __triml_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -445,7 +445,7 @@ func __triml_(_args []Object) Object {
 var __trimr__P ProcFn = __trimr_
 var trimr_ Proc = Proc{Fn: __trimr__P, Name: "trimr_", Package: "std/string"}
 
-func __trimr_(_args []Object) Object {
+func __trimr_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -462,7 +462,7 @@ func __trimr_(_args []Object) Object {
 var __upper_case__P ProcFn = __upper_case_
 var upper_case_ Proc = Proc{Fn: __upper_case__P, Name: "upper_case_", Package: "std/string"}
 
-func __upper_case_(_args []Object) Object {
+func __upper_case_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -107,10 +107,10 @@ func capitalize(s string) string {
 	return strings.ToUpper(string([]rune(s)[:1])) + strings.ToLower(string([]rune(s)[1:]))
 }
 
-func escape(s string, cmap Callable) string {
+func escape(rt *Runtime, s string, cmap Callable) string {
 	var b bytes.Buffer
 	for _, r := range s {
-		if obj := cmap.Call([]Object{Char{Ch: r}}); !obj.Equals(NIL) {
+		if obj := cmap.Call(rt, []Object{Char{Ch: r}}); !obj.Equals(NIL) {
 			b.WriteString(obj.ToString(false))
 		} else {
 			b.WriteRune(r)
@@ -31,7 +31,7 @@ var unix_date_ String
 var __add__P ProcFn = __add_
 var add_ Proc = Proc{Fn: __add__P, Name: "add_", Package: "std/time"}
 
-func __add_(_args []Object) Object {
+func __add_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -49,7 +49,7 @@ func __add_(_args []Object) Object {
 var __add_date__P ProcFn = __add_date_
 var add_date_ Proc = Proc{Fn: __add_date__P, Name: "add_date_", Package: "std/time"}
 
-func __add_date_(_args []Object) Object {
+func __add_date_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 4:
@@ -69,7 +69,7 @@ func __add_date_(_args []Object) Object {
 var __format__P ProcFn = __format_
 var format_ Proc = Proc{Fn: __format__P, Name: "format_", Package: "std/time"}
 
-func __format_(_args []Object) Object {
+func __format_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -87,7 +87,7 @@ func __format_(_args []Object) Object {
 var __from_unix__P ProcFn = __from_unix_
 var from_unix_ Proc = Proc{Fn: __from_unix__P, Name: "from_unix_", Package: "std/time"}
 
-func __from_unix_(_args []Object) Object {
+func __from_unix_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -105,7 +105,7 @@ func __from_unix_(_args []Object) Object {
 var __hours__P ProcFn = __hours_
 var hours_ Proc = Proc{Fn: __hours__P, Name: "hours_", Package: "std/time"}
 
-func __hours_(_args []Object) Object {
+func __hours_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -122,7 +122,7 @@ func __hours_(_args []Object) Object {
 var __in_timezone__P ProcFn = __in_timezone_
 var in_timezone_ Proc = Proc{Fn: __in_timezone__P, Name: "in_timezone_", Package: "std/time"}
 
-func __in_timezone_(_args []Object) Object {
+func __in_timezone_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -140,7 +140,7 @@ func __in_timezone_(_args []Object) Object {
 var __minutes__P ProcFn = __minutes_
 var minutes_ Proc = Proc{Fn: __minutes__P, Name: "minutes_", Package: "std/time"}
 
-func __minutes_(_args []Object) Object {
+func __minutes_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -157,7 +157,7 @@ func __minutes_(_args []Object) Object {
 var __now__P ProcFn = __now_
 var now_ Proc = Proc{Fn: __now__P, Name: "now_", Package: "std/time"}
 
-func __now_(_args []Object) Object {
+func __now_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 0:
@@ -173,7 +173,7 @@ func __now_(_args []Object) Object {
 var __parse__P ProcFn = __parse_
 var parse_ Proc = Proc{Fn: __parse__P, Name: "parse_", Package: "std/time"}
 
-func __parse_(_args []Object) Object {
+func __parse_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -192,7 +192,7 @@ func __parse_(_args []Object) Object {
 var __parse_duration__P ProcFn = __parse_duration_
 var parse_duration_ Proc = Proc{Fn: __parse_duration__P, Name: "parse_duration_", Package: "std/time"}
 
-func __parse_duration_(_args []Object) Object {
+func __parse_duration_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -211,7 +211,7 @@ func __parse_duration_(_args []Object) Object {
 var __round__P ProcFn = __round_
 var round_ Proc = Proc{Fn: __round__P, Name: "round_", Package: "std/time"}
 
-func __round_(_args []Object) Object {
+func __round_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -229,7 +229,7 @@ func __round_(_args []Object) Object {
 var __seconds__P ProcFn = __seconds_
 var seconds_ Proc = Proc{Fn: __seconds__P, Name: "seconds_", Package: "std/time"}
 
-func __seconds_(_args []Object) Object {
+func __seconds_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -246,7 +246,7 @@ func __seconds_(_args []Object) Object {
 var __since__P ProcFn = __since_
 var since_ Proc = Proc{Fn: __since__P, Name: "since_", Package: "std/time"}
 
-func __since_(_args []Object) Object {
+func __since_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -263,14 +263,12 @@ func __since_(_args []Object) Object {
 var __sleep__P ProcFn = __sleep_
 var sleep_ Proc = Proc{Fn: __sleep__P, Name: "sleep_", Package: "std/time"}
 
-func __sleep_(_args []Object) Object {
+func __sleep_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
 		d := ExtractInteger(_args, 0)
-		RT.GIL.Unlock()
-		time.Sleep(time.Duration(d))
-		RT.GIL.Lock()
+		_rt.Sleep(time.Duration(d))
 		_res := NIL
 		return _res
 
@@ -283,7 +281,7 @@ func __sleep_(_args []Object) Object {
 var __string__P ProcFn = __string_
 var string_ Proc = Proc{Fn: __string__P, Name: "string_", Package: "std/time"}
 
-func __string_(_args []Object) Object {
+func __string_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -300,7 +298,7 @@ func __string_(_args []Object) Object {
 var __sub__P ProcFn = __sub_
 var sub_ Proc = Proc{Fn: __sub__P, Name: "sub_", Package: "std/time"}
 
-func __sub_(_args []Object) Object {
+func __sub_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -318,7 +316,7 @@ func __sub_(_args []Object) Object {
 var __truncate__P ProcFn = __truncate_
 var truncate_ Proc = Proc{Fn: __truncate__P, Name: "truncate_", Package: "std/time"}
 
-func __truncate_(_args []Object) Object {
+func __truncate_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 2:
@@ -336,7 +334,7 @@ func __truncate_(_args []Object) Object {
 var __unix__P ProcFn = __unix_
 var unix_ Proc = Proc{Fn: __unix__P, Name: "unix_", Package: "std/time"}
 
-func __unix_(_args []Object) Object {
+func __unix_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -353,7 +351,7 @@ func __unix_(_args []Object) Object {
 var __until__P ProcFn = __until_
 var until_ Proc = Proc{Fn: __until__P, Name: "until_", Package: "std/time"}
 
-func __until_(_args []Object) Object {
+func __until_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -0,0 +1,36 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package toml
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of toml.InternsOrThunks().")
+	}
+	tomlNamespace.ResetMeta(MakeMeta(nil, `Implements encoding and decoding of TOML (https://toml.io).`, "1.0"))
+
+	tomlNamespace.InternVar("read-string", read_string_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s")), NewVectorFrom(MakeSymbol("s"), MakeSymbol("opts"))),
+			`Parses the TOML-encoded data and returns the result as a map.
+  Tables become maps, arrays become vectors and offset date-times
+  become Time values. Local date-times, local dates and local times,
+  which have no time zone, become tagged literals with the value as
+  written, e.g. #toml/local-date-time "1979-05-27T07:32:00",
+  #toml/local-date "1979-05-27" and #toml/local-time "07:32:00".
+  Optional opts map may have the following keys:
+  :keywords? - if true, keys will be converted from strings to keywords.`, "1.0"))
+
+	tomlNamespace.InternVar("write-string", write_string_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("v"))),
+			`Returns the TOML encoding of map v. Keys with nil values are omitted,
+  since TOML has no null. Tagged literals returned by read-string for
+  local date-times, local dates and local times are encoded as such.`, "1.0"))
+
+}
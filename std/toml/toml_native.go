@@ -0,0 +1,167 @@
+package toml
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	. "github.com/candid82/joker/core"
+)
+
+// localValue is TOML local date-time, local date or local time,
+// which is encoded as is.
+type localValue string
+
+// Layouts of TOML local values by the tags they are read with.
+var localLayouts = map[string]string{
+	"toml/local-date-time": "2006-01-02T15:04:05.999999999",
+	"toml/local-date":      "2006-01-02",
+	"toml/local-time":      "15:04:05.999999999",
+}
+
+// Decoded local values are in time zones named after their types.
+var localTags = map[string]string{
+	"datetime-local": "toml/local-date-time",
+	"date-local":     "toml/local-date",
+	"time-local":     "toml/local-time",
+}
+
+func (v localValue) MarshalTOML() ([]byte, error) {
+	return []byte(v), nil
+}
+
+func fromTaggedLiteral(obj *TaggedLiteral) interface{} {
+	tag := obj.Tag.ToString(false)
+	layout, ok := localLayouts[tag]
+	if !ok {
+		return obj.ToString(false)
+	}
+	s, ok := obj.Form.(String)
+	if !ok {
+		panic(RT.NewError("Cannot encode value to toml: #" + tag + " value must be a string, got " + obj.Form.GetType().ToString(false)))
+	}
+	if _, err := time.Parse(layout, s.S); err != nil {
+		panic(RT.NewError("Cannot encode value to toml: invalid #" + tag + " value: " + s.S))
+	}
+	return localValue(s.S)
+}
+
+func fromObject(obj Object) interface{} {
+	switch obj := obj.(type) {
+	case Keyword:
+		return obj.ToString(false)[1:]
+	case Boolean:
+		return obj.B
+	case Int:
+		return int64(obj.I)
+	case *BigInt:
+		if !obj.BigInt().IsInt64() {
+			panic(RT.NewError("Cannot encode value to toml: integer is out of int64 range: " + obj.ToString(false)))
+		}
+		return obj.BigInt().Int64()
+	case Number:
+		return obj.Double().D
+	case Time:
+		return obj.T
+	case *TaggedLiteral:
+		return fromTaggedLiteral(obj)
+	case Nil:
+		panic(RT.NewError("Cannot encode value to toml: nil is not supported"))
+	case Map:
+		res := make(map[string]interface{})
+		for iter := obj.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			// TOML has no null, so keys with nil values are omitted.
+			if p.Value.Equals(NIL) {
+				continue
+			}
+			var k string
+			switch p.Key.(type) {
+			case Keyword:
+				k = p.Key.ToString(false)[1:]
+			default:
+				k = p.Key.ToString(false)
+			}
+			res[k] = fromObject(p.Value)
+		}
+		return res
+	case String:
+		return obj.S
+	case Seqable:
+		res := []interface{}{}
+		for s := obj.Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, fromObject(s.First()))
+		}
+		return res
+	default:
+		return obj.ToString(false)
+	}
+}
+
+func toObject(v interface{}, keywordize bool) Object {
+	switch v := v.(type) {
+	case string:
+		return MakeString(v)
+	case int64:
+		return MakeInt(int(v))
+	case float64:
+		return MakeDouble(v)
+	case bool:
+		return MakeBoolean(v)
+	case time.Time:
+		if tag, ok := localTags[v.Location().String()]; ok {
+			return MakeTaggedLiteral(MakeSymbol(tag), MakeString(v.Format(localLayouts[tag])))
+		}
+		return MakeTime(v)
+	case []interface{}:
+		res := EmptyVector()
+		for _, v := range v {
+			res = res.Conjoin(toObject(v, keywordize))
+		}
+		return res
+	case []map[string]interface{}:
+		res := EmptyVector()
+		for _, v := range v {
+			res = res.Conjoin(toObject(v, keywordize))
+		}
+		return res
+	case map[string]interface{}:
+		res := EmptyArrayMap()
+		for k, v := range v {
+			if keywordize {
+				res.Add(MakeKeyword(k), toObject(v, keywordize))
+			} else {
+				res.Add(MakeString(k), toObject(v, keywordize))
+			}
+		}
+		return res
+	default:
+		panic(RT.NewError(fmt.Sprintf("Unknown toml value: %v", v)))
+	}
+}
+
+func readString(s string, opts Map) Object {
+	var v map[string]interface{}
+	if _, err := toml.Decode(s, &v); err != nil {
+		panic(RT.NewError("Invalid toml: " + err.Error()))
+	}
+	var keywordize bool
+	if ok, k := opts.Get(MakeKeyword("keywords?")); ok {
+		keywordize = ToBool(k)
+	}
+	return toObject(v, keywordize)
+}
+
+func writeString(obj Object) String {
+	m, ok := fromObject(obj).(map[string]interface{})
+	if !ok {
+		panic(RT.NewError("Cannot encode value to toml: expected a map, got " + obj.GetType().ToString(false)))
+	}
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(m); err != nil {
+		panic(RT.NewError("Cannot encode value to toml: " + err.Error()))
+	}
+	return String{S: b.String()}
+}
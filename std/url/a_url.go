@@ -10,7 +10,7 @@ import (
 var __path_escape__P ProcFn = __path_escape_
 var path_escape_ Proc = Proc{Fn: __path_escape__P, Name: "path_escape_", Package: "std/url"}
 
-func __path_escape_(_args []Object) Object {
+func __path_escape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -27,7 +27,7 @@ func __path_escape_(_args []Object) Object {
 var __path_unescape__P ProcFn = __path_unescape_
 var path_unescape_ Proc = Proc{Fn: __path_unescape__P, Name: "path_unescape_", Package: "std/url"}
 
-func __path_unescape_(_args []Object) Object {
+func __path_unescape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -44,7 +44,7 @@ func __path_unescape_(_args []Object) Object {
 var __query_escape__P ProcFn = __query_escape_
 var query_escape_ Proc = Proc{Fn: __query_escape__P, Name: "query_escape_", Package: "std/url"}
 
-func __query_escape_(_args []Object) Object {
+func __query_escape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
@@ -61,7 +61,7 @@ func __query_escape_(_args []Object) Object {
 var __query_unescape__P ProcFn = __query_unescape_
 var query_unescape_ Proc = Proc{Fn: __query_unescape__P, Name: "query_unescape_", Package: "std/url"}
 
-func __query_unescape_(_args []Object) Object {
+func __query_unescape_(_rt *Runtime, _args []Object) Object {
 	_c := len(_args)
 	switch {
 	case _c == 1:
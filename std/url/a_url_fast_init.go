@@ -0,0 +1,21 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+// +build !gen_code
+
+package url
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running fast version of url.InternsOrThunks().")
+	}
+	STD_thunk_url_path_escape__var = __path_escape_
+	STD_thunk_url_path_unescape__var = __path_unescape_
+	STD_thunk_url_query_escape__var = __query_escape_
+	STD_thunk_url_query_unescape__var = __query_unescape_
+}
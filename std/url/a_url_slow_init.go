@@ -1,5 +1,7 @@
 // This file is generated by generate-std.joke script. Do not edit manually!
 
+// +build gen_code
+
 package url
 
 import (
@@ -17,6 +17,7 @@ func InternsOrThunks() {
 	uuidNamespace.InternVar("new", new_,
 		MakeMeta(
 			NewListFrom(NewVectorFrom()),
-			`Creates a new random UUID.`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
+			`Creates a new random UUID and returns its string representation.
+  See also joker.core/random-uuid, which returns a UUID.`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
 
 }
@@ -0,0 +1,147 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package websocket
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __close_BANG__P ProcFn = __close_BANG_
+var close_BANG_ Proc = Proc{Fn: __close_BANG__P, Name: "close_BANG_", Package: "std/websocket"}
+
+func __close_BANG_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractWebSocket(_args, 0)
+		_res := closeConn(conn, 1000, "")
+		return _res
+
+	case _c == 3:
+		conn := ExtractWebSocket(_args, 0)
+		code := ExtractInt(_args, 1)
+		reason := ExtractString(_args, 2)
+		_res := closeConn(conn, code, reason)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __connect__P ProcFn = __connect_
+var connect_ Proc = Proc{Fn: __connect__P, Name: "connect_", Package: "std/websocket"}
+
+func __connect_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		url := ExtractString(_args, 0)
+		_res := connect(url, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		url := ExtractString(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := connect(url, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __messages__P ProcFn = __messages_
+var messages_ Proc = Proc{Fn: __messages__P, Name: "messages_", Package: "std/websocket"}
+
+func __messages_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractWebSocket(_args, 0)
+		_res := messages(conn)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __ping_BANG__P ProcFn = __ping_BANG_
+var ping_BANG_ Proc = Proc{Fn: __ping_BANG__P, Name: "ping_BANG_", Package: "std/websocket"}
+
+func __ping_BANG_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		conn := ExtractWebSocket(_args, 0)
+		_res := ping(conn, "")
+		return _res
+
+	case _c == 2:
+		conn := ExtractWebSocket(_args, 0)
+		data := ExtractString(_args, 1)
+		_res := ping(conn, data)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __send_BANG__P ProcFn = __send_BANG_
+var send_BANG_ Proc = Proc{Fn: __send_BANG__P, Name: "send_BANG_", Package: "std/websocket"}
+
+func __send_BANG_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		conn := ExtractWebSocket(_args, 0)
+		data := ExtractBinary(_args, 1)
+		_res := send(conn, data, ":text")
+		return _res
+
+	case _c == 3:
+		conn := ExtractWebSocket(_args, 0)
+		data := ExtractBinary(_args, 1)
+		kind := ExtractKeyword(_args, 2)
+		_res := send(conn, data, kind)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __isupgrade_request__P ProcFn = __isupgrade_request_
+var isupgrade_request_ Proc = Proc{Fn: __isupgrade_request__P, Name: "isupgrade_request_", Package: "std/websocket"}
+
+func __isupgrade_request_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		request := ExtractMap(_args, 0)
+		_res := IsUpgradeRequest(request)
+		return MakeBoolean(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var websocketNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.websocket"))
+
+func init() {
+	websocketNamespace.Lazy = Init
+}
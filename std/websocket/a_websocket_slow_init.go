@@ -0,0 +1,89 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package websocket
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of websocket.InternsOrThunks().")
+	}
+	websocketNamespace.ResetMeta(MakeMeta(nil, `Provides websocket client and server implementations.
+
+         Incoming messages of a connection are put on a channel (see messages)
+         as maps with :type (:text or :binary) and :data keys. Data of text
+         messages is a string, data of binary messages is Bytes.
+         The channel is closed when the connection is closed. Pings are
+         replied to automatically.
+
+         Client example:
+
+         user=> (def conn (joker.websocket/connect "ws://localhost:8080/echo"))
+         #'user/conn
+         user=> (joker.websocket/send! conn "hello")
+         nil
+         user=> (<! (joker.websocket/messages conn))
+         {:type :text, :data "hello"}
+         user=> (joker.websocket/close! conn)
+         nil
+
+         Server example (see joker.http/start-server):
+
+         (joker.http/start-server
+          "localhost:8080"
+          (fn [req]
+            {:websocket (fn [conn]
+                          (go (loop []
+                                (when-let [msg (<! (joker.websocket/messages conn))]
+                                  (joker.websocket/send! conn (:data msg) (:type msg))
+                                  (recur)))))}))`, "1.0"))
+
+	websocketNamespace.InternVar("close!", close_BANG_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn")), NewVectorFrom(MakeSymbol("conn"), MakeSymbol("code"), MakeSymbol("reason"))),
+			`Closes conn, sending close frame with status code (defaults to 1000)
+  and reason (no longer than 123 bytes) and waiting for the peer
+  to reply with close frame for up to 5 seconds.`, "1.0"))
+
+	websocketNamespace.InternVar("connect", connect_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("url")), NewVectorFrom(MakeSymbol("url"), MakeSymbol("opts"))),
+			`Connects to websocket server at url (with ws or wss scheme) and
+  returns the connection.
+  Optional opts map may have the following keys:
+  - headers (map, additional handshake request headers)
+  - timeout (int, handshake timeout in nanoseconds)
+  - insecure? (boolean, if true, server TLS certificate is not verified)
+  - buffer (int, size of the buffer of messages channel, defaults to 16)
+  - ping-interval (int, if provided, the connection is pinged every
+    ping-interval nanoseconds)
+  - max-message-size (int, maximum size of incoming message in bytes,
+    defaults to 32MB; the connection is closed if a bigger message is received).`, "1.0"))
+
+	websocketNamespace.InternVar("messages", messages_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"))),
+			`Returns the channel of incoming messages of conn.`, "1.0"))
+
+	websocketNamespace.InternVar("ping!", ping_BANG_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn")), NewVectorFrom(MakeSymbol("conn"), MakeSymbol("data"))),
+			`Sends ping with optional data (no longer than 125 bytes) to conn.`, "1.0"))
+
+	websocketNamespace.InternVar("send!", send_BANG_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("conn"), MakeSymbol("data")), NewVectorFrom(MakeSymbol("conn"), MakeSymbol("data"), MakeSymbol("kind"))),
+			`Sends message with data (String or Bytes) to conn.
+  kind is :text (default) or :binary. Text messages must be valid UTF-8.`, "1.0"))
+
+	websocketNamespace.InternVar("upgrade-request?", isupgrade_request_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("request"))),
+			`Returns true if request (as passed to joker.http/start-server handler)
+  is a websocket handshake request.`, "1.0").Plus(MakeKeyword("tag"), String{S: "Boolean"}))
+
+}
@@ -0,0 +1,329 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	. "github.com/candid82/joker/core"
+)
+
+// Frame opcodes, see RFC 6455, section 5.2.
+const (
+	opContinuation = 0
+	opText         = 1
+	opBinary       = 2
+	opClose        = 8
+	opPing         = 9
+	opPong         = 10
+)
+
+// Close status codes, see RFC 6455, section 7.4.1.
+const (
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeInvalidData   = 1007
+	closeTooBig        = 1009
+	closeNoStatus      = 1005
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// closeTimeout is how long close waits for the peer to reply
+// with a close frame before dropping the connection.
+const closeTimeout = 5 * time.Second
+
+var errProtocol = errors.New("websocket protocol error")
+
+type (
+	// Conn is a websocket connection. Incoming messages are put
+	// on the messages channel by a goroutine started for each connection,
+	// which also replies to pings and close frames. The channel is closed
+	// when the connection is closed.
+	Conn struct {
+		conn           net.Conn
+		br             *bufio.Reader
+		client         bool
+		maxMessageSize int
+		messages       *Channel
+		writeMu        sync.Mutex // serializes writes of frames
+		closeMu        sync.Mutex // guards closeSent
+		closeSent      bool
+		done           chan struct{}
+	}
+)
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+acceptGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func newKey() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	PanicOnErr(err)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, client bool, opts *options) *Conn {
+	c := &Conn{
+		conn:           conn,
+		br:             br,
+		client:         client,
+		maxMessageSize: opts.maxMessageSize,
+		messages:       MakeBufferedChannel(MakeChannelBuffer(opts.buffer, FIXED_BUFFER)),
+		done:           make(chan struct{}),
+	}
+	// Nothing interrupts the read loop, it stops when the connection does.
+	go c.readLoop(NewRuntime())
+	if opts.pingInterval > 0 {
+		go c.pingLoop(opts.pingInterval)
+	}
+	return c
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | op
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if c.client {
+		// Frames sent by clients must be masked.
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		header[1] |= 0x80
+		header = append(header, mask[:]...)
+		masked := make([]byte, len(payload))
+		for i, b := range payload {
+			masked[i] = b ^ mask[i%4]
+		}
+		payload = masked
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	op = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if header[0]&0x70 != 0 || masked == c.client {
+		// Reserved bits are not supported, frames from clients
+		// must be masked and frames from servers must not.
+		err = errProtocol
+		return
+	}
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if op >= opClose && (n > 125 || !fin) {
+		err = errProtocol
+		return
+	}
+	if c.maxMessageSize > 0 && n > uint64(c.maxMessageSize) {
+		err = errMessageTooBig
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+var errMessageTooBig = errors.New("websocket message is too big")
+
+func makeMessage(op byte, data []byte) Object {
+	t := "text"
+	if op == opBinary {
+		t = "binary"
+	}
+	var d Object = MakeString(string(data))
+	if op == opBinary {
+		d = MakeBytes(data)
+	}
+	res := EmptyArrayMap()
+	res.Add(MakeKeyword("type"), MakeKeyword(t))
+	res.Add(MakeKeyword("data"), d)
+	return res
+}
+
+func (c *Conn) readLoop(rt *Runtime) {
+	defer c.finish()
+	var op byte
+	var msg []byte
+	for {
+		fin, frameOp, payload, err := c.readFrame()
+		if err != nil {
+			switch err {
+			case errProtocol:
+				c.sendClose(closeProtocolError, "")
+			case errMessageTooBig:
+				c.sendClose(closeTooBig, "")
+			}
+			return
+		}
+		switch frameOp {
+		case opPing:
+			c.writeFrame(opPong, payload)
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := closeNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			if code == closeNoStatus {
+				code = closeNormal
+			}
+			c.sendClose(code, "")
+			return
+		case opText, opBinary:
+			if msg != nil {
+				c.sendClose(closeProtocolError, "")
+				return
+			}
+			op, msg = frameOp, payload
+		case opContinuation:
+			if msg == nil {
+				c.sendClose(closeProtocolError, "")
+				return
+			}
+			msg = append(msg, payload...)
+			if c.maxMessageSize > 0 && len(msg) > c.maxMessageSize {
+				c.sendClose(closeTooBig, "")
+				return
+			}
+		default:
+			c.sendClose(closeProtocolError, "")
+			return
+		}
+		if fin {
+			if op == opText && !utf8.Valid(msg) {
+				c.sendClose(closeInvalidData, "")
+				return
+			}
+			c.messages.Put(rt, makeMessage(op, msg))
+			msg = nil
+		}
+	}
+}
+
+func (c *Conn) pingLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if c.writeFrame(opPing, nil) != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Conn) finish() {
+	c.conn.Close()
+	c.messages.Close()
+	close(c.done)
+}
+
+// sendClose sends close frame unless it's already been sent.
+// Returns false if it has.
+func (c *Conn) sendClose(code int, reason string) bool {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	if c.closeSent {
+		return false
+	}
+	c.closeSent = true
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	c.writeFrame(opClose, append(payload, reason...))
+	return true
+}
+
+// Close starts the closing handshake and waits (for no longer than
+// closeTimeout) for the peer to reply, after which the connection is closed.
+func (c *Conn) Close(code int, reason string) {
+	if len(reason) > 123 {
+		panic(RT.NewError("Close reason must be no longer than 123 bytes"))
+	}
+	c.sendClose(code, reason)
+	select {
+	case <-c.done:
+	case <-time.After(closeTimeout):
+		// Closing the channel unblocks the reading goroutine
+		// if it waits for a message to be taken.
+		c.conn.Close()
+		c.messages.Close()
+		<-c.done
+	}
+}
+
+func (c *Conn) Send(op byte, data []byte) {
+	select {
+	case <-c.done:
+		panic(RT.NewError("Websocket connection is closed"))
+	default:
+	}
+	if op == opText && !utf8.Valid(data) {
+		panic(RT.NewError("Text message must be valid UTF-8"))
+	}
+	PanicOnErr(c.writeFrame(op, data))
+}
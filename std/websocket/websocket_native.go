@@ -0,0 +1,275 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unsafe"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	WebSocket struct {
+		*Conn
+		hash uint32
+	}
+	options struct {
+		buffer         int
+		pingInterval   time.Duration
+		maxMessageSize int
+	}
+)
+
+var webSocketType *Type
+
+func MakeWebSocket(conn *Conn) WebSocket {
+	res := WebSocket{conn, 0}
+	res.hash = HashPtr(uintptr(unsafe.Pointer(conn)))
+	return res
+}
+
+func (ws WebSocket) ToString(escape bool) string {
+	return "#object[WebSocket]"
+}
+
+func (ws WebSocket) Equals(other interface{}) bool {
+	if otherWs, ok := other.(WebSocket); ok {
+		return ws.Conn == otherWs.Conn
+	}
+	return false
+}
+
+func (ws WebSocket) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (ws WebSocket) GetType() *Type {
+	return webSocketType
+}
+
+func (ws WebSocket) Hash() uint32 {
+	return ws.hash
+}
+
+func (ws WebSocket) WithInfo(info *ObjectInfo) Object {
+	return ws
+}
+
+func EnsureWebSocket(args []Object, index int) WebSocket {
+	switch c := args[index].(type) {
+	case WebSocket:
+		return c
+	default:
+		panic(RT.NewArgTypeError(index, c, "WebSocket"))
+	}
+}
+
+func ExtractWebSocket(args []Object, index int) *Conn {
+	return EnsureWebSocket(args, index).Conn
+}
+
+func getInt(m Map, k string, def int) int {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return AssertInt(v, k+" must be an integer").I
+	}
+	return def
+}
+
+func extractOptions(opts Map) *options {
+	return &options{
+		buffer:         getInt(opts, "buffer", 16),
+		pingInterval:   time.Duration(getInt(opts, "ping-interval", 0)),
+		maxMessageSize: getInt(opts, "max-message-size", 32<<20),
+	}
+}
+
+func headerContains(header http.Header, name string, value string) bool {
+	for _, v := range header[http.CanonicalHeaderKey(name)] {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isUpgradeRequest(req *http.Request) bool {
+	return req.Method == "GET" &&
+		headerContains(req.Header, "Connection", "upgrade") &&
+		headerContains(req.Header, "Upgrade", "websocket")
+}
+
+// IsUpgradeRequest returns true if request map (as passed to
+// joker.http/start-server handlers) is a websocket handshake request.
+func IsUpgradeRequest(request Map) bool {
+	ok, m := request.Get(MakeKeyword("request-method"))
+	if !ok || !m.Equals(MakeKeyword("get")) {
+		return false
+	}
+	ok, h := request.Get(MakeKeyword("headers"))
+	if !ok {
+		return false
+	}
+	header := http.Header{}
+	headers := AssertMap(h, "headers must be a map")
+	for _, name := range []string{"connection", "upgrade"} {
+		if ok, v := headers.Get(MakeString(name)); ok {
+			header.Add(name, v.ToString(false))
+		}
+	}
+	return headerContains(header, "Connection", "upgrade") && headerContains(header, "Upgrade", "websocket")
+}
+
+// Upgrade completes websocket handshake for req and calls onOpen with
+// the connection. Responds with 400 status if req is not a websocket
+// handshake request.
+func Upgrade(rt *Runtime, w http.ResponseWriter, req *http.Request, onOpen Callable, opts Map) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if !isUpgradeRequest(req) || key == "" {
+		http.Error(w, "Not a websocket handshake", http.StatusBadRequest)
+		return
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported websocket version", http.StatusUpgradeRequired)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(rt.NewError("HTTP connection doesn't support websocket upgrade"))
+	}
+	conn, rw, err := hijacker.Hijack()
+	PanicOnErr(err)
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		panic(rt.NewError(err.Error()))
+	}
+	if opts == nil {
+		opts = EmptyArrayMap()
+	}
+	onOpen.Call(rt, []Object{MakeWebSocket(newConn(conn, rw.Reader, false, extractOptions(opts)))})
+}
+
+func connect(rawURL string, opts Map) Object {
+	u, err := url.Parse(rawURL)
+	PanicOnErr(err)
+	var tlsConfig *tls.Config
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		tlsConfig = &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: ToBool(getObject(opts, "insecure?")),
+		}
+	default:
+		panic(RT.NewError("Websocket URL scheme must be ws or wss, got " + u.Scheme))
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		if tlsConfig != nil {
+			addr += ":443"
+		} else {
+			addr += ":80"
+		}
+	}
+	dialer := &net.Dialer{Timeout: time.Duration(getInt(opts, "timeout", 0))}
+	var conn net.Conn
+	if tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	PanicOnErr(err)
+	ok := false
+	defer func() {
+		if !ok {
+			conn.Close()
+		}
+	}()
+	if dialer.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+	key := newKey()
+	req := &http.Request{
+		Method:     "GET",
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       u.Host,
+	}
+	if h, ok := getObject(opts, "headers").(Map); ok {
+		for iter := h.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			req.Header.Add(p.Key.ToString(false), p.Value.ToString(false))
+		}
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	PanicOnErr(req.Write(conn))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	PanicOnErr(err)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		panic(RT.NewError("Websocket handshake failed with status " + resp.Status))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		panic(RT.NewError("Websocket handshake failed: invalid Sec-WebSocket-Accept header"))
+	}
+	conn.SetDeadline(time.Time{})
+	ok = true
+	return MakeWebSocket(newConn(conn, br, true, extractOptions(opts)))
+}
+
+func getObject(m Map, k string) Object {
+	if ok, v := m.Get(MakeKeyword(k)); ok {
+		return v
+	}
+	return NIL
+}
+
+func messages(conn *Conn) Object {
+	return conn.messages
+}
+
+func send(conn *Conn, data []byte, t string) Object {
+	switch t {
+	case ":text":
+		conn.Send(opText, data)
+	case ":binary":
+		conn.Send(opBinary, data)
+	default:
+		panic(RT.NewError("Message type must be :text or :binary, got " + t))
+	}
+	return NIL
+}
+
+func ping(conn *Conn, data string) Object {
+	if len(data) > 125 {
+		panic(RT.NewError("Ping data must be no longer than 125 bytes"))
+	}
+	conn.Send(opPing, []byte(data))
+	return NIL
+}
+
+func closeConn(conn *Conn, code int, reason string) Object {
+	conn.Close(code, reason)
+	return NIL
+}
+
+func init() {
+	webSocketType = RegType("WebSocket", (*WebSocket)(nil), "Wraps websocket connection")
+}
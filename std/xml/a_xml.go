@@ -0,0 +1,112 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package xml
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+var __emit__P ProcFn = __emit_
+var emit_ Proc = Proc{Fn: __emit__P, Name: "emit_", Package: "std/xml"}
+
+func __emit_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 2:
+		e := ExtractMap(_args, 0)
+		w := ExtractIOWriter(_args, 1)
+		_res := emit(e, w, EmptyArrayMap())
+		return _res
+
+	case _c == 3:
+		e := ExtractMap(_args, 0)
+		w := ExtractIOWriter(_args, 1)
+		opts := ExtractMap(_args, 2)
+		_res := emit(e, w, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __emit_str__P ProcFn = __emit_str_
+var emit_str_ Proc = Proc{Fn: __emit_str__P, Name: "emit_str_", Package: "std/xml"}
+
+func __emit_str_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		e := ExtractMap(_args, 0)
+		_res := emitString(e, EmptyArrayMap())
+		return MakeString(_res)
+
+	case _c == 2:
+		e := ExtractMap(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := emitString(e, opts)
+		return MakeString(_res)
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __parse__P ProcFn = __parse_
+var parse_ Proc = Proc{Fn: __parse__P, Name: "parse_", Package: "std/xml"}
+
+func __parse_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		rdr := ExtractIOReader(_args, 0)
+		_res := parse(_rt, rdr, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		rdr := ExtractIOReader(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := parse(_rt, rdr, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+var __parse_string__P ProcFn = __parse_string_
+var parse_string_ Proc = Proc{Fn: __parse_string__P, Name: "parse_string_", Package: "std/xml"}
+
+func __parse_string_(_rt *Runtime, _args []Object) Object {
+	_c := len(_args)
+	switch {
+	case _c == 1:
+		s := ExtractString(_args, 0)
+		_res := parseString(_rt, s, EmptyArrayMap())
+		return _res
+
+	case _c == 2:
+		s := ExtractString(_args, 0)
+		opts := ExtractMap(_args, 1)
+		_res := parseString(_rt, s, opts)
+		return _res
+
+	default:
+		PanicArity(_c)
+	}
+	return NIL
+}
+
+func Init() {
+
+	InternsOrThunks()
+}
+
+var xmlNamespace = GLOBAL_ENV.EnsureLib(MakeSymbol("joker.xml"))
+
+func init() {
+	xmlNamespace.Lazy = Init
+}
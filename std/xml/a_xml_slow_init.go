@@ -0,0 +1,73 @@
+// This file is generated by generate-std.joke script. Do not edit manually!
+
+package xml
+
+import (
+	"fmt"
+	. "github.com/candid82/joker/core"
+	"os"
+)
+
+func InternsOrThunks() {
+	if VerbosityLevel > 0 {
+		fmt.Fprintln(os.Stderr, "Lazily running slow version of xml.InternsOrThunks().")
+	}
+	xmlNamespace.ResetMeta(MakeMeta(nil, `Parses and emits XML.
+
+         Elements are represented as maps with the following keys:
+         :tag (keyword), :attrs (map of keywords to strings) and :content
+         (sequence of elements and strings), as in clojure.data.xml.
+         Namespace prefixes become keyword namespaces, e.g. <soap:Body> is
+         parsed as {:tag :soap/Body ...} and xmlns:soap attribute as :xmlns/soap.
+
+         Example:
+
+         user=> (joker.xml/parse-string "<a href=\"/\">Home <b>page</b></a>")
+         {:tag :a, :attrs {:href "/"}, :content ("Home " {:tag :b, :attrs {}, :content ("page")})}
+         user=> (joker.xml/emit-str {:tag :a :attrs {:href "/"} :content ["Home"]} {:declaration? false})
+         "<a href=\"/\">Home</a>"
+
+         See also joker.core/xml-seq.`, "1.0"))
+
+	xmlNamespace.InternVar("emit", emit_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("e"), MakeSymbol("w")), NewVectorFrom(MakeSymbol("e"), MakeSymbol("w"), MakeSymbol("opts"))),
+			`Writes element e as XML to w (IOWriter, e.g. as returned by joker.os/create).
+  Element's content may contain elements, strings, nested sequences
+  (which are flattened) and other values (which are converted to strings);
+  nils are skipped, as are attributes with nil values.
+  Keyword namespaces are written as prefixes, e.g. :soap/Body as soap:Body.
+  Optional opts map may have the following keys:
+  - indent (int, number of spaces to indent nested elements with;
+    by default no indentation or newlines are added)
+  - declaration? (boolean, whether to write XML declaration; defaults to true)
+  - namespaces (map of prefixes to namespace URIs to declare on the root element,
+    empty prefix declaring the default namespace,
+    keys may be strings, keywords or symbols,
+    e.g. {"" "http://maven.apache.org/POM/4.0.0"}).`, "1.0"))
+
+	xmlNamespace.InternVar("emit-str", emit_str_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("e")), NewVectorFrom(MakeSymbol("e"), MakeSymbol("opts"))),
+			`Returns element e as XML string. opts are as in emit.`, "1.0").Plus(MakeKeyword("tag"), String{S: "String"}))
+
+	xmlNamespace.InternVar("parse", parse_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("rdr")), NewVectorFrom(MakeSymbol("rdr"), MakeSymbol("opts"))),
+			`Parses XML from rdr (IOReader, e.g. as returned by joker.os/open)
+  and returns the root element.
+  Parsing is lazy: contents of elements are lazy sequences that read
+  rdr as they are realized, so syntax errors may be thrown while traversing
+  the result. Comments, processing instructions and directives (e.g. DOCTYPE)
+  are skipped.
+  Optional opts map may have the following keys:
+  - preserve-whitespace? (boolean, if true, whitespace-only strings are kept
+    in elements' content; defaults to false).`, "1.0"))
+
+	xmlNamespace.InternVar("parse-string", parse_string_,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("s")), NewVectorFrom(MakeSymbol("s"), MakeSymbol("opts"))),
+			`Parses XML from string s and returns the root element.
+  opts are as in parse.`, "1.0"))
+
+}
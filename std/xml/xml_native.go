@@ -0,0 +1,293 @@
+package xml
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	. "github.com/candid82/joker/core"
+)
+
+type (
+	// event is a start of an element, an end of an element, a text or
+	// the end of the document. Other tokens (comments, processing instructions,
+	// directives) are skipped.
+	event struct {
+		start *xml.StartElement
+		end   bool
+		text  string
+		eof   bool
+	}
+	// reader reads events from the decoder. It checks that end elements
+	// match start elements, since RawToken doesn't.
+	reader struct {
+		mu                 sync.Mutex // guards realization of nodes
+		dec                *xml.Decoder
+		stack              []xml.Name
+		preserveWhitespace bool
+	}
+	// node is an element of the lazily read, memoized list of events.
+	// Elements and their contents are built on top of this list,
+	// so that it's read only once, no matter how the resulting tree is traversed.
+	node struct {
+		r        *reader
+		realized bool
+		ev       event
+		next     *node
+	}
+)
+
+var (
+	kwTag     = MakeKeyword("tag")
+	kwAttrs   = MakeKeyword("attrs")
+	kwContent = MakeKeyword("content")
+)
+
+func (r *reader) readEvent() event {
+	for {
+		t, err := r.dec.RawToken()
+		if err == io.EOF {
+			if len(r.stack) > 0 {
+				panic(RT.NewError("XML syntax error: unexpected EOF, element <" + qualifiedName(r.stack[len(r.stack)-1]) + "> is not closed"))
+			}
+			return event{eof: true}
+		}
+		PanicOnErr(err)
+		switch t := t.(type) {
+		case xml.StartElement:
+			t = t.Copy()
+			r.stack = append(r.stack, t.Name)
+			return event{start: &t}
+		case xml.EndElement:
+			if len(r.stack) == 0 {
+				panic(RT.NewError("XML syntax error: unexpected end element </" + qualifiedName(t.Name) + ">"))
+			}
+			if top := r.stack[len(r.stack)-1]; top != t.Name {
+				panic(RT.NewError("XML syntax error: element <" + qualifiedName(top) + "> closed by </" + qualifiedName(t.Name) + ">"))
+			}
+			r.stack = r.stack[:len(r.stack)-1]
+			return event{end: true}
+		case xml.CharData:
+			return event{text: string(t)}
+		}
+	}
+}
+
+func (n *node) event() event {
+	n.r.mu.Lock()
+	defer n.r.mu.Unlock()
+	if !n.realized {
+		n.ev = n.r.readEvent()
+		n.next = &node{r: n.r}
+		n.realized = true
+	}
+	return n.ev
+}
+
+// readText returns the text of consecutive text events starting at n
+// (e.g. text interrupted by comments or CDATA sections) and the node following them.
+func readText(n *node) (string, *node) {
+	var b strings.Builder
+	for ev := n.event(); ev.start == nil && !ev.end && !ev.eof; ev = n.event() {
+		b.WriteString(ev.text)
+		n = n.next
+	}
+	return b.String(), n
+}
+
+// skipElement returns the node following the end of the element
+// whose content starts at n.
+func skipElement(n *node) *node {
+	for depth := 1; ; n = n.next {
+		ev := n.event()
+		switch {
+		case ev.start != nil:
+			depth++
+		case ev.end:
+			depth--
+			if depth == 0 {
+				return n.next
+			}
+		case ev.eof:
+			return n
+		}
+	}
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func nameToKeyword(name xml.Name) Keyword {
+	if name.Space == "" {
+		return MakeKeyword(name.Local)
+	}
+	return MakeKeyword(name.Space + "/" + name.Local)
+}
+
+func makeElement(start *xml.StartElement, content Object) Object {
+	attrs := EmptyArrayMap()
+	for _, attr := range start.Attr {
+		attrs.Set(nameToKeyword(attr.Name), MakeString(attr.Value))
+	}
+	res := EmptyArrayMap()
+	res.Add(kwTag, nameToKeyword(start.Name))
+	res.Add(kwAttrs, attrs)
+	res.Add(kwContent, content)
+	return res
+}
+
+// contentSeq returns lazy sequence of nodes (elements and strings)
+// starting at the node returned by start up to the end of the enclosing element.
+// start is only called when the sequence is realized.
+func contentSeq(rt *Runtime, start func() *node) *LazySeq {
+	var c = func(rt *Runtime, args []Object) Object {
+		text, n := readText(start())
+		if text != "" && (n.r.preserveWhitespace || strings.TrimSpace(text) != "") {
+			return NewConsSeq(MakeString(text), contentSeq(rt, func() *node { return n }))
+		}
+		ev := n.event()
+		if ev.start == nil {
+			return EmptyList
+		}
+		el := makeElement(ev.start, contentSeq(rt, func() *node { return n.next }))
+		return NewConsSeq(el, contentSeq(rt, func() *node { return skipElement(n.next) }))
+	}
+	return NewLazySeq(rt, Proc{Fn: c})
+}
+
+func parse(rt *Runtime, rdr io.Reader, opts Map) Object {
+	r := &reader{dec: xml.NewDecoder(rdr)}
+	if ok, p := opts.Get(MakeKeyword("preserve-whitespace?")); ok {
+		r.preserveWhitespace = ToBool(p)
+	}
+	n := &node{r: r}
+	for {
+		ev := n.event()
+		switch {
+		case ev.start != nil:
+			return makeElement(ev.start, contentSeq(rt, func() *node { return n.next }))
+		case ev.eof:
+			panic(rt.NewError("XML syntax error: no root element"))
+		}
+		n = n.next
+	}
+}
+
+func parseString(rt *Runtime, s string, opts Map) Object {
+	return parse(rt, strings.NewReader(s), opts)
+}
+
+type emitter struct {
+	enc        *xml.Encoder
+	namespaces []xml.Attr
+}
+
+func toName(obj Object) xml.Name {
+	switch obj := obj.(type) {
+	case String:
+		return xml.Name{Local: obj.S}
+	case Named:
+		// Namespace is a prefix here, which is written as a part of the name
+		// rather than translated into xmlns attribute by the encoder.
+		if obj.Namespace() == "" {
+			return xml.Name{Local: obj.Name()}
+		}
+		return xml.Name{Local: obj.Namespace() + ":" + obj.Name()}
+	default:
+		panic(RT.NewError("XML name must be a keyword, symbol or string, got " + obj.GetType().ToString(false)))
+	}
+}
+
+func toPrefix(obj Object) string {
+	switch obj := obj.(type) {
+	case String:
+		return obj.S
+	case Named:
+		return obj.Name()
+	default:
+		panic(RT.NewError("XML namespace prefix must be a keyword, symbol or string, got " + obj.GetType().ToString(false)))
+	}
+}
+
+func (e *emitter) emitElement(el Map) {
+	_, tag := el.Get(kwTag)
+	start := xml.StartElement{Name: toName(tag)}
+	start.Attr = append(start.Attr, e.namespaces...)
+	e.namespaces = nil
+	if ok, attrs := el.Get(kwAttrs); ok && !attrs.Equals(NIL) {
+		for iter := AssertMap(attrs, "XML attrs must be a map").Iter(); iter.HasNext(); {
+			p := iter.Next()
+			if p.Value.Equals(NIL) {
+				continue
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: toName(p.Key), Value: p.Value.ToString(false)})
+		}
+	}
+	PanicOnErr(e.enc.EncodeToken(start))
+	if ok, content := el.Get(kwContent); ok {
+		e.emitNode(content)
+	}
+	PanicOnErr(e.enc.EncodeToken(start.End()))
+}
+
+func (e *emitter) emitNode(obj Object) {
+	switch obj := obj.(type) {
+	case Nil:
+	case String:
+		PanicOnErr(e.enc.EncodeToken(xml.CharData(obj.S)))
+	case Map:
+		if ok, _ := obj.Get(kwTag); !ok {
+			panic(RT.NewError("XML element must have :tag key, got " + obj.ToString(true)))
+		}
+		e.emitElement(obj)
+	case Seqable:
+		for s := obj.Seq(); !s.IsEmpty(); s = s.Rest() {
+			e.emitNode(s.First())
+		}
+	default:
+		PanicOnErr(e.enc.EncodeToken(xml.CharData(obj.ToString(false))))
+	}
+}
+
+func emit(el Map, w io.Writer, opts Map) Object {
+	e := &emitter{enc: xml.NewEncoder(w)}
+	indent := 0
+	if ok, i := opts.Get(MakeKeyword("indent")); ok {
+		indent = AssertInt(i, "indent must be an integer").I
+		e.enc.Indent("", strings.Repeat(" ", indent))
+	}
+	if ok, ns := opts.Get(MakeKeyword("namespaces")); ok {
+		for iter := AssertMap(ns, "namespaces must be a map").Iter(); iter.HasNext(); {
+			p := iter.Next()
+			name := "xmlns"
+			if prefix := toPrefix(p.Key); prefix != "" {
+				name += ":" + prefix
+			}
+			e.namespaces = append(e.namespaces, xml.Attr{Name: xml.Name{Local: name}, Value: p.Value.ToString(false)})
+		}
+		sort.Slice(e.namespaces, func(i, j int) bool { return e.namespaces[i].Name.Local < e.namespaces[j].Name.Local })
+	}
+	if ok, d := opts.Get(MakeKeyword("declaration?")); !ok || ToBool(d) {
+		decl := xml.Header
+		if indent == 0 {
+			decl = strings.TrimSuffix(decl, "\n")
+		}
+		_, err := io.WriteString(w, decl)
+		PanicOnErr(err)
+	}
+	e.emitNode(el)
+	PanicOnErr(e.enc.Flush())
+	return NIL
+}
+
+func emitString(el Map, opts Map) string {
+	var b strings.Builder
+	emit(el, &b, opts)
+	return b.String()
+}
@@ -0,0 +1,5 @@
+(ns fix-fn-literal)
+
+(def a #(do %))
+
+(def b (fn [x] (do x)))
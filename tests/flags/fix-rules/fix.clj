@@ -0,0 +1,15 @@
+(ns fix
+  (:require [foo.c :as c] ; c
+            [foo.b :refer [x]]
+            [foo.a :as a :refer [y z]]))
+
+(defn f
+  [v]
+  (if v (a/x (z)) nil))
+
+(defn g
+  []
+  (do (c/x "a
+  b")
+      (do (c/y)
+          (c/z))))
@@ -0,0 +1,9 @@
+(ns fix
+(:require [foo.b :as b]
+[foo.unused]))
+
+(b/x)
+
+(defn f
+[]
+(do (b/y)))
@@ -0,0 +1,9 @@
+(ns format
+(:require [foo.b :as b]))
+
+(defn f
+[x]   ; comment
+   (let [y #?(:clj 0x1F
+     :cljs 31)]
+ (b/x x
+  y)))
@@ -0,0 +1,9 @@
+(ns fixable
+  (:require [foo.z :as z :refer [x y]]
+            [foo.b :as b]))
+
+(defn f
+  [v]
+  (if v (z/k (y)) nil))
+
+(when (b/c) 1)
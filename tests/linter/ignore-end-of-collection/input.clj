@@ -0,0 +1,9 @@
+(ns ignore-end-of-collection)
+
+(def a [1 #_:joker/ignore])
+
+(let [q 1] (zzz))
+
+(defn f [x] (inc x) #_{:joker/ignore [:unused-binding]})
+
+(let [r 1] 2)
@@ -0,0 +1,16 @@
+(ns ^{:joker/lint {:rules {:if-without-else :error}}} ignore)
+
+(let [x 1] (if true 2))
+
+#_:joker/ignore
+(let [y 1] (g))
+
+^{:joker/ignore [:unused-binding]} (let [z 1] (h))
+
+(defn f [a] a)
+
+(defn g
+  []
+  #_{:joker/ignore [:wrong-arity]}
+  (f)
+  (f))
@@ -0,0 +1,9 @@
+(ns rule-levels)
+
+(let [x 1] (-> x))
+
+(defn f [a] a)
+
+(f)
+
+(do (f 1))